@@ -0,0 +1,158 @@
+package aggregator
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/datallboy/gonzb/internal/app"
+)
+
+const (
+	// search timeout for sources that do not configure their own.
+	defaultSourceTimeout = 10 * time.Second
+
+	// consecutive failures before a source is skipped, and how long the
+	// first skip lasts; each further failure doubles it up to the max.
+	sourceFailureThreshold = 3
+	sourceBackoffBase      = 30 * time.Second
+	sourceBackoffMax       = 30 * time.Minute
+)
+
+// RetryAfterError is returned by sources whose upstream asked not to be
+// called again for a while, through Retry-After or an API limit error. The
+// manager skips the source until Wait has passed.
+type RetryAfterError struct {
+	Wait time.Duration
+	Err  error
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%v (retry after %s)", e.Err, e.Wait)
+}
+
+func (e *RetryAfterError) Unwrap() error { return e.Err }
+
+// timeoutSource is implemented by sources with their own search timeout.
+type timeoutSource interface {
+	SearchTimeout() time.Duration
+}
+
+// sourceHealth tracks search outcomes for one source and decides when it is
+// backed off.
+type sourceHealth struct {
+	mu sync.Mutex
+
+	name    string
+	timeout time.Duration
+
+	searches            int64
+	failures            int64
+	consecutiveFailures int
+	totalLatency        time.Duration
+	lastLatency         time.Duration
+	lastSuccess         time.Time
+	lastFailure         time.Time
+	lastError           string
+	backoffUntil        time.Time
+}
+
+func newSourceHealth(src catalogSource) *sourceHealth {
+	timeout := defaultSourceTimeout
+	if ts, ok := src.(timeoutSource); ok && ts.SearchTimeout() > 0 {
+		timeout = ts.SearchTimeout()
+	}
+	return &sourceHealth{name: src.Name(), timeout: timeout}
+}
+
+// available reports whether the source may be searched at now.
+func (h *sourceHealth) available(now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return !now.Before(h.backoffUntil)
+}
+
+func (h *sourceHealth) recordSuccess(now time.Time, latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.searches++
+	h.totalLatency += latency
+	h.lastLatency = latency
+	h.lastSuccess = now
+	h.consecutiveFailures = 0
+	h.backoffUntil = time.Time{}
+}
+
+// recordFailure counts a failed search and returns how long the source is
+// now backed off for, or 0 when it stays available.
+func (h *sourceHealth) recordFailure(now time.Time, latency time.Duration, err error) time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.searches++
+	h.failures++
+	h.consecutiveFailures++
+	h.totalLatency += latency
+	h.lastLatency = latency
+	h.lastFailure = now
+	h.lastError = err.Error()
+
+	wait := time.Duration(0)
+	if h.consecutiveFailures >= sourceFailureThreshold {
+		wait = sourceBackoffBase
+		for i := sourceFailureThreshold; i < h.consecutiveFailures && wait < sourceBackoffMax; i++ {
+			wait *= 2
+		}
+		wait = min(wait, sourceBackoffMax)
+	}
+
+	var retryAfter *RetryAfterError
+	if errors.As(err, &retryAfter) && retryAfter.Wait > wait {
+		wait = retryAfter.Wait
+	}
+
+	if wait > 0 {
+		h.backoffUntil = now.Add(wait)
+	}
+	return wait
+}
+
+func (h *sourceHealth) snapshot(now time.Time) app.AggregatorSourceHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	out := app.AggregatorSourceHealth{
+		Name:                h.name,
+		State:               "healthy",
+		TimeoutMS:           h.timeout.Milliseconds(),
+		Searches:            h.searches,
+		Failures:            h.failures,
+		ConsecutiveFailures: h.consecutiveFailures,
+		LastLatencyMS:       h.lastLatency.Milliseconds(),
+		LastError:           h.lastError,
+	}
+	if h.searches > 0 {
+		out.ErrorRate = float64(h.failures) / float64(h.searches)
+		out.AvgLatencyMS = (h.totalLatency / time.Duration(h.searches)).Milliseconds()
+	}
+	if !h.lastSuccess.IsZero() {
+		t := h.lastSuccess.UTC()
+		out.LastSuccessAt = &t
+	}
+	if !h.lastFailure.IsZero() {
+		t := h.lastFailure.UTC()
+		out.LastFailureAt = &t
+	}
+
+	switch {
+	case now.Before(h.backoffUntil):
+		out.State = "backoff"
+		t := h.backoffUntil.UTC()
+		out.BackoffUntil = &t
+	case h.consecutiveFailures > 0:
+		out.State = "degraded"
+	}
+	return out
+}
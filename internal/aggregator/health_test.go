@@ -0,0 +1,107 @@
+package aggregator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/datallboy/gonzb/internal/app"
+	"github.com/datallboy/gonzb/internal/domain"
+)
+
+type failingSource struct {
+	fakeSource
+	err error
+}
+
+func (s *failingSource) Search(_ context.Context, req SearchRequest) ([]*domain.Release, int, error) {
+	s.requests = append(s.requests, req)
+	return nil, 0, s.err
+}
+
+func TestSourceHealthBacksOffAfterRepeatedFailures(t *testing.T) {
+	now := time.Now()
+	h := &sourceHealth{name: "idx", timeout: defaultSourceTimeout}
+	boom := errors.New("boom")
+
+	for i := 1; i < sourceFailureThreshold; i++ {
+		if wait := h.recordFailure(now, time.Second, boom); wait != 0 {
+			t.Fatalf("failure %d: expected no backoff yet, got %s", i, wait)
+		}
+	}
+	if got := h.snapshot(now).State; got != "degraded" {
+		t.Fatalf("expected degraded below threshold, got %q", got)
+	}
+
+	if wait := h.recordFailure(now, time.Second, boom); wait != sourceBackoffBase {
+		t.Fatalf("expected first backoff of %s, got %s", sourceBackoffBase, wait)
+	}
+	if wait := h.recordFailure(now, time.Second, boom); wait != 2*sourceBackoffBase {
+		t.Fatalf("expected backoff to double, got %s", wait)
+	}
+	if h.available(now) {
+		t.Fatal("expected source to be skipped while backed off")
+	}
+	for i := 0; i < 20; i++ {
+		h.recordFailure(now, time.Second, boom)
+	}
+	if got := h.snapshot(now); got.State != "backoff" || got.BackoffUntil == nil || got.BackoffUntil.Sub(now) != sourceBackoffMax {
+		t.Fatalf("expected backoff capped at %s, got %+v", sourceBackoffMax, got)
+	}
+
+	h.recordSuccess(now, 100*time.Millisecond)
+	snap := h.snapshot(now)
+	if !h.available(now) || snap.State != "healthy" || snap.ConsecutiveFailures != 0 || snap.LastSuccessAt == nil {
+		t.Fatalf("expected success to clear backoff, got %+v", snap)
+	}
+	if snap.Searches != 25 || snap.Failures != 24 || snap.LastError != "boom" {
+		t.Fatalf("unexpected counters: %+v", snap)
+	}
+}
+
+func TestSourceHealthHonoursRetryAfter(t *testing.T) {
+	now := time.Now()
+	h := &sourceHealth{name: "idx", timeout: defaultSourceTimeout}
+
+	err := &RetryAfterError{Wait: time.Hour, Err: errors.New("limit reached")}
+	if wait := h.recordFailure(now, time.Second, err); wait != time.Hour {
+		t.Fatalf("expected retry-after wait on first failure, got %s", wait)
+	}
+	if h.available(now.Add(59 * time.Minute)) {
+		t.Fatal("expected source to stay skipped until retry-after passes")
+	}
+	if !h.available(now.Add(time.Hour)) {
+		t.Fatal("expected source to be available after retry-after")
+	}
+}
+
+func TestSearchAllWithRequestSkipsBackedOffSources(t *testing.T) {
+	dead := &failingSource{fakeSource: fakeSource{name: "dead"}, err: errors.New("connection refused")}
+	live := &fakeSource{name: "live", releases: []*domain.Release{{GUID: "a", Source: "live"}}}
+	m := NewManager(nil, nopLogger{}, false, false)
+	m.AddSource(dead)
+	m.AddSource(live)
+
+	req := app.SearchRequest{Type: "search", Query: "show"}
+	for i := 0; i < sourceFailureThreshold+2; i++ {
+		results, _, err := m.SearchAllWithRequest(context.Background(), req)
+		if err != nil || len(results) != 1 {
+			t.Fatalf("search %d: %+v (%v)", i, results, err)
+		}
+	}
+	if len(dead.requests) != sourceFailureThreshold {
+		t.Fatalf("expected dead source to be skipped after %d failures, got %d calls", sourceFailureThreshold, len(dead.requests))
+	}
+
+	health := m.SourceHealth()
+	if len(health) != 2 || health[0].Name != "dead" || health[1].Name != "live" {
+		t.Fatalf("expected health sorted by name, got %+v", health)
+	}
+	if health[0].State != "backoff" || health[0].ErrorRate != 1 || health[0].LastError != "connection refused" {
+		t.Fatalf("unexpected dead source health: %+v", health[0])
+	}
+	if health[1].State != "healthy" || health[1].Searches != int64(sourceFailureThreshold+2) {
+		t.Fatalf("unexpected live source health: %+v", health[1])
+	}
+}
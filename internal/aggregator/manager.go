@@ -5,32 +5,55 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/datallboy/gonzb/internal/app"
+	"github.com/datallboy/gonzb/internal/categories/newsnab"
 	"github.com/datallboy/gonzb/internal/domain"
 )
 
+const (
+	// page size used when a search does not ask for one.
+	defaultSearchLimit = 100
+	// how long a merged feed page is served before the sources are asked again.
+	feedCacheTTL = 2 * time.Minute
+	// requests made to one source to cover offset+limit when it caps page sizes.
+	maxSourcePages = 10
+)
+
+// feedPage is one cached page of an RSS-style feed.
+type feedPage struct {
+	releases []*domain.Release
+	total    int
+	expires  time.Time
+}
+
 type Manager struct {
 	mu                       sync.RWMutex
 	sources                  map[string]catalogSource
+	health                   map[string]*sourceHealth
 	store                    store
 	logger                   logger
 	cacheEnabled             bool
 	searchPersistenceEnabled bool
 	recentResults            map[string]*domain.Release
+	feeds                    map[string]feedPage
 }
 
 func NewManager(s store, l logger, cacheEnabled bool, searchPersistenceEnabled bool) *Manager {
 	return &Manager{
 		sources:                  make(map[string]catalogSource),
+		health:                   make(map[string]*sourceHealth),
 		store:                    s,
 		logger:                   l,
 		cacheEnabled:             cacheEnabled,
 		searchPersistenceEnabled: searchPersistenceEnabled,
 		recentResults:            make(map[string]*domain.Release),
+		feeds:                    make(map[string]feedPage),
 	}
 }
 
@@ -39,19 +62,60 @@ func (m *Manager) AddSource(src catalogSource) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.sources[src.Name()] = src
+	m.health[src.Name()] = newSourceHealth(src)
+}
+
+// SourceHealth reports search outcomes and backoff state per source, by name.
+func (m *Manager) SourceHealth() []app.AggregatorSourceHealth {
+	now := time.Now()
+
+	m.mu.RLock()
+	out := make([]app.AggregatorSourceHealth, 0, len(m.health))
+	for _, h := range m.health {
+		out = append(out, h.snapshot(now))
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
 }
 
 // CHANGED: backward-compatible wrapper for older callers.
 func (m *Manager) SearchAll(ctx context.Context, query string) ([]*domain.Release, error) {
-	return m.SearchAllWithRequest(ctx, app.SearchRequest{
+	results, _, err := m.SearchAllWithRequest(ctx, app.SearchRequest{
 		Type:  string(SearchTypeGeneric),
 		Query: query,
 	})
+	return results, err
 }
 
 // CHANGED: structured search request path for real Newznab movie/tvsearch support.
-func (m *Manager) SearchAllWithRequest(ctx context.Context, req app.SearchRequest) ([]*domain.Release, error) {
+// Every source is paged through until it has returned its first offset+limit
+// results, which are merged, sorted by date and then cut to the requested
+// page; the total is the largest count any source or the merged set reports,
+// since sources overlap.
+// Feed requests (a generic search with no query) are cached per category
+// filter for feedCacheTTL.
+func (m *Manager) SearchAllWithRequest(ctx context.Context, req app.SearchRequest) ([]*domain.Release, int, error) {
 	internalReq := toSearchRequest(req)
+	now := time.Now()
+
+	// pages only line up across sources when each is read from the start.
+	sourceReq := internalReq
+	sourceReq.Offset = 0
+	if internalReq.Limit > 0 {
+		sourceReq.Limit = internalReq.Offset + internalReq.Limit
+	}
+
+	feedKey := ""
+	if internalReq.IsFeed() {
+		feedKey = internalReq.FeedKey()
+		if releases, total, ok := m.cachedFeed(feedKey, now); ok {
+			return releases, total, nil
+		}
+	}
+
+	cacheTotal := 0
 	merged := make(map[string]*domain.Release, 256)
 	order := make([]string, 0, 256)
 
@@ -76,43 +140,66 @@ func (m *Manager) SearchAllWithRequest(ctx context.Context, req app.SearchReques
 		order = append(order, in.ID)
 	}
 
-	// Only generic text search uses the local cache search path for now.
-	if m.searchPersistenceEnabled && internalReq.Type == SearchTypeGeneric && internalReq.Query != "" {
-		cacheResults, err := m.store.SearchAggregatorReleaseCache(ctx, internalReq.Query, 100)
+	// Only generic text search and feeds use the local cache search path for now.
+	if m.searchPersistenceEnabled && internalReq.Type == SearchTypeGeneric {
+		cacheResults, total, err := m.store.SearchAggregatorReleaseCache(ctx, sourceReq.CacheQuery(now))
 		if err != nil {
 			m.logger.Warn("Failed to search aggregator_release_cache: %v", err)
 		} else {
+			cacheTotal = total
 			for _, rel := range cacheResults {
 				addOrMerge(rel, false)
 			}
 		}
 	}
 
+	type sourcePage struct {
+		releases []*domain.Release
+		total    int
+	}
+
 	var wg sync.WaitGroup
-	resultsChan := make(chan []*domain.Release, len(m.sources))
+	resultsChan := make(chan sourcePage, len(m.sources))
 
 	m.mu.RLock()
-	for _, src := range m.sources {
+	for name, src := range m.sources {
+		health := m.health[name]
+		if !health.available(now) {
+			m.logger.Debug("Skipping indexer %s while it is backed off", name)
+			continue
+		}
+
 		wg.Add(1)
-		go func(s catalogSource) {
+		go func(s catalogSource, health *sourceHealth) {
 			defer wg.Done()
 
-			searchCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			searchCtx, cancel := context.WithTimeout(ctx, health.timeout)
 			defer cancel()
 
-			res, err := s.Search(searchCtx, internalReq)
+			started := time.Now()
+			res, total, err := searchPages(searchCtx, s, sourceReq)
+			latency := time.Since(started)
 			if err != nil {
-				m.logger.Error("Indexer %s error: %v", s.Name(), err)
+				// a caller that gave up is not the source's fault.
+				if ctx.Err() != nil {
+					return
+				}
+				if wait := health.recordFailure(time.Now(), latency, err); wait > 0 {
+					m.logger.Warn("Indexer %s error, backing off for %s: %v", s.Name(), wait, err)
+				} else {
+					m.logger.Error("Indexer %s error: %v", s.Name(), err)
+				}
 				return
 			}
+			health.recordSuccess(time.Now(), latency)
 
 			for _, r := range res {
 				if r.ID == "" {
 					r.ID = domain.GenerateCompositeID(r.Source, r.GUID)
 				}
 			}
-			resultsChan <- res
-		}(src)
+			resultsChan <- sourcePage{releases: res, total: total}
+		}(src, health)
 	}
 	m.mu.RUnlock()
 
@@ -121,20 +208,36 @@ func (m *Manager) SearchAllWithRequest(ctx context.Context, req app.SearchReques
 		close(resultsChan)
 	}()
 
-	allRemote := make([]*domain.Release, 0, 256)
-	for res := range resultsChan {
-		allRemote = append(allRemote, res...)
-		for _, rel := range res {
+	sourceTotal := 0
+	for page := range resultsChan {
+		sourceTotal = max(sourceTotal, page.total)
+		for _, rel := range page.releases {
 			addOrMerge(rel, true)
 		}
 	}
 
+	// sources that ignore a filter upstream are held to it here.
+	categories := make(map[int]bool, len(internalReq.Categories))
+	for _, id := range internalReq.Categories {
+		categories[id] = true
+	}
+	postedAfter := internalReq.PostedAfter(now)
+
 	allResults := make([]*domain.Release, 0, len(order))
 	for _, id := range order {
-		if rel := merged[id]; rel != nil {
-			allResults = append(allResults, rel)
+		rel := merged[id]
+		if rel == nil || !matchesCategories(rel, categories) {
+			continue
+		}
+		if !postedAfter.IsZero() && !rel.PublishDate.IsZero() && rel.PublishDate.Before(postedAfter) {
+			continue
 		}
+		allResults = append(allResults, rel)
 	}
+	sort.SliceStable(allResults, func(i, j int) bool {
+		return allResults[i].PublishDate.After(allResults[j].PublishDate)
+	})
+	total := max(sourceTotal, cacheTotal, len(allResults))
 
 	m.mu.Lock()
 	m.recentResults = make(map[string]*domain.Release, len(allResults))
@@ -152,7 +255,105 @@ func (m *Manager) SearchAllWithRequest(ctx context.Context, req app.SearchReques
 		}
 	}
 
-	return allResults, nil
+	page := allResults[min(internalReq.Offset, len(allResults)):]
+	if internalReq.Limit > 0 && len(page) > internalReq.Limit {
+		page = page[:internalReq.Limit]
+	}
+
+	if feedKey != "" {
+		m.storeFeed(feedKey, page, total, now)
+	}
+
+	return page, total, nil
+}
+
+// searchPages reads req.Limit results from the start of a source. Sources
+// may return shorter pages than asked for (newznab indexers cap the limit),
+// so it keeps asking from the next offset until the limit is covered, the
+// source runs out or maxSourcePages requests have been made.
+func searchPages(ctx context.Context, src catalogSource, req SearchRequest) ([]*domain.Release, int, error) {
+	if req.Limit <= 0 {
+		return src.Search(ctx, req)
+	}
+
+	want := req.Limit
+	var out []*domain.Release
+	total := 0
+	for range maxSourcePages {
+		req.Offset = len(out)
+		req.Limit = want - len(out)
+		res, pageTotal, err := src.Search(ctx, req)
+		if err != nil {
+			return nil, 0, err
+		}
+		total = max(total, pageTotal)
+		out = append(out, res...)
+		if len(res) == 0 || len(out) >= want || len(out) >= total {
+			break
+		}
+	}
+	return out, max(total, len(out)), nil
+}
+
+// cachedFeed returns copies of a feed page that has not expired yet. The
+// releases are also made resolvable by id again, since a later search may
+// have replaced recentResults since the page was built.
+func (m *Manager) cachedFeed(key string, now time.Time) ([]*domain.Release, int, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	page, ok := m.feeds[key]
+	if !ok || !now.Before(page.expires) {
+		return nil, 0, false
+	}
+
+	out := make([]*domain.Release, 0, len(page.releases))
+	for _, rel := range page.releases {
+		out = append(out, cloneRelease(rel))
+		m.recentResults[rel.ID] = cloneRelease(rel)
+	}
+	return out, page.total, true
+}
+
+// storeFeed caches a feed page and drops any pages that have expired.
+func (m *Manager) storeFeed(key string, releases []*domain.Release, total int, now time.Time) {
+	page := feedPage{
+		releases: make([]*domain.Release, 0, len(releases)),
+		total:    total,
+		expires:  now.Add(feedCacheTTL),
+	}
+	for _, rel := range releases {
+		if rel != nil && rel.ID != "" {
+			page.releases = append(page.releases, cloneRelease(rel))
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for k, cached := range m.feeds {
+		if !now.Before(cached.expires) {
+			delete(m.feeds, k)
+		}
+	}
+	m.feeds[key] = page
+}
+
+// matchesCategories reports whether rel falls in one of the requested
+// category ids. A category that does not read as an id is left to the source
+// that returned it.
+func matchesCategories(rel *domain.Release, categories map[int]bool) bool {
+	if len(categories) == 0 {
+		return true
+	}
+	id, err := strconv.Atoi(strings.TrimSpace(rel.Category))
+	if err != nil {
+		parsed, ok := newsnab.ParseName(rel.Category)
+		if !ok {
+			return true
+		}
+		id = parsed
+	}
+	return categories[id]
 }
 
 // GetNZB handles retrieving nzb from cache or downloading from an indexer.
@@ -244,6 +445,75 @@ func (m *Manager) GetResultByID(ctx context.Context, id string) (*domain.Release
 	return m.store.GetAggregatorReleaseCacheByID(ctx, id)
 }
 
+// GetDetails returns the fullest view of a release the sources have. A
+// release whose source keeps no details is returned as it was found.
+func (m *Manager) GetDetails(ctx context.Context, id string) (*domain.Release, error) {
+	rel, err := m.GetResultByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, src := range m.detailSourcesFor(rel) {
+		details, err := src.Details(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if details != nil {
+			details.ID = id
+			return details, nil
+		}
+	}
+	return rel, nil
+}
+
+// GetNFO returns the stored NFO text of a release, or "" when no source
+// has one.
+func (m *Manager) GetNFO(ctx context.Context, id string) (string, error) {
+	rel, err := m.GetResultByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	for _, src := range m.detailSourcesFor(rel) {
+		text, err := src.NFO(ctx, id)
+		if err != nil {
+			return "", err
+		}
+		if text != "" {
+			return text, nil
+		}
+	}
+	return "", nil
+}
+
+// detailSourcesFor picks the sources to ask about rel: its own source when
+// it is known, otherwise every source that keeps details, by name.
+func (m *Manager) detailSourcesFor(rel *domain.Release) []detailSource {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if rel != nil {
+		if src, ok := m.sources[rel.Source].(detailSource); ok {
+			return []detailSource{src}
+		}
+		return nil
+	}
+
+	names := make([]string, 0, len(m.sources))
+	for name, src := range m.sources {
+		if _, ok := src.(detailSource); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	out := make([]detailSource, 0, len(names))
+	for _, name := range names {
+		out = append(out, m.sources[name].(detailSource))
+	}
+	return out
+}
+
 func cloneRelease(in *domain.Release) *domain.Release {
 	if in == nil {
 		return nil
@@ -287,6 +557,9 @@ func mergeRelease(existing, incoming *domain.Release, preferIncoming bool) *doma
 	if out.PublishDate.IsZero() {
 		out.PublishDate = incoming.PublishDate
 	}
+	if out.Attributes == nil {
+		out.Attributes = incoming.Attributes
+	}
 	if out.DownloadURL == "" {
 		out.DownloadURL = incoming.DownloadURL
 	}
@@ -300,15 +573,31 @@ func toSearchRequest(req app.SearchRequest) SearchRequest {
 		searchType = SearchTypeGeneric
 	}
 
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+
 	return SearchRequest{
-		Type:     searchType,
-		Query:    req.Query,
-		IMDbID:   req.IMDbID,
-		TVDBID:   req.TVDBID,
-		TVMazeID: req.TVMazeID,
-		RageID:   req.RageID,
-		Season:   req.Season,
-		Episode:  req.Episode,
-		Genre:    req.Genre,
+		Type:       searchType,
+		Query:      req.Query,
+		IMDbID:     req.IMDbID,
+		TVDBID:     req.TVDBID,
+		TVMazeID:   req.TVMazeID,
+		RageID:     req.RageID,
+		Season:     req.Season,
+		Episode:    req.Episode,
+		Genre:      req.Genre,
+		Artist:     req.Artist,
+		Album:      req.Album,
+		Label:      req.Label,
+		Track:      req.Track,
+		Year:       req.Year,
+		Author:     req.Author,
+		Title:      req.Title,
+		Categories: newsnab.ExpandIDs(req.Categories),
+		Offset:     max(req.Offset, 0),
+		Limit:      limit,
+		MaxAgeDays: max(req.MaxAgeDays, 0),
 	}
 }
@@ -0,0 +1,254 @@
+package aggregator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/datallboy/gonzb/internal/app"
+	"github.com/datallboy/gonzb/internal/domain"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+
+type fakeSource struct {
+	name     string
+	releases []*domain.Release
+	requests []SearchRequest
+	maxLimit int // like an indexer that caps limit, when set
+}
+
+func (s *fakeSource) Name() string { return s.name }
+
+func (s *fakeSource) Search(_ context.Context, req SearchRequest) ([]*domain.Release, int, error) {
+	s.requests = append(s.requests, req)
+	limit := req.Limit
+	if s.maxLimit > 0 && (limit <= 0 || limit > s.maxLimit) {
+		limit = s.maxLimit
+	}
+	out := make([]*domain.Release, 0, len(s.releases))
+	for _, rel := range s.releases[min(req.Offset, len(s.releases)):] {
+		if limit > 0 && len(out) == limit {
+			break
+		}
+		out = append(out, cloneRelease(rel))
+	}
+	return out, len(s.releases), nil
+}
+
+func (s *fakeSource) GetNZB(context.Context, *domain.Release) (io.ReadCloser, error) {
+	return nil, io.EOF
+}
+
+func newFeedTestManager(src *fakeSource) *Manager {
+	m := NewManager(nil, nopLogger{}, false, false)
+	m.AddSource(src)
+	return m
+}
+
+func TestSearchAllWithRequestCachesFeedPerCategory(t *testing.T) {
+	now := time.Now()
+	src := &fakeSource{name: "idx", releases: []*domain.Release{
+		{GUID: "old", Source: "idx", Category: "5040", PublishDate: now.Add(-time.Hour)},
+		{GUID: "new", Source: "idx", Category: "5040", PublishDate: now},
+	}}
+	m := newFeedTestManager(src)
+
+	results, _, err := m.SearchAllWithRequest(context.Background(), app.SearchRequest{Type: "search", Categories: []int{5000}})
+	if err != nil {
+		t.Fatalf("feed search: %v", err)
+	}
+	if len(results) != 2 || results[0].GUID != "new" {
+		t.Fatalf("expected newest release first, got %+v", results)
+	}
+
+	if _, _, err := m.SearchAllWithRequest(context.Background(), app.SearchRequest{Type: "search", Categories: []int{5000}}); err != nil {
+		t.Fatalf("repeat feed search: %v", err)
+	}
+	if len(src.requests) != 1 {
+		t.Fatalf("expected repeat feed to be served from cache, got %d source calls", len(src.requests))
+	}
+
+	if _, _, err := m.SearchAllWithRequest(context.Background(), app.SearchRequest{Type: "search", Categories: []int{2000}}); err != nil {
+		t.Fatalf("other category feed: %v", err)
+	}
+	if len(src.requests) != 2 {
+		t.Fatalf("expected a different category to miss the cache, got %d source calls", len(src.requests))
+	}
+
+	if _, _, err := m.SearchAllWithRequest(context.Background(), app.SearchRequest{Type: "search", Query: "show"}); err != nil {
+		t.Fatalf("query search: %v", err)
+	}
+	if _, _, err := m.SearchAllWithRequest(context.Background(), app.SearchRequest{Type: "search", Query: "show"}); err != nil {
+		t.Fatalf("repeat query search: %v", err)
+	}
+	if len(src.requests) != 4 {
+		t.Fatalf("expected query searches to bypass the feed cache, got %d source calls", len(src.requests))
+	}
+}
+
+func TestSearchAllWithRequestRefetchesExpiredFeed(t *testing.T) {
+	src := &fakeSource{name: "idx", releases: []*domain.Release{{GUID: "a", Source: "idx"}}}
+	m := newFeedTestManager(src)
+
+	req := app.SearchRequest{Type: "search"}
+	if _, _, err := m.SearchAllWithRequest(context.Background(), req); err != nil {
+		t.Fatalf("feed search: %v", err)
+	}
+	for key, page := range m.feeds {
+		page.expires = time.Now().Add(-time.Second)
+		m.feeds[key] = page
+	}
+	if _, _, err := m.SearchAllWithRequest(context.Background(), req); err != nil {
+		t.Fatalf("feed search after expiry: %v", err)
+	}
+	if len(src.requests) != 2 {
+		t.Fatalf("expected expired feed to be refetched, got %d source calls", len(src.requests))
+	}
+}
+
+type fakeDetailSource struct {
+	fakeSource
+	details map[string]*domain.Release
+	nfos    map[string]string
+}
+
+func (s *fakeDetailSource) Details(_ context.Context, id string) (*domain.Release, error) {
+	return cloneRelease(s.details[id]), nil
+}
+
+func (s *fakeDetailSource) NFO(_ context.Context, id string) (string, error) {
+	return s.nfos[id], nil
+}
+
+func TestGetDetailsAsksDetailSourcesForUnknownIDs(t *testing.T) {
+	indexed := &fakeDetailSource{
+		fakeSource: fakeSource{name: "usenet_index"},
+		details: map[string]*domain.Release{
+			"rel-1": {Title: "Indexed", Source: "usenet_index", Attributes: &domain.ReleaseAttributes{Files: 3}},
+		},
+		nfos: map[string]string{"rel-1": "nfo text"},
+	}
+	m := NewManager(nil, nopLogger{}, false, false)
+	m.AddSource(&fakeSource{name: "idx"})
+	m.AddSource(indexed)
+
+	rel, err := m.GetDetails(context.Background(), "rel-1")
+	if err != nil {
+		t.Fatalf("get details: %v", err)
+	}
+	if rel == nil || rel.ID != "rel-1" || rel.Attributes == nil || rel.Attributes.Files != 3 {
+		t.Fatalf("expected indexed details for rel-1, got %+v", rel)
+	}
+
+	text, err := m.GetNFO(context.Background(), "rel-1")
+	if err != nil || text != "nfo text" {
+		t.Fatalf("expected stored nfo, got %q (%v)", text, err)
+	}
+
+	missing, err := m.GetDetails(context.Background(), "rel-2")
+	if err != nil || missing != nil {
+		t.Fatalf("expected unknown release to be missing, got %+v (%v)", missing, err)
+	}
+}
+
+func TestGetDetailsFallsBackToSearchResult(t *testing.T) {
+	src := &fakeSource{name: "idx", releases: []*domain.Release{{GUID: "a", Source: "idx", Title: "Upstream"}}}
+	m := newFeedTestManager(src)
+
+	results, _, err := m.SearchAllWithRequest(context.Background(), app.SearchRequest{Type: "search", Query: "up"})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("search: %+v (%v)", results, err)
+	}
+
+	rel, err := m.GetDetails(context.Background(), results[0].ID)
+	if err != nil {
+		t.Fatalf("get details: %v", err)
+	}
+	if rel == nil || rel.Title != "Upstream" {
+		t.Fatalf("expected search result as details, got %+v", rel)
+	}
+}
+
+func TestSearchAllWithRequestPagesMergedResults(t *testing.T) {
+	now := time.Now()
+	releases := func(source string, guids ...string) []*domain.Release {
+		out := make([]*domain.Release, 0, len(guids))
+		for i, guid := range guids {
+			out = append(out, &domain.Release{GUID: guid, Source: source, PublishDate: now.Add(-time.Duration(i) * time.Hour)})
+		}
+		return out
+	}
+	a := &fakeSource{name: "a", releases: releases("a", "a1", "a2", "a3")}
+	b := &fakeSource{name: "b", releases: releases("b", "b1", "b2")}
+	m := NewManager(nil, nopLogger{}, false, false)
+	m.AddSource(a)
+	m.AddSource(b)
+
+	first, _, err := m.SearchAllWithRequest(context.Background(), app.SearchRequest{Type: "search", Query: "show", Limit: 2})
+	if err != nil {
+		t.Fatalf("first page: %v", err)
+	}
+	second, total, err := m.SearchAllWithRequest(context.Background(), app.SearchRequest{Type: "search", Query: "show", Offset: 2, Limit: 2})
+	if err != nil {
+		t.Fatalf("second page: %v", err)
+	}
+
+	if len(first) != 2 || len(second) != 2 {
+		t.Fatalf("expected pages of 2, got %d and %d", len(first), len(second))
+	}
+	if total != 5 {
+		t.Fatalf("expected the merged count as total, got %d", total)
+	}
+	seen := make(map[string]bool, 4)
+	for _, rel := range append(first, second...) {
+		if seen[rel.GUID] {
+			t.Fatalf("release %s appears on both pages", rel.GUID)
+		}
+		seen[rel.GUID] = true
+	}
+	if last := b.requests[len(b.requests)-1]; last.Offset != 0 || last.Limit != 4 {
+		t.Fatalf("expected sources to be read from the start up to offset+limit, got offset=%d limit=%d", last.Offset, last.Limit)
+	}
+}
+
+func TestSearchAllWithRequestPagesSourcesThatCapTheLimit(t *testing.T) {
+	now := time.Now()
+	capped := &fakeSource{name: "capped", maxLimit: 100}
+	for i := range 250 {
+		capped.releases = append(capped.releases, &domain.Release{
+			GUID:        fmt.Sprintf("r%03d", i),
+			Source:      "capped",
+			PublishDate: now.Add(-time.Duration(i) * time.Minute),
+		})
+	}
+	m := NewManager(nil, nopLogger{}, false, false)
+	m.AddSource(capped)
+
+	page, total, err := m.SearchAllWithRequest(context.Background(), app.SearchRequest{Type: "search", Query: "show", Offset: 150, Limit: 50})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if total != 250 {
+		t.Fatalf("expected total 250, got %d", total)
+	}
+	if len(page) != 50 || page[0].GUID != "r150" || page[49].GUID != "r199" {
+		t.Fatalf("expected r150..r199, got %d releases starting at %v", len(page), page)
+	}
+
+	var offsets []int
+	for _, req := range capped.requests {
+		offsets = append(offsets, req.Offset)
+	}
+	if want := []int{0, 100}; !slices.Equal(offsets, want) {
+		t.Fatalf("expected source pages at offsets %v, got %v", want, offsets)
+	}
+}
@@ -12,6 +12,7 @@ import (
 var (
 	ErrUnavailable    = errors.New("aggregator runtime is unavailable")
 	ErrReleaseMissing = errors.New("release not found")
+	ErrNFOMissing     = errors.New("nfo not found")
 )
 
 type Logger interface {
@@ -32,18 +33,18 @@ func NewModule(provider DependencyProvider) *Module {
 	return &Module{provider: provider}
 }
 
-func (m *Module) Search(ctx context.Context, req app.SearchRequest) ([]*domain.Release, error) {
+func (m *Module) Search(ctx context.Context, req app.SearchRequest) ([]*domain.Release, int, error) {
 	aggregator := m.provider.Aggregator()
 	if aggregator == nil {
-		return nil, ErrUnavailable
+		return nil, 0, ErrUnavailable
 	}
 
-	results, err := aggregator.SearchAllWithRequest(ctx, req)
+	results, total, err := aggregator.SearchAllWithRequest(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("search releases: %w", err)
+		return nil, 0, fmt.Errorf("search releases: %w", err)
 	}
 
-	return results, nil
+	return results, total, nil
 }
 
 func (m *Module) PrepareDownload(ctx context.Context, id string) (*app.AggregatorDownloadResult, error) {
@@ -81,3 +82,43 @@ func (m *Module) PrepareDownload(ctx context.Context, id string) (*app.Aggregato
 		Reader:  reader,
 	}, nil
 }
+
+func (m *Module) SourceHealth(ctx context.Context) ([]app.AggregatorSourceHealth, error) {
+	aggregator := m.provider.Aggregator()
+	if aggregator == nil {
+		return nil, ErrUnavailable
+	}
+	return aggregator.SourceHealth(), nil
+}
+
+func (m *Module) ReleaseDetails(ctx context.Context, id string) (*domain.Release, error) {
+	aggregator := m.provider.Aggregator()
+	if aggregator == nil {
+		return nil, ErrUnavailable
+	}
+
+	rel, err := aggregator.GetDetails(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("lookup release details: %w", err)
+	}
+	if rel == nil {
+		return nil, ErrReleaseMissing
+	}
+	return rel, nil
+}
+
+func (m *Module) ReleaseNFO(ctx context.Context, id string) (string, error) {
+	aggregator := m.provider.Aggregator()
+	if aggregator == nil {
+		return "", ErrUnavailable
+	}
+
+	text, err := aggregator.GetNFO(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("lookup release nfo: %w", err)
+	}
+	if text == "" {
+		return "", ErrNFOMissing
+	}
+	return text, nil
+}
@@ -2,7 +2,12 @@ package aggregator
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/datallboy/gonzb/internal/domain"
 )
@@ -13,6 +18,8 @@ const (
 	SearchTypeGeneric SearchType = "search"
 	SearchTypeMovie   SearchType = "movie"
 	SearchTypeTV      SearchType = "tvsearch"
+	SearchTypeMusic   SearchType = "music"
+	SearchTypeBook    SearchType = "book"
 )
 
 type SearchRequest struct {
@@ -27,21 +34,91 @@ type SearchRequest struct {
 	Season   string
 	Episode  string
 	Genre    string
+
+	// t=music parameters.
+	Artist string
+	Album  string
+	Label  string
+	Track  string
+	Year   string
+
+	// t=book parameters.
+	Author string
+	Title  string
+
+	// Newznab category ids, already widened from roots to subcategories.
+	Categories []int
+	Offset     int
+	Limit      int
+	MaxAgeDays int
+}
+
+// IsFeed reports whether the request asks for the newest releases rather
+// than for matches to a query, as RSS polling does.
+func (r SearchRequest) IsFeed() bool {
+	return r.Type == SearchTypeGeneric && strings.TrimSpace(r.Query) == ""
+}
+
+// FeedKey identifies the feed page a request asks for.
+func (r SearchRequest) FeedKey() string {
+	categories := make([]int, len(r.Categories))
+	copy(categories, r.Categories)
+	sort.Ints(categories)
+
+	parts := make([]string, 0, len(categories))
+	for _, id := range categories {
+		parts = append(parts, strconv.Itoa(id))
+	}
+	return fmt.Sprintf("cat=%s;offset=%d;limit=%d;maxage=%d", strings.Join(parts, ","), r.Offset, r.Limit, r.MaxAgeDays)
+}
+
+// PostedAfter is the oldest publish date MaxAgeDays allows, or the zero time
+// when there is no age limit.
+func (r SearchRequest) PostedAfter(now time.Time) time.Time {
+	if r.MaxAgeDays <= 0 {
+		return time.Time{}
+	}
+	return now.AddDate(0, 0, -r.MaxAgeDays)
+}
+
+// CacheQuery maps the request onto a search of the release cache.
+func (r SearchRequest) CacheQuery(now time.Time) domain.ReleaseCacheQuery {
+	categories := make([]string, 0, len(r.Categories))
+	for _, id := range r.Categories {
+		categories = append(categories, strconv.Itoa(id))
+	}
+	return domain.ReleaseCacheQuery{
+		Query:       r.Query,
+		Categories:  categories,
+		PostedAfter: r.PostedAfter(now),
+		Offset:      r.Offset,
+		Limit:       r.Limit,
+	}
 }
 
 type catalogSource interface {
 	Name() string
-	Search(ctx context.Context, req SearchRequest) ([]*domain.Release, error)
+	// Search returns one page of results and the number of matches the
+	// source holds, or the page length when it cannot tell.
+	Search(ctx context.Context, req SearchRequest) ([]*domain.Release, int, error)
 	GetNZB(ctx context.Context, rel *domain.Release) (io.ReadCloser, error)
 }
 
+// detailSource is implemented by sources that hold more about a release
+// than its search result, such as the local usenet index. Both methods
+// return zero values when the source does not know the release.
+type detailSource interface {
+	Details(ctx context.Context, id string) (*domain.Release, error)
+	NFO(ctx context.Context, id string) (string, error)
+}
+
 type store interface {
 	GetNZBReader(id string) (io.ReadCloser, error)
 	SaveNZBAtomically(id string, data []byte) error
 	Exists(id string) bool
 
 	UpsertAggregatorReleaseCache(ctx context.Context, releases []*domain.Release) error
-	SearchAggregatorReleaseCache(ctx context.Context, query string, limit int) ([]*domain.Release, error)
+	SearchAggregatorReleaseCache(ctx context.Context, q domain.ReleaseCacheQuery) ([]*domain.Release, int, error)
 	GetAggregatorReleaseCacheByID(ctx context.Context, id string) (*domain.Release, error)
 }
 
@@ -3,13 +3,14 @@ package localblob
 import (
 	"context"
 	"io"
+	"time"
 
 	"github.com/datallboy/gonzb/internal/aggregator"
 	"github.com/datallboy/gonzb/internal/domain"
 )
 
 type store interface {
-	SearchAggregatorReleaseCache(ctx context.Context, query string, limit int) ([]*domain.Release, error)
+	SearchAggregatorReleaseCache(ctx context.Context, q domain.ReleaseCacheQuery) ([]*domain.Release, int, error)
 	GetNZBReader(id string) (io.ReadCloser, error)
 }
 
@@ -25,17 +26,13 @@ func (s *Source) Name() string {
 	return "Local Store"
 }
 
-func (s *Source) Search(ctx context.Context, req aggregator.SearchRequest) ([]*domain.Release, error) {
-	query := req.Query
-	if query == "" {
-		return []*domain.Release{}, nil
+// Search matches cached titles; an empty query lists the newest cached
+// releases for feeds.
+func (s *Source) Search(ctx context.Context, req aggregator.SearchRequest) ([]*domain.Release, int, error) {
+	if req.Query == "" && !req.IsFeed() {
+		return []*domain.Release{}, 0, nil
 	}
-
-	items, err := s.store.SearchAggregatorReleaseCache(ctx, query, 100)
-	if err != nil {
-		return nil, err
-	}
-	return items, nil
+	return s.store.SearchAggregatorReleaseCache(ctx, req.CacheQuery(time.Now()))
 }
 
 func (s *Source) GetNZB(ctx context.Context, rel *domain.Release) (io.ReadCloser, error) {
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,34 +15,51 @@ import (
 	"github.com/datallboy/gonzb/internal/domain"
 )
 
+const (
+	// how long to leave an indexer alone after it reports an API limit
+	// (codes 500/501) or answers 429 without saying for how long.
+	apiLimitBackoff  = time.Hour
+	rateLimitBackoff = 5 * time.Minute
+
+	// floor for the HTTP client timeout, which also bounds NZB downloads.
+	defaultHTTPTimeout = 30 * time.Second
+)
+
 type Client struct {
 	BaseURL         string
 	ApiPath         string
 	APIKey          string
 	name            string
 	redirectAllowed bool
+	searchTimeout   time.Duration
 	httpClient      *http.Client
 }
 
-func New(name, baseURL, apiPath, apiKey string, redirect bool) *Client {
+// New builds a client for one indexer. A zero searchTimeout leaves the
+// aggregator default in place; a longer one also raises the HTTP client
+// timeout so it does not cut searches short.
+func New(name, baseURL, apiPath, apiKey string, redirect bool, searchTimeout time.Duration) *Client {
 	return &Client{
 		name:            name,
 		BaseURL:         baseURL,
 		ApiPath:         apiPath,
 		APIKey:          apiKey,
 		redirectAllowed: redirect,
+		searchTimeout:   searchTimeout,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: max(defaultHTTPTimeout, searchTimeout),
 		},
 	}
 }
 
 func (c *Client) Name() string { return c.name }
 
-func (c *Client) Search(ctx context.Context, req aggregator.SearchRequest) ([]*domain.Release, error) {
+func (c *Client) SearchTimeout() time.Duration { return c.searchTimeout }
+
+func (c *Client) Search(ctx context.Context, req aggregator.SearchRequest) ([]*domain.Release, int, error) {
 	base, err := url.Parse(c.BaseURL)
 	if err != nil {
-		return nil, fmt.Errorf("invalid indexer base URL %q: %w", c.BaseURL, err)
+		return nil, 0, fmt.Errorf("invalid indexer base URL %q: %w", c.BaseURL, err)
 	}
 
 	searchURL := base.ResolveReference(&url.URL{Path: c.ApiPath})
@@ -71,29 +89,85 @@ func (c *Client) Search(ctx context.Context, req aggregator.SearchRequest) ([]*d
 		setIfNotEmpty(params, "season", req.Season)
 		setIfNotEmpty(params, "ep", req.Episode)
 
+	case aggregator.SearchTypeMusic:
+		setIfNotEmpty(params, "q", req.Query)
+		setIfNotEmpty(params, "artist", req.Artist)
+		setIfNotEmpty(params, "album", req.Album)
+		setIfNotEmpty(params, "label", req.Label)
+		setIfNotEmpty(params, "track", req.Track)
+		setIfNotEmpty(params, "year", req.Year)
+
+	case aggregator.SearchTypeBook:
+		setIfNotEmpty(params, "q", req.Query)
+		setIfNotEmpty(params, "author", req.Author)
+		setIfNotEmpty(params, "title", req.Title)
+
 	default:
 		setIfNotEmpty(params, "q", req.Query)
 	}
 
+	if len(req.Categories) > 0 {
+		cats := make([]string, 0, len(req.Categories))
+		for _, id := range req.Categories {
+			cats = append(cats, strconv.Itoa(id))
+		}
+		params.Set("cat", strings.Join(cats, ","))
+	}
+	if req.Offset > 0 {
+		params.Set("offset", strconv.Itoa(req.Offset))
+	}
+	if req.Limit > 0 {
+		params.Set("limit", strconv.Itoa(req.Limit))
+	}
+	if req.MaxAgeDays > 0 {
+		params.Set("maxage", strconv.Itoa(req.MaxAgeDays))
+	}
+
 	searchURL.RawQuery = params.Encode()
 
 	reqHTTP, err := http.NewRequestWithContext(ctx, http.MethodGet, searchURL.String(), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create search request: %w", err)
+		return nil, 0, fmt.Errorf("failed to create search request: %w", err)
 	}
 	resp, err := c.httpClient.Do(reqHTTP)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("indexer %s returned status: %d", c.name, resp.StatusCode)
+		err := fmt.Errorf("indexer %s returned status: %d", c.name, resp.StatusCode)
+		if wait := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); wait > 0 {
+			return nil, 0, &aggregator.RetryAfterError{Wait: wait, Err: err}
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return nil, 0, &aggregator.RetryAfterError{Wait: rateLimitBackoff, Err: err}
+		}
+		return nil, 0, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Newznab reports API errors as an <error> document with status 200.
+	var apiErr APIError
+	if xml.Unmarshal(body, &apiErr) == nil {
+		err := fmt.Errorf("indexer %s returned error %d: %s", c.name, apiErr.Code, apiErr.Description)
+		if apiErr.IsLimit() {
+			wait := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+			if wait <= 0 {
+				wait = apiLimitBackoff
+			}
+			return nil, 0, &aggregator.RetryAfterError{Wait: wait, Err: err}
+		}
+		return nil, 0, err
 	}
 
 	var rss RSSResponse
-	if err := xml.NewDecoder(resp.Body).Decode(&rss); err != nil {
-		return nil, err
+	if err := xml.Unmarshal(body, &rss); err != nil {
+		return nil, 0, err
 	}
 
 	results := make([]*domain.Release, 0, len(rss.Channel.Items))
@@ -102,7 +176,10 @@ func (c *Client) Search(ctx context.Context, req aggregator.SearchRequest) ([]*d
 		res.RedirectAllowed = c.redirectAllowed
 		results = append(results, res)
 	}
-	return results, nil
+
+	// indexers that omit newznab:response still account for this page.
+	total := max(rss.Channel.Response.Total, req.Offset+len(results))
+	return results, total, nil
 }
 
 func (c *Client) GetNZB(ctx context.Context, res *domain.Release) (io.ReadCloser, error) {
@@ -134,6 +211,22 @@ func (c *Client) GetNZB(ctx context.Context, res *domain.Release) (io.ReadCloser
 	return resp.Body, nil
 }
 
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP
+// date, returning 0 when it is absent or already past.
+func parseRetryAfter(raw string, now time.Time) time.Duration {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(raw); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+	if at, err := http.ParseTime(raw); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
 func setIfNotEmpty(values url.Values, key, value string) {
 	value = strings.TrimSpace(value)
 	if value != "" {
@@ -0,0 +1,102 @@
+package newznab
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/datallboy/gonzb/internal/aggregator"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		raw  string
+		want time.Duration
+	}{
+		{name: "empty", raw: "", want: 0},
+		{name: "seconds", raw: " 120 ", want: 2 * time.Minute},
+		{name: "negative seconds", raw: "-5", want: 0},
+		{name: "http date", raw: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90 * time.Second},
+		{name: "past date", raw: now.Add(-time.Hour).Format(http.TimeFormat), want: 0},
+		{name: "garbage", raw: "soon", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.raw, now); got != tt.want {
+				t.Fatalf("parseRetryAfter(%q) = %s, want %s", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchMapsAPIErrorsToRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		retryAfter string
+		wantWait   time.Duration
+		wantRetry  bool
+	}{
+		{name: "request limit", body: `<error code="500" description="Request limit reached"/>`, wantWait: apiLimitBackoff, wantRetry: true},
+		{name: "download limit", body: `<error code="501" description="Download limit reached"/>`, wantWait: apiLimitBackoff, wantRetry: true},
+		{name: "limit with retry-after", body: `<error code="500" description="Request limit reached"/>`, retryAfter: "600", wantWait: 10 * time.Minute, wantRetry: true},
+		{name: "bad api key", body: `<error code="100" description="Incorrect user credentials"/>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>` + tt.body))
+			}))
+			defer srv.Close()
+
+			client := New("idx", srv.URL, "/api", "key", false, 0)
+			_, _, err := client.Search(context.Background(), aggregator.SearchRequest{Query: "show"})
+			if err == nil {
+				t.Fatal("expected an error for an <error> document")
+			}
+
+			var retry *aggregator.RetryAfterError
+			if got := errors.As(err, &retry); got != tt.wantRetry {
+				t.Fatalf("RetryAfterError = %v, want %v (err %v)", got, tt.wantRetry, err)
+			}
+			if tt.wantRetry && retry.Wait != tt.wantWait {
+				t.Fatalf("wait = %s, want %s", retry.Wait, tt.wantWait)
+			}
+		})
+	}
+}
+
+func TestNewDoesNotCapLongSearchTimeouts(t *testing.T) {
+	if got := New("idx", "http://idx", "/api", "key", false, 0).httpClient.Timeout; got != defaultHTTPTimeout {
+		t.Fatalf("expected default HTTP timeout %s, got %s", defaultHTTPTimeout, got)
+	}
+	if got := New("idx", "http://idx", "/api", "key", false, 2*time.Minute).httpClient.Timeout; got != 2*time.Minute {
+		t.Fatalf("expected HTTP timeout to follow the search timeout, got %s", got)
+	}
+}
+
+func TestSearchSendsOffsetForLaterPages(t *testing.T) {
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><rss><channel></channel></rss>`))
+	}))
+	defer srv.Close()
+
+	client := New("idx", srv.URL, "/api", "key", false, 0)
+	if _, _, err := client.Search(context.Background(), aggregator.SearchRequest{Query: "show", Offset: 100, Limit: 50}); err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if query.Get("offset") != "100" || query.Get("limit") != "50" {
+		t.Fatalf("expected offset=100 limit=50, got %q", query.Encode())
+	}
+}
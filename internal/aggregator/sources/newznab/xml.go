@@ -17,12 +17,25 @@ type RSSResponse struct {
 	Channel Channel  `xml:"channel"`
 }
 
+// APIError is the <error code=".." description=".."/> document Newznab
+// servers answer with instead of a feed.
+type APIError struct {
+	XMLName     xml.Name `xml:"error"`
+	Code        int      `xml:"code,attr"`
+	Description string   `xml:"description,attr"`
+}
+
+// IsLimit reports whether the error is a request or download limit.
+func (e APIError) IsLimit() bool {
+	return e.Code == 500 || e.Code == 501
+}
+
 type Channel struct {
 	Title       string       `xml:"title"`
 	Description string       `xml:"description"`
 	Link        string       `xml:"link"`
 	Items       []Item       `xml:"item"`
-	Response    ResponseInfo `xml:"newznab:response"`
+	Response    ResponseInfo `xml:"response"` // newznab:response, matched by local name
 }
 
 type ResponseInfo struct {
@@ -12,6 +12,7 @@ import (
 	"github.com/datallboy/gonzb/internal/app"
 	"github.com/datallboy/gonzb/internal/categories/newsnab"
 	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/indexing/releasetitle"
 	"github.com/datallboy/gonzb/internal/resolver"
 	"github.com/datallboy/gonzb/internal/store/pgindex"
 )
@@ -25,6 +26,8 @@ type store interface {
 	ListCatalogReleaseFileArticles(ctx context.Context, releaseFileID int64) ([]pgindex.CatalogArticleRef, error)
 	ListCatalogReleaseNewsgroups(ctx context.Context, releaseID string) ([]string, error)
 	GetReleaseArchiveState(ctx context.Context, releaseID string) (*pgindex.ReleaseArchiveState, error)
+	GetPublicIndexerReleaseDetailWithPolicy(ctx context.Context, releaseID string, policy pgindex.ReleaseReadyPolicy) (*pgindex.PublicIndexerReleaseDetail, error)
+	GetPublicIndexerReleaseNFOWithPolicy(ctx context.Context, releaseID string, policy pgindex.ReleaseReadyPolicy) (string, error)
 }
 
 type archiveStore interface {
@@ -51,15 +54,21 @@ func (s *Source) Name() string {
 	return sourceName
 }
 
-func (s *Source) Search(ctx context.Context, req aggregator.SearchRequest) ([]*domain.Release, error) {
+func (s *Source) Search(ctx context.Context, req aggregator.SearchRequest) ([]*domain.Release, int, error) {
 	if s == nil || s.store == nil {
-		return nil, fmt.Errorf("usenet index source is not configured")
+		return nil, 0, fmt.Errorf("usenet index source is not configured")
 	}
 
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 100
+	}
 	params := pgindex.PublicIndexerReleaseListParams{
 		Query:       strings.TrimSpace(req.Query),
-		Limit:       100,
+		Limit:       limit,
+		Offset:      req.Offset,
 		Sort:        "posted_at_desc",
+		CategoryIDs: req.Categories,
 		IMDBID:      req.IMDbID,
 		TVDBID:      parseInt64(req.TVDBID),
 		Season:      parseInt(req.Season),
@@ -72,18 +81,40 @@ func (s *Source) Search(ctx context.Context, req aggregator.SearchRequest) ([]*d
 		params.BrowseCategory = "movies"
 	case aggregator.SearchTypeTV:
 		params.BrowseCategory = "tv"
+	case aggregator.SearchTypeMusic:
+		params.BrowseCategory = "audio"
+		params.Terms = searchTerms(req.Artist, req.Album, req.Label, req.Track, req.Year)
+	case aggregator.SearchTypeBook:
+		params.BrowseCategory = "books"
+		params.Terms = searchTerms(req.Author, req.Title)
+	}
+
+	if postedAfter := req.PostedAfter(time.Now()); !postedAfter.IsZero() {
+		params.PostedAfter = &postedAfter
 	}
 
-	items, _, err := s.store.ListPublicIndexerReleases(ctx, params)
+	items, total, err := s.store.ListPublicIndexerReleases(ctx, params)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	out := make([]*domain.Release, 0, len(items))
 	for _, item := range items {
 		out = append(out, publicReleaseToDomain(item))
 	}
-	return out, nil
+	return out, total, nil
+}
+
+// searchTerms normalizes structured search values the way release
+// search_title is stored, dropping empty ones.
+func searchTerms(values ...string) []string {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if term := releasetitle.NormalizeSearchTitle(v); term != "" {
+			out = append(out, term)
+		}
+	}
+	return out
 }
 
 func (s *Source) releaseReadyPolicy(ctx context.Context) pgindex.ReleaseReadyPolicy {
@@ -106,6 +137,47 @@ func (s *Source) releaseReadyPolicy(ctx context.Context) pgindex.ReleaseReadyPol
 	})
 }
 
+// Details reads the catalog view of one release, including its media
+// summary, newsgroups and poster.
+func (s *Source) Details(ctx context.Context, id string) (*domain.Release, error) {
+	if s == nil || s.store == nil {
+		return nil, fmt.Errorf("usenet index source is not configured")
+	}
+
+	detail, err := s.store.GetPublicIndexerReleaseDetailWithPolicy(ctx, id, s.releaseReadyPolicy(ctx))
+	if err != nil || detail == nil {
+		return nil, err
+	}
+
+	rel := publicReleaseToDomain(detail.Release)
+	rel.Attributes.VideoCodec = detail.Media.PrimaryVideoCodec
+	rel.Attributes.AudioCodec = detail.Media.PrimaryAudioCodec
+	rel.Attributes.Resolution = detail.Media.PrimaryResolution
+
+	groups, err := s.store.ListCatalogReleaseNewsgroups(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	rel.Attributes.Groups = groups
+
+	catalog, err := s.store.GetCatalogReleaseByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if catalog != nil {
+		rel.Poster = catalog.Poster
+	}
+	return rel, nil
+}
+
+// NFO returns the NFO text inspected for a release.
+func (s *Source) NFO(ctx context.Context, id string) (string, error) {
+	if s == nil || s.store == nil {
+		return "", fmt.Errorf("usenet index source is not configured")
+	}
+	return s.store.GetPublicIndexerReleaseNFOWithPolicy(ctx, id, s.releaseReadyPolicy(ctx))
+}
+
 func (s *Source) GetNZB(ctx context.Context, rel *domain.Release) (io.ReadCloser, error) {
 	if s == nil || s.resolver == nil {
 		return nil, fmt.Errorf("usenet index source is not configured")
@@ -141,6 +213,14 @@ func publicReleaseToDomain(item pgindex.PublicIndexerReleaseSummary) *domain.Rel
 		Size:        item.SizeBytes,
 		PublishDate: publishDate,
 		Category:    category,
+		Attributes: &domain.ReleaseAttributes{
+			Files:         item.FileCount,
+			PasswordState: item.PasswordState,
+			HasNFO:        item.HasNFO,
+			TMDBID:        item.TMDBID,
+			TVDBID:        item.TVDBID,
+			IMDBID:        item.IMDBID,
+		},
 	}
 }
 
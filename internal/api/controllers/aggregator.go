@@ -37,12 +37,30 @@ func (ctrl *AggregatorController) SearchReleases(c *echo.Context) error {
 		return c.JSON(http.StatusOK, map[string]any{
 			"items": []aggregatorReleaseSearchResponse{},
 			"count": 0,
+			"total": 0,
 		})
 	}
 
-	results, err := ctrl.Service.Search(c.Request().Context(), aggregatorSearchRequest{
-		Type:  "search",
-		Query: query,
+	limit, offset, err := parsePaginationParams(c, defaultPageLimit, maxPageLimit)
+	if err != nil {
+		return jsonError(c, http.StatusBadRequest, err.Error())
+	}
+	categories, err := parseCategoryIDs(queryParamTrimmed(c, "cat"))
+	if err != nil {
+		return jsonError(c, http.StatusBadRequest, err.Error())
+	}
+	maxAge, err := parseOptionalBoundedInt(queryParamTrimmed(c, "maxage"), "maxage", 0, 0, 100000)
+	if err != nil {
+		return jsonError(c, http.StatusBadRequest, err.Error())
+	}
+
+	results, total, err := ctrl.Service.Search(c.Request().Context(), aggregatorSearchRequest{
+		Type:       "search",
+		Query:      query,
+		Categories: categories,
+		Offset:     offset,
+		Limit:      limit,
+		MaxAgeDays: maxAge,
 	})
 	if err != nil {
 		return jsonError(c, aggregatorErrorStatus(err), err.Error())
@@ -62,7 +80,27 @@ func (ctrl *AggregatorController) SearchReleases(c *echo.Context) error {
 	}
 
 	return c.JSON(http.StatusOK, map[string]any{
-		"items": items,
-		"count": len(items),
+		"items":  items,
+		"count":  len(items),
+		"total":  total,
+		"offset": offset,
+		"limit":  limit,
 	})
 }
+
+// GetSourceHealth reports search latency, errors and backoff per source.
+func (ctrl *AggregatorController) GetSourceHealth(c *echo.Context) error {
+	if ctrl == nil || ctrl.Service == nil {
+		return jsonError(c, http.StatusServiceUnavailable, "aggregator runtime is unavailable")
+	}
+
+	items, err := ctrl.Service.SourceHealth(c.Request().Context())
+	if err != nil {
+		return jsonError(c, aggregatorErrorStatus(err), err.Error())
+	}
+	if items == nil {
+		items = []app.AggregatorSourceHealth{}
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{"items": items, "count": len(items)})
+}
@@ -21,11 +21,27 @@ type aggregatorSearchRequest struct {
 	Season   string
 	Episode  string
 	Genre    string
+
+	Artist string
+	Album  string
+	Label  string
+	Track  string
+	Year   string
+	Author string
+	Title  string
+
+	Categories []int
+	Offset     int
+	Limit      int
+	MaxAgeDays int
 }
 
 type aggregatorService interface {
-	Search(ctx context.Context, req aggregatorSearchRequest) ([]*domain.Release, error)
+	Search(ctx context.Context, req aggregatorSearchRequest) ([]*domain.Release, int, error)
 	PrepareDownload(ctx context.Context, id string) (*app.AggregatorDownloadResult, error)
+	Details(ctx context.Context, id string) (*domain.Release, error)
+	NFO(ctx context.Context, id string) (string, error)
+	SourceHealth(ctx context.Context) ([]app.AggregatorSourceHealth, error)
 }
 
 type runtimeAggregatorService struct {
@@ -42,27 +58,38 @@ func newAggregatorService(module app.AggregatorModule) aggregatorService {
 	}
 }
 
-func (s *runtimeAggregatorService) Search(ctx context.Context, req aggregatorSearchRequest) ([]*domain.Release, error) {
+func (s *runtimeAggregatorService) Search(ctx context.Context, req aggregatorSearchRequest) ([]*domain.Release, int, error) {
 	if s == nil || s.module == nil {
-		return nil, aggregatormodule.ErrUnavailable
+		return nil, 0, aggregatormodule.ErrUnavailable
 	}
 
-	results, err := s.module.Search(ctx, app.SearchRequest{
-		Type:     req.Type,
-		Query:    req.Query,
-		IMDbID:   req.IMDbID,
-		TVDBID:   req.TVDBID,
-		TVMazeID: req.TVMazeID,
-		RageID:   req.RageID,
-		Season:   req.Season,
-		Episode:  req.Episode,
-		Genre:    req.Genre,
+	results, total, err := s.module.Search(ctx, app.SearchRequest{
+		Type:       req.Type,
+		Query:      req.Query,
+		IMDbID:     req.IMDbID,
+		TVDBID:     req.TVDBID,
+		TVMazeID:   req.TVMazeID,
+		RageID:     req.RageID,
+		Season:     req.Season,
+		Episode:    req.Episode,
+		Genre:      req.Genre,
+		Artist:     req.Artist,
+		Album:      req.Album,
+		Label:      req.Label,
+		Track:      req.Track,
+		Year:       req.Year,
+		Author:     req.Author,
+		Title:      req.Title,
+		Categories: req.Categories,
+		Offset:     req.Offset,
+		Limit:      req.Limit,
+		MaxAgeDays: req.MaxAgeDays,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("search releases: %w", err)
+		return nil, 0, fmt.Errorf("search releases: %w", err)
 	}
 
-	return results, nil
+	return results, total, nil
 }
 
 func (s *runtimeAggregatorService) PrepareDownload(ctx context.Context, id string) (*app.AggregatorDownloadResult, error) {
@@ -72,11 +99,32 @@ func (s *runtimeAggregatorService) PrepareDownload(ctx context.Context, id strin
 	return s.module.PrepareDownload(ctx, id)
 }
 
+func (s *runtimeAggregatorService) Details(ctx context.Context, id string) (*domain.Release, error) {
+	if s == nil || s.module == nil {
+		return nil, aggregatormodule.ErrUnavailable
+	}
+	return s.module.ReleaseDetails(ctx, id)
+}
+
+func (s *runtimeAggregatorService) NFO(ctx context.Context, id string) (string, error) {
+	if s == nil || s.module == nil {
+		return "", aggregatormodule.ErrUnavailable
+	}
+	return s.module.ReleaseNFO(ctx, id)
+}
+
+func (s *runtimeAggregatorService) SourceHealth(ctx context.Context) ([]app.AggregatorSourceHealth, error) {
+	if s == nil || s.module == nil {
+		return nil, aggregatormodule.ErrUnavailable
+	}
+	return s.module.SourceHealth(ctx)
+}
+
 func aggregatorErrorStatus(err error) int {
 	switch {
 	case errors.Is(err, aggregatormodule.ErrUnavailable):
 		return http.StatusServiceUnavailable
-	case errors.Is(err, aggregatormodule.ErrReleaseMissing):
+	case errors.Is(err, aggregatormodule.ErrReleaseMissing), errors.Is(err, aggregatormodule.ErrNFOMissing):
 		return http.StatusNotFound
 	default:
 		return http.StatusInternalServerError
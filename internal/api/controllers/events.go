@@ -27,6 +27,8 @@ type EventStats struct {
 	Progress   float64          `json:"progress"`
 	ActiveJobs int              `json:"active_jobs"`
 	ActiveItem *ActiveItemStats `json:"active_item,omitempty"`
+	// ActiveItems lists every concurrently running item; ActiveItem is its first entry.
+	ActiveItems []*ActiveItemStats `json:"active_items"`
 }
 
 type ActiveItemStats struct {
@@ -80,10 +82,11 @@ func (ctrl *DownloadEvent) HandleEvents(c *echo.Context) error {
 			lastBytes = snapshot.CurrentBytes
 
 			stats := EventStats{
-				Bps:        bps,
-				Progress:   snapshot.Progress,
-				ActiveJobs: snapshot.ActiveJobs,
-				ActiveItem: snapshot.ActiveItem,
+				Bps:         bps,
+				Progress:    snapshot.Progress,
+				ActiveJobs:  snapshot.ActiveJobs,
+				ActiveItem:  snapshot.ActiveItem,
+				ActiveItems: snapshot.ActiveItems,
 			}
 
 			data, err := json.Marshal(stats)
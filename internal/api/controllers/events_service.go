@@ -14,6 +14,7 @@ type downloadEventSnapshot struct {
 	Progress     float64
 	ActiveJobs   int
 	ActiveItem   *ActiveItemStats
+	ActiveItems  []*ActiveItemStats
 }
 
 type downloadEventService interface {
@@ -39,7 +40,6 @@ func (s *runtimeDownloadEventService) Snapshot() (*downloadEventSnapshot, error)
 		return nil, errDownloadEventUnavailable
 	}
 
-	activeItem := s.queries.GetActiveItem()
 	activeJobs := 0
 
 	for _, item := range s.queries.ListActive() {
@@ -48,27 +48,38 @@ func (s *runtimeDownloadEventService) Snapshot() (*downloadEventSnapshot, error)
 		}
 	}
 
-	var currentBytes int64
+	var currentBytes, totalSize int64
 	var progress float64
-	var activePayload *ActiveItemStats
+	activePayloads := make([]*ActiveItemStats, 0)
 
-	if activeItem != nil {
-		currentBytes = activeItem.BytesWritten.Load()
-		if activeItem.Release != nil && activeItem.Release.Size > 0 {
-			progress = float64(currentBytes) / float64(activeItem.Release.Size) * 100
-		}
+	// progress is reported across every concurrently running item.
+	for _, activeItem := range s.queries.GetActiveItems() {
+		itemBytes := activeItem.BytesWritten.Load()
+		currentBytes += itemBytes
 
-		activePayload = &ActiveItemStats{
+		payload := &ActiveItemStats{
 			ID:        activeItem.ID,
 			ReleaseID: activeItem.ReleaseID,
 			Status:    activeItem.Status,
-			Bytes:     currentBytes,
+			Bytes:     itemBytes,
 		}
 
 		if activeItem.Release != nil {
-			activePayload.Title = activeItem.Release.Title
-			activePayload.Size = activeItem.Release.Size
+			payload.Title = activeItem.Release.Title
+			payload.Size = activeItem.Release.Size
+			totalSize += activeItem.Release.Size
 		}
+
+		activePayloads = append(activePayloads, payload)
+	}
+
+	if totalSize > 0 {
+		progress = float64(currentBytes) / float64(totalSize) * 100
+	}
+
+	var activePayload *ActiveItemStats
+	if len(activePayloads) > 0 {
+		activePayload = activePayloads[0]
 	}
 
 	return &downloadEventSnapshot{
@@ -76,5 +87,6 @@ func (s *runtimeDownloadEventService) Snapshot() (*downloadEventSnapshot, error)
 		Progress:     progress,
 		ActiveJobs:   activeJobs,
 		ActiveItem:   activePayload,
+		ActiveItems:  activePayloads,
 	}, nil
 }
@@ -173,6 +173,29 @@ func parseOptionalBoundedInt(raw, field string, fallback, min, max int) (int, er
 	return n, nil
 }
 
+// parseCategoryIDs reads a comma-separated list of Newznab category ids, as
+// sent in cat=. Empty entries are skipped.
+func parseCategoryIDs(raw string) ([]int, error) {
+	raw = normalizeTrimmed(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	ids := make([]int, 0, strings.Count(raw, ",")+1)
+	for _, part := range strings.Split(raw, ",") {
+		part = normalizeTrimmed(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.Atoi(part)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("cat must be a comma-separated list of category ids")
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
 func parseOptionalBool(raw, field string) (*bool, error) {
 	raw = normalizeLowerTrimmed(raw)
 	if raw == "" {
@@ -14,6 +14,14 @@ import (
 	"github.com/labstack/echo/v5"
 )
 
+// page sizes advertised in caps; larger limit= values are clamped to the max.
+const (
+	newznabDefaultLimit = 100
+	newznabMaxLimit     = 100
+)
+
+const newznabAttrNamespace = "http://www.newznab.com/DTD/2010/feeds/attributes/"
+
 type NewznabController struct {
 	Service aggregatorService
 }
@@ -35,10 +43,16 @@ func (ctrl *NewznabController) Handle(c *echo.Context) error {
 	switch t {
 	case "caps":
 		return ctrl.handleCaps(c)
-	case "search", "tvsearch", "movie":
+	case "search", "tvsearch", "movie", "music", "book":
 		return ctrl.handleSearch(c)
 	case "get":
 		return ctrl.HandleDownload(c)
+	case "details":
+		return ctrl.handleDetails(c)
+	case "getnfo":
+		return ctrl.handleGetNFO(c)
+	case "comments":
+		return ctrl.handleComments(c)
 	default:
 		return writeNewznabError(c, http.StatusBadRequest, 100, "unknown or missing t parameter")
 	}
@@ -59,8 +73,8 @@ func (ctrl *NewznabController) handleCaps(c *echo.Context) error {
 			Image:      "",
 		},
 		Limits: Limits{
-			Max:     100,
-			Default: 100,
+			Max:     newznabMaxLimit,
+			Default: newznabDefaultLimit,
 		},
 		Registration: Registration{
 			Available: "no",
@@ -79,6 +93,14 @@ func (ctrl *NewznabController) handleCaps(c *echo.Context) error {
 				Available:       "yes",
 				SupportedParams: "q,imdbid,genre",
 			},
+			Audio: SearchCapability{
+				Available:       "yes",
+				SupportedParams: "q,artist,album,label,track,year",
+			},
+			Book: SearchCapability{
+				Available:       "yes",
+				SupportedParams: "q,author,title",
+			},
 		},
 		Categories: buildCapCategories(),
 		Groups:     []CapGroup{},
@@ -92,16 +114,38 @@ func (ctrl *NewznabController) handleCaps(c *echo.Context) error {
 func (ctrl *NewznabController) handleSearch(c *echo.Context) error {
 	searchType := queryParamLower(c, "t")
 
-	results, err := ctrl.Service.Search(c.Request().Context(), aggregatorSearchRequest{
-		Type:     searchType,
-		Query:    queryParamTrimmed(c, "q"),
-		IMDbID:   queryParamTrimmed(c, "imdbid"),
-		TVDBID:   queryParamTrimmed(c, "tvdbid"),
-		TVMazeID: queryParamTrimmed(c, "tvmazeid"),
-		RageID:   queryParamTrimmed(c, "rid"),
-		Season:   queryParamTrimmed(c, "season"),
-		Episode:  queryParamTrimmed(c, "ep"),
-		Genre:    queryParamTrimmed(c, "genre"),
+	categories, err := parseCategoryIDs(queryParamTrimmed(c, "cat"))
+	if err != nil {
+		return writeNewznabError(c, http.StatusBadRequest, 201, "incorrect parameter: cat")
+	}
+	offset := parseIntDefault(queryParamTrimmed(c, "offset"), 0)
+	limit := parseIntDefault(queryParamTrimmed(c, "limit"), newznabDefaultLimit)
+	if limit <= 0 {
+		limit = newznabDefaultLimit
+	}
+	limit = min(limit, newznabMaxLimit)
+
+	results, total, err := ctrl.Service.Search(c.Request().Context(), aggregatorSearchRequest{
+		Type:       searchType,
+		Query:      queryParamTrimmed(c, "q"),
+		IMDbID:     queryParamTrimmed(c, "imdbid"),
+		TVDBID:     queryParamTrimmed(c, "tvdbid"),
+		TVMazeID:   queryParamTrimmed(c, "tvmazeid"),
+		RageID:     queryParamTrimmed(c, "rid"),
+		Season:     queryParamTrimmed(c, "season"),
+		Episode:    queryParamTrimmed(c, "ep"),
+		Genre:      queryParamTrimmed(c, "genre"),
+		Artist:     queryParamTrimmed(c, "artist"),
+		Album:      queryParamTrimmed(c, "album"),
+		Label:      queryParamTrimmed(c, "label"),
+		Track:      queryParamTrimmed(c, "track"),
+		Year:       queryParamTrimmed(c, "year"),
+		Author:     queryParamTrimmed(c, "author"),
+		Title:      queryParamTrimmed(c, "title"),
+		Categories: categories,
+		Offset:     offset,
+		Limit:      limit,
+		MaxAgeDays: parseIntDefault(queryParamTrimmed(c, "maxage"), 0),
 	})
 	if err != nil {
 		if aggregatorErrorStatus(err) == http.StatusServiceUnavailable {
@@ -112,15 +156,125 @@ func (ctrl *NewznabController) handleSearch(c *echo.Context) error {
 
 	baseAddr := fmt.Sprintf("%s://%s", c.Scheme(), c.Request().Host)
 
-	apiKey := queryParamTrimmed(c, "apikey")
-	if apiKey == "" {
-		apiKey = c.Request().Header.Get("X-API-Key")
-	}
+	apiKey := requestAPIKey(c)
 
-	rssResp := buildRSSResponse(results, baseAddr, apiKey)
+	extended := queryParamTrimmed(c, "extended") == "1"
+	rssResp := buildRSSResponse(results, offset, total, baseAddr, apiKey, extended)
 	return c.XML(http.StatusOK, rssResp)
 }
 
+// HandleRSS serves the newest releases across all sources as a Newznab RSS
+// feed, optionally narrowed to the categories in cat= (or t=, as classic
+// Newznab feed URLs send it).
+func (ctrl *NewznabController) HandleRSS(c *echo.Context) error {
+	if ctrl == nil || ctrl.Service == nil {
+		return writeNewznabError(c, http.StatusNotFound, 100, "Newznab-compatible API is not enabled")
+	}
+
+	rawCategories := queryParamTrimmed(c, "cat")
+	if rawCategories == "" {
+		rawCategories = queryParamTrimmed(c, "t")
+	}
+	categories, err := parseCategoryIDs(rawCategories)
+	if err != nil {
+		return writeNewznabError(c, http.StatusBadRequest, 201, "incorrect parameter: cat")
+	}
+
+	rawLimit := queryParamTrimmed(c, "limit")
+	if rawLimit == "" {
+		rawLimit = queryParamTrimmed(c, "num")
+	}
+	limit := parseIntDefault(rawLimit, newznabDefaultLimit)
+	if limit <= 0 {
+		limit = newznabDefaultLimit
+	}
+	limit = min(limit, newznabMaxLimit)
+
+	results, total, err := ctrl.Service.Search(c.Request().Context(), aggregatorSearchRequest{
+		Type:       "search",
+		Categories: categories,
+		Limit:      limit,
+		MaxAgeDays: parseIntDefault(queryParamTrimmed(c, "maxage"), 0),
+	})
+	if err != nil {
+		if aggregatorErrorStatus(err) == http.StatusServiceUnavailable {
+			return writeNewznabError(c, http.StatusNotFound, 100, "Newznab-compatible API is not enabled")
+		}
+		return writeNewznabError(c, http.StatusInternalServerError, 300, "feed failed")
+	}
+
+	baseAddr := fmt.Sprintf("%s://%s", c.Scheme(), c.Request().Host)
+
+	apiKey := requestAPIKey(c)
+
+	return c.XML(http.StatusOK, buildRSSResponse(results, 0, total, baseAddr, apiKey, queryParamTrimmed(c, "extended") == "1"))
+}
+
+// handleDetails returns one release as a single-item feed carrying every
+// extended attribute the sources know.
+func (ctrl *NewznabController) handleDetails(c *echo.Context) error {
+	id := queryParamTrimmed(c, "id")
+	if id == "" {
+		return writeNewznabError(c, http.StatusBadRequest, 200, "missing id parameter")
+	}
+
+	rel, err := ctrl.Service.Details(c.Request().Context(), id)
+	if err != nil {
+		return writeNewznabLookupError(c, err, "details lookup failed")
+	}
+
+	baseAddr := fmt.Sprintf("%s://%s", c.Scheme(), c.Request().Host)
+	return c.XML(http.StatusOK, buildRSSResponse([]*domain.Release{rel}, 0, 1, baseAddr, requestAPIKey(c), true))
+}
+
+// handleGetNFO serves the stored NFO text of a release, as raw text when
+// o=file is passed and as the description of a feed item otherwise.
+func (ctrl *NewznabController) handleGetNFO(c *echo.Context) error {
+	id := queryParamTrimmed(c, "id")
+	if id == "" {
+		return writeNewznabError(c, http.StatusBadRequest, 200, "missing id parameter")
+	}
+
+	text, err := ctrl.Service.NFO(c.Request().Context(), id)
+	if err != nil {
+		return writeNewznabLookupError(c, err, "nfo lookup failed")
+	}
+
+	if queryParamLower(c, "o") == "file" {
+		filename := strings.TrimSuffix(buildDownloadFilename(id, id), ".nzb") + ".nfo"
+		c.Response().Header().Set(echo.HeaderContentDisposition, contentDispositionFilename(filename))
+		return c.Blob(http.StatusOK, "text/x-nfo; charset=utf-8", []byte(text))
+	}
+
+	baseAddr := fmt.Sprintf("%s://%s", c.Scheme(), c.Request().Host)
+	return c.XML(http.StatusOK, NewznabRSS{
+		Version: "2.0",
+		NS:      newznabAttrNamespace,
+		Channel: Channel{
+			Title:       "GoNZB",
+			Description: "GoNZB NFO",
+			Link:        baseAddr,
+			Items: []RSSItem{{
+				Title:       id,
+				GUID:        RSSGUID{Value: id},
+				Description: text,
+			}},
+			Response: Response{Offset: 0, Total: 1},
+		},
+	})
+}
+
+// handleComments answers t=comments with an empty feed; GoNZB keeps no
+// release comments, but clients expect the call to succeed.
+func (ctrl *NewznabController) handleComments(c *echo.Context) error {
+	if queryParamTrimmed(c, "id") == "" {
+		return writeNewznabError(c, http.StatusBadRequest, 200, "missing id parameter")
+	}
+
+	baseAddr := fmt.Sprintf("%s://%s", c.Scheme(), c.Request().Host)
+	return c.XML(http.StatusOK, buildRSSResponse(nil, 0, 0, baseAddr, "", false))
+}
+
 // handleDownload serves the actual NZB file from cache or source
 func (ctrl *NewznabController) HandleDownload(c *echo.Context) error {
 	if ctrl == nil || ctrl.Service == nil {
@@ -158,8 +312,31 @@ func (ctrl *NewznabController) HandleDownload(c *echo.Context) error {
 	return c.Stream(http.StatusOK, "application/x-nzb", result.Reader)
 }
 
-// buildRSSResponse maps internal Releases to the outgoing Newznab XML format
-func buildRSSResponse(results []*domain.Release, baseAddr, apiKey string) NewznabRSS {
+// requestAPIKey returns the key the caller authenticated with, so feed links
+// work when followed by the same client.
+func requestAPIKey(c *echo.Context) string {
+	if apiKey := queryParamTrimmed(c, "apikey"); apiKey != "" {
+		return apiKey
+	}
+	return normalizeTrimmed(c.Request().Header.Get("X-API-Key"))
+}
+
+// writeNewznabLookupError maps a release lookup failure to a Newznab error.
+func writeNewznabLookupError(c *echo.Context, err error, failure string) error {
+	switch aggregatorErrorStatus(err) {
+	case http.StatusServiceUnavailable:
+		return writeNewznabError(c, http.StatusNotFound, 100, "Newznab-compatible API is not enabled")
+	case http.StatusNotFound:
+		return writeNewznabError(c, http.StatusNotFound, 300, "no such item")
+	default:
+		return writeNewznabError(c, http.StatusInternalServerError, 900, failure)
+	}
+}
+
+// buildRSSResponse maps internal Releases to the outgoing Newznab XML format.
+// offset and total describe where this page sits in the full result set;
+// extended adds the attributes sources keep beyond the basic listing.
+func buildRSSResponse(results []*domain.Release, offset, total int, baseAddr, apiKey string, extended bool) NewznabRSS {
 	items := make([]RSSItem, 0, len(results))
 
 	for _, res := range results {
@@ -177,6 +354,15 @@ func buildRSSResponse(results []*domain.Release, baseAddr, apiKey string) Newzna
 			downloadURL = fmt.Sprintf("%s&apikey=%s", downloadURL, url.QueryEscape(apiKey))
 		}
 
+		attrs := []Attr{
+			{Name: "category", Value: categoryAttr},
+			{Name: "size", Value: fmt.Sprintf("%d", res.Size)},
+			{Name: "guid", Value: res.ID},
+		}
+		if extended {
+			attrs = append(attrs, extendedAttrs(res)...)
+		}
+
 		items = append(items, RSSItem{
 			Title: res.Title,
 			GUID: RSSGUID{
@@ -191,30 +377,73 @@ func buildRSSResponse(results []*domain.Release, baseAddr, apiKey string) Newzna
 				Length: res.Size,
 				Type:   "application/x-nzb",
 			},
-			Attributes: []Attr{
-				{Name: "category", Value: categoryAttr},
-				{Name: "size", Value: fmt.Sprintf("%d", res.Size)},
-				{Name: "guid", Value: res.ID},
-			},
+			Attributes: attrs,
 		})
 	}
 
 	return NewznabRSS{
 		Version: "2.0",
-		NS:      "http://www.newznab.com/DTD/2010/feeds/attributes/",
+		NS:      newznabAttrNamespace,
 		Channel: Channel{
 			Title:       "GoNZB",
 			Description: "GoNZB Aggregated Feed",
 			Link:        baseAddr,
 			Items:       items,
 			Response: Response{
-				Offset: 0,
-				Total:  len(items),
+				Offset: offset,
+				Total:  max(total, offset+len(items)),
 			},
 		},
 	}
 }
 
+// extendedAttrs maps what a source knows about a release onto the extended
+// newznab:attr names clients read. Unknown values are left out.
+func extendedAttrs(res *domain.Release) []Attr {
+	out := make([]Attr, 0, 12)
+	add := func(name, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			out = append(out, Attr{Name: name, Value: value})
+		}
+	}
+
+	if !res.PublishDate.IsZero() {
+		add("usenetdate", res.PublishDate.Format(time.RFC1123Z))
+	}
+	add("poster", res.Poster)
+
+	attrs := res.Attributes
+	if attrs == nil {
+		return out
+	}
+	if attrs.Files > 0 {
+		add("files", strconv.Itoa(attrs.Files))
+	}
+	for _, group := range attrs.Groups {
+		add("group", group)
+	}
+	switch attrs.PasswordState {
+	case "not_passworded":
+		add("password", "0")
+	case "password_known", "password_unknown":
+		add("password", "1")
+	}
+	if attrs.HasNFO {
+		add("nfo", "1")
+	}
+	add("video", attrs.VideoCodec)
+	add("audio", attrs.AudioCodec)
+	add("resolution", attrs.Resolution)
+	if attrs.TMDBID > 0 {
+		add("tmdbid", strconv.FormatInt(attrs.TMDBID, 10))
+	}
+	if attrs.TVDBID > 0 {
+		add("tvdbid", strconv.FormatInt(attrs.TVDBID, 10))
+	}
+	add("imdb", strings.TrimPrefix(strings.ToLower(attrs.IMDBID), "tt"))
+	return out
+}
+
 func buildCapCategories() []CapCategory {
 	roots := newsnab.Roots()
 	out := make([]CapCategory, 0, len(roots))
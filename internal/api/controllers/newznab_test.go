@@ -37,7 +37,7 @@ func TestBuildRSSResponseUsesNumericCategoryAttr(t *testing.T) {
 		Size:        1024,
 		PublishDate: time.Unix(1, 0).UTC(),
 		Category:    "2040",
-	}}, "http://localhost:8080", "")
+	}}, 0, 1, "http://localhost:8080", "", false)
 
 	if len(resp.Channel.Items) != 1 {
 		t.Fatalf("expected one rss item, got %+v", resp.Channel.Items)
@@ -50,3 +50,72 @@ func TestBuildRSSResponseUsesNumericCategoryAttr(t *testing.T) {
 		t.Fatalf("expected numeric category attr, got %+v", item.Attributes)
 	}
 }
+
+func TestBuildRSSResponseReportsPageOffsetAndTotal(t *testing.T) {
+	resp := buildRSSResponse([]*domain.Release{{ID: "rel-1"}, {ID: "rel-2"}}, 100, 250, "http://localhost:8080", "", false)
+	if resp.Channel.Response.Offset != 100 || resp.Channel.Response.Total != 250 {
+		t.Fatalf("expected offset 100 and total 250, got %+v", resp.Channel.Response)
+	}
+
+	resp = buildRSSResponse([]*domain.Release{{ID: "rel-1"}}, 100, 0, "http://localhost:8080", "", false)
+	if resp.Channel.Response.Total != 101 {
+		t.Fatalf("expected total to cover the returned page, got %+v", resp.Channel.Response)
+	}
+}
+
+func TestParseCategoryIDsReadsCommaSeparatedList(t *testing.T) {
+	ids, err := parseCategoryIDs(" 2000, 5040,,")
+	if err != nil {
+		t.Fatalf("parse categories: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != newsnab.MoviesRoot || ids[1] != newsnab.TVHD {
+		t.Fatalf("expected movies root and TVHD, got %+v", ids)
+	}
+	if _, err := parseCategoryIDs("2000,tv"); err == nil {
+		t.Fatalf("expected non-numeric category to be rejected")
+	}
+}
+
+func TestBuildRSSResponseAddsExtendedAttrsOnlyWhenAsked(t *testing.T) {
+	rel := &domain.Release{
+		ID:       "rel-1",
+		Category: "2040",
+		Poster:   "poster@example.com",
+		Attributes: &domain.ReleaseAttributes{
+			Files:         12,
+			Groups:        []string{"alt.binaries.example"},
+			PasswordState: "not_passworded",
+			VideoCodec:    "h264",
+			TMDBID:        603,
+			IMDBID:        "tt0133093",
+		},
+	}
+
+	plain := buildRSSResponse([]*domain.Release{rel}, 0, 1, "http://localhost:8080", "", false)
+	if got := len(plain.Channel.Items[0].Attributes); got != 3 {
+		t.Fatalf("expected only basic attrs without extended=1, got %d", got)
+	}
+
+	resp := buildRSSResponse([]*domain.Release{rel}, 0, 1, "http://localhost:8080", "", true)
+	attrs := map[string]string{}
+	for _, attr := range resp.Channel.Items[0].Attributes {
+		attrs[attr.Name] = attr.Value
+	}
+	want := map[string]string{
+		"files":    "12",
+		"group":    "alt.binaries.example",
+		"poster":   "poster@example.com",
+		"password": "0",
+		"video":    "h264",
+		"tmdbid":   "603",
+		"imdb":     "0133093",
+	}
+	for name, value := range want {
+		if attrs[name] != value {
+			t.Fatalf("expected attr %s=%q, got %+v", name, value, attrs)
+		}
+	}
+	if _, ok := attrs["audio"]; ok {
+		t.Fatalf("expected unknown audio codec to be omitted, got %+v", attrs)
+	}
+}
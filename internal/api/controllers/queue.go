@@ -31,23 +31,54 @@ type enqueueRequest struct {
 	Title      string `json:"title"`
 }
 
+type availabilityRequest struct {
+	SourceKind string `json:"source_kind"`
+	ReleaseID  string `json:"release_id"`
+	Sample     *int   `json:"sample"` // omitted uses download.availability_sample; 0 checks all
+}
+
 type bulkIDsRequest struct {
 	IDs []string `json:"ids"`
 }
 
+type priorityRequest struct {
+	Priority string `json:"priority"`
+}
+
+type reorderRequest struct {
+	ID       string `json:"id"`
+	Action   string `json:"action"`   // top | bottom | up | down
+	Position *int   `json:"position"` // absolute index; takes precedence over action
+}
+
+type passwordRequest struct {
+	Password string `json:"password"`
+}
+
+type speedLimitRequest struct {
+	LimitKBps *int `json:"limit_kbps"` // 0 removes the limit
+}
+
 type queueItemResponse struct {
-	ID          string                `json:"id"`
-	ReleaseID   string                `json:"release_id"`
-	Status      domain.JobStatus      `json:"status"`
-	OutDir      string                `json:"out_dir"`
-	Error       *string               `json:"error,omitempty"`
-	CreatedAt   string                `json:"created_at,omitempty"`
-	UpdatedAt   string                `json:"updated_at,omitempty"`
-	StartedAt   string                `json:"started_at,omitempty"`
-	CompletedAt string                `json:"completed_at,omitempty"`
-	Release     *queueReleaseResponse `json:"release,omitempty"`
-	Progress    queueProgressResponse `json:"progress"`
-	Metrics     queueMetricsResponse  `json:"metrics"`
+	ID          string                  `json:"id"`
+	ReleaseID   string                  `json:"release_id"`
+	Status      domain.JobStatus        `json:"status"`
+	Priority    string                  `json:"priority"`
+	OutDir      string                  `json:"out_dir"`
+	Error       *string                 `json:"error,omitempty"`
+	CreatedAt   string                  `json:"created_at,omitempty"`
+	UpdatedAt   string                  `json:"updated_at,omitempty"`
+	StartedAt   string                  `json:"started_at,omitempty"`
+	CompletedAt string                  `json:"completed_at,omitempty"`
+	Release     *queueReleaseResponse   `json:"release,omitempty"`
+	Progress    queueProgressResponse   `json:"progress"`
+	Metrics     queueMetricsResponse    `json:"metrics"`
+	Duplicate   *queueDuplicateResponse `json:"duplicate,omitempty"`
+}
+
+type queueDuplicateResponse struct {
+	Of  string `json:"of"`
+	Key string `json:"key"` // release_id, nzb_hash or title
 }
 
 type queueReleaseResponse struct {
@@ -76,9 +107,12 @@ type queueFileResponse struct {
 	Size     int64    `json:"size"`
 	Index    int      `json:"index"`
 	IsPars   bool     `json:"is_pars"`
+	Skipped  bool     `json:"skipped"`
 	Subject  string   `json:"subject"`
 	Date     int64    `json:"date"`
 	Groups   []string `json:"groups"`
+
+	MissingSegments []int `json:"missing_segments,omitempty"`
 }
 
 type queueEventResponse struct {
@@ -215,6 +249,39 @@ func (ctrl *QueueController) Add(c *echo.Context) error {
 	return c.JSON(http.StatusCreated, mapQueueItem(item))
 }
 
+// CheckAvailability STATs a release's articles before it is enqueued.
+func (ctrl *QueueController) CheckAvailability(c *echo.Context) error {
+	if ctrl.Commands == nil {
+		return jsonError(c, http.StatusServiceUnavailable, "downloader queue service is unavailable")
+	}
+
+	var req availabilityRequest
+	if err := decodeJSONBody(c, &req); err != nil {
+		return jsonError(c, http.StatusBadRequest, err.Error())
+	}
+
+	req.ReleaseID = normalizeTrimmed(req.ReleaseID)
+	req.SourceKind = normalizeTrimmed(req.SourceKind)
+	if req.ReleaseID == "" {
+		return jsonError(c, http.StatusBadRequest, "release_id is required")
+	}
+
+	sample := -1
+	if req.Sample != nil {
+		if *req.Sample < 0 {
+			return jsonError(c, http.StatusBadRequest, "sample must be >= 0")
+		}
+		sample = *req.Sample
+	}
+
+	report, err := ctrl.Commands.CheckAvailability(c.Request().Context(), req.SourceKind, req.ReleaseID, sample)
+	if err != nil {
+		return jsonError(c, http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
 func (ctrl *QueueController) GetItemFiles(c *echo.Context) error {
 	if ctrl.Queries == nil {
 		return jsonError(c, http.StatusServiceUnavailable, "downloader queue service is unavailable")
@@ -286,6 +353,94 @@ func (ctrl *QueueController) Cancel(c *echo.Context) error {
 	})
 }
 
+func (ctrl *QueueController) Pause(c *echo.Context) error {
+	if ctrl.Commands == nil {
+		return jsonError(c, http.StatusServiceUnavailable, "downloader queue service is unavailable")
+	}
+
+	id := pathParamTrimmed(c, "id")
+	if id == "" {
+		return jsonError(c, http.StatusBadRequest, "missing queue item id")
+	}
+
+	if !ctrl.Commands.PauseItem(id) {
+		return jsonError(c, http.StatusNotFound, "unable to pause queue item")
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"ok": true,
+		"id": id,
+	})
+}
+
+func (ctrl *QueueController) Resume(c *echo.Context) error {
+	if ctrl.Commands == nil {
+		return jsonError(c, http.StatusServiceUnavailable, "downloader queue service is unavailable")
+	}
+
+	id := pathParamTrimmed(c, "id")
+	if id == "" {
+		return jsonError(c, http.StatusBadRequest, "missing queue item id")
+	}
+
+	if !ctrl.Commands.ResumeItem(id) {
+		return jsonError(c, http.StatusNotFound, "unable to resume queue item")
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"ok": true,
+		"id": id,
+	})
+}
+
+// Reprocess re-runs repair/extract/move for a failed history item from the
+// files already on disk; its events continue on the same queue item.
+func (ctrl *QueueController) Reprocess(c *echo.Context) error {
+	if ctrl.Commands == nil {
+		return jsonError(c, http.StatusServiceUnavailable, "downloader queue service is unavailable")
+	}
+
+	id := pathParamTrimmed(c, "id")
+	if id == "" {
+		return jsonError(c, http.StatusBadRequest, "missing queue item id")
+	}
+
+	item, err := ctrl.Commands.Reprocess(c.Request().Context(), id)
+	if err != nil {
+		return jsonError(c, http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, mapQueueItem(item))
+}
+
+// RetryWithPassword re-runs post-processing for a failed history item with a
+// supplied archive password, without downloading it again.
+func (ctrl *QueueController) RetryWithPassword(c *echo.Context) error {
+	if ctrl.Commands == nil {
+		return jsonError(c, http.StatusServiceUnavailable, "downloader queue service is unavailable")
+	}
+
+	id := pathParamTrimmed(c, "id")
+	if id == "" {
+		return jsonError(c, http.StatusBadRequest, "missing queue item id")
+	}
+
+	var req passwordRequest
+	if err := decodeJSONBody(c, &req); err != nil {
+		return jsonError(c, http.StatusBadRequest, err.Error())
+	}
+	if req.Password == "" {
+		return jsonError(c, http.StatusBadRequest, "password is required")
+	}
+
+	item, err := ctrl.Commands.RetryWithPassword(c.Request().Context(), id, req.Password)
+	if err != nil {
+		return jsonError(c, http.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, mapQueueItem(item))
+}
+
 func (ctrl *QueueController) CancelMany(c *echo.Context) error {
 	if ctrl.Commands == nil {
 		return jsonError(c, http.StatusServiceUnavailable, "downloader queue service is unavailable")
@@ -336,6 +491,39 @@ func (ctrl *QueueController) DeleteMany(c *echo.Context) error {
 	})
 }
 
+func (ctrl *QueueController) GetSpeedLimit(c *echo.Context) error {
+	if ctrl.Queries == nil {
+		return jsonError(c, http.StatusServiceUnavailable, "downloader queue service is unavailable")
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"limit_kbps": ctrl.Queries.SpeedLimit(),
+	})
+}
+
+func (ctrl *QueueController) SetSpeedLimit(c *echo.Context) error {
+	if ctrl.Commands == nil {
+		return jsonError(c, http.StatusServiceUnavailable, "downloader queue service is unavailable")
+	}
+
+	var req speedLimitRequest
+	if err := decodeJSONBody(c, &req); err != nil {
+		return jsonError(c, http.StatusBadRequest, err.Error())
+	}
+	if req.LimitKBps == nil || *req.LimitKBps < 0 {
+		return jsonError(c, http.StatusBadRequest, "limit_kbps must be >= 0")
+	}
+
+	if !ctrl.Commands.SetSpeedLimit(*req.LimitKBps) {
+		return jsonError(c, http.StatusServiceUnavailable, "download speed limiter is unavailable")
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"ok":         true,
+		"limit_kbps": *req.LimitKBps,
+	})
+}
+
 func (ctrl *QueueController) ClearHistory(c *echo.Context) error {
 	if ctrl.Commands == nil {
 		return jsonError(c, http.StatusServiceUnavailable, "downloader queue service is unavailable")
@@ -352,6 +540,88 @@ func (ctrl *QueueController) ClearHistory(c *echo.Context) error {
 	})
 }
 
+func (ctrl *QueueController) SetPriority(c *echo.Context) error {
+	if ctrl.Commands == nil {
+		return jsonError(c, http.StatusServiceUnavailable, "downloader queue service is unavailable")
+	}
+
+	id := pathParamTrimmed(c, "id")
+	if id == "" {
+		return jsonError(c, http.StatusBadRequest, "missing queue item id")
+	}
+
+	var req priorityRequest
+	if err := decodeJSONBody(c, &req); err != nil {
+		return jsonError(c, http.StatusBadRequest, err.Error())
+	}
+
+	priority, ok := domain.ParseQueuePriority(req.Priority)
+	if !ok {
+		return jsonError(c, http.StatusBadRequest, "priority must be one of force, high, normal, low")
+	}
+
+	position, ok := ctrl.Commands.SetPriority(id, priority)
+	if !ok {
+		return jsonError(c, http.StatusNotFound, "unable to set priority for queue item")
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"ok":       true,
+		"id":       id,
+		"priority": strings.ToLower(priority.String()),
+		"position": position,
+	})
+}
+
+func (ctrl *QueueController) Reorder(c *echo.Context) error {
+	if ctrl.Commands == nil {
+		return jsonError(c, http.StatusServiceUnavailable, "downloader queue service is unavailable")
+	}
+
+	var req reorderRequest
+	if err := decodeJSONBody(c, &req); err != nil {
+		return jsonError(c, http.StatusBadRequest, err.Error())
+	}
+
+	req.ID = normalizeTrimmed(req.ID)
+	if req.ID == "" {
+		return jsonError(c, http.StatusBadRequest, "missing queue item id")
+	}
+
+	var (
+		position int
+		ok       bool
+	)
+	if req.Position != nil {
+		if *req.Position < 0 {
+			return jsonError(c, http.StatusBadRequest, "position must be >= 0")
+		}
+		position, ok = ctrl.Commands.MoveToPosition(req.ID, *req.Position)
+	} else {
+		switch domain.QueueMove(normalizeLowerTrimmed(req.Action)) {
+		case domain.QueueMoveTop:
+			position, ok = ctrl.Commands.MoveToTop(req.ID)
+		case domain.QueueMoveBottom:
+			position, ok = ctrl.Commands.MoveToBottom(req.ID)
+		case domain.QueueMoveUp:
+			position, ok = ctrl.Commands.MoveUp(req.ID)
+		case domain.QueueMoveDown:
+			position, ok = ctrl.Commands.MoveDown(req.ID)
+		default:
+			return jsonError(c, http.StatusBadRequest, "action must be one of top, bottom, up, down or position must be set")
+		}
+	}
+	if !ok {
+		return jsonError(c, http.StatusNotFound, "unable to reorder queue item")
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"ok":       true,
+		"id":       req.ID,
+		"position": position,
+	})
+}
+
 func normalizeIDs(ids []string) []string {
 	out := make([]string, 0, len(ids))
 	seen := make(map[string]struct{}, len(ids))
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"strings"
 	"time"
 
 	"github.com/datallboy/gonzb/internal/domain"
@@ -19,6 +20,7 @@ func mapQueueItem(item *domain.QueueItem) queueItemResponse {
 		ID:        item.ID,
 		ReleaseID: item.ReleaseID,
 		Status:    item.Status,
+		Priority:  strings.ToLower(item.Priority.String()),
 		OutDir:    item.OutDir,
 		Error:     item.Error,
 		Progress: queueProgressResponse{
@@ -58,6 +60,9 @@ func mapQueueItem(item *domain.QueueItem) queueItemResponse {
 		}
 		resp.Release = rel
 	}
+	if item.DuplicateOf != "" {
+		resp.Duplicate = &queueDuplicateResponse{Of: item.DuplicateOf, Key: item.DuplicateKey}
+	}
 
 	return resp
 }
@@ -71,9 +76,12 @@ func mapQueueFileResponses(files []*domain.DownloadFile) []queueFileResponse {
 			Size:     f.Size,
 			Index:    f.Index,
 			IsPars:   f.IsPars,
+			Skipped:  f.Skipped,
 			Subject:  f.Subject,
 			Date:     f.Date,
 			Groups:   f.Groups,
+
+			MissingSegments: f.MissingSegments,
 		})
 	}
 	return resp
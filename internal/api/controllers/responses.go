@@ -38,6 +38,8 @@ type Searching struct {
 	Search   SearchCapability `xml:"search"`
 	TVSearch SearchCapability `xml:"tv-search"`
 	Movie    SearchCapability `xml:"movie-search"`
+	Audio    SearchCapability `xml:"audio-search"`
+	Book     SearchCapability `xml:"book-search"`
 }
 
 type SearchCapability struct {
@@ -83,13 +85,14 @@ type Channel struct {
 }
 
 type RSSItem struct {
-	Title      string    `xml:"title"`
-	GUID       RSSGUID   `xml:"guid"`
-	Link       string    `xml:"link"`
-	Category   string    `xml:"category"`
-	PubDate    string    `xml:"pubDate"`
-	Enclosure  Enclosure `xml:"enclosure"`
-	Attributes []Attr    `xml:"newznab:attr"`
+	Title       string    `xml:"title"`
+	GUID        RSSGUID   `xml:"guid"`
+	Description string    `xml:"description,omitempty"`
+	Link        string    `xml:"link"`
+	Category    string    `xml:"category"`
+	PubDate     string    `xml:"pubDate"`
+	Enclosure   Enclosure `xml:"enclosure"`
+	Attributes  []Attr    `xml:"newznab:attr"`
 }
 
 type RSSGUID struct {
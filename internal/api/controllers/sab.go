@@ -2,16 +2,20 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"mime/multipart"
 	"net/http"
 	"net/url"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/datallboy/gonzb/internal/app"
 	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/downloader"
 	"github.com/datallboy/gonzb/internal/infra/config"
 	"github.com/labstack/echo/v5"
 )
@@ -72,12 +76,18 @@ func (ctrl *SABController) Handle(c *echo.Context) error {
 		return ctrl.handleAddFile(c, req)
 	case "delete":
 		return ctrl.handleDelete(c, req)
+	case "retry":
+		return ctrl.handleRetry(c, req)
+	case "switch":
+		return ctrl.handleSwitch(c, req)
 	case "pause":
 		return ctrl.handlePause(c, req)
 	case "resume":
 		return ctrl.handleResume(c, req)
 	case "get_files":
 		return ctrl.handleGetFiles(c, req)
+	case "config":
+		return ctrl.handleConfig(c, req)
 	case "get_config":
 		return ctrl.handleGetConfig(c, req)
 	case "get_cats":
@@ -106,6 +116,15 @@ func bindSABRequest(c *echo.Context) (sabAPIRequest, error) {
 }
 
 func (ctrl *SABController) handleQueue(c *echo.Context, req sabAPIRequest) error {
+	switch strings.ToLower(req.Name) {
+	case "priority":
+		return ctrl.handleQueuePriority(c, req)
+	case "pause":
+		return ctrl.handleQueueItemPause(c, req, ctrl.Commands.PauseItem)
+	case "resume":
+		return ctrl.handleQueueItemPause(c, req, ctrl.Commands.ResumeItem)
+	}
+
 	queueData := ctrl.buildQueueData(req)
 
 	return c.JSON(http.StatusOK, sabQueueResponse{
@@ -113,6 +132,135 @@ func (ctrl *SABController) handleQueue(c *echo.Context, req sabAPIRequest) error
 	})
 }
 
+// SAB: mode=queue&name=priority&value=<nzo_id>&value2=<priority>
+func (ctrl *SABController) handleQueuePriority(c *echo.Context, req sabAPIRequest) error {
+	id := req.Value
+	if id == "" {
+		id = req.NZOID
+	}
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, sabStatusResponse{
+			Status: false,
+			Error:  "missing nzo_id/value for priority",
+		})
+	}
+
+	priority, ok := domain.ParseQueuePriority(req.Value2)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, sabStatusResponse{
+			Status: false,
+			Error:  "invalid priority value",
+		})
+	}
+
+	position, ok := ctrl.Commands.SetPriority(id, priority)
+	if !ok {
+		return c.JSON(http.StatusNotFound, sabStatusResponse{
+			Status: false,
+			Error:  "queue item not found",
+		})
+	}
+
+	return c.JSON(http.StatusOK, sabPriorityResponse{Position: position})
+}
+
+// SAB: mode=queue&name=pause|resume&value=<nzo_id>[,<nzo_id>...]
+func (ctrl *SABController) handleQueueItemPause(c *echo.Context, req sabAPIRequest, apply func(string) bool) error {
+	target := req.Value
+	if target == "" {
+		target = req.NZOID
+	}
+
+	ids := make([]string, 0)
+	for _, id := range strings.Split(target, ",") {
+		if id = normalizeTrimmed(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return c.JSON(http.StatusBadRequest, sabStatusResponse{
+			Status: false,
+			Error:  "missing nzo_id/value for " + strings.ToLower(req.Name),
+		})
+	}
+
+	found := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if apply(id) {
+			found = append(found, id)
+		}
+	}
+	if len(found) == 0 {
+		return c.JSON(http.StatusNotFound, sabStatusResponse{
+			Status: false,
+			Error:  "queue item not found",
+		})
+	}
+
+	return c.JSON(http.StatusOK, sabAddResponse{
+		Status: true,
+		NZOIDs: found,
+	})
+}
+
+// SAB: mode=switch&value=<nzo_id>&value2=<position|nzo_id>
+// The moved item takes the target position, clamped to its own priority band.
+func (ctrl *SABController) handleSwitch(c *echo.Context, req sabAPIRequest) error {
+	if req.Value == "" || req.Value2 == "" {
+		return c.JSON(http.StatusBadRequest, sabStatusResponse{
+			Status: false,
+			Error:  "switch requires value and value2",
+		})
+	}
+
+	var position int
+	var ok bool
+	if target, err := strconv.Atoi(req.Value2); err == nil {
+		position, ok = ctrl.Commands.MoveToPosition(req.Value, target)
+	} else {
+		position, ok = ctrl.Commands.MoveToItem(req.Value, req.Value2)
+	}
+	if !ok {
+		return c.JSON(http.StatusNotFound, sabStatusResponse{
+			Status: false,
+			Error:  "queue item not found",
+		})
+	}
+
+	priority := domain.PriorityNormal
+	if item, err := ctrl.Queries.GetItem(c.Request().Context(), req.Value); err == nil && item != nil {
+		priority = item.Priority
+	}
+
+	return c.JSON(http.StatusOK, sabSwitchResponse{
+		Result: sabSwitchResult{
+			Priority: int(priority),
+			Position: position,
+		},
+	})
+}
+
+// applyAddPriority honours the optional SAB priority param on addurl/addfile.
+// -100 (or empty) means "use default" and leaves the queue item untouched;
+// -2 (paused) holds the item and -3 (duplicate) queues it as-is, both at the default priority.
+func (ctrl *SABController) applyAddPriority(item *domain.QueueItem, raw string) {
+	if item == nil {
+		return
+	}
+	switch strings.TrimSpace(raw) {
+	case "", "-100", "-3":
+		return
+	case "-2":
+		ctrl.Commands.PauseItem(item.ID)
+		return
+	}
+	priority, ok := domain.ParseQueuePriority(raw)
+	if !ok {
+		return
+	}
+	ctrl.Commands.SetPriority(item.ID, priority)
+}
+
 func (ctrl *SABController) handleHistory(c *echo.Context, req sabAPIRequest) error {
 	if strings.EqualFold(req.Name, "delete") {
 		return ctrl.handleHistoryDelete(c, req)
@@ -137,7 +285,7 @@ func (ctrl *SABController) handleHistory(c *echo.Context, req sabAPIRequest) err
 		if item == nil {
 			continue
 		}
-		if item.Status != domain.StatusCompleted && item.Status != domain.StatusFailed {
+		if !item.Status.IsTerminal() {
 			continue
 		}
 		if !matchesSABSearch(item, req.Search) {
@@ -153,6 +301,7 @@ func (ctrl *SABController) handleHistory(c *echo.Context, req sabAPIRequest) err
 			Status:    mapSABHistoryStatus(item.Status),
 			Category:  queueItemCategory(item),
 			SizeBytes: queueItemSize(item),
+			Duplicate: item.DuplicateOf != "",
 		}
 
 		if item.Error != nil {
@@ -280,6 +429,7 @@ func (ctrl *SABController) handleAddURL(c *echo.Context, req sabAPIRequest) erro
 			Error:  err.Error(),
 		})
 	}
+	ctrl.applyAddPriority(item, req.Priority)
 
 	return c.JSON(http.StatusOK, sabAddResponse{
 		Status: true,
@@ -325,6 +475,7 @@ func (ctrl *SABController) handleAddFile(c *echo.Context, req sabAPIRequest) err
 			Error:  err.Error(),
 		})
 	}
+	ctrl.applyAddPriority(item, req.Priority)
 
 	return c.JSON(http.StatusOK, sabAddResponse{
 		Status: true,
@@ -364,11 +515,43 @@ func (ctrl *SABController) handleDelete(c *echo.Context, req sabAPIRequest) erro
 	})
 }
 
+// SAB: mode=retry&value=<nzo_id>[&password=<archive password>]
+// Re-runs post-processing on the files already downloaded for a failed job.
+func (ctrl *SABController) handleRetry(c *echo.Context, req sabAPIRequest) error {
+	id := req.retryTarget()
+	if id == "" {
+		return c.JSON(http.StatusBadRequest, sabStatusResponse{
+			Status: false,
+			Error:  "missing nzo_id/value for retry",
+		})
+	}
+
+	ctx := c.Request().Context()
+
+	var err error
+	if req.Password != "" {
+		_, err = ctrl.Commands.RetryWithPassword(ctx, id, req.Password)
+	} else {
+		_, err = ctrl.Commands.Reprocess(ctx, id)
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, sabStatusResponse{
+			Status: false,
+			Error:  err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, sabAddResponse{
+		Status: true,
+		NZOIDs: []string{id},
+	})
+}
+
 func (ctrl *SABController) handlePause(c *echo.Context, req sabAPIRequest) error {
 	if req.NZOID != "" || req.Value != "" || req.Name != "" {
 		return c.JSON(http.StatusBadRequest, sabStatusResponse{
 			Status: false,
-			Error:  "per-job pause is not supported; use global pause",
+			Error:  "per-job pause uses mode=queue&name=pause",
 		})
 	}
 
@@ -388,7 +571,7 @@ func (ctrl *SABController) handleResume(c *echo.Context, req sabAPIRequest) erro
 	if req.NZOID != "" || req.Value != "" || req.Name != "" {
 		return c.JSON(http.StatusBadRequest, sabStatusResponse{
 			Status: false,
-			Error:  "per-job resume is not supported; use global resume",
+			Error:  "per-job resume uses mode=queue&name=resume",
 		})
 	}
 
@@ -453,11 +636,99 @@ func (ctrl *SABController) handleVersion(c *echo.Context, _ sabAPIRequest) error
 }
 
 func (ctrl *SABController) handleGetCategories(c *echo.Context, _ sabAPIRequest) error {
+	categories := []string{config.DefaultCategory}
+	for _, category := range ctrl.configuredCategories() {
+		if category.Name != config.DefaultCategory {
+			categories = append(categories, category.Name)
+		}
+	}
 	return c.JSON(http.StatusOK, sabCategoriesResponse{
-		Categories: []string{"*", "movies", "tv"},
+		Categories: categories,
 	})
 }
 
+func (ctrl *SABController) handleConfig(c *echo.Context, req sabAPIRequest) error {
+	if !strings.EqualFold(req.Name, "speedlimit") {
+		return c.JSON(http.StatusBadRequest, sabStatusResponse{
+			Status: false,
+			Error:  "unsupported config name",
+		})
+	}
+
+	limitKBps, err := parseSABSpeedLimit(req.Value, ctrl.speedLimitBaseKBps())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, sabStatusResponse{
+			Status: false,
+			Error:  err.Error(),
+		})
+	}
+
+	if !ctrl.Commands.SetSpeedLimit(limitKBps) {
+		return c.JSON(http.StatusInternalServerError, sabStatusResponse{
+			Status: false,
+			Error:  "failed to set speed limit",
+		})
+	}
+
+	return c.JSON(http.StatusOK, sabStatusResponse{Status: true})
+}
+
+// parseSABSpeedLimit returns the limit in KB/s for a SAB speedlimit value. "500K" and
+// "2M" are absolute; a bare number is a percentage of baseKBps. As in SAB, "", 0 and
+// 100 clear the limit.
+func parseSABSpeedLimit(value string, baseKBps int) (int, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if value == "" {
+		return 0, nil
+	}
+
+	multiplier := 0
+	switch {
+	case strings.HasSuffix(value, "M"):
+		multiplier = 1024
+		value = strings.TrimSuffix(value, "M")
+	case strings.HasSuffix(value, "K"):
+		multiplier = 1
+		value = strings.TrimSuffix(value, "K")
+	}
+
+	n, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || n < 0 {
+		return 0, errors.New("invalid speedlimit value")
+	}
+	if multiplier > 0 {
+		return int(n * float64(multiplier)), nil
+	}
+
+	if n == 0 || n >= 100 {
+		return 0, nil
+	}
+	if baseKBps <= 0 {
+		return 0, errors.New("a percentage speedlimit needs a configured or scheduled speed limit")
+	}
+	return max(1, int(float64(baseKBps)*n/100)), nil
+}
+
+// speedLimitBaseKBps is the 100% mark for SAB percentage limits. GoNZB has no
+// line-speed setting, so the limit the config puts in effect right now stands
+// in: the active speed schedule window, else download.speed_limit_kbps.
+func (ctrl *SABController) speedLimitBaseKBps() int {
+	if cfg := ctrl.currentConfig(); cfg != nil {
+		limitKBps, _ := downloader.ScheduledSpeedLimit(cfg.Download, time.Now())
+		return limitKBps
+	}
+	return 0
+}
+
+// sabSpeedLimitPercent reports the active limit as a percentage of the base;
+// "100" when either is unlimited.
+func sabSpeedLimitPercent(limitKBps, baseKBps int) string {
+	if limitKBps <= 0 || baseKBps <= 0 {
+		return "100"
+	}
+	return strconv.Itoa(int(math.Round(float64(limitKBps) * 100 / float64(baseKBps))))
+}
+
 func (ctrl *SABController) handleGetConfig(c *echo.Context, req sabAPIRequest) error {
 	cfg := ctrl.buildSABConfig()
 
@@ -497,6 +768,11 @@ func (ctrl *SABController) handleFullStatus(c *echo.Context, req sabAPIRequest)
 		completeDir = strings.TrimSpace(cfg.Download.CompletedDir)
 	}
 
+	warnings := []map[string]any{}
+	if disk := ctrl.Queries.DiskSpace(); disk.Paused {
+		warnings = append(warnings, map[string]any{"type": "WARNING", "text": disk.Reason, "time": time.Now().Unix()})
+	}
+
 	return c.JSON(http.StatusOK, sabFullStatusResponse{
 		Status: sabFullStatusData{
 			Status:          queueData.Status,
@@ -562,7 +838,7 @@ func (ctrl *SABController) handleFullStatus(c *echo.Context, req sabAPIRequest)
 			WebLogFile:       nil,
 			NewRelease:       false,
 			NewRelURL:        nil,
-			Warnings:         []map[string]any{},
+			Warnings:         warnings,
 			Servers:          []sabStatusServer{},
 		},
 	})
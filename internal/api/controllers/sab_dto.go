@@ -5,6 +5,7 @@ type sabAPIRequest struct {
 	Output      string `query:"output" form:"output"`
 	Name        string `query:"name" form:"name"`
 	Value       string `query:"value" form:"value"`
+	Value2      string `query:"value2" form:"value2"`
 	NZOID       string `query:"nzo_id" form:"nzo_id"`
 	Limit       string `query:"limit" form:"limit"`
 	Start       string `query:"start" form:"start"`
@@ -25,6 +26,7 @@ type sabAPIRequest struct {
 	Priority string `query:"priority" form:"priority"`
 	PP       string `query:"pp" form:"pp"`
 	Script   string `query:"script" form:"script"`
+	Password string `query:"password" form:"password"`
 }
 
 func (r *sabAPIRequest) normalize() {
@@ -32,6 +34,7 @@ func (r *sabAPIRequest) normalize() {
 	r.Output = normalizeLowerTrimmed(r.Output)
 	r.Name = normalizeTrimmed(r.Name)
 	r.Value = normalizeTrimmed(r.Value)
+	r.Value2 = normalizeTrimmed(r.Value2)
 	r.NZOID = normalizeTrimmed(r.NZOID)
 	r.Limit = normalizeTrimmed(r.Limit)
 	r.Start = normalizeTrimmed(r.Start)
@@ -66,6 +69,13 @@ func (r sabAPIRequest) addURL() string {
 	return r.Value
 }
 
+func (r sabAPIRequest) retryTarget() string {
+	if r.Value != "" {
+		return r.Value
+	}
+	return r.NZOID
+}
+
 func (r sabAPIRequest) deleteTarget() string {
 	if r.Value != "" {
 		return r.Value
@@ -97,6 +107,19 @@ type sabAddResponse struct {
 	Error  string   `json:"error,omitempty"`
 }
 
+type sabPriorityResponse struct {
+	Position int `json:"position"`
+}
+
+type sabSwitchResponse struct {
+	Result sabSwitchResult `json:"result"`
+}
+
+type sabSwitchResult struct {
+	Priority int `json:"priority"`
+	Position int `json:"position"`
+}
+
 type sabQueueResponse struct {
 	Queue sabQueueData `json:"queue"`
 }
@@ -182,6 +205,7 @@ type sabHistorySlot struct {
 	Category    string `json:"category,omitempty"`
 	SizeBytes   int64  `json:"bytes"`
 	Storage     string `json:"storage,omitempty"`
+	Duplicate   bool   `json:"duplicate"`
 }
 
 type sabFilesResponse struct {
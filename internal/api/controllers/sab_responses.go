@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/infra/config"
 )
 
 // Keep SAB transport handlers in sab.go and move
@@ -34,8 +35,17 @@ func (ctrl *SABController) buildQueueData(req sabAPIRequest) sabQueueData {
 
 	visibleSlots := slots[start:end]
 
+	// a queue held by the free-space guard reports as paused, like SABnzbd's.
+	disk := ctrl.Queries.DiskSpace()
+	paused := ctrl.Queries.IsPaused() || disk.Paused
+	limitKBps := ctrl.Queries.SpeedLimit()
+	haveWarnings := "0"
+	if disk.Paused {
+		haveWarnings = "1"
+	}
+
 	queueStatus := "Paused"
-	if !ctrl.Queries.IsPaused() {
+	if !paused {
 		queueStatus = "Idle"
 	}
 	for _, slot := range slots {
@@ -53,10 +63,10 @@ func (ctrl *SABController) buildQueueData(req sabAPIRequest) sabQueueData {
 
 	return sabQueueData{
 		Status:          queueStatus,
-		Speedlimit:      "0",
-		SpeedlimitAbs:   "0",
-		Paused:          ctrl.Queries.IsPaused(),
-		PausedAll:       ctrl.Queries.IsPaused(),
+		Speedlimit:      sabSpeedLimitPercent(limitKBps, ctrl.speedLimitBaseKBps()),
+		SpeedlimitAbs:   strconv.Itoa(limitKBps * 1024),
+		Paused:          paused,
+		PausedAll:       paused,
 		NoOfSlotsTotal:  len(slots),
 		NoOfSlots:       len(visibleSlots),
 		Limit:           limit,
@@ -69,13 +79,13 @@ func (ctrl *SABController) buildQueueData(req sabAPIRequest) sabQueueData {
 		MB:              formatMB(summary.TotalBytes),
 		MBLeft:          formatMB(summary.LeftBytes),
 		Slots:           visibleSlots,
-		DiskSpace1:      "0.00",
-		DiskSpace2:      "0.00",
-		DiskSpaceTotal1: "0.00",
-		DiskSpaceTotal2: "0.00",
-		DiskSpace1Norm:  "0.0 G",
-		DiskSpace2Norm:  "0.0 G",
-		HaveWarnings:    "0",
+		DiskSpace1:      formatGB(disk.Incomplete.FreeBytes),
+		DiskSpace2:      formatGB(disk.Complete.FreeBytes),
+		DiskSpaceTotal1: formatGB(disk.Incomplete.TotalBytes),
+		DiskSpaceTotal2: formatGB(disk.Complete.TotalBytes),
+		DiskSpace1Norm:  formatNormGB(disk.Incomplete.FreeBytes),
+		DiskSpace2Norm:  formatNormGB(disk.Complete.FreeBytes),
+		HaveWarnings:    haveWarnings,
 		PauseInt:        "0",
 		LeftQuota:       "0 ",
 		Version:         "4.5.0",
@@ -97,25 +107,22 @@ func (ctrl *SABController) buildSABConfig() sabConfigData {
 		completeDir = strings.TrimSpace(cfg.Download.CompletedDir)
 	}
 
-	categories := []sabConfigCategory{
-		{
-			Name:     "movies",
-			Order:    1,
-			PP:       "3",
-			Script:   "None",
-			Dir:      categoryDir(completeDir, "movies"),
-			Newzbin:  "",
-			Priority: "0",
-		},
-		{
-			Name:     "tv",
-			Order:    2,
-			PP:       "3",
+	categories := make([]sabConfigCategory, 0)
+	for i, category := range ctrl.configuredCategories() {
+		dir := strings.TrimSpace(category.CompletedDir)
+		if dir == "" {
+			dir = categoryDir(completeDir, category.Name)
+		}
+		priority, _ := domain.ParseQueuePriority(category.Priority)
+		categories = append(categories, sabConfigCategory{
+			Name:     category.Name,
+			Order:    i + 1,
+			PP:       strconv.Itoa(category.PostProcessLevel()),
 			Script:   "None",
-			Dir:      categoryDir(completeDir, "tv"),
+			Dir:      dir,
 			Newzbin:  "",
-			Priority: "0",
-		},
+			Priority: strconv.Itoa(int(priority)),
+		})
 	}
 
 	return sabConfigData{
@@ -129,6 +136,21 @@ func (ctrl *SABController) buildSABConfig() sabConfigData {
 	}
 }
 
+// configuredCategories returns the user-defined categories resolved against
+// the global download settings, in configured order.
+func (ctrl *SABController) configuredCategories() []config.CategoryConfig {
+	cfg := ctrl.currentConfig()
+	if cfg == nil {
+		return nil
+	}
+	out := make([]config.CategoryConfig, 0, len(cfg.Download.Categories))
+	for _, category := range cfg.Download.Categories {
+		resolved, _ := cfg.Download.ResolveCategory(category.Name)
+		out = append(out, resolved)
+	}
+	return out
+}
+
 func categoryDir(baseDir, category string) string {
 	baseDir = strings.TrimSpace(baseDir)
 	category = strings.TrimSpace(category)
@@ -150,7 +172,7 @@ func (ctrl *SABController) buildQueueSlots(req sabAPIRequest) []sabQueueSlot {
 		if item == nil {
 			continue
 		}
-		if item.Status == domain.StatusCompleted || item.Status == domain.StatusFailed {
+		if item.Status.IsTerminal() {
 			continue
 		}
 		if !matchesSABSearch(item, req.Search) {
@@ -186,8 +208,8 @@ func (ctrl *SABController) buildQueueSlots(req sabAPIRequest) []sabQueueSlot {
 			Size:         formatSize(totalBytes),
 			SizeLeft:     formatSize(left),
 			Filename:     queueItemDisplayName(item),
-			Labels:       []string{},
-			Priority:     "Normal",
+			Labels:       queueItemLabels(item),
+			Priority:     item.Priority.String(),
 			Category:     queueItemCategory(item),
 			TimeLeft:     "0:00:00",
 			Percentage:   formatPercentage(written, totalBytes),
@@ -314,9 +336,11 @@ func mapSABQueueStatus(status domain.JobStatus) string {
 		return "Downloading"
 	case domain.StatusProcessing:
 		return "Processing"
+	case domain.StatusPaused:
+		return "Paused"
 	case domain.StatusCompleted:
 		return "Completed"
-	case domain.StatusFailed:
+	case domain.StatusFailed, domain.StatusPasswordRequired:
 		return "Failed"
 	default:
 		return string(status)
@@ -327,7 +351,7 @@ func mapSABHistoryStatus(status domain.JobStatus) string {
 	switch status {
 	case domain.StatusCompleted:
 		return "Completed"
-	case domain.StatusFailed:
+	case domain.StatusFailed, domain.StatusPasswordRequired:
 		return "Failed"
 	default:
 		return string(status)
@@ -341,6 +365,21 @@ func formatMB(bytes int64) string {
 	return fmt.Sprintf("%.2f", float64(bytes)/(1024*1024))
 }
 
+// formatGB renders free/total disk space the way SABnzbd's diskspace fields do.
+func formatGB(bytes int64) string {
+	if bytes <= 0 {
+		return "0.00"
+	}
+	return fmt.Sprintf("%.2f", float64(bytes)/(1024*1024*1024))
+}
+
+func formatNormGB(bytes int64) string {
+	if bytes <= 0 {
+		return "0.0 G"
+	}
+	return fmt.Sprintf("%.1f G", float64(bytes)/(1024*1024*1024))
+}
+
 func formatPercentage(done, total int64) string {
 	if total <= 0 {
 		return "0"
@@ -371,3 +410,12 @@ func formatSize(bytes int64) string {
 		return fmt.Sprintf("%d B", bytes)
 	}
 }
+
+// queueItemLabels mirrors SABnzbd's slot labels; flagged duplicates carry
+// "DUPLICATE" like SAB's own duplicate detection.
+func queueItemLabels(item *domain.QueueItem) []string {
+	if item.DuplicateOf != "" {
+		return []string{"DUPLICATE"}
+	}
+	return []string{}
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/datallboy/gonzb/internal/infra/config"
+)
+
+func TestParseSABSpeedLimit(t *testing.T) {
+	tests := []struct {
+		value   string
+		base    int
+		want    int
+		wantErr bool
+	}{
+		{value: "", base: 1000, want: 0},
+		{value: "500K", base: 1000, want: 500},
+		{value: "2m", base: 0, want: 2048},
+		{value: "50", base: 1000, want: 500},
+		{value: "100", base: 1000, want: 0},
+		{value: "150", base: 1000, want: 0},
+		{value: "0", base: 1000, want: 0},
+		{value: "0", base: 0, want: 0},
+		{value: "50", base: 0, wantErr: true},
+		{value: "-5", base: 1000, wantErr: true},
+		{value: "fast", base: 1000, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseSABSpeedLimit(tt.value, tt.base)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Fatalf("parseSABSpeedLimit(%q, %d) = %d, %v; want %d (error %v)", tt.value, tt.base, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestSABSpeedLimitPercent(t *testing.T) {
+	tests := []struct {
+		limit, base int
+		want        string
+	}{
+		{limit: 0, base: 1000, want: "100"},
+		{limit: 500, base: 0, want: "100"},
+		{limit: 250, base: 1000, want: "25"},
+		{limit: 1000, base: 1000, want: "100"},
+	}
+	for _, tt := range tests {
+		if got := sabSpeedLimitPercent(tt.limit, tt.base); got != tt.want {
+			t.Fatalf("sabSpeedLimitPercent(%d, %d) = %q, want %q", tt.limit, tt.base, got, tt.want)
+		}
+	}
+}
+
+func TestSABSpeedLimitBaseFollowsActiveSchedule(t *testing.T) {
+	cfg := &config.Config{Download: config.DownloadConfig{SpeedLimitKBps: 1000}}
+	ctrl := &SABController{CurrentConfig: func() *config.Config { return cfg }}
+	if got := ctrl.speedLimitBaseKBps(); got != 1000 {
+		t.Fatalf("base without a schedule = %d, want the configured 1000", got)
+	}
+
+	// start == end covers the whole day, so the window is always active.
+	cfg.Download.SpeedSchedules = []config.SpeedScheduleConfig{{Start: "00:00", End: "00:00", LimitKBps: 400}}
+	base := ctrl.speedLimitBaseKBps()
+	if base != 400 {
+		t.Fatalf("base inside a schedule window = %d, want 400", base)
+	}
+	if got, err := parseSABSpeedLimit("50", base); err != nil || got != 200 {
+		t.Fatalf("50%% of the scheduled limit = %d, %v; want 200", got, err)
+	}
+}
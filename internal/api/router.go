@@ -145,8 +145,15 @@ func RegisterRoutes(e *echo.Echo, appCtx *app.Context) {
 		v1Agg := e.Group("/api/v1", bodyLimitMiddleware(defaultJSONBodyLimit, defaultMultipartBodyLimit), apiTokenMiddleware(authSvc, auth.PermissionAggregatorReleasesRead))
 		v1Agg.GET("/releases/search", aggCtrl.SearchReleases)
 
+		// Stable feed URL for RSS pollers; ?cat= narrows it to categories.
+		e.GET("/rss", nzbCtrl.HandleRSS, apiTokenMiddleware(authSvc, auth.PermissionAggregatorReleasesRead))
+
 		// Keep direct NZB download endpoint under aggregator ownership.
 		e.GET("/nzb/:id", nzbCtrl.HandleDownload, apiTokenMiddleware(authSvc, auth.PermissionAggregatorReleasesRead))
+
+		v1AdminAgg := e.Group("/api/v1/admin/aggregator", bodyLimitMiddleware(adminJSONBodyLimit, defaultMultipartBodyLimit))
+		v1AdminAgg.Use(authMiddleware(authSvc, false, auth.PermissionAggregatorRuntimeRead))
+		v1AdminAgg.GET("/sources", aggCtrl.GetSourceHealth)
 	}
 
 	// Indexer-owned API surface.
@@ -230,11 +237,20 @@ func RegisterRoutes(e *echo.Echo, appCtx *app.Context) {
 		v1Queue.POST("/queue/bulk/cancel", queueCtrl.CancelMany)
 		v1Queue.POST("/queue/bulk/delete", queueCtrl.DeleteMany)
 		v1Queue.POST("/queue/history/clear", queueCtrl.ClearHistory)
+		v1Queue.POST("/queue/reorder", queueCtrl.Reorder)
+		v1Queue.GET("/queue/speedlimit", queueCtrl.GetSpeedLimit)
+		v1Queue.POST("/queue/speedlimit", queueCtrl.SetSpeedLimit)
 		v1Queue.GET("/queue/:id", queueCtrl.GetItem)
 		v1Queue.GET("/queue/:id/files", queueCtrl.GetItemFiles)
 		v1Queue.GET("/queue/:id/events", queueCtrl.GetItemEvents)
 		v1Queue.POST("/queue", queueCtrl.Add)
+		v1Queue.POST("/queue/availability", queueCtrl.CheckAvailability)
 		v1Queue.POST("/queue/:id/cancel", queueCtrl.Cancel)
+		v1Queue.POST("/queue/:id/priority", queueCtrl.SetPriority)
+		v1Queue.POST("/queue/:id/pause", queueCtrl.Pause)
+		v1Queue.POST("/queue/:id/resume", queueCtrl.Resume)
+		v1Queue.POST("/queue/:id/actions/password", queueCtrl.RetryWithPassword)
+		v1Queue.POST("/queue/:id/actions/reprocess", queueCtrl.Reprocess)
 		v1Queue.GET("/events/queue", eventCtrl.HandleEvents)
 
 		// Explicit SAB-compatible downloader surface.
@@ -333,7 +349,7 @@ func registerWebUIRoutes(e *echo.Echo) {
 	// SPA fallback for non-API paths.
 	e.RouteNotFound("/*", func(c *echo.Context) error {
 		p := c.Request().URL.Path
-		if strings.HasPrefix(p, "/api") || strings.HasPrefix(p, "/nzb") {
+		if strings.HasPrefix(p, "/api") || strings.HasPrefix(p, "/nzb") || strings.HasPrefix(p, "/rss") {
 			return c.NoContent(http.StatusNotFound)
 		}
 
@@ -26,10 +26,13 @@ func TestRegisterRoutesDownloaderOnly(t *testing.T) {
 	routes := routePaths(e)
 
 	assertRoutePresent(t, routes, "/api/v1/queue")
+	assertRoutePresent(t, routes, "/api/v1/queue/:id/actions/reprocess")
 	assertRoutePresent(t, routes, "/api/sab")
 	assertRoutePresent(t, routes, "/api/v1/events/queue")
 	assertRouteMissing(t, routes, "/api/v1/releases/search")
 	assertRouteMissing(t, routes, "/nzb/:id")
+	assertRouteMissing(t, routes, "/rss")
+	assertRouteMissing(t, routes, "/api/v1/admin/aggregator/sources")
 }
 
 func TestRegisterRoutesAggregatorOnly(t *testing.T) {
@@ -51,6 +54,8 @@ func TestRegisterRoutesAggregatorOnly(t *testing.T) {
 
 	assertRoutePresent(t, routes, "/api/v1/releases/search")
 	assertRoutePresent(t, routes, "/nzb/:id")
+	assertRoutePresent(t, routes, "/rss")
+	assertRoutePresent(t, routes, "/api/v1/admin/aggregator/sources")
 	assertRouteMissing(t, routes, "/api/v1/queue")
 	assertRouteMissing(t, routes, "/api/sab")
 }
@@ -33,6 +33,7 @@ type Context struct {
 	SettingsStore       SettingsStore
 	PGIndexStore        UsenetIndexStore
 	ArrNotifier         ArrNotifier
+	SpeedLimiter        SpeedLimiter
 
 	DownloaderModule DownloaderModule
 	AggregatorModule AggregatorModule
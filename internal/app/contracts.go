@@ -21,16 +21,33 @@ type DownloaderCommands interface {
 	ClearHistory(ctx context.Context) (int64, error)
 	Pause() bool
 	Resume() bool
+	PauseItem(id string) bool
+	ResumeItem(id string) bool
+	SetSpeedLimit(limitKBps int) bool
+	CheckAvailability(ctx context.Context, sourceKind, releaseID string, sample int) (*AvailabilityReport, error)
+	RetryWithPassword(ctx context.Context, id, password string) (*domain.QueueItem, error)
+	Reprocess(ctx context.Context, id string) (*domain.QueueItem, error)
+
+	// queue ordering; returns the item's new index in the active queue.
+	SetPriority(id string, priority domain.QueuePriority) (int, bool)
+	MoveToTop(id string) (int, bool)
+	MoveToBottom(id string) (int, bool)
+	MoveUp(id string) (int, bool)
+	MoveDown(id string) (int, bool)
+	MoveToPosition(id string, position int) (int, bool)
+	MoveToItem(id, targetID string) (int, bool)
 }
 
 type DownloaderQueries interface {
 	ListActive() []*domain.QueueItem
 	ListHistory(ctx context.Context, status string, limit, offset int) ([]*domain.QueueItem, int, error)
-	GetActiveItem() *domain.QueueItem
+	GetActiveItems() []*domain.QueueItem
+	SpeedLimit() int
 	GetItem(ctx context.Context, id string) (*domain.QueueItem, error)
 	GetItemFiles(ctx context.Context, id string) ([]*domain.DownloadFile, error)
 	GetItemEvents(ctx context.Context, id string) ([]*domain.QueueItemEvent, error)
 	IsPaused() bool
+	DiskSpace() DiskSpaceStatus
 }
 
 type DownloaderModule interface {
@@ -45,8 +62,29 @@ type AggregatorDownloadResult struct {
 }
 
 type AggregatorModule interface {
-	Search(ctx context.Context, req SearchRequest) ([]*domain.Release, error)
+	Search(ctx context.Context, req SearchRequest) ([]*domain.Release, int, error)
 	PrepareDownload(ctx context.Context, id string) (*AggregatorDownloadResult, error)
+	ReleaseDetails(ctx context.Context, id string) (*domain.Release, error)
+	ReleaseNFO(ctx context.Context, id string) (string, error)
+	SourceHealth(ctx context.Context) ([]AggregatorSourceHealth, error)
+}
+
+// AggregatorSourceHealth is the search track record of one aggregator source.
+// State is healthy, degraded (failing but still searched) or backoff.
+type AggregatorSourceHealth struct {
+	Name                string     `json:"name"`
+	State               string     `json:"state"`
+	TimeoutMS           int64      `json:"timeout_ms"`
+	Searches            int64      `json:"searches"`
+	Failures            int64      `json:"failures"`
+	ErrorRate           float64    `json:"error_rate"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastLatencyMS       int64      `json:"last_latency_ms"`
+	AvgLatencyMS        int64      `json:"avg_latency_ms"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	LastFailureAt       *time.Time `json:"last_failure_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	BackoffUntil        *time.Time `json:"backoff_until,omitempty"`
 }
 
 type SettingsAdmin interface {
@@ -59,6 +97,9 @@ type RuntimeCheck struct {
 	Name   string
 	OK     bool
 	Detail string
+	// Advisory checks report a degraded module when they fail but leave
+	// readiness untouched.
+	Advisory bool
 }
 
 type RuntimeModule interface {
@@ -71,13 +112,56 @@ type RuntimeModule interface {
 	ReadinessChecks(ctx context.Context) []RuntimeCheck
 }
 
+// SpeedLimiter is the downloader's global bandwidth cap; a limit of 0 means unlimited.
+type SpeedLimiter interface {
+	Limit() int64 // bytes per second
+	SetLimit(bytesPerSec int64)
+	WaitN(ctx context.Context, n int) error
+}
+
 type NNTPManager interface {
 	// This allows the engine to call the manager without importing the nntp package
 	Fetch(ctx context.Context, seg *domain.Segment, groups []string) (io.Reader, error)
+	Stat(ctx context.Context, msgIDs []string) (*AvailabilityReport, error)
 	TotalCapacity() int
 	Close() error // allows idle runtime swaps on settings reload
 }
 
+// AvailabilityReport is the outcome of a STAT pass over sampled message IDs.
+type AvailabilityReport struct {
+	Checked   int                    `json:"checked"`
+	Available int                    `json:"available"` // held by at least one provider
+	Percent   float64                `json:"percent"`
+	Providers []ProviderAvailability `json:"providers"`
+}
+
+// DiskSpaceStatus reports free space on the downloader's working paths and
+// whether the free-space guard is holding the queue.
+type DiskSpaceStatus struct {
+	Incomplete DiskSpacePath `json:"incomplete"`
+	Complete   DiskSpacePath `json:"complete"`
+	Paused     bool          `json:"paused"`
+	Reason     string        `json:"reason,omitempty"`
+}
+
+type DiskSpacePath struct {
+	Path         string `json:"path"`
+	FreeBytes    int64  `json:"free_bytes"`
+	TotalBytes   int64  `json:"total_bytes"`
+	MinFreeBytes int64  `json:"min_free_bytes"`
+	Visible      bool   `json:"visible"` // false when the platform cannot report the filesystem
+}
+
+type ProviderAvailability struct {
+	ProviderID string  `json:"provider_id"`
+	Provider   string  `json:"provider"`
+	Backup     bool    `json:"backup,omitempty"`
+	Checked    int     `json:"checked"`
+	Available  int     `json:"available"`
+	Percent    float64 `json:"percent"`
+	Error      string  `json:"error,omitempty"`
+}
+
 type NNTPRuntimeStats struct {
 	Scope           string                     `json:"scope"`
 	Policy          string                     `json:"policy"`
@@ -152,9 +236,15 @@ type NNTPProviderRuntimeStats struct {
 // Manager defines the contract for our NZB search and download engine.
 type IndexerAggregator interface {
 	SearchAll(ctx context.Context, query string) ([]*domain.Release, error)
-	SearchAllWithRequest(ctx context.Context, req SearchRequest) ([]*domain.Release, error)
+	// SearchAllWithRequest returns one page of merged results and the number
+	// of matches across all sources.
+	SearchAllWithRequest(ctx context.Context, req SearchRequest) ([]*domain.Release, int, error)
 	GetNZB(ctx context.Context, res *domain.Release) (io.ReadCloser, error)
 	GetResultByID(ctx context.Context, id string) (*domain.Release, error)
+	// GetDetails and GetNFO return nil/"" when no source knows the release.
+	GetDetails(ctx context.Context, id string) (*domain.Release, error)
+	GetNFO(ctx context.Context, id string) (string, error)
+	SourceHealth() []AggregatorSourceHealth
 }
 
 // minimal PG catalog boundary for Milestone 7 resolver routing.
@@ -209,6 +299,7 @@ type UsenetIndexStore interface {
 	ListPublicIndexerReleases(ctx context.Context, params pgindex.PublicIndexerReleaseListParams) ([]pgindex.PublicIndexerReleaseSummary, int, error)
 	GetPublicIndexerReleaseDetailWithPolicy(ctx context.Context, releaseID string, policy pgindex.ReleaseReadyPolicy) (*pgindex.PublicIndexerReleaseDetail, error)
 	GetPublicIndexerReleaseDetail(ctx context.Context, releaseID string) (*pgindex.PublicIndexerReleaseDetail, error)
+	GetPublicIndexerReleaseNFOWithPolicy(ctx context.Context, releaseID string, policy pgindex.ReleaseReadyPolicy) (string, error)
 	UpsertReleaseOverride(ctx context.Context, in pgindex.ReleaseOverrideRecord) error
 	GetReleaseOverride(ctx context.Context, releaseID string) (*pgindex.ReleaseOverrideRecord, error)
 	ResetReleaseInspectionState(ctx context.Context, releaseID string) error
@@ -375,6 +466,8 @@ type Processor interface {
 	Prepare(ctx context.Context, item *domain.QueueItem, nzbModel *nzb.Model, nzbFilename string) (*domain.PreparationResult, error)
 	Finalize(ctx context.Context, tasks []*domain.DownloadFile) error
 	PostProcess(ctx context.Context, item *domain.QueueItem, tasks []*domain.DownloadFile) error
+	// CompletedDir is where PostProcess moves the item's files.
+	CompletedDir(item *domain.QueueItem) string
 }
 
 type Downloader interface {
@@ -390,12 +483,14 @@ type QueueAddRequest struct {
 	SourceReleaseID string
 	Release         *domain.Release
 	Title           string
+	// NZBHash is the SHA-256 of the NZB when the caller already has its bytes.
+	NZBHash string
 }
 
 type QueueManager interface {
 	Start(ctx context.Context)
 	Add(ctx context.Context, req QueueAddRequest) (*domain.QueueItem, error)
-	GetActiveItem() *domain.QueueItem
+	GetActiveItems() []*domain.QueueItem
 	GetItem(ctx context.Context, id string) (*domain.QueueItem, bool)
 	GetAllItems() []*domain.QueueItem
 	Cancel(id string) bool
@@ -405,8 +500,19 @@ type QueueManager interface {
 	Pause() bool
 	Resume() bool
 	IsPaused() bool
+	DiskSpace() DiskSpaceStatus
+	PauseItem(id string) bool
+	ResumeItem(id string) bool
+
+	SetPriority(id string, priority domain.QueuePriority) (int, bool)
+	Move(id string, move domain.QueueMove) (int, bool)
+	MoveToPosition(id string, position int) (int, bool)
+	MoveToItem(id, targetID string) (int, bool)
 
 	HydrateItem(ctx context.Context, item *domain.QueueItem) error
+	RetryWithPassword(ctx context.Context, id, password string) (*domain.QueueItem, error)
+	Reprocess(ctx context.Context, id string) (*domain.QueueItem, error)
+	CheckReleaseAvailability(ctx context.Context, sourceKind, releaseID string, sample int) (*AvailabilityReport, error)
 	UpdateStatus(ctx context.Context, item *domain.QueueItem, status domain.JobStatus)
 	ReloadRuntime(appCtx *Context) // refresh future-job dependencies after settings reload
 }
@@ -428,6 +534,8 @@ type JobStore interface {
 	SaveQueueEvent(ctx context.Context, ev *domain.QueueItemEvent) error
 	GetQueueEvents(ctx context.Context, queueID string) ([]*domain.QueueItemEvent, error)
 	ResetStuckQueueItems(ctx context.Context, newStatus domain.JobStatus, oldStatuses ...domain.JobStatus) error
+	UpdateQueueItemOrder(ctx context.Context, items []*domain.QueueItem) error
+	FindDuplicateQueueItems(ctx context.Context, item *domain.QueueItem, excludeID string) ([]domain.DuplicateMatch, error)
 
 	// store liveness + schema handshake.
 	Ping(ctx context.Context) error
@@ -440,6 +548,13 @@ type JobStore interface {
 type QueueFileStore interface {
 	SaveQueueItemFiles(ctx context.Context, queueItemID string, files []*domain.DownloadFile) error
 	GetQueueItemFiles(ctx context.Context, queueItemID string) ([]*domain.DownloadFile, error)
+	SetQueueItemFilesSkipped(ctx context.Context, queueItemID string, fileIndexes []int) error
+	ReplaceMissingSegments(ctx context.Context, queueItemID string, missing map[int][]int) error
+
+	// segment-level resume journal for partially downloaded files.
+	SaveSegmentJournals(ctx context.Context, queueItemID string, journals []*domain.SegmentJournal) error
+	GetSegmentJournals(ctx context.Context, queueItemID string) ([]*domain.SegmentJournal, error)
+	DeleteSegmentJournals(ctx context.Context, queueItemID string) error
 }
 
 type BlobStore interface {
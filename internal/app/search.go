@@ -12,4 +12,23 @@ type SearchRequest struct {
 	Season   string
 	Episode  string
 	Genre    string
+
+	// t=music parameters.
+	Artist string
+	Album  string
+	Label  string
+	Track  string
+	Year   string
+
+	// t=book parameters.
+	Author string
+	Title  string
+
+	// Newznab category ids; a root id also matches its subcategories.
+	Categories []int
+	// paging is applied by each source; 0 Limit uses the source default.
+	Offset int
+	Limit  int
+	// only releases posted within this many days; 0 means no limit.
+	MaxAgeDays int
 }
@@ -16,9 +16,19 @@ func DefaultRuntimeSettings() *RuntimeSettings {
 		Indexers:          []IndexerRuntimeSettings{},
 		Aggregator:        &AggregatorRuntimeSettings{},
 		Download: &DownloadRuntimeSettings{
-			OutDir:            "./downloads",
-			CompletedDir:      "./downloads/completed",
-			CleanupExtensions: []string{"nzb", "par2", "sfv", "nfo"},
+			OutDir:             "./downloads",
+			CompletedDir:       "./downloads/completed",
+			CleanupExtensions:  []string{"nzb", "par2", "sfv", "nfo"},
+			MaxActiveDownloads: 1,
+			SpeedSchedules:     []DownloadSpeedScheduleRuntimeSettings{},
+			AvailabilitySample: 100,
+			Categories: []DownloadCategoryRuntimeSettings{
+				{Name: "movies"},
+				{Name: "tv"},
+			},
+			ScriptTimeoutSeconds:  600,
+			UnpackHeadroomPercent: 100,
+			WatchIntervalSeconds:  5,
 		},
 		NNTPPool: DefaultNNTPPoolRuntimeSettings(),
 		Indexing: &IndexingRuntimeSettings{
@@ -240,9 +250,24 @@ func FromConfig(cfg *config.Config) *RuntimeSettings {
 		Aggregator:        aggregatorRuntimeFromConfig(cfg.Aggregator),
 		ArrIntegrations:   []ArrIntegrationRuntimeSettings{},
 		Download: &DownloadRuntimeSettings{
-			OutDir:            cfg.Download.OutDir,
-			CompletedDir:      cfg.Download.CompletedDir,
-			CleanupExtensions: append([]string(nil), cfg.Download.CleanupExtensions...),
+			OutDir:                cfg.Download.OutDir,
+			CompletedDir:          cfg.Download.CompletedDir,
+			CleanupExtensions:     append([]string(nil), cfg.Download.CleanupExtensions...),
+			MaxActiveDownloads:    cfg.Download.MaxActiveDownloads,
+			SpeedLimitKBps:        cfg.Download.SpeedLimitKBps,
+			SpeedSchedules:        speedSchedulesFromConfig(cfg.Download.SpeedSchedules),
+			AvailabilityCheck:     cfg.Download.AvailabilityCheck,
+			AvailabilitySample:    cfg.Download.AvailabilitySample,
+			Categories:            categoriesFromConfig(cfg.Download.Categories),
+			DirectUnpack:          cfg.Download.DirectUnpack,
+			PreQueueScript:        cfg.Download.PreQueueScript,
+			ScriptTimeoutSeconds:  cfg.Download.ScriptTimeoutSeconds,
+			ArchivePasswords:      append([]string(nil), cfg.Download.ArchivePasswords...),
+			MinFreeIncompleteMB:   cfg.Download.MinFreeIncompleteMB,
+			MinFreeCompleteMB:     cfg.Download.MinFreeCompleteMB,
+			UnpackHeadroomPercent: cfg.Download.UnpackHeadroomPercent,
+			WatchDirs:             append([]string(nil), cfg.Download.WatchDirs...),
+			WatchIntervalSeconds:  cfg.Download.WatchIntervalSeconds,
 		},
 		NNTPPool: DefaultNNTPPoolRuntimeSettings(),
 		Indexing: func() *IndexingRuntimeSettings {
@@ -280,6 +305,8 @@ func FromConfig(cfg *config.Config) *RuntimeSettings {
 			APIPath:  idx.ApiPath,
 			APIKey:   idx.ApiKey,
 			Redirect: idx.Redirect,
+
+			TimeoutSeconds: idx.TimeoutSeconds,
 		})
 	}
 
@@ -458,6 +485,8 @@ func ApplyToConfig(base *config.Config, runtime *RuntimeSettings) *config.Config
 			ApiPath:  strings.TrimSpace(idx.APIPath),
 			ApiKey:   idx.APIKey,
 			Redirect: idx.Redirect,
+
+			TimeoutSeconds: idx.TimeoutSeconds,
 		})
 	}
 
@@ -476,6 +505,25 @@ func ApplyToConfig(base *config.Config, runtime *RuntimeSettings) *config.Config
 		if runtime.Download.CleanupExtensions != nil {
 			effective.Download.CleanupExtensions = append([]string(nil), runtime.Download.CleanupExtensions...)
 		}
+		if runtime.Download.MaxActiveDownloads > 0 {
+			effective.Download.MaxActiveDownloads = runtime.Download.MaxActiveDownloads
+		}
+		effective.Download.SpeedLimitKBps = runtime.Download.SpeedLimitKBps
+		effective.Download.SpeedSchedules = speedSchedulesToConfig(runtime.Download.SpeedSchedules)
+		effective.Download.AvailabilityCheck = runtime.Download.AvailabilityCheck
+		effective.Download.AvailabilitySample = runtime.Download.AvailabilitySample
+		if runtime.Download.Categories != nil {
+			effective.Download.Categories = categoriesToConfig(runtime.Download.Categories)
+		}
+		effective.Download.DirectUnpack = runtime.Download.DirectUnpack
+		effective.Download.PreQueueScript = strings.TrimSpace(runtime.Download.PreQueueScript)
+		effective.Download.ScriptTimeoutSeconds = runtime.Download.ScriptTimeoutSeconds
+		effective.Download.ArchivePasswords = cleanPasswords(runtime.Download.ArchivePasswords)
+		effective.Download.MinFreeIncompleteMB = runtime.Download.MinFreeIncompleteMB
+		effective.Download.MinFreeCompleteMB = runtime.Download.MinFreeCompleteMB
+		effective.Download.UnpackHeadroomPercent = runtime.Download.UnpackHeadroomPercent
+		effective.Download.WatchDirs = cleanWatchDirs(runtime.Download.WatchDirs)
+		effective.Download.WatchIntervalSeconds = runtime.Download.WatchIntervalSeconds
 	}
 
 	if runtime.Indexing != nil {
@@ -854,11 +902,116 @@ func cloneDownload(in *DownloadRuntimeSettings) *DownloadRuntimeSettings {
 	if in == nil {
 		return nil
 	}
-	return &DownloadRuntimeSettings{
-		OutDir:            in.OutDir,
-		CompletedDir:      in.CompletedDir,
-		CleanupExtensions: append([]string(nil), in.CleanupExtensions...),
+	cp := *in
+	cp.CleanupExtensions = append([]string(nil), in.CleanupExtensions...)
+	cp.ArchivePasswords = append([]string(nil), in.ArchivePasswords...)
+	cp.WatchDirs = append([]string(nil), in.WatchDirs...)
+	cp.SpeedSchedules = make([]DownloadSpeedScheduleRuntimeSettings, 0, len(in.SpeedSchedules))
+	for _, schedule := range in.SpeedSchedules {
+		schedule.Days = append([]string(nil), schedule.Days...)
+		cp.SpeedSchedules = append(cp.SpeedSchedules, schedule)
+	}
+	if in.Categories != nil {
+		cp.Categories = make([]DownloadCategoryRuntimeSettings, 0, len(in.Categories))
+		for _, category := range in.Categories {
+			category.CleanupExtensions = cloneCleanupExtensions(category.CleanupExtensions)
+			category.Passwords = append([]string(nil), category.Passwords...)
+			cp.Categories = append(cp.Categories, category)
+		}
+	}
+	return &cp
+}
+
+func categoriesFromConfig(in []config.CategoryConfig) []DownloadCategoryRuntimeSettings {
+	out := make([]DownloadCategoryRuntimeSettings, 0, len(in))
+	for _, category := range in {
+		out = append(out, DownloadCategoryRuntimeSettings{
+			Name:              category.Name,
+			CompletedDir:      category.CompletedDir,
+			PostProcessing:    category.PostProcessing,
+			Priority:          category.Priority,
+			CleanupExtensions: cloneCleanupExtensions(category.CleanupExtensions),
+			Script:            category.Script,
+			Passwords:         append([]string(nil), category.Passwords...),
+			DuplicatePolicy:   category.DuplicatePolicy,
+		})
+	}
+	return out
+}
+
+func categoriesToConfig(in []DownloadCategoryRuntimeSettings) []config.CategoryConfig {
+	out := make([]config.CategoryConfig, 0, len(in))
+	for _, category := range in {
+		out = append(out, config.CategoryConfig{
+			Name:              strings.TrimSpace(category.Name),
+			CompletedDir:      strings.TrimSpace(category.CompletedDir),
+			PostProcessing:    strings.ToLower(strings.TrimSpace(category.PostProcessing)),
+			Priority:          strings.ToLower(strings.TrimSpace(category.Priority)),
+			CleanupExtensions: cloneCleanupExtensions(category.CleanupExtensions),
+			Script:            strings.TrimSpace(category.Script),
+			Passwords:         cleanPasswords(category.Passwords),
+			DuplicatePolicy:   strings.ToLower(strings.TrimSpace(category.DuplicatePolicy)),
+		})
+	}
+	return out
+}
+
+// cleanPasswords drops blank entries. Passwords are not trimmed: leading or
+// trailing spaces may be part of one.
+func cleanPasswords(in []string) []string {
+	out := make([]string, 0, len(in))
+	for _, password := range in {
+		if strings.TrimSpace(password) == "" {
+			continue
+		}
+		out = append(out, password)
 	}
+	return out
+}
+
+func cleanWatchDirs(in []string) []string {
+	out := make([]string, 0, len(in))
+	for _, dir := range in {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			out = append(out, dir)
+		}
+	}
+	return out
+}
+
+// cloneCleanupExtensions keeps nil distinct from empty: nil inherits the
+// global cleanup list, empty cleans nothing.
+func cloneCleanupExtensions(in []string) []string {
+	if in == nil {
+		return nil
+	}
+	return append([]string{}, in...)
+}
+
+func speedSchedulesFromConfig(in []config.SpeedScheduleConfig) []DownloadSpeedScheduleRuntimeSettings {
+	out := make([]DownloadSpeedScheduleRuntimeSettings, 0, len(in))
+	for _, schedule := range in {
+		out = append(out, DownloadSpeedScheduleRuntimeSettings{
+			Days:      append([]string(nil), schedule.Days...),
+			Start:     schedule.Start,
+			End:       schedule.End,
+			LimitKBps: schedule.LimitKBps,
+		})
+	}
+	return out
+}
+
+func speedSchedulesToConfig(in []DownloadSpeedScheduleRuntimeSettings) []config.SpeedScheduleConfig {
+	out := make([]config.SpeedScheduleConfig, 0, len(in))
+	for _, schedule := range in {
+		out = append(out, config.SpeedScheduleConfig{
+			Days:      append([]string(nil), schedule.Days...),
+			Start:     schedule.Start,
+			End:       schedule.End,
+			LimitKBps: schedule.LimitKBps,
+		})
+	}
+	return out
 }
 
 func cloneNNTPPool(in *NNTPPoolRuntimeSettings) *NNTPPoolRuntimeSettings {
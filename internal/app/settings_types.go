@@ -48,6 +48,8 @@ type IndexerRuntimeSettings struct {
 	APIPath  string `json:"api_path"`
 	APIKey   string `json:"api_key"`
 	Redirect bool   `json:"redirect"`
+	// Search timeout in seconds; 0 uses the aggregator default.
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
 }
 
 type AggregatorRuntimeSettings struct {
@@ -64,9 +66,50 @@ type RuntimeToggle struct {
 }
 
 type DownloadRuntimeSettings struct {
-	OutDir            string   `json:"out_dir"`
+	OutDir             string   `json:"out_dir"`
+	CompletedDir       string   `json:"completed_dir"`
+	CleanupExtensions  []string `json:"cleanup_extensions"`
+	MaxActiveDownloads int      `json:"max_active_downloads,omitempty"`
+
+	SpeedLimitKBps int                                    `json:"speed_limit_kbps"`
+	SpeedSchedules []DownloadSpeedScheduleRuntimeSettings `json:"speed_schedules"`
+
+	AvailabilityCheck  bool `json:"availability_check"`
+	AvailabilitySample int  `json:"availability_sample"`
+
+	Categories []DownloadCategoryRuntimeSettings `json:"categories"`
+
+	DirectUnpack bool `json:"direct_unpack"`
+
+	PreQueueScript       string `json:"pre_queue_script"`
+	ScriptTimeoutSeconds int    `json:"script_timeout_seconds"`
+
+	ArchivePasswords []string `json:"archive_passwords"`
+
+	MinFreeIncompleteMB   int64 `json:"min_free_incomplete_mb"`
+	MinFreeCompleteMB     int64 `json:"min_free_complete_mb"`
+	UnpackHeadroomPercent int   `json:"unpack_headroom_percent"`
+
+	WatchDirs            []string `json:"watch_dirs"`
+	WatchIntervalSeconds int      `json:"watch_interval_seconds"`
+}
+
+type DownloadCategoryRuntimeSettings struct {
+	Name              string   `json:"name"`
 	CompletedDir      string   `json:"completed_dir"`
+	PostProcessing    string   `json:"post_processing"`
+	Priority          string   `json:"priority"`
 	CleanupExtensions []string `json:"cleanup_extensions"`
+	Script            string   `json:"script"`
+	Passwords         []string `json:"passwords"`
+	DuplicatePolicy   string   `json:"duplicate_policy"`
+}
+
+type DownloadSpeedScheduleRuntimeSettings struct {
+	Days      []string `json:"days"`
+	Start     string   `json:"start"`
+	End       string   `json:"end"`
+	LimitKBps int      `json:"limit_kbps"`
 }
 
 type NNTPPoolRuntimeSettings struct {
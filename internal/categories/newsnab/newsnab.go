@@ -327,6 +327,27 @@ func IDsForBrowse(categorySlug, subcategorySlug string) []int {
 	return nil
 }
 
+// ExpandIDs widens each root id in ids to the root and all of its
+// subcategories, the way a Newznab cat= filter is read. Unknown ids are kept
+// as given and duplicates are dropped.
+func ExpandIDs(ids []int) []int {
+	out := make([]int, 0, len(ids))
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		expanded := []int{id}
+		if subs, ok := subcategoriesByRoot[id]; ok {
+			expanded = subs
+		}
+		for _, e := range expanded {
+			if !seen[e] {
+				seen[e] = true
+				out = append(out, e)
+			}
+		}
+	}
+	return out
+}
+
 func CategoryIDs() []int {
 	out := make([]int, 0, len(byID))
 	for id := range byID {
@@ -126,3 +126,23 @@ func TestIDsForBrowseReturnsRootAndSubcategories(t *testing.T) {
 		t.Fatalf("expected tv browse ids to include TVHD, got %+v", ids)
 	}
 }
+
+func TestExpandIDsWidensRootsAndKeepsUnknownIDs(t *testing.T) {
+	ids := ExpandIDs([]int{TVHD, MoviesRoot, MoviesHD, 100042})
+	if ids[0] != TVHD {
+		t.Fatalf("expected subcategory to stay as given, got %+v", ids)
+	}
+	counts := map[int]int{}
+	for _, id := range ids {
+		counts[id]++
+	}
+	if counts[MoviesRoot] != 1 || counts[MoviesUHD] != 1 || counts[MoviesHD] != 1 {
+		t.Fatalf("expected movies root widened once without duplicates, got %+v", ids)
+	}
+	if counts[TVSD] != 0 {
+		t.Fatalf("expected TVHD alone not to pull in TVSD, got %+v", ids)
+	}
+	if counts[100042] != 1 {
+		t.Fatalf("expected unknown id to be kept, got %+v", ids)
+	}
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Keys a queue item can match an earlier download on, strongest first.
+const (
+	DuplicateKeyReleaseID = "release_id"
+	DuplicateKeyNZBHash   = "nzb_hash"
+	DuplicateKeyTitle     = "title"
+)
+
+var (
+	titlePasswordPattern  = regexp.MustCompile(`\{\{.*?\}\}`)
+	titleSeparatorPattern = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+)
+
+// NormalizeReleaseTitle reduces a release name to a comparison key: embedded
+// {{password}}, a trailing .nzb, case and punctuation are dropped, so
+// "Show.S01E01.720p" and "show s01e01 720p.nzb" compare equal.
+func NormalizeReleaseTitle(title string) string {
+	title = titlePasswordPattern.ReplaceAllString(title, " ")
+	title = strings.ToLower(strings.TrimSpace(title))
+	title = strings.TrimSuffix(title, ".nzb")
+	title = titleSeparatorPattern.ReplaceAllString(title, " ")
+	return strings.TrimSpace(title)
+}
+
+// DuplicateMatch is an earlier queue or history item a new download duplicates.
+type DuplicateMatch struct {
+	Item *QueueItem
+	Key  string // one of the DuplicateKey* constants
+}
@@ -2,6 +2,8 @@ package domain
 
 import (
 	"context"
+	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 )
@@ -12,8 +14,84 @@ const (
 	StatusPending     JobStatus = "pending"
 	StatusDownloading JobStatus = "downloading"
 	StatusProcessing  JobStatus = "processing" // Post-processing (unrar/7z)
+	StatusPaused      JobStatus = "paused"     // Held by the user; skipped until resumed
 	StatusCompleted   JobStatus = "completed"
 	StatusFailed      JobStatus = "failed"
+	// StatusPasswordRequired is a failed item whose archives are encrypted and
+	// no known password opened them; it can be retried with a password.
+	StatusPasswordRequired JobStatus = "password_required"
+)
+
+// IsTerminal reports whether the item has left the live queue.
+func (s JobStatus) IsTerminal() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusPasswordRequired
+}
+
+// QueuePriority orders queue items ahead of their sort position.
+// Values mirror SABnzbd's numeric priorities so *arr clients map directly.
+type QueuePriority int
+
+const (
+	PriorityLow    QueuePriority = -1
+	PriorityNormal QueuePriority = 0
+	PriorityHigh   QueuePriority = 1
+	PriorityForce  QueuePriority = 2 // starts even while the queue is paused
+
+	// SAB sends these on add; they are states rather than dispatch priorities.
+	sabPriorityPaused    QueuePriority = -2
+	sabPriorityDuplicate QueuePriority = -3
+)
+
+func (p QueuePriority) String() string {
+	switch p {
+	case PriorityLow:
+		return "Low"
+	case PriorityHigh:
+		return "High"
+	case PriorityForce:
+		return "Force"
+	default:
+		return "Normal"
+	}
+}
+
+// ParseQueuePriority accepts priority names (low/normal/high/force) or SAB numeric values.
+// SAB's paused (-2) and duplicate (-3) pseudo-priorities map to normal.
+func ParseQueuePriority(value string) (QueuePriority, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "low":
+		return PriorityLow, true
+	case "normal":
+		return PriorityNormal, true
+	case "high":
+		return PriorityHigh, true
+	case "force":
+		return PriorityForce, true
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return PriorityNormal, false
+	}
+	switch QueuePriority(n) {
+	case PriorityLow, PriorityNormal, PriorityHigh, PriorityForce:
+		return QueuePriority(n), true
+	case sabPriorityPaused, sabPriorityDuplicate:
+		return PriorityNormal, true
+	default:
+		return PriorityNormal, false
+	}
+}
+
+// QueueMove is a relative reorder action within an item's priority band.
+type QueueMove string
+
+const (
+	QueueMoveTop    QueueMove = "top"
+	QueueMoveBottom QueueMove = "bottom"
+	QueueMoveUp     QueueMove = "up"
+	QueueMoveDown   QueueMove = "down"
 )
 
 const (
@@ -29,6 +107,10 @@ type QueueItem struct {
 	Status    JobStatus
 	OutDir    string
 
+	// Queue ordering: higher priority first, then ascending sort position.
+	Priority     QueuePriority
+	SortPosition int64
+
 	// MIlestone 3 snapshot/reference fields
 	SourceKind          string // manaual | aggregator | usenet_index
 	SourceReleaseID     string
@@ -40,6 +122,12 @@ type QueueItem struct {
 	PayloadMode string // cached | ephemeral
 	Resumable   bool
 
+	// Duplicate detection: NZBHash is the SHA-256 of the NZB once known;
+	// DuplicateOf is the earlier item this one matched and DuplicateKey how.
+	NZBHash      string
+	DuplicateOf  string
+	DuplicateKey string
+
 	// Tasks are only present in RAM. When loaded from queue_items,
 	// this is nil until hydrated from BLOB store.
 	Tasks []*DownloadFile
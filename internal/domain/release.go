@@ -3,6 +3,7 @@ package domain
 import (
 	"path/filepath"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -23,6 +24,35 @@ type Release struct {
 	CacheVerifiedAt time.Time `json:"cache_verified_at"`
 	RedirectAllowed bool
 	Poster          string
+
+	// set by sources that know more than the basic listing, such as the
+	// local usenet index; nil otherwise.
+	Attributes *ReleaseAttributes `json:"attributes,omitempty"`
+}
+
+// ReleaseAttributes is the extended metadata a source holds for a release,
+// reported to Newznab clients as extended newznab:attr values.
+type ReleaseAttributes struct {
+	Files         int
+	Groups        []string
+	PasswordState string // not_passworded, password_known or password_unknown
+	HasNFO        bool
+	VideoCodec    string
+	AudioCodec    string
+	Resolution    string
+	TMDBID        int64
+	TVDBID        int64
+	IMDBID        string
+}
+
+// ReleaseCacheQuery filters a search of cached releases.
+type ReleaseCacheQuery struct {
+	Query string
+	// exact category values; empty matches every category.
+	Categories  []string
+	PostedAfter time.Time // zero means no age limit
+	Offset      int
+	Limit       int
 }
 
 // Segment represents an individual article to be fetched from Usenet
@@ -42,6 +72,7 @@ type DownloadFile struct {
 	Size      int64  // Expected total size from NZB
 	Index     int    // Original order in the NZB
 	IsPars    bool   // True if the file is a repar volume
+	Skipped   bool   // Recovery volume held back because verification did not need it
 	Subject   string
 	Date      int64
 	Groups    []string
@@ -52,10 +83,19 @@ type DownloadFile struct {
 	FinalPath  string
 	IsComplete bool
 	Password   string
+	PartExists bool // .part was already on disk before hydration, so a segment journal may apply
 
 	// Progress tracking
 	actualSize atomic.Int64
 	Segments   []Segment
+
+	// Segment resume journal, see segment_journal.go
+	journalMu    sync.Mutex
+	segmentDone  []byte
+	journalDirty bool
+
+	// MissingSegments holds the numbers of segments no provider could deliver.
+	MissingSegments []int
 }
 
 // NewDownloadFile is the constructor for creating a live download task.
@@ -0,0 +1,120 @@
+package domain
+
+// SegmentJournal is the persisted resume state for one file of a queue item.
+// Bit i of Bitmap is set once Segments[i] has been written and CRC-verified.
+type SegmentJournal struct {
+	FileIndex  int
+	Bitmap     []byte
+	ActualSize int64 // decoded size from the yEnc header, needed to truncate on finalize
+}
+
+// MarkSegmentDone records segment i as written and verified.
+func (f *DownloadFile) MarkSegmentDone(i int) {
+	if i < 0 || i >= len(f.Segments) {
+		return
+	}
+
+	f.journalMu.Lock()
+	defer f.journalMu.Unlock()
+
+	if f.segmentDone == nil {
+		f.segmentDone = make([]byte, (len(f.Segments)+7)/8)
+	}
+	f.segmentDone[i/8] |= 1 << (i % 8)
+	f.journalDirty = true
+}
+
+func (f *DownloadFile) IsSegmentDone(i int) bool {
+	f.journalMu.Lock()
+	defer f.journalMu.Unlock()
+
+	if i < 0 || i/8 >= len(f.segmentDone) {
+		return false
+	}
+	return f.segmentDone[i/8]&(1<<(i%8)) != 0
+}
+
+// PendingSegments counts segments not yet recorded in the journal.
+func (f *DownloadFile) PendingSegments() int {
+	pending := 0
+	for i := range f.Segments {
+		if !f.IsSegmentDone(i) {
+			pending++
+		}
+	}
+	return pending
+}
+
+// DoneSegmentBytes sums the NZB sizes of journaled segments for progress reporting.
+func (f *DownloadFile) DoneSegmentBytes() int64 {
+	var total int64
+	for i, seg := range f.Segments {
+		if f.IsSegmentDone(i) {
+			total += seg.Bytes
+		}
+	}
+	return total
+}
+
+// RestoreSegmentJournal loads persisted resume state into a freshly hydrated file.
+func (f *DownloadFile) RestoreSegmentJournal(journal *SegmentJournal) {
+	if journal == nil {
+		return
+	}
+
+	f.journalMu.Lock()
+	defer f.journalMu.Unlock()
+
+	f.segmentDone = make([]byte, (len(f.Segments)+7)/8)
+	copy(f.segmentDone, journal.Bitmap)
+	f.journalDirty = false
+	if journal.ActualSize > 0 {
+		f.actualSize.Store(journal.ActualSize)
+	}
+}
+
+// TakeSegmentJournal returns a snapshot of unsaved journal changes, or nil if nothing changed.
+func (f *DownloadFile) TakeSegmentJournal() *SegmentJournal {
+	f.journalMu.Lock()
+	defer f.journalMu.Unlock()
+
+	if !f.journalDirty {
+		return nil
+	}
+	f.journalDirty = false
+
+	return &SegmentJournal{
+		FileIndex:  f.Index,
+		Bitmap:     append([]byte(nil), f.segmentDone...),
+		ActualSize: f.actualSize.Load(),
+	}
+}
+
+// MarkSegmentMissing records segment i as permanently unavailable.
+func (f *DownloadFile) MarkSegmentMissing(i int) {
+	if i < 0 || i >= len(f.Segments) {
+		return
+	}
+
+	f.journalMu.Lock()
+	defer f.journalMu.Unlock()
+
+	f.MissingSegments = append(f.MissingSegments, f.Segments[i].Number)
+}
+
+// MissingSegmentNumbers returns a copy of the segments recorded missing.
+func (f *DownloadFile) MissingSegmentNumbers() []int {
+	f.journalMu.Lock()
+	defer f.journalMu.Unlock()
+
+	return append([]int(nil), f.MissingSegments...)
+}
+
+// ResetMissingSegments forgets earlier failures so a new download pass can try
+// those segments again.
+func (f *DownloadFile) ResetMissingSegments() {
+	f.journalMu.Lock()
+	defer f.journalMu.Unlock()
+
+	f.MissingSegments = nil
+}
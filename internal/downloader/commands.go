@@ -9,6 +9,7 @@ import (
 
 	"github.com/datallboy/gonzb/internal/app"
 	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/infra/config"
 )
 
 type Commands struct {
@@ -47,6 +48,10 @@ func (c *Commands) EnqueueByReleaseID(ctx context.Context, sourceKind, releaseID
 	if title != "" {
 		releaseCopy.Title = title
 	}
+	category, known := c.resolveCategory(releaseCopy.Category)
+	if known {
+		releaseCopy.Category = category.Name
+	}
 
 	item, err := queue.Add(ctx, app.QueueAddRequest{
 		SourceKind:      sourceKind,
@@ -61,6 +66,9 @@ func (c *Commands) EnqueueByReleaseID(ctx context.Context, sourceKind, releaseID
 	if item.Release == nil {
 		item.Release = &releaseCopy
 	}
+	if known {
+		c.applyCategoryPriority(queue, item, category)
+	}
 
 	return item, nil
 }
@@ -101,12 +109,13 @@ func (c *Commands) EnqueueNZBWithCategory(ctx context.Context, filename, categor
 		return nil, fmt.Errorf("failed to persist nzb in blob store: %w", err)
 	}
 
+	resolved, _ := c.resolveCategory(normalizeQueueCategory(category))
 	manualRelease := &domain.Release{
 		ID:       releaseID,
 		GUID:     releaseID,
 		Title:    filename,
 		Source:   "manual",
-		Category: normalizeQueueCategory(category),
+		Category: resolved.Name,
 	}
 
 	item, err := queue.Add(ctx, app.QueueAddRequest{
@@ -114,6 +123,7 @@ func (c *Commands) EnqueueNZBWithCategory(ctx context.Context, filename, categor
 		SourceReleaseID: releaseID,
 		Release:         manualRelease,
 		Title:           filename,
+		NZBHash:         releaseID,
 	})
 	if err != nil {
 		return nil, err
@@ -122,6 +132,7 @@ func (c *Commands) EnqueueNZBWithCategory(ctx context.Context, filename, categor
 	if item.Release == nil {
 		item.Release = manualRelease
 	}
+	c.applyCategoryPriority(queue, item, resolved)
 
 	return item, nil
 }
@@ -162,7 +173,7 @@ func (c *Commands) DeleteMany(ctx context.Context, ids []string) (int64, error)
 		if !ok || item == nil {
 			continue
 		}
-		if item.Status == domain.StatusCompleted || item.Status == domain.StatusFailed {
+		if item.Status.IsTerminal() {
 			terminal = append(terminal, id)
 		}
 	}
@@ -175,7 +186,7 @@ func (c *Commands) ClearHistory(ctx context.Context) (int64, error) {
 	if jobStore == nil {
 		return 0, fmt.Errorf("job store is unavailable")
 	}
-	return jobStore.ClearQueueHistory(ctx, []domain.JobStatus{domain.StatusCompleted, domain.StatusFailed})
+	return jobStore.ClearQueueHistory(ctx, []domain.JobStatus{domain.StatusCompleted, domain.StatusFailed, domain.StatusPasswordRequired})
 }
 
 func (c *Commands) Pause() bool {
@@ -194,6 +205,152 @@ func (c *Commands) Resume() bool {
 	return queue.Resume()
 }
 
+func (c *Commands) PauseItem(id string) bool {
+	queue := c.provider.Queue()
+	if queue == nil {
+		return false
+	}
+	return queue.PauseItem(id)
+}
+
+func (c *Commands) ResumeItem(id string) bool {
+	queue := c.provider.Queue()
+	if queue == nil {
+		return false
+	}
+	return queue.ResumeItem(id)
+}
+
+// SetSpeedLimit overrides the global download limit in KB/s until the next schedule boundary.
+// 0 removes the limit.
+func (c *Commands) SetSpeedLimit(limitKBps int) bool {
+	limiter := c.speedLimiter()
+	if limiter == nil || limitKBps < 0 {
+		return false
+	}
+	limiter.SetLimit(int64(limitKBps) * 1024)
+	return true
+}
+
+// CheckAvailability STATs a sample of a release's articles on every download
+// provider without queueing it. A negative sample uses the configured default.
+func (c *Commands) CheckAvailability(ctx context.Context, sourceKind, releaseID string, sample int) (*app.AvailabilityReport, error) {
+	if releaseID == "" {
+		return nil, fmt.Errorf("release_id is required")
+	}
+
+	queue := c.provider.Queue()
+	if queue == nil {
+		return nil, fmt.Errorf("downloader queue is unavailable")
+	}
+	return queue.CheckReleaseAvailability(ctx, normalizeReleaseSourceKind(sourceKind), releaseID, sample)
+}
+
+// RetryWithPassword re-runs post-processing for a failed history item with a
+// user-supplied archive password, reusing the files already downloaded.
+func (c *Commands) RetryWithPassword(ctx context.Context, id, password string) (*domain.QueueItem, error) {
+	if password == "" {
+		return nil, fmt.Errorf("password is required")
+	}
+
+	queue := c.provider.Queue()
+	if queue == nil {
+		return nil, fmt.Errorf("downloader queue is unavailable")
+	}
+	return queue.RetryWithPassword(ctx, id, password)
+}
+
+// Reprocess re-runs repair/extract/move for a failed history item from the
+// files already on disk.
+func (c *Commands) Reprocess(ctx context.Context, id string) (*domain.QueueItem, error) {
+	queue := c.provider.Queue()
+	if queue == nil {
+		return nil, fmt.Errorf("downloader queue is unavailable")
+	}
+	return queue.Reprocess(ctx, id)
+}
+
+func (c *Commands) speedLimiter() app.SpeedLimiter {
+	if c.provider.SpeedLimiter == nil {
+		return nil
+	}
+	return c.provider.SpeedLimiter()
+}
+
+func (c *Commands) SetPriority(id string, priority domain.QueuePriority) (int, bool) {
+	queue := c.provider.Queue()
+	if queue == nil {
+		return -1, false
+	}
+	return queue.SetPriority(id, priority)
+}
+
+func (c *Commands) MoveToTop(id string) (int, bool) {
+	return c.move(id, domain.QueueMoveTop)
+}
+
+func (c *Commands) MoveToBottom(id string) (int, bool) {
+	return c.move(id, domain.QueueMoveBottom)
+}
+
+func (c *Commands) MoveUp(id string) (int, bool) {
+	return c.move(id, domain.QueueMoveUp)
+}
+
+func (c *Commands) MoveDown(id string) (int, bool) {
+	return c.move(id, domain.QueueMoveDown)
+}
+
+func (c *Commands) MoveToPosition(id string, position int) (int, bool) {
+	queue := c.provider.Queue()
+	if queue == nil {
+		return -1, false
+	}
+	return queue.MoveToPosition(id, position)
+}
+
+func (c *Commands) MoveToItem(id, targetID string) (int, bool) {
+	queue := c.provider.Queue()
+	if queue == nil {
+		return -1, false
+	}
+	return queue.MoveToItem(id, targetID)
+}
+
+func (c *Commands) move(id string, move domain.QueueMove) (int, bool) {
+	queue := c.provider.Queue()
+	if queue == nil {
+		return -1, false
+	}
+	return queue.Move(id, move)
+}
+
+// resolveCategory matches name against the configured download categories;
+// the bool reports whether it is one of them.
+func (c *Commands) resolveCategory(name string) (config.CategoryConfig, bool) {
+	if c.provider.Config == nil {
+		return config.CategoryConfig{Name: name}, false
+	}
+	cfg := c.provider.Config()
+	if cfg == nil {
+		return config.CategoryConfig{Name: name}, false
+	}
+	return cfg.Download.ResolveCategory(name)
+}
+
+// applyCategoryPriority gives a freshly queued item its category's default
+// priority unless the pre-queue script already set one. Callers with an
+// explicit priority apply it afterwards.
+func (c *Commands) applyCategoryPriority(queue app.QueueManager, item *domain.QueueItem, category config.CategoryConfig) {
+	priority, ok := domain.ParseQueuePriority(category.Priority)
+	if !ok || priority == domain.PriorityNormal || item == nil || item.Priority != domain.PriorityNormal {
+		return
+	}
+	if _, ok := queue.SetPriority(item.ID, priority); ok {
+		item.Priority = priority
+	}
+}
+
 func normalizeQueueCategory(category string) string {
 	category = strings.TrimSpace(category)
 	if category == "" {
@@ -1,6 +1,9 @@
 package downloader
 
-import "github.com/datallboy/gonzb/internal/app"
+import (
+	"github.com/datallboy/gonzb/internal/app"
+	"github.com/datallboy/gonzb/internal/infra/config"
+)
 
 type DependencyProvider struct {
 	Queue          func() app.QueueManager
@@ -8,6 +11,8 @@ type DependencyProvider struct {
 	BlobStore      func() app.BlobStore
 	JobStore       func() app.JobStore
 	QueueFileStore func() app.QueueFileStore
+	SpeedLimiter   func() app.SpeedLimiter
+	Config         func() *config.Config
 }
 
 type Module struct {
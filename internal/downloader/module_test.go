@@ -5,9 +5,11 @@ import (
 	"context"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/datallboy/gonzb/internal/app"
 	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/infra/config"
 )
 
 func TestCommandsEnqueueByReleaseIDUsesResolverAndQueue(t *testing.T) {
@@ -146,18 +148,147 @@ func TestQueriesListHistoryReversesAndPaginates(t *testing.T) {
 	}
 }
 
+func TestCommandsMoveMapsToQueueMoves(t *testing.T) {
+	queue := &fakeQueueManager{}
+	module := NewModule(DependencyProvider{
+		Queue: func() app.QueueManager { return queue },
+	})
+
+	cmds := module.Commands()
+	cases := []struct {
+		run  func(string) (int, bool)
+		want domain.QueueMove
+	}{
+		{cmds.MoveToTop, domain.QueueMoveTop},
+		{cmds.MoveToBottom, domain.QueueMoveBottom},
+		{cmds.MoveUp, domain.QueueMoveUp},
+		{cmds.MoveDown, domain.QueueMoveDown},
+	}
+	for _, tc := range cases {
+		if _, ok := tc.run("queue-1"); !ok {
+			t.Fatalf("expected %s move to succeed", tc.want)
+		}
+		if queue.lastMove != tc.want {
+			t.Fatalf("expected move %q, got %q", tc.want, queue.lastMove)
+		}
+	}
+
+	if _, ok := cmds.SetPriority("queue-1", domain.PriorityForce); !ok {
+		t.Fatal("expected SetPriority to succeed")
+	}
+	if queue.lastPriority != domain.PriorityForce {
+		t.Fatalf("expected force priority, got %s", queue.lastPriority)
+	}
+}
+
+func TestCommandsCheckAvailabilityNormalizesSourceKind(t *testing.T) {
+	queue := &fakeQueueManager{}
+	module := NewModule(DependencyProvider{
+		Queue: func() app.QueueManager { return queue },
+	})
+
+	report, err := module.Commands().CheckAvailability(context.Background(), "usenet_indexer", "rel-1", 25)
+	if err != nil {
+		t.Fatalf("check availability: %v", err)
+	}
+	if queue.lastAvailabilityCheck != "usenet_index/rel-1" || report.Checked != 25 {
+		t.Fatalf("unexpected availability call %q with report %+v", queue.lastAvailabilityCheck, report)
+	}
+
+	if _, err := module.Commands().CheckAvailability(context.Background(), "", "", -1); err == nil {
+		t.Fatal("expected an error without a release id")
+	}
+}
+
+func TestSpeedSchedulerKeepsOverrideUntilWindowChanges(t *testing.T) {
+	limiter := &fakeSpeedLimiter{}
+	cfg := &config.Config{Download: config.DownloadConfig{
+		SpeedLimitKBps: 0,
+		SpeedSchedules: []config.SpeedScheduleConfig{{Start: "09:00", End: "17:00", LimitKBps: 2048}},
+	}}
+	now := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+
+	scheduler := NewSpeedScheduler(
+		func() app.SpeedLimiter { return limiter },
+		func() *config.Config { return cfg },
+		nil,
+	)
+	scheduler.now = func() time.Time { return now }
+
+	scheduler.Refresh()
+	if limiter.limit != 0 {
+		t.Fatalf("expected unlimited outside the window, got %d", limiter.limit)
+	}
+
+	// manual override survives ticks inside the same window.
+	limiter.SetLimit(100 * 1024)
+	scheduler.evaluate(false)
+	if limiter.limit != 100*1024 {
+		t.Fatalf("expected override to hold, got %d", limiter.limit)
+	}
+
+	now = now.Add(2 * time.Hour)
+	scheduler.evaluate(false)
+	if limiter.limit != 2048*1024 {
+		t.Fatalf("expected scheduled limit once the window opens, got %d", limiter.limit)
+	}
+}
+
+func TestCommandsEnqueueNZBWithCategoryAppliesCategoryDefaults(t *testing.T) {
+	queue := &fakeQueueManager{
+		addResult: &domain.QueueItem{ID: "queue-3"},
+	}
+	cfg := &config.Config{Download: config.DownloadConfig{
+		Categories: []config.CategoryConfig{{Name: "TV", Priority: "high"}},
+	}}
+
+	module := NewModule(DependencyProvider{
+		Queue:     func() app.QueueManager { return queue },
+		BlobStore: func() app.BlobStore { return &fakeBlobStore{} },
+		Config:    func() *config.Config { return cfg },
+	})
+
+	item, err := module.Commands().EnqueueNZBWithCategory(context.Background(), "upload.nzb", "tv", bytes.NewBufferString("sample-nzb"))
+	if err != nil {
+		t.Fatalf("EnqueueNZBWithCategory() error = %v", err)
+	}
+	if queue.lastAddRequest.Release.Category != "TV" {
+		t.Fatalf("expected canonical category TV, got %q", queue.lastAddRequest.Release.Category)
+	}
+	if queue.lastPriority != domain.PriorityHigh || item.Priority != domain.PriorityHigh {
+		t.Fatalf("expected category priority high, got queue=%v item=%v", queue.lastPriority, item.Priority)
+	}
+
+	queue.lastPriority = domain.PriorityNormal
+	if _, err := module.Commands().EnqueueNZBWithCategory(context.Background(), "other.nzb", "anime", bytes.NewBufferString("other-nzb")); err != nil {
+		t.Fatalf("EnqueueNZBWithCategory() error = %v", err)
+	}
+	if queue.lastAddRequest.Release.Category != "anime" || queue.lastPriority != domain.PriorityNormal {
+		t.Fatalf("expected unknown category to keep its name and default priority, got %q/%v", queue.lastAddRequest.Release.Category, queue.lastPriority)
+	}
+}
+
 type fakeQueueManager struct {
 	addResult      *domain.QueueItem
 	lastAddRequest app.QueueAddRequest
+	addRequests    []app.QueueAddRequest
 	items          map[string]*domain.QueueItem
-	activeItem     *domain.QueueItem
+	activeItems    []*domain.QueueItem
 	paused         bool
+	lastPriority   domain.QueuePriority
+	lastMove       domain.QueueMove
+	lastPausedID   string
+	lastResumedID  string
+
+	lastAvailabilityCheck string
+	lastRetryPassword     string
 }
 
 func (f *fakeQueueManager) Start(context.Context) {}
 
 func (f *fakeQueueManager) Add(_ context.Context, req app.QueueAddRequest) (*domain.QueueItem, error) {
 	f.lastAddRequest = req
+	f.addRequests = append(f.addRequests, req)
 	if f.items == nil {
 		f.items = map[string]*domain.QueueItem{}
 	}
@@ -168,7 +299,7 @@ func (f *fakeQueueManager) Add(_ context.Context, req app.QueueAddRequest) (*dom
 	return f.addResult, nil
 }
 
-func (f *fakeQueueManager) GetActiveItem() *domain.QueueItem { return f.activeItem }
+func (f *fakeQueueManager) GetActiveItems() []*domain.QueueItem { return f.activeItems }
 
 func (f *fakeQueueManager) GetItem(_ context.Context, id string) (*domain.QueueItem, bool) {
 	if f.items == nil {
@@ -198,9 +329,45 @@ func (f *fakeQueueManager) Resume() bool {
 	return true
 }
 func (f *fakeQueueManager) IsPaused() bool { return f.paused }
+func (f *fakeQueueManager) DiskSpace() app.DiskSpaceStatus {
+	return app.DiskSpaceStatus{}
+}
+func (f *fakeQueueManager) PauseItem(id string) bool {
+	f.lastPausedID = id
+	return true
+}
+func (f *fakeQueueManager) ResumeItem(id string) bool {
+	f.lastResumedID = id
+	return true
+}
+func (f *fakeQueueManager) SetPriority(id string, priority domain.QueuePriority) (int, bool) {
+	f.lastPriority = priority
+	return 0, true
+}
+func (f *fakeQueueManager) Move(id string, move domain.QueueMove) (int, bool) {
+	f.lastMove = move
+	return 0, true
+}
+func (f *fakeQueueManager) MoveToPosition(id string, position int) (int, bool) {
+	return position, true
+}
+func (f *fakeQueueManager) MoveToItem(id, targetID string) (int, bool) {
+	return 0, true
+}
 func (f *fakeQueueManager) HydrateItem(context.Context, *domain.QueueItem) error {
 	return nil
 }
+func (f *fakeQueueManager) CheckReleaseAvailability(_ context.Context, sourceKind, releaseID string, sample int) (*app.AvailabilityReport, error) {
+	f.lastAvailabilityCheck = sourceKind + "/" + releaseID
+	return &app.AvailabilityReport{Checked: sample}, nil
+}
+func (f *fakeQueueManager) RetryWithPassword(_ context.Context, id, password string) (*domain.QueueItem, error) {
+	f.lastRetryPassword = password
+	return f.items[id], nil
+}
+func (f *fakeQueueManager) Reprocess(_ context.Context, id string) (*domain.QueueItem, error) {
+	return f.items[id], nil
+}
 func (f *fakeQueueManager) UpdateStatus(context.Context, *domain.QueueItem, domain.JobStatus) {}
 func (f *fakeQueueManager) ReloadRuntime(*app.Context)                                        {}
 
@@ -276,6 +443,12 @@ func (f *fakeJobStore) GetQueueEvents(context.Context, string) ([]*domain.QueueI
 func (f *fakeJobStore) ResetStuckQueueItems(context.Context, domain.JobStatus, ...domain.JobStatus) error {
 	return nil
 }
+func (f *fakeJobStore) UpdateQueueItemOrder(context.Context, []*domain.QueueItem) error {
+	return nil
+}
+func (f *fakeJobStore) FindDuplicateQueueItems(context.Context, *domain.QueueItem, string) ([]domain.DuplicateMatch, error) {
+	return nil, nil
+}
 func (f *fakeJobStore) Ping(context.Context) error                 { return nil }
 func (f *fakeJobStore) SchemaVersion(context.Context) (int, error) { return 1, nil }
 func (f *fakeJobStore) ExpectedSchemaVersion() int                 { return 1 }
@@ -291,3 +464,11 @@ var _ app.QueueManager = (*fakeQueueManager)(nil)
 var _ app.ReleaseResolver = (*fakeResolver)(nil)
 var _ app.BlobStore = (*fakeBlobStore)(nil)
 var _ app.JobStore = (*fakeJobStore)(nil)
+
+type fakeSpeedLimiter struct {
+	limit int64
+}
+
+func (f *fakeSpeedLimiter) Limit() int64                     { return f.limit }
+func (f *fakeSpeedLimiter) SetLimit(bytesPerSec int64)       { f.limit = bytesPerSec }
+func (f *fakeSpeedLimiter) WaitN(context.Context, int) error { return nil }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"slices"
 
+	"github.com/datallboy/gonzb/internal/app"
 	"github.com/datallboy/gonzb/internal/domain"
 )
 
@@ -58,12 +59,24 @@ func (q *Queries) ListHistory(ctx context.Context, status string, limit, offset
 	return filtered[start:end], total, nil
 }
 
-func (q *Queries) GetActiveItem() *domain.QueueItem {
+func (q *Queries) GetActiveItems() []*domain.QueueItem {
 	queue := q.provider.Queue()
 	if queue == nil {
-		return nil
+		return []*domain.QueueItem{}
+	}
+	return queue.GetActiveItems()
+}
+
+// SpeedLimit returns the current global download limit in KB/s; 0 means unlimited.
+func (q *Queries) SpeedLimit() int {
+	if q.provider.SpeedLimiter == nil {
+		return 0
+	}
+	limiter := q.provider.SpeedLimiter()
+	if limiter == nil {
+		return 0
 	}
-	return queue.GetActiveItem()
+	return int(limiter.Limit() / 1024)
 }
 
 func (q *Queries) GetItem(ctx context.Context, id string) (*domain.QueueItem, error) {
@@ -114,3 +127,13 @@ func (q *Queries) IsPaused() bool {
 	}
 	return queue.IsPaused()
 }
+
+// DiskSpace reports free space on the output and completed paths and whether
+// low space is holding the queue.
+func (q *Queries) DiskSpace() app.DiskSpaceStatus {
+	queue := q.provider.Queue()
+	if queue == nil {
+		return app.DiskSpaceStatus{}
+	}
+	return queue.DiskSpace()
+}
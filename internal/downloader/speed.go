@@ -0,0 +1,106 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/datallboy/gonzb/internal/app"
+	"github.com/datallboy/gonzb/internal/infra/config"
+)
+
+const speedScheduleInterval = 30 * time.Second
+
+type Logger interface {
+	Info(format string, args ...any)
+}
+
+// SpeedScheduler applies download.speed_schedules to the shared limiter.
+// The limit is only rewritten when the active window changes, so a manual
+// override (SAB speedlimit, native endpoint) holds until the next boundary.
+type SpeedScheduler struct {
+	limiter func() app.SpeedLimiter
+	config  func() *config.Config
+	logger  Logger
+	now     func() time.Time
+
+	mu      sync.Mutex
+	lastKey string
+}
+
+func NewSpeedScheduler(limiter func() app.SpeedLimiter, cfg func() *config.Config, logger Logger) *SpeedScheduler {
+	return &SpeedScheduler{
+		limiter: limiter,
+		config:  cfg,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// Run evaluates the schedule until ctx is cancelled.
+func (s *SpeedScheduler) Run(ctx context.Context) {
+	s.Refresh()
+
+	ticker := time.NewTicker(speedScheduleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.evaluate(false)
+		}
+	}
+}
+
+// Refresh re-applies the scheduled limit unconditionally, e.g. after a settings change.
+func (s *SpeedScheduler) Refresh() {
+	s.evaluate(true)
+}
+
+func (s *SpeedScheduler) evaluate(force bool) {
+	limiter := s.limiter()
+	cfg := s.config()
+	if limiter == nil || cfg == nil {
+		return
+	}
+
+	limitKBps, key := ScheduledSpeedLimit(cfg.Download, s.now())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !force && key == s.lastKey {
+		return
+	}
+	s.lastKey = key
+
+	limiter.SetLimit(int64(limitKBps) * 1024)
+	if s.logger != nil {
+		s.logger.Info("Download speed limit set to %s (%s)", formatSpeedLimit(limitKBps), key)
+	}
+}
+
+// ScheduledSpeedLimit returns the limit in KB/s that applies at now and a key naming
+// the window that produced it. Schedules that fail to parse are ignored.
+func ScheduledSpeedLimit(download config.DownloadConfig, now time.Time) (int, string) {
+	for i, schedule := range download.SpeedSchedules {
+		window, err := schedule.Window()
+		if err != nil {
+			continue
+		}
+		if window.Contains(now) {
+			return window.LimitKBps, fmt.Sprintf("schedule %d", i)
+		}
+	}
+	return download.SpeedLimitKBps, "default"
+}
+
+func formatSpeedLimit(limitKBps int) string {
+	if limitKBps <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d KB/s", limitKBps)
+}
@@ -0,0 +1,324 @@
+package downloader
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/datallboy/gonzb/internal/app"
+	"github.com/datallboy/gonzb/internal/infra/config"
+)
+
+const (
+	defaultWatchInterval = 5 * time.Second
+
+	// folders inside each watch dir that imported files are moved to; they
+	// are never scanned and cannot be used as category names.
+	watchProcessedDir = "processed"
+	watchFailedDir    = "failed"
+
+	// decompressed bytes read from one watched file, matching the API's
+	// multipart upload limit.
+	maxWatchedNZBBytes int64 = 64 << 20
+)
+
+var errWatchedFileTooLarge = fmt.Errorf("decompressed size exceeds %d MiB", maxWatchedNZBBytes>>20)
+
+type WatchLogger interface {
+	Logger
+	Warn(format string, args ...any)
+}
+
+// FolderWatcher queues NZBs dropped into download.watch_dirs. Files directly
+// in a watch dir use the default category; files in a subfolder are queued
+// under the category it is named after. A file is picked up once its size and
+// modification time are unchanged between two scans, so half-copied files are
+// left alone, and is then moved to the processed or failed folder.
+type FolderWatcher struct {
+	commands func() app.DownloaderCommands
+	config   func() *config.Config
+	logger   WatchLogger
+
+	seen  map[string]watchStamp // candidates from the previous scan
+	stuck map[string]watchStamp // imported files that could not be moved away
+}
+
+type watchStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+func NewFolderWatcher(commands func() app.DownloaderCommands, cfg func() *config.Config, logger WatchLogger) *FolderWatcher {
+	return &FolderWatcher{
+		commands: commands,
+		config:   cfg,
+		logger:   logger,
+		seen:     make(map[string]watchStamp),
+		stuck:    make(map[string]watchStamp),
+	}
+}
+
+// Run scans the watch dirs until ctx is cancelled. The dirs and interval are
+// re-read on every pass, so settings changes apply without a restart.
+func (w *FolderWatcher) Run(ctx context.Context) {
+	for {
+		w.scan(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.interval()):
+		}
+	}
+}
+
+func (w *FolderWatcher) interval() time.Duration {
+	if cfg := w.config(); cfg != nil && cfg.Download.WatchIntervalSeconds > 0 {
+		return time.Duration(cfg.Download.WatchIntervalSeconds) * time.Second
+	}
+	return defaultWatchInterval
+}
+
+func (w *FolderWatcher) scan(ctx context.Context) {
+	cfg := w.config()
+	commands := w.commands()
+	if cfg == nil || commands == nil {
+		return
+	}
+
+	next := make(map[string]watchStamp)
+	for _, root := range cfg.Download.WatchDirs {
+		root = strings.TrimSpace(root)
+		if root == "" {
+			continue
+		}
+
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			w.warn("Failed to read watch folder %s: %v", root, err)
+			continue
+		}
+		w.scanDir(ctx, commands, root, root, "", entries, next)
+
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.IsDir() || name == watchProcessedDir || name == watchFailedDir || strings.HasPrefix(name, ".") {
+				continue
+			}
+			dir := filepath.Join(root, name)
+			sub, err := os.ReadDir(dir)
+			if err != nil {
+				w.warn("Failed to read watch folder %s: %v", dir, err)
+				continue
+			}
+			w.scanDir(ctx, commands, root, dir, name, sub, next)
+		}
+	}
+	w.seen = next
+}
+
+func (w *FolderWatcher) scanDir(ctx context.Context, commands app.DownloaderCommands, root, dir, category string, entries []os.DirEntry, next map[string]watchStamp) {
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return
+		}
+		if entry.IsDir() || !isWatchedNZB(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		file := filepath.Join(dir, entry.Name())
+		stamp := watchStamp{size: info.Size(), modTime: info.ModTime()}
+		if stuck, ok := w.stuck[file]; ok && stuck == stamp {
+			next[file] = stamp
+			continue
+		}
+		delete(w.stuck, file)
+		if prev, ok := w.seen[file]; !ok || prev != stamp {
+			next[file] = stamp
+			continue
+		}
+
+		w.importFile(ctx, commands, root, file, category, stamp)
+	}
+}
+
+// importFile queues every NZB in file and moves it out of the watch dir. A
+// zip that queued at least one NZB counts as processed; the entries that
+// failed are only logged.
+func (w *FolderWatcher) importFile(ctx context.Context, commands app.DownloaderCommands, root, file, category string, stamp watchStamp) {
+	queued, err := enqueueWatchedFile(ctx, commands, file, category)
+
+	target := watchProcessedDir
+	switch {
+	case queued == 0 && err != nil:
+		target = watchFailedDir
+		w.warn("Watch folder import of %s failed: %v", file, err)
+	case err != nil:
+		w.info("Queued %d NZB(s) from watch folder file %s", queued, file)
+		w.warn("Watch folder import of %s skipped some entries: %v", file, err)
+	default:
+		w.info("Queued %d NZB(s) from watch folder file %s", queued, file)
+	}
+
+	if moveErr := moveWatchedFile(file, filepath.Join(root, target)); moveErr != nil {
+		// leave it be until it changes rather than queueing it again every scan.
+		w.stuck[file] = stamp
+		w.warn("Failed to move %s to the %s folder: %v", file, target, moveErr)
+	}
+}
+
+func (w *FolderWatcher) info(format string, args ...any) {
+	if w.logger != nil {
+		w.logger.Info(format, args...)
+	}
+}
+
+func (w *FolderWatcher) warn(format string, args ...any) {
+	if w.logger != nil {
+		w.logger.Warn(format, args...)
+	}
+}
+
+func isWatchedNZB(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".nzb") || strings.HasSuffix(lower, ".nzb.gz") || strings.HasSuffix(lower, ".zip")
+}
+
+// enqueueWatchedFile queues the NZB in file, or each NZB in a zip, and returns
+// how many were queued.
+func enqueueWatchedFile(ctx context.Context, commands app.DownloaderCommands, file, category string) (int, error) {
+	name := filepath.Base(file)
+	lower := strings.ToLower(name)
+
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return enqueueZippedNZBs(ctx, commands, file, category)
+
+	case strings.HasSuffix(lower, ".gz"):
+		f, err := os.Open(file)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return 0, fmt.Errorf("failed to open gzip: %w", err)
+		}
+		defer gz.Close()
+		if _, err := commands.EnqueueNZBWithCategory(ctx, name[:len(name)-len(".gz")], category, newCappedReader(gz)); err != nil {
+			return 0, err
+		}
+		return 1, nil
+
+	default:
+		f, err := os.Open(file)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+		if _, err := commands.EnqueueNZBWithCategory(ctx, name, category, newCappedReader(f)); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	}
+}
+
+func enqueueZippedNZBs(ctx context.Context, commands app.DownloaderCommands, file, category string) (int, error) {
+	archive, err := zip.OpenReader(file)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open zip: %w", err)
+	}
+	defer archive.Close()
+
+	// one budget for the whole archive, so many small members cannot add up.
+	budget := newCappedReader(nil)
+	queued := 0
+	var errs []error
+	for _, member := range archive.File {
+		name := path.Base(member.Name)
+		if member.FileInfo().IsDir() || !strings.HasSuffix(strings.ToLower(name), ".nzb") {
+			continue
+		}
+		err := func() error {
+			if budget.left < 0 || member.UncompressedSize64 > uint64(budget.left) {
+				return errWatchedFileTooLarge
+			}
+			r, err := member.Open()
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+			budget.r = r
+			_, err = commands.EnqueueNZBWithCategory(ctx, name, category, budget)
+			return err
+		}()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		queued++
+	}
+
+	if queued == 0 && len(errs) == 0 {
+		return 0, errors.New("zip contains no NZB files")
+	}
+	return queued, errors.Join(errs...)
+}
+
+// cappedReader fails once more than maxWatchedNZBBytes have been read, so a
+// small compressed file cannot expand into an unbounded read.
+type cappedReader struct {
+	r    io.Reader
+	left int64
+}
+
+func newCappedReader(r io.Reader) *cappedReader {
+	return &cappedReader{r: r, left: maxWatchedNZBBytes}
+}
+
+func (c *cappedReader) Read(p []byte) (int, error) {
+	if c.left < 0 {
+		return 0, errWatchedFileTooLarge
+	}
+	// read one byte past the budget to tell "exactly at the cap" from "over it".
+	if int64(len(p)) > c.left+1 {
+		p = p[:c.left+1]
+	}
+	n, err := c.r.Read(p)
+	c.left -= int64(n)
+	if c.left < 0 {
+		return n, errWatchedFileTooLarge
+	}
+	return n, err
+}
+
+// moveWatchedFile moves file into dir, numbering the name if it is taken.
+func moveWatchedFile(file, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	name := filepath.Base(file)
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	target := filepath.Join(dir, name)
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(target); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		target = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", stem, i, ext))
+	}
+	return os.Rename(file, target)
+}
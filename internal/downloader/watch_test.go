@@ -0,0 +1,197 @@
+package downloader
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/datallboy/gonzb/internal/app"
+	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/infra/config"
+)
+
+func TestFolderWatcherQueuesStableFilesByCategory(t *testing.T) {
+	root := t.TempDir()
+	writeWatchFile(t, filepath.Join(root, "plain.nzb"), []byte("plain-nzb"))
+	writeWatchFile(t, filepath.Join(root, "tv", "show.nzb.gz"), gzipBytes(t, []byte("show-nzb")))
+	writeWatchFile(t, filepath.Join(root, "movies", "pack.zip"), zipBytes(t, map[string][]byte{
+		"a.nzb":      []byte("a-nzb"),
+		"sub/b.NZB":  []byte("b-nzb"),
+		"readme.txt": []byte("ignored"),
+	}))
+	writeWatchFile(t, filepath.Join(root, "empty.nzb"), nil)
+	writeWatchFile(t, filepath.Join(root, "processed", "old.nzb"), []byte("old-nzb"))
+
+	queue := &fakeQueueManager{addResult: &domain.QueueItem{ID: "queue-1"}}
+	cfg := &config.Config{Download: config.DownloadConfig{
+		WatchDirs:  []string{root},
+		Categories: []config.CategoryConfig{{Name: "movies"}, {Name: "tv"}},
+	}}
+	module := NewModule(DependencyProvider{
+		Queue:     func() app.QueueManager { return queue },
+		BlobStore: func() app.BlobStore { return &fakeBlobStore{} },
+		Config:    func() *config.Config { return cfg },
+	})
+	watcher := NewFolderWatcher(module.Commands, func() *config.Config { return cfg }, nil)
+
+	// the first scan only notes the files, in case they are still being written.
+	watcher.scan(context.Background())
+	if len(queue.addRequests) != 0 {
+		t.Fatalf("expected no imports on first sighting, got %d", len(queue.addRequests))
+	}
+
+	watcher.scan(context.Background())
+	var got []string
+	for _, req := range queue.addRequests {
+		got = append(got, req.Title+"@"+req.Release.Category)
+	}
+	sort.Strings(got)
+	want := []string{"a.nzb@movies", "b.NZB@movies", "plain.nzb@*", "show.nzb@tv"}
+	if len(got) != len(want) {
+		t.Fatalf("queued %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("queued %v, want %v", got, want)
+		}
+	}
+
+	for _, rel := range []string{"processed/plain.nzb", "processed/show.nzb.gz", "processed/pack.zip", "failed/empty.nzb", "processed/old.nzb"} {
+		if _, err := os.Stat(filepath.Join(root, rel)); err != nil {
+			t.Fatalf("expected %s: %v", rel, err)
+		}
+	}
+	for _, rel := range []string{"plain.nzb", "tv/show.nzb.gz", "movies/pack.zip", "empty.nzb"} {
+		if _, err := os.Stat(filepath.Join(root, rel)); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be moved out of the watch folder", rel)
+		}
+	}
+}
+
+func TestFolderWatcherWaitsForFileToStopChanging(t *testing.T) {
+	root := t.TempDir()
+	file := filepath.Join(root, "growing.nzb")
+	writeWatchFile(t, file, []byte("part"))
+
+	queue := &fakeQueueManager{addResult: &domain.QueueItem{ID: "queue-1"}}
+	cfg := &config.Config{Download: config.DownloadConfig{WatchDirs: []string{root}}}
+	module := NewModule(DependencyProvider{
+		Queue:     func() app.QueueManager { return queue },
+		BlobStore: func() app.BlobStore { return &fakeBlobStore{} },
+	})
+	watcher := NewFolderWatcher(module.Commands, func() *config.Config { return cfg }, nil)
+
+	watcher.scan(context.Background())
+	writeWatchFile(t, file, []byte("part-and-the-rest"))
+	watcher.scan(context.Background())
+	if len(queue.addRequests) != 0 {
+		t.Fatal("expected a file that changed between scans to be left alone")
+	}
+
+	watcher.scan(context.Background())
+	if len(queue.addRequests) != 1 {
+		t.Fatalf("expected the settled file to be queued once, got %d", len(queue.addRequests))
+	}
+}
+
+func TestFolderWatcherKeepsPartialZipImportsAndCapsDecompression(t *testing.T) {
+	root := t.TempDir()
+	writeWatchFile(t, filepath.Join(root, "pack.zip"), zipBytes(t, map[string][]byte{
+		"good.nzb":  []byte("good-nzb"),
+		"empty.nzb": nil,
+	}))
+
+	var bomb bytes.Buffer
+	zw := gzip.NewWriter(&bomb)
+	chunk := make([]byte, 1<<20)
+	for written := int64(0); written <= maxWatchedNZBBytes; written += int64(len(chunk)) {
+		if _, err := zw.Write(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	writeWatchFile(t, filepath.Join(root, "bomb.nzb.gz"), bomb.Bytes())
+
+	queue := &fakeQueueManager{addResult: &domain.QueueItem{ID: "queue-1"}}
+	cfg := &config.Config{Download: config.DownloadConfig{WatchDirs: []string{root}}}
+	module := NewModule(DependencyProvider{
+		Queue:     func() app.QueueManager { return queue },
+		BlobStore: func() app.BlobStore { return &fakeBlobStore{} },
+	})
+	watcher := NewFolderWatcher(module.Commands, func() *config.Config { return cfg }, nil)
+
+	watcher.scan(context.Background())
+	watcher.scan(context.Background())
+	if len(queue.addRequests) != 1 || queue.addRequests[0].Title != "good.nzb" {
+		t.Fatalf("expected only good.nzb to be queued, got %+v", queue.addRequests)
+	}
+	for _, rel := range []string{"processed/pack.zip", "failed/bomb.nzb.gz"} {
+		if _, err := os.Stat(filepath.Join(root, rel)); err != nil {
+			t.Fatalf("expected %s: %v", rel, err)
+		}
+	}
+}
+
+func TestMoveWatchedFileNumbersTakenNames(t *testing.T) {
+	root := t.TempDir()
+	dest := filepath.Join(root, "processed")
+	writeWatchFile(t, filepath.Join(dest, "a.nzb"), []byte("first"))
+	writeWatchFile(t, filepath.Join(root, "a.nzb"), []byte("second"))
+
+	if err := moveWatchedFile(filepath.Join(root, "a.nzb"), dest); err != nil {
+		t.Fatalf("moveWatchedFile() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dest, "a (1).nzb"))
+	if err != nil || string(data) != "second" {
+		t.Fatalf("expected second file under a numbered name, got %q (%v)", data, err)
+	}
+}
+
+func writeWatchFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zipBytes(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
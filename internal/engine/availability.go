@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/datallboy/gonzb/internal/app"
+	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/nzb"
+)
+
+// CheckReleaseAvailability STATs a sample of a release's articles without
+// queueing it, so search results can be vetted before they cost disk space.
+// A negative sample uses the configured download.availability_sample.
+func (m *QueueManager) CheckReleaseAvailability(ctx context.Context, sourceKind, releaseID string, sample int) (*app.AvailabilityReport, error) {
+	if m.nntp == nil {
+		return nil, fmt.Errorf("nntp manager is unavailable")
+	}
+
+	rel, err := m.resolver.GetRelease(ctx, sourceKind, releaseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s release %s: %w", sourceKind, releaseID, err)
+	}
+	if rel == nil {
+		return nil, fmt.Errorf("release %s not found", releaseID)
+	}
+
+	reader, err := m.payloadFetcher.GetNZB(ctx, sourceKind, rel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch nzb for release %s: %w", releaseID, err)
+	}
+	defer reader.Close()
+
+	model, err := m.parser.Parse(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse nzb payload: %w", err)
+	}
+
+	if sample < 0 {
+		sample = m.config.Download.AvailabilitySample
+	}
+	return m.nntp.Stat(ctx, sampleMessageIDs(model, sample))
+}
+
+// checkAvailability runs the optional pre-download STAT pass for a queue item
+// and records the result as an event. Only a release none of whose sampled
+// articles exist anywhere is failed; partial coverage is left to PAR2.
+func (m *QueueManager) checkAvailability(ctx context.Context, item *domain.QueueItem, model *nzb.Model) error {
+	if m.config == nil || !m.config.Download.AvailabilityCheck || m.nntp == nil {
+		return nil
+	}
+
+	report, err := m.nntp.Stat(ctx, sampleMessageIDs(model, m.config.Download.AvailabilitySample))
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		m.logger.Warn("Availability check failed for %s: %v", releaseTitle(item), err)
+		m.recordEventMeta(ctx, item.ID, "availability", "failed", err.Error(), nil)
+		return nil
+	}
+
+	status := "ok"
+	switch {
+	case report.Checked > 0 && report.Available == 0:
+		status = "unavailable"
+	case report.Available < report.Checked:
+		status = "degraded"
+	}
+	message := describeAvailability(report)
+	m.recordEventMeta(ctx, item.ID, "availability", status, message, report)
+	m.logger.Info("Availability for %s: %s", releaseTitle(item), message)
+
+	if status == "unavailable" {
+		return fmt.Errorf("none of the %d sampled articles are available on any provider", report.Checked)
+	}
+	return nil
+}
+
+// sampleMessageIDs spreads up to n message IDs evenly across every file of
+// the NZB; n <= 0 returns them all.
+func sampleMessageIDs(model *nzb.Model, n int) []string {
+	if model == nil {
+		return nil
+	}
+
+	var all []string
+	for _, f := range model.Files {
+		for _, seg := range f.Segments {
+			if id := strings.TrimSpace(seg.MessageID); id != "" {
+				all = append(all, id)
+			}
+		}
+	}
+	if n <= 0 || n >= len(all) {
+		return all
+	}
+
+	out := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		out = append(out, all[i*len(all)/n])
+	}
+	return out
+}
+
+func describeAvailability(report *app.AvailabilityReport) string {
+	parts := make([]string, 0, len(report.Providers))
+	for _, p := range report.Providers {
+		if p.Error != "" {
+			parts = append(parts, fmt.Sprintf("%s error", p.Provider))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s %.1f%%", p.Provider, p.Percent))
+	}
+	message := fmt.Sprintf("%.1f%% of %d sampled articles available", report.Percent, report.Checked)
+	if len(parts) > 0 {
+		message += " (" + strings.Join(parts, ", ") + ")"
+	}
+	return message
+}
+
+func (m *QueueManager) recordEventMeta(ctx context.Context, queueID, stage, status, message string, meta any) {
+	ev := &domain.QueueItemEvent{
+		QueueID: queueID,
+		Stage:   stage,
+		Status:  status,
+		Message: message,
+	}
+	if meta != nil {
+		if raw, err := json.Marshal(meta); err == nil {
+			ev.MetaJSON = string(raw)
+		}
+	}
+	if err := m.jobStore.SaveQueueEvent(ctx, ev); err != nil {
+		m.logger.Debug("Failed to persist queue event for %s: %v", queueID, err)
+	}
+}
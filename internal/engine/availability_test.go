@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/datallboy/gonzb/internal/app"
+	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/nzb"
+)
+
+type fakeStatNNTP struct {
+	available int
+	checked   []string
+}
+
+func (f *fakeStatNNTP) Fetch(context.Context, *domain.Segment, []string) (io.Reader, error) {
+	return nil, io.EOF
+}
+func (f *fakeStatNNTP) TotalCapacity() int { return 1 }
+func (f *fakeStatNNTP) Close() error       { return nil }
+func (f *fakeStatNNTP) Stat(_ context.Context, msgIDs []string) (*app.AvailabilityReport, error) {
+	f.checked = msgIDs
+	available := min(f.available, len(msgIDs))
+	return &app.AvailabilityReport{
+		Checked:   len(msgIDs),
+		Available: available,
+		Percent:   float64(available) * 100 / float64(len(msgIDs)),
+		Providers: []app.ProviderAvailability{{ProviderID: "p1", Provider: "news.example", Checked: len(msgIDs), Available: available}},
+	}, nil
+}
+
+func TestSampleMessageIDsSpreadsAcrossFiles(t *testing.T) {
+	model := &nzb.Model{Files: []nzb.File{
+		{Segments: []nzb.Segment{{MessageID: "a1"}, {MessageID: "a2"}, {MessageID: "a3"}}},
+		{Segments: []nzb.Segment{{MessageID: "b1"}, {MessageID: "b2"}, {MessageID: "b3"}}},
+	}}
+
+	if got := sampleMessageIDs(model, 0); len(got) != 6 {
+		t.Fatalf("expected every segment with sample 0, got %v", got)
+	}
+	if got := strings.Join(sampleMessageIDs(model, 2), ","); got != "a1,b1" {
+		t.Fatalf("expected the sample to span both files, got %s", got)
+	}
+}
+
+func TestCheckAvailabilityRecordsEventAndFailsUnavailableItems(t *testing.T) {
+	m, store := newOrderTestManager(t)
+	item := addOrderTestItems(t, m, "a")["a"]
+	fake := &fakeStatNNTP{available: 1}
+	m.nntp = fake
+	m.config.Download.AvailabilityCheck = true
+	m.config.Download.AvailabilitySample = 2
+
+	model := &nzb.Model{Files: []nzb.File{{Segments: []nzb.Segment{{MessageID: "a1"}, {MessageID: "a2"}, {MessageID: "a3"}}}}}
+	if err := m.checkAvailability(context.Background(), item, model); err != nil {
+		t.Fatalf("expected partial availability to pass, got %v", err)
+	}
+	if len(fake.checked) != 2 {
+		t.Fatalf("expected the configured sample size, got %v", fake.checked)
+	}
+
+	fake.available = 0
+	if err := m.checkAvailability(context.Background(), item, model); err == nil {
+		t.Fatal("expected an item with no available articles to fail")
+	}
+
+	events, err := store.GetQueueEvents(context.Background(), item.ID)
+	if err != nil {
+		t.Fatalf("get queue events: %v", err)
+	}
+	statuses := map[string]bool{}
+	for _, ev := range events {
+		if ev.Stage == "availability" {
+			statuses[ev.Status] = true
+			if !strings.Contains(ev.MetaJSON, `"provider_id":"p1"`) {
+				t.Fatalf("expected per-provider meta, got %s", ev.MetaJSON)
+			}
+		}
+	}
+	if !statuses["degraded"] || !statuses["unavailable"] {
+		t.Fatalf("expected degraded and unavailable events, got %v", statuses)
+	}
+}
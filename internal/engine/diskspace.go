@@ -0,0 +1,239 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/datallboy/gonzb/internal/app"
+	"github.com/datallboy/gonzb/internal/domain"
+)
+
+// ErrInsufficientSpace is returned when a release cannot fit on the download paths.
+var ErrInsufficientSpace = errors.New("insufficient disk space")
+
+const (
+	diskCheckInterval = 30 * time.Second
+
+	// free space a queue stopped by a failed write waits for before resuming
+	// when no minimum is configured for the path.
+	diskFullResumeBytes = 1 << 30
+
+	bytesPerMB = 1024 * 1024
+)
+
+// DiskSpace reports free space on the output and completed paths and whether
+// the free-space guard is holding the queue.
+func (m *QueueManager) DiskSpace() app.DiskSpaceStatus {
+	var status app.DiskSpaceStatus
+	if m.config != nil {
+		download := m.config.Download
+		completed := download.CompletedDir
+		if completed == "" {
+			completed = download.OutDir
+		}
+		status.Incomplete = m.diskPath(download.OutDir, download.MinFreeIncompleteMB)
+		status.Complete = m.diskPath(completed, download.MinFreeCompleteMB)
+	}
+
+	m.mu.RLock()
+	status.Paused = m.diskPaused
+	status.Reason = m.diskPauseReason
+	m.mu.RUnlock()
+	return status
+}
+
+func (m *QueueManager) diskPath(path string, minFreeMB int64) app.DiskSpacePath {
+	out := app.DiskSpacePath{Path: path, MinFreeBytes: minFreeMB * bytesPerMB}
+	if path == "" {
+		return out
+	}
+	usage, err := m.statDisk(path)
+	if err != nil {
+		m.logger.Debug("Disk space lookup failed for %s: %v", path, err)
+		return out
+	}
+	out.FreeBytes = usage.FreeBytes
+	out.TotalBytes = usage.TotalBytes
+	out.Visible = usage.Visible
+	return out
+}
+
+// watchDiskSpace re-runs the free-space guard until ctx ends.
+func (m *QueueManager) watchDiskSpace(ctx context.Context) {
+	ticker := time.NewTicker(diskCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.checkDiskSpace()
+		}
+	}
+}
+
+// checkDiskSpace holds downloads while a working path is below its minimum
+// free space and releases them once space returns.
+func (m *QueueManager) checkDiskSpace() {
+	status := m.DiskSpace()
+
+	m.mu.Lock()
+	reason := lowSpaceReason(status, m.diskFull)
+	switch {
+	case reason != "" && !m.diskPaused:
+		m.pauseForDiskLocked(reason)
+		m.mu.Unlock()
+		m.logger.Warn("Downloads paused: %s", reason)
+	case reason == "" && m.diskPaused:
+		m.diskPaused = false
+		m.diskFull = false
+		m.diskPauseReason = ""
+		m.mu.Unlock()
+		m.logger.Info("Disk space recovered, downloads resumed")
+		m.signalNewJob()
+	default:
+		if m.diskPaused {
+			m.diskPauseReason = reason
+		}
+		m.mu.Unlock()
+	}
+}
+
+// pauseForDiskLocked stops new jobs and cooperatively requeues active
+// downloads. Force priority does not bypass a full disk; post-processing
+// already under way is left to finish.
+func (m *QueueManager) pauseForDiskLocked(reason string) {
+	m.diskPaused = true
+	m.diskPauseReason = reason
+
+	for _, item := range m.activeItems {
+		if item.CancelFunc == nil || item.Status == domain.StatusProcessing {
+			continue
+		}
+		if _, requested := m.pauseRequested[item.ID]; requested {
+			continue
+		}
+		m.pauseRequested[item.ID] = domain.StatusPending
+		item.CancelFunc()
+		m.recordEvent(context.Background(), item.ID, "queue", "pause_requested", "Paused for disk space: "+reason)
+	}
+}
+
+// holdForDiskFullLocked pauses the queue after a write ran out of space and
+// marks item, whose job failed on that write, to be requeued.
+func (m *QueueManager) holdForDiskFullLocked(item *domain.QueueItem) {
+	m.diskFull = true
+	if !m.diskPaused {
+		m.pauseForDiskLocked(fmt.Sprintf("disk full while downloading %q", releaseTitle(item)))
+		m.logger.Warn("Downloads paused: %s", m.diskPauseReason)
+	}
+	target := domain.StatusPending
+	if item.Status == domain.StatusProcessing {
+		target = domain.StatusProcessing
+	}
+	m.pauseRequested[item.ID] = target
+}
+
+// lowSpaceReason describes the first path below its minimum free space, or
+// returns "" when downloads may run. After a disk-full write the queue waits
+// for diskFullResumeBytes even on paths without a configured minimum.
+func lowSpaceReason(status app.DiskSpaceStatus, diskFull bool) string {
+	paths := []struct {
+		name string
+		path app.DiskSpacePath
+	}{
+		{"incomplete", status.Incomplete},
+		{"complete", status.Complete},
+	}
+	for _, p := range paths {
+		if !p.path.Visible {
+			continue
+		}
+		required := p.path.MinFreeBytes
+		if diskFull && required < diskFullResumeBytes {
+			required = diskFullResumeBytes
+		}
+		if required > 0 && p.path.FreeBytes < required {
+			return fmt.Sprintf("low disk space on %s path %s: %d MB free, %d MB required",
+				p.name, p.path.Path, p.path.FreeBytes/bytesPerMB, required/bytesPerMB)
+		}
+	}
+	return ""
+}
+
+// checkFits refuses a release that would take a working path below its
+// configured minimum free space once everything already queued has
+// downloaded. The output path also needs the unpack headroom. Paths without a
+// minimum are not checked; the disk-full guard covers them.
+func (m *QueueManager) checkFits(id, title string, size int64) error {
+	if size <= 0 || m.config == nil {
+		return nil
+	}
+	download := m.config.Download
+	if download.MinFreeIncompleteMB <= 0 && download.MinFreeCompleteMB <= 0 {
+		return nil
+	}
+
+	status := m.DiskSpace()
+	queued := m.queuedBytes(id)
+	unpack := size * int64(download.UnpackHeadroomPercent) / 100
+	needs := []struct {
+		name  string
+		path  app.DiskSpacePath
+		bytes int64
+	}{
+		{"incomplete", status.Incomplete, size + unpack},
+		{"complete", status.Complete, size},
+	}
+	for _, need := range needs {
+		if !need.path.Visible || need.path.MinFreeBytes <= 0 {
+			continue
+		}
+		if need.path.FreeBytes-need.path.MinFreeBytes-queued < need.bytes {
+			return fmt.Errorf("%w: %q needs %d MB on %s path %s, %d MB free with %d MB still queued",
+				ErrInsufficientSpace, title, need.bytes/bytesPerMB, need.name, need.path.Path,
+				need.path.FreeBytes/bytesPerMB, queued/bytesPerMB)
+		}
+	}
+	return nil
+}
+
+// queuedBytes is what the live queue, other than item id, still has to
+// download. Items that are not hydrated yet count their release size.
+func (m *QueueManager) queuedBytes(id string) int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var queued int64
+	for _, item := range m.queue {
+		if item.ID == id {
+			continue
+		}
+		switch item.Status {
+		case domain.StatusPending, domain.StatusDownloading, domain.StatusPaused:
+		default:
+			continue
+		}
+		if len(item.Tasks) > 0 {
+			queued += remainingBytes(item)
+		} else {
+			queued += max(0, item.ReleaseSize-item.GetBytes())
+		}
+	}
+	return queued
+}
+
+// remainingBytes is what is left to download for a hydrated item.
+func remainingBytes(item *domain.QueueItem) int64 {
+	var remaining int64
+	for _, task := range item.Tasks {
+		if task.IsComplete {
+			continue
+		}
+		remaining += int64(task.Size) - task.DoneSegmentBytes()
+	}
+	return remaining
+}
@@ -0,0 +1,174 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/datallboy/gonzb/internal/app"
+	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/infra/platform"
+)
+
+func fakeDiskFree(m *QueueManager, freeMB *int64) {
+	m.config.Download.OutDir = "/downloads/incomplete"
+	m.config.Download.CompletedDir = "/downloads/complete"
+	m.statDisk = func(path string) (platform.DiskUsage, error) {
+		return platform.DiskUsage{Path: path, FreeBytes: *freeMB * bytesPerMB, TotalBytes: 100_000 * bytesPerMB, Visible: true}, nil
+	}
+}
+
+func TestDiskSpaceGuardPausesAndResumes(t *testing.T) {
+	m, _ := newOrderTestManager(t)
+	freeMB := int64(500)
+	fakeDiskFree(m, &freeMB)
+	m.config.Download.MinFreeIncompleteMB = 1024
+	items := addOrderTestItems(t, m, "a", "b")
+	items["b"].Priority = domain.PriorityForce
+
+	active := items["a"]
+	cancelled := false
+	m.mu.Lock()
+	active.CancelFunc = func() { cancelled = true }
+	m.activeItems[active.ID] = active
+	m.mu.Unlock()
+
+	m.checkDiskSpace()
+	status := m.DiskSpace()
+	if !status.Paused || status.Reason == "" {
+		t.Fatalf("expected low space to pause the queue with a reason, got %+v", status)
+	}
+	if !cancelled {
+		t.Fatal("expected active download to be cancelled")
+	}
+	m.finalizeJob(t.Context(), active, context.Canceled)
+	if active.Status != domain.StatusPending {
+		t.Fatalf("expected paused download to be requeued, got %s", active.Status)
+	}
+
+	m.mu.Lock()
+	next := m.nextRunnableLocked()
+	m.mu.Unlock()
+	if next != nil {
+		t.Fatalf("expected no dispatch while low on space, got %s", next.ReleaseTitle)
+	}
+
+	freeMB = 4096
+	m.checkDiskSpace()
+	if status := m.DiskSpace(); status.Paused {
+		t.Fatalf("expected queue to resume once space returns, got %+v", status)
+	}
+	m.mu.Lock()
+	next = m.nextRunnableLocked()
+	m.mu.Unlock()
+	if next == nil {
+		t.Fatal("expected dispatch after space returned")
+	}
+}
+
+func TestAddRejectsReleaseThatCannotFit(t *testing.T) {
+	m, _ := newOrderTestManager(t)
+	freeMB := int64(3000)
+	fakeDiskFree(m, &freeMB)
+	m.config.Download.MinFreeIncompleteMB = 500
+	m.config.Download.UnpackHeadroomPercent = 100
+
+	add := func(sizeMB int64) error {
+		_, err := m.Add(t.Context(), app.QueueAddRequest{
+			SourceKind:      "manual",
+			SourceReleaseID: fmt.Sprintf("r%d", sizeMB),
+			Release:         &domain.Release{Title: fmt.Sprintf("release %d", sizeMB), Size: sizeMB * bytesPerMB},
+		})
+		return err
+	}
+
+	// 1200 MB plus 100% headroom needs 2400 MB of the 2500 MB above the minimum.
+	if err := add(1200); err != nil {
+		t.Fatalf("expected release to fit, got %v", err)
+	}
+	if err := add(1300); !errors.Is(err, ErrInsufficientSpace) {
+		t.Fatalf("expected ErrInsufficientSpace, got %v", err)
+	}
+	if got := len(m.GetAllItems()); got != 1 {
+		t.Fatalf("expected only the fitting release to be queued, got %d", got)
+	}
+}
+
+func TestAddCountsQueuedItemsAgainstFreeSpace(t *testing.T) {
+	m, _ := newOrderTestManager(t)
+	freeMB := int64(3000)
+	fakeDiskFree(m, &freeMB)
+	m.config.Download.MinFreeIncompleteMB = 500
+	m.config.Download.UnpackHeadroomPercent = 0
+
+	add := func(id string, sizeMB int64) error {
+		_, err := m.Add(t.Context(), app.QueueAddRequest{
+			SourceKind:      "manual",
+			SourceReleaseID: id,
+			Release:         &domain.Release{Title: id, Size: sizeMB * bytesPerMB},
+		})
+		return err
+	}
+
+	// each fits on its own, but not both within the 2500 MB above the minimum.
+	if err := add("first", 1500); err != nil {
+		t.Fatalf("expected first release to fit, got %v", err)
+	}
+	if err := add("second", 1500); !errors.Is(err, ErrInsufficientSpace) {
+		t.Fatalf("expected the queued release to count against free space, got %v", err)
+	}
+}
+
+func TestAddSkipsFitCheckWithoutMinimum(t *testing.T) {
+	m, _ := newOrderTestManager(t)
+	freeMB := int64(1000)
+	fakeDiskFree(m, &freeMB)
+
+	_, err := m.Add(t.Context(), app.QueueAddRequest{
+		SourceKind:      "manual",
+		SourceReleaseID: "big",
+		Release:         &domain.Release{Title: "big", Size: 800 * bytesPerMB},
+	})
+	if err != nil {
+		t.Fatalf("expected no fit check without a configured minimum, got %v", err)
+	}
+}
+
+func TestDiskFullWriteRequeuesAndPausesQueue(t *testing.T) {
+	m, _ := newOrderTestManager(t)
+	freeMB := int64(10)
+	fakeDiskFree(m, &freeMB)
+	items := addOrderTestItems(t, m, "a")
+	item := items["a"]
+
+	m.mu.Lock()
+	item.Status = domain.StatusDownloading
+	item.CancelFunc = func() {}
+	m.activeItems[item.ID] = item
+	m.mu.Unlock()
+
+	writeErr := fmt.Errorf("write error %w", &os.PathError{Op: "write", Path: "a.part", Err: syscall.ENOSPC})
+	m.finalizeJob(t.Context(), item, writeErr)
+
+	if item.Status != domain.StatusPending || item.Error != nil {
+		t.Fatalf("expected disk-full job to be requeued, got %s (%v)", item.Status, item.Error)
+	}
+	if status := m.DiskSpace(); !status.Paused {
+		t.Fatal("expected disk-full write to pause the queue")
+	}
+
+	// without a configured minimum the queue waits for a gigabyte before resuming.
+	freeMB = 512
+	m.checkDiskSpace()
+	if !m.DiskSpace().Paused {
+		t.Fatal("expected queue to stay paused below the disk-full resume floor")
+	}
+	freeMB = 2048
+	m.checkDiskSpace()
+	if m.DiskSpace().Paused {
+		t.Fatal("expected queue to resume once space returned")
+	}
+}
@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"sort"
+
+	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/processor"
+)
+
+// downloadOrder returns tasks in the order their segments are dispatched. The
+// PAR2 index comes first so the health check knows the slice size early. Archive
+// volumes follow set by set in volume order, so each volume completes before the
+// next starts and a direct unpack can consume them as they land. Everything else
+// keeps its NZB order at the end.
+func downloadOrder(tasks []*domain.DownloadFile) []*domain.DownloadFile {
+	type volume struct {
+		task   *domain.DownloadFile
+		set    int
+		number int
+	}
+
+	index := par2IndexTask(tasks)
+	setRank := make(map[string]int)
+	volumes := make([]volume, 0, len(tasks))
+	rest := make([]*domain.DownloadFile, 0, len(tasks))
+
+	for _, task := range tasks {
+		if task == index {
+			continue
+		}
+		set, number, ok := processor.ArchiveVolume(task.FileName)
+		if !ok {
+			rest = append(rest, task)
+			continue
+		}
+		rank, seen := setRank[set]
+		if !seen {
+			rank = len(setRank)
+			setRank[set] = rank
+		}
+		volumes = append(volumes, volume{task: task, set: rank, number: number})
+	}
+
+	sort.SliceStable(volumes, func(i, j int) bool {
+		if volumes[i].set != volumes[j].set {
+			return volumes[i].set < volumes[j].set
+		}
+		return volumes[i].number < volumes[j].number
+	})
+
+	ordered := make([]*domain.DownloadFile, 0, len(tasks))
+	if index != nil {
+		ordered = append(ordered, index)
+	}
+	for _, v := range volumes {
+		ordered = append(ordered, v.task)
+	}
+	return append(ordered, rest...)
+}
@@ -0,0 +1,26 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/datallboy/gonzb/internal/domain"
+)
+
+func TestDownloadOrderSequencesArchiveVolumes(t *testing.T) {
+	var tasks []*domain.DownloadFile
+	for _, name := range []string{
+		"b.part2.rar", "movie.nfo", "a.r01", "b.part1.rar", "set.vol00+01.par2", "a.rar", "a.r00", "set.par2",
+	} {
+		tasks = append(tasks, &domain.DownloadFile{FileName: name})
+	}
+
+	var got []string
+	for _, task := range downloadOrder(tasks) {
+		got = append(got, task.FileName)
+	}
+	want := "set.par2,b.part1.rar,b.part2.rar,a.rar,a.r00,a.r01,movie.nfo,set.vol00+01.par2"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("downloadOrder = %v, want %s", got, want)
+	}
+}
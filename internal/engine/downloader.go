@@ -33,19 +33,29 @@ func NewDownloader(ctx *app.Context, writer *FileWriter) *Downloader {
 
 // Download processes a QueueItem from start to finish
 func (s *Downloader) Download(ctx context.Context, item *domain.QueueItem) error {
-	defer s.writer.CloseAll()
-
 	if item == nil || item.Release == nil {
 		return fmt.Errorf("download item is missing release metadata")
 	}
 
+	// The writer is shared with other active downloads, so only release this item's files.
+	partPaths := make([]string, 0, len(item.Tasks))
+	for _, t := range item.Tasks {
+		partPaths = append(partPaths, t.PartPath)
+	}
+	defer s.writer.CloseFiles(partPaths)
+
 	// Reset counters for new job
 	var alreadyDone int64
 
 	for _, t := range item.Tasks {
+		// Gaps from an earlier attempt get another chance at every provider.
+		t.ResetMissingSegments()
+
 		if t.IsComplete {
 			alreadyDone += int64(t.Size)
+			continue
 		}
+		alreadyDone += t.DoneSegmentBytes()
 	}
 	item.BytesWritten.Store(alreadyDone)
 
@@ -59,9 +69,22 @@ func (s *Downloader) Download(ctx context.Context, item *domain.QueueItem) error
 
 	item.StartedAt = time.Now()
 
-	err := s.runWorkerPool(ctx, item)
+	// Recovery volumes wait until verification shows how many blocks are needed.
+	held := holdRecoveryVolumes(item.Tasks)
+
+	// a failed or paused download discards its direct unpack.
+	unpack := s.processor.StartDirectUnpack(ctx, item)
+	downloaded := false
+	defer func() {
+		if !downloaded {
+			unpack.Abort()
+		}
+	}()
+
+	err := s.runWorkerPool(ctx, item, unpack)
 	if err != nil {
-		s.writer.CloseAll()
+		s.writer.CloseFiles(partPaths)
+		s.reportAbandonedSegments(item, err)
 		return err
 	}
 
@@ -69,11 +92,27 @@ func (s *Downloader) Download(ctx context.Context, item *domain.QueueItem) error
 	if err := s.processor.Finalize(ctx, item.Tasks); err != nil {
 		return fmt.Errorf("post-processing failed: %w", err)
 	}
+	// volumes finalized only now were damaged; post-processing repairs and
+	// extracts their sets.
+	unpack.DownloadDone()
+
+	if len(held) > 0 {
+		if err := s.fetchNeededRecoveryVolumes(ctx, item, held); err != nil {
+			s.writer.CloseFiles(partPaths)
+			s.reportAbandonedSegments(item, err)
+			return err
+		}
+	}
+
+	if err := s.reportMissingSegments(item); err != nil {
+		return err
+	}
 
 	if s.onProgressDone != nil {
 		s.onProgressDone(item)
 	}
 
+	downloaded = true
 	return nil
 }
 
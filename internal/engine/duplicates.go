@@ -0,0 +1,140 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/infra/config"
+)
+
+// ErrDuplicate is returned when a category's duplicate policy refuses a release
+// that is already queued or downloaded.
+var ErrDuplicate = errors.New("duplicate download")
+
+// duplicateDecision is what a category's policy does with a flagged item.
+type duplicateDecision struct {
+	policy   string
+	match    domain.DuplicateMatch
+	pause    bool
+	replaced []*domain.QueueItem // smaller queued copies to cancel once item is saved
+}
+
+// findDuplicates returns earlier queued or completed items that item repeats.
+// hashOnly limits the match to the NZB content hash, for the second check made
+// once the NZB has been fetched during hydration.
+func (m *QueueManager) findDuplicates(ctx context.Context, item *domain.QueueItem, hashOnly bool) []domain.DuplicateMatch {
+	matches, err := m.jobStore.FindDuplicateQueueItems(ctx, item, item.ID)
+	if err != nil {
+		// a failed lookup should not stop the download.
+		m.logger.Warn("Duplicate lookup failed for %s: %v", releaseTitle(item), err)
+		return nil
+	}
+
+	if !hashOnly {
+		return matches
+	}
+
+	out := matches[:0]
+	for _, match := range matches {
+		// only earlier items are originals; a later copy is flagged on its own check.
+		if match.Key != domain.DuplicateKeyNZBHash || match.Item.CreatedAt.Unix() >= item.CreatedAt.Unix() {
+			continue
+		}
+		out = append(out, match)
+	}
+	return out
+}
+
+// decideDuplicate flags item as a copy of the first match and applies the
+// duplicate policy of its category. The error wraps ErrDuplicate when the
+// policy refuses the item.
+func (m *QueueManager) decideDuplicate(item *domain.QueueItem, matches []domain.DuplicateMatch) (duplicateDecision, error) {
+	decision := duplicateDecision{policy: m.duplicatePolicy(item), match: matches[0]}
+	item.DuplicateOf = decision.match.Item.ID
+	item.DuplicateKey = decision.match.Key
+
+	switch decision.policy {
+	case config.DuplicatePause:
+		decision.pause = true
+	case config.DuplicateReject:
+		return decision, fmt.Errorf("%w: %s", ErrDuplicate, describeDuplicate(decision.match))
+	case config.DuplicateReplace:
+		for _, match := range matches {
+			// the same NZB bytes can never be a better copy.
+			if match.Key == domain.DuplicateKeyNZBHash || item.ReleaseSize <= match.Item.ReleaseSize {
+				return decision, fmt.Errorf("%w: not larger than %s", ErrDuplicate, describeDuplicate(match))
+			}
+		}
+		for _, match := range matches {
+			if !match.Item.Status.IsTerminal() {
+				decision.replaced = append(decision.replaced, match.Item)
+			}
+		}
+	}
+	return decision, nil
+}
+
+// duplicatePolicy returns the policy of the category item is filed under.
+func (m *QueueManager) duplicatePolicy(item *domain.QueueItem) string {
+	if m.config == nil {
+		return config.DuplicateAllow
+	}
+	category := ""
+	if item.Release != nil {
+		category = item.Release.Category
+	}
+	resolved, _ := m.config.Download.ResolveCategory(category)
+	return strings.ToLower(strings.TrimSpace(resolved.DuplicatePolicy))
+}
+
+// recordDuplicate puts the detection on the item's timeline and cancels the
+// smaller queued copies a replace policy dropped.
+func (m *QueueManager) recordDuplicate(ctx context.Context, item *domain.QueueItem, decision duplicateDecision) {
+	m.recordEventMeta(ctx, item.ID, "duplicate", decision.policy,
+		"Duplicate of "+describeDuplicate(decision.match),
+		map[string]string{"duplicate_of": decision.match.Item.ID, "key": decision.match.Key},
+	)
+
+	for _, old := range decision.replaced {
+		m.recordEvent(ctx, old.ID, "duplicate", "replaced", fmt.Sprintf("Replaced by larger duplicate %s", item.ID))
+		m.Cancel(old.ID)
+	}
+}
+
+func describeDuplicate(match domain.DuplicateMatch) string {
+	return fmt.Sprintf("%q (%s, same %s)", releaseTitle(match.Item), match.Item.ID, strings.ReplaceAll(match.Key, "_", " "))
+}
+
+// checkHashDuplicate records the NZB hash found at hydration and, for items
+// not already flagged when queued, applies the category's policy to an earlier
+// download of the same NZB. A pause cooperatively returns the item to paused.
+func (m *QueueManager) checkHashDuplicate(ctx context.Context, item *domain.QueueItem, nzbHash string) error {
+	if item.NZBHash == nzbHash {
+		return nil
+	}
+	item.NZBHash = nzbHash
+	if item.DuplicateOf != "" {
+		return nil
+	}
+
+	matches := m.findDuplicates(ctx, item, true)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	decision, err := m.decideDuplicate(item, matches)
+	m.recordDuplicate(ctx, item, decision)
+	if err != nil {
+		return err
+	}
+	if decision.pause {
+		m.mu.Lock()
+		m.pauseRequested[item.ID] = domain.StatusPaused
+		m.mu.Unlock()
+		return fmt.Errorf("paused as duplicate: %w", context.Canceled)
+	}
+	return nil
+}
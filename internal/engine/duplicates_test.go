@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/datallboy/gonzb/internal/app"
+	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/infra/config"
+)
+
+func newDuplicateTestManager(t *testing.T, policy string) *QueueManager {
+	t.Helper()
+
+	m, _ := newOrderTestManager(t)
+	m.config.Download.Categories = []config.CategoryConfig{{Name: "tv", DuplicatePolicy: policy}}
+	return m
+}
+
+func addDuplicateTestRelease(m *QueueManager, releaseID, title string, size int64) (*domain.QueueItem, error) {
+	return m.Add(context.Background(), app.QueueAddRequest{
+		SourceKind:      "aggregator",
+		SourceReleaseID: releaseID,
+		Release:         &domain.Release{Title: title, Size: size, Category: "tv"},
+	})
+}
+
+func TestDuplicatePolicies(t *testing.T) {
+	t.Run("allow flags a normalized title match", func(t *testing.T) {
+		m := newDuplicateTestManager(t, config.DuplicateAllow)
+		first, err := addDuplicateTestRelease(m, "r1", "Show.S01E01.720p-GRP", 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := addDuplicateTestRelease(m, "r2", "show s01e01 720p grp.nzb", 100)
+		if err != nil {
+			t.Fatalf("allow policy refused a duplicate: %v", err)
+		}
+		if second.Status != domain.StatusPending || second.DuplicateOf != first.ID || second.DuplicateKey != domain.DuplicateKeyTitle {
+			t.Fatalf("second = %s dup_of=%q key=%q, want pending duplicate of %s by title", second.Status, second.DuplicateOf, second.DuplicateKey, first.ID)
+		}
+	})
+
+	t.Run("pause holds the copy", func(t *testing.T) {
+		m := newDuplicateTestManager(t, config.DuplicatePause)
+		if _, err := addDuplicateTestRelease(m, "r1", "Show.S01E01", 100); err != nil {
+			t.Fatal(err)
+		}
+		second, err := addDuplicateTestRelease(m, "r1", "Show.S01E01", 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if second.Status != domain.StatusPaused || second.DuplicateKey != domain.DuplicateKeyReleaseID {
+			t.Fatalf("second = %s key=%q, want paused release_id duplicate", second.Status, second.DuplicateKey)
+		}
+	})
+
+	t.Run("reject ignores failed history", func(t *testing.T) {
+		m := newDuplicateTestManager(t, config.DuplicateReject)
+		first, err := addDuplicateTestRelease(m, "r1", "Show.S01E01", 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := addDuplicateTestRelease(m, "r1", "Show.S01E01", 100); !errors.Is(err, ErrDuplicate) {
+			t.Fatalf("error = %v, want ErrDuplicate", err)
+		}
+		if len(m.GetAllItems()) != 1 {
+			t.Fatalf("rejected duplicate was queued")
+		}
+
+		m.finalizeJob(context.Background(), first, fmt.Errorf("missing articles"))
+		retry, err := addDuplicateTestRelease(m, "r1", "Show.S01E01", 100)
+		if err != nil {
+			t.Fatalf("failed download blocked a new attempt: %v", err)
+		}
+		if retry.DuplicateOf != "" {
+			t.Fatalf("retry flagged as duplicate of %s", retry.DuplicateOf)
+		}
+	})
+
+	t.Run("replace keeps only a larger copy", func(t *testing.T) {
+		m := newDuplicateTestManager(t, config.DuplicateReplace)
+		small, err := addDuplicateTestRelease(m, "r1", "Show.S01E01", 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		large, err := addDuplicateTestRelease(m, "r2", "Show S01E01", 200)
+		if err != nil {
+			t.Fatalf("larger copy refused: %v", err)
+		}
+		if small.Status != domain.StatusFailed {
+			t.Fatalf("smaller queued copy status = %s, want failed", small.Status)
+		}
+		if large.DuplicateOf != small.ID {
+			t.Fatalf("larger copy dup_of = %q, want %s", large.DuplicateOf, small.ID)
+		}
+
+		if _, err := addDuplicateTestRelease(m, "r3", "Show-S01E01", 150); !errors.Is(err, ErrDuplicate) {
+			t.Fatalf("smaller copy error = %v, want ErrDuplicate", err)
+		}
+	})
+}
+
+func TestHydratedNZBHashMatchesEarlierDownload(t *testing.T) {
+	m := newDuplicateTestManager(t, config.DuplicateReject)
+	first, err := addDuplicateTestRelease(m, "r1", "First.Name", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.NZBHash = "abc123"
+	if err := m.jobStore.SaveQueueItem(context.Background(), first); err != nil {
+		t.Fatal(err)
+	}
+
+	renamed, err := addDuplicateTestRelease(m, "r2", "Other.Name", 100)
+	if err != nil {
+		t.Fatalf("different title and release should queue: %v", err)
+	}
+	renamed.CreatedAt = time.Now().Add(2 * time.Second)
+
+	if err := m.checkHashDuplicate(context.Background(), renamed, "abc123"); !errors.Is(err, ErrDuplicate) {
+		t.Fatalf("hash check error = %v, want ErrDuplicate", err)
+	}
+	if renamed.DuplicateOf != first.ID || renamed.DuplicateKey != domain.DuplicateKeyNZBHash {
+		t.Fatalf("renamed dup_of=%q key=%q, want %s by nzb_hash", renamed.DuplicateOf, renamed.DuplicateKey, first.ID)
+	}
+}
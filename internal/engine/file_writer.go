@@ -97,6 +97,30 @@ func (fw *FileWriter) CloseAll() {
 	}
 }
 
+// CloseFiles releases the handles for the given paths only, leaving files that
+// belong to other concurrently running downloads open.
+func (fw *FileWriter) CloseFiles(paths []string) {
+	for _, path := range paths {
+		_ = fw.CloseFile(path, 0) // Ignore error on cleanup
+	}
+}
+
+// SyncFiles flushes open handles for the given paths to disk.
+func (fw *FileWriter) SyncFiles(paths []string) {
+	for _, path := range paths {
+		fw.mu.RLock()
+		h, ok := fw.handles[path]
+		fw.mu.RUnlock()
+		if !ok {
+			continue
+		}
+
+		h.mu.Lock()
+		_ = h.file.Sync() // best effort
+		h.mu.Unlock()
+	}
+}
+
 func (fw *FileWriter) CloseFile(path string, finalSize int64) error {
 	fw.mu.Lock()
 	defer fw.mu.Unlock()
@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/processor"
+)
+
+// repairCheck carries what checkRepairable learns across the missing segments
+// of one download pass.
+type repairCheck struct {
+	// slice size read from the PAR2 index, 0 until it has been read.
+	sliceSize int64
+}
+
+// checkRepairable compares the blocks damaged by permanently missing segments
+// with the recovery blocks the release carries. It returns an error wrapping
+// processor.ErrPar2Unrepairable once PAR2 can no longer make the item whole,
+// so a DMCA'd release fails fast instead of after a full download. Releases
+// whose PAR2 index cannot be identified, such as obfuscated ones, keep
+// downloading and are left to post-processing.
+func (s *Downloader) checkRepairable(item *domain.QueueItem, check *repairCheck) error {
+	if !hasMissingSegments(item.Tasks) {
+		return nil
+	}
+
+	index := par2IndexTask(item.Tasks)
+	if index == nil {
+		return nil
+	}
+	if len(index.MissingSegmentNumbers()) > 0 {
+		return fmt.Errorf("unrepairable: %w: the PAR2 index itself is missing segments", processor.ErrPar2Unrepairable)
+	}
+
+	if check.sliceSize == 0 {
+		// The slice size is only known once the index has been fetched.
+		path := index.FinalPath
+		if !index.IsComplete {
+			if index.PendingSegments() > 0 {
+				return nil
+			}
+			path = index.PartPath
+		}
+		sliceSize, err := processor.Par2SliceSize(path)
+		if err != nil {
+			s.ctx.Logger.Debug("Health check could not read PAR2 slice size for %s: %v", item.Release.Title, err)
+			return nil
+		}
+		check.sliceSize = sliceSize
+	}
+
+	damaged, available := repairBudget(item.Tasks, check.sliceSize)
+	if damaged > available {
+		return fmt.Errorf("unrepairable: %w: %d blocks damaged, %d recovery blocks available",
+			processor.ErrPar2Unrepairable, damaged, available)
+	}
+	return nil
+}
+
+// repairBudget estimates the input blocks touched by missing segments and the
+// recovery blocks left in the release's volumes after their own losses.
+func repairBudget(tasks []*domain.DownloadFile, sliceSize int64) (damaged, available int) {
+	for _, task := range tasks {
+		numbers := task.MissingSegmentNumbers()
+		missing := make(map[int]struct{}, len(numbers))
+		for _, n := range numbers {
+			missing[n] = struct{}{}
+		}
+
+		if blocks, ok := processor.Par2VolumeBlocks(task.FileName); ok {
+			lost := 0
+			for _, seg := range task.Segments {
+				if _, ok := missing[seg.Number]; ok {
+					lost += int((seg.Bytes+sliceSize-1)/sliceSize) + 1
+				}
+			}
+			available += max(0, blocks-lost)
+			continue
+		}
+		if task.IsPars || len(missing) == 0 {
+			continue
+		}
+
+		// Contiguous gaps share blocks, so count distinct block indexes per file.
+		touched := make(map[int64]struct{})
+		var offset int64
+		for _, seg := range task.Segments {
+			if _, ok := missing[seg.Number]; ok && seg.Bytes > 0 {
+				for b := offset / sliceSize; b <= (offset+seg.Bytes-1)/sliceSize; b++ {
+					touched[b] = struct{}{}
+				}
+			}
+			offset += seg.Bytes
+		}
+		damaged += len(touched)
+	}
+	return damaged, available
+}
+
+func hasMissingSegments(tasks []*domain.DownloadFile) bool {
+	for _, task := range tasks {
+		if len(task.MissingSegmentNumbers()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// par2IndexTask returns the index .par2 of a task list, the one without a
+// .volNN+MM suffix. Titles such as "Show.Vol.2.par2" are still an index.
+func par2IndexTask(tasks []*domain.DownloadFile) *domain.DownloadFile {
+	for _, task := range tasks {
+		if !strings.HasSuffix(strings.ToLower(task.FileName), ".par2") {
+			continue
+		}
+		if _, volume := processor.Par2VolumeBlocks(task.FileName); !volume {
+			return task
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/processor"
+)
+
+func TestRepairBudgetCountsDistinctDamagedBlocks(t *testing.T) {
+	dir := t.TempDir()
+	segments := []domain.Segment{{Number: 1, Bytes: 100}, {Number: 2, Bytes: 100}, {Number: 3, Bytes: 100}, {Number: 4, Bytes: 100}}
+	data := domain.NewDownloadFile("movie.mkv", 400, 0, segments, dir, "")
+	index := domain.NewDownloadFile("movie.par2", 10, 1, nil, dir, "")
+	index.IsPars = true
+	volume := domain.NewDownloadFile("movie.vol00+03.par2", 600, 2, []domain.Segment{{Number: 1, Bytes: 600}}, dir, "")
+	volume.IsPars = true
+	tasks := []*domain.DownloadFile{data, index, volume}
+
+	// segments 2 and 3 cover bytes 100-299, which touch blocks 0, 1 and 2 of 128 bytes.
+	data.MarkSegmentMissing(1)
+	data.MarkSegmentMissing(2)
+	damaged, available := repairBudget(tasks, 128)
+	if damaged != 3 || available != 3 {
+		t.Fatalf("got damaged=%d available=%d, want 3 and 3", damaged, available)
+	}
+
+	volume.MarkSegmentMissing(0)
+	if _, available := repairBudget(tasks, 128); available != 0 {
+		t.Fatalf("expected a lost volume to contribute no blocks, got %d", available)
+	}
+}
+
+func TestCheckRepairableKeepsDownloadingWithoutPar2(t *testing.T) {
+	s := &Downloader{}
+	data := domain.NewDownloadFile("movie.mkv", 200, 0, []domain.Segment{{Number: 1, Bytes: 100}, {Number: 2, Bytes: 100}}, t.TempDir(), "")
+	item := &domain.QueueItem{Release: &domain.Release{Title: "movie"}, Tasks: []*domain.DownloadFile{data}}
+
+	if err := s.checkRepairable(item, &repairCheck{}); err != nil {
+		t.Fatalf("expected a healthy item to pass, got %v", err)
+	}
+
+	// an obfuscated release may carry PAR2 under names we cannot recognise.
+	data.MarkSegmentMissing(0)
+	if err := s.checkRepairable(item, &repairCheck{}); err != nil {
+		t.Fatalf("expected an item without a known PAR2 index to keep downloading, got %v", err)
+	}
+}
+
+func TestCheckRepairableUsesCachedSliceSize(t *testing.T) {
+	s := &Downloader{}
+	dir := t.TempDir()
+	data := domain.NewDownloadFile("movie.mkv", 200, 0, []domain.Segment{{Number: 1, Bytes: 100}, {Number: 2, Bytes: 100}}, dir, "")
+	index := domain.NewDownloadFile("movie.par2", 10, 1, nil, dir, "")
+	index.IsPars = true
+	item := &domain.QueueItem{Release: &domain.Release{Title: "movie"}, Tasks: []*domain.DownloadFile{data, index}}
+
+	// no index file is on disk, so only the cached slice size can drive the check.
+	data.MarkSegmentMissing(0)
+	if err := s.checkRepairable(item, &repairCheck{sliceSize: 128}); !errors.Is(err, processor.ErrPar2Unrepairable) {
+		t.Fatalf("expected an unrepairable error, got %v", err)
+	}
+}
+
+func TestPar2IndexTaskIgnoresVolInTitles(t *testing.T) {
+	dir := t.TempDir()
+	volume := domain.NewDownloadFile("Guardians.Vol.2.2017.vol00+01.par2", 10, 0, nil, dir, "")
+	index := domain.NewDownloadFile("Guardians.Vol.2.2017.par2", 10, 1, nil, dir, "")
+
+	if got := par2IndexTask([]*domain.DownloadFile{volume, index}); got != index {
+		t.Fatalf("expected the title with .Vol. to be the index, got %+v", got)
+	}
+	if got := par2IndexTask([]*domain.DownloadFile{volume}); got != nil {
+		t.Fatalf("expected no index among recovery volumes, got %s", got.FileName)
+	}
+}
@@ -2,9 +2,12 @@ package engine
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -15,6 +18,8 @@ import (
 	"github.com/datallboy/gonzb/internal/domain"
 	"github.com/datallboy/gonzb/internal/infra/config"
 	"github.com/datallboy/gonzb/internal/infra/logger"
+	"github.com/datallboy/gonzb/internal/infra/platform"
+	"github.com/datallboy/gonzb/internal/processor"
 	"github.com/segmentio/ksuid"
 )
 
@@ -24,18 +29,28 @@ type QueueManager struct {
 	processor      app.Processor
 	queue          []*domain.QueueItem
 	parser         app.NZBParser
-	activeItem     *domain.QueueItem
+	activeItems    map[string]*domain.QueueItem
 	jobStore       app.JobStore
 	queueFiles     app.QueueFileStore
 	resolver       app.ReleaseResolver
 	payloadFetcher app.PayloadFetcher
+	nntp           app.NNTPManager
 	arrNotifier    app.ArrNotifier
 	logger         *logger.Logger
 	config         *config.Config
 
 	// global runtime pause state for SAB-compatible downloader API.
-	paused           bool
-	pauseRequestedID string
+	paused bool
+	// status a cooperatively cancelled active job returns to: pending for a global
+	// pause, paused for a per-item pause.
+	pauseRequested map[string]domain.JobStatus
+
+	// free-space guard: holds downloads while a working path runs low. diskFull
+	// marks a hold caused by a failed write rather than a configured minimum.
+	diskPaused      bool
+	diskFull        bool
+	diskPauseReason string
+	statDisk        func(path string) (platform.DiskUsage, error)
 
 	stopFunc   context.CancelFunc
 	newJobChan chan struct{}
@@ -54,11 +69,15 @@ func NewQueueManager(app *app.Context, loadExisting bool) *QueueManager {
 		queueFiles:     app.QueueFileStore,
 		resolver:       app.Resolver,
 		payloadFetcher: app.PayloadFetcher,
+		nntp:           app.NNTP,
 		arrNotifier:    app.ArrNotifier,
 		logger:         app.Logger,
 		config:         app.Config,
 		newJobChan:     make(chan struct{}, 1),
 		queue:          make([]*domain.QueueItem, 0),
+		activeItems:    make(map[string]*domain.QueueItem),
+		pauseRequested: make(map[string]domain.JobStatus),
+		statDisk:       platform.StatDisk,
 	}
 
 	if loadExisting {
@@ -83,6 +102,7 @@ func (m *QueueManager) ReloadRuntime(appCtx *app.Context) {
 	m.parser = appCtx.NZBParser
 	m.resolver = appCtx.Resolver
 	m.payloadFetcher = appCtx.PayloadFetcher
+	m.nntp = appCtx.NNTP
 	m.arrNotifier = appCtx.ArrNotifier
 	m.config = appCtx.Config
 }
@@ -136,6 +156,7 @@ func (m *QueueManager) initFromDatabase() {
 
 	m.mu.Lock()
 	m.queue = activeItems
+	m.sortQueueLocked()
 	m.mu.Unlock()
 
 	m.logger.Info("Queue initialized with %d items", len(m.queue))
@@ -184,6 +205,14 @@ func (m *QueueManager) Add(ctx context.Context, req app.QueueAddRequest) (*domai
 		release.Category = "Uncategorized"
 	}
 
+	preQueue, err := m.runPreQueueScript(ctx, release)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkFits("", release.Title, release.Size); err != nil {
+		return nil, err
+	}
+
 	payloadMode := domain.PayloadModeCached
 	resumable := true
 	if m.config != nil && !m.config.Store.PayloadCacheEnabled {
@@ -212,9 +241,27 @@ func (m *QueueManager) Add(ctx context.Context, req app.QueueAddRequest) (*domai
 		ReleaseSnapshotJSON: buildReleaseSnapshotJSON(release),
 		PayloadMode:         payloadMode,
 		Resumable:           resumable,
+		NZBHash:             req.NZBHash,
 		CreatedAt:           now,
 		UpdatedAt:           now,
 	}
+	preQueue.apply(item)
+
+	var duplicate *duplicateDecision
+	if matches := m.findDuplicates(ctx, item, false); len(matches) > 0 {
+		decision, err := m.decideDuplicate(item, matches)
+		if err != nil {
+			return nil, err
+		}
+		if decision.pause {
+			item.Status = domain.StatusPaused
+		}
+		duplicate = &decision
+	}
+
+	m.mu.Lock()
+	item.SortPosition = m.nextSortPositionLocked()
+	m.mu.Unlock()
 
 	if err := m.jobStore.SaveQueueItem(ctx, item); err != nil {
 		return nil, fmt.Errorf("failed to save job to database: %w", err)
@@ -222,18 +269,22 @@ func (m *QueueManager) Add(ctx context.Context, req app.QueueAddRequest) (*domai
 
 	m.mu.Lock()
 	m.queue = append(m.queue, item)
+	m.sortQueueLocked()
 	m.mu.Unlock()
 
+	m.recordPreQueueEvent(ctx, item.ID, preQueue)
 	m.recordEvent(ctx, item.ID, "queue", string(domain.StatusPending), "Queued")
-
-	select {
-	case m.newJobChan <- struct{}{}:
-	default:
+	if duplicate != nil {
+		m.recordDuplicate(ctx, item, *duplicate)
 	}
+	m.signalNewJob()
 
 	return item, nil
 }
 
+// Start runs the dispatch loop. Up to Download.MaxActiveDownloads items hydrate/download
+// concurrently; items in post-processing release their download slot so the next
+// item can start downloading while the previous one repairs/extracts.
 func (m *QueueManager) Start(ctx context.Context) {
 	workflow := newQueueWorkflow(m)
 
@@ -243,23 +294,34 @@ func (m *QueueManager) Start(ctx context.Context) {
 	m.stopFunc = loopCancel
 	m.mu.Unlock()
 
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	// settle the free-space guard before the first dispatch.
+	m.checkDiskSpace()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		m.watchDiskSpace(loopCtx)
+	}()
+
 	for {
-		var next *domain.QueueItem
-		var paused bool
-
-		m.mu.RLock()
-		paused = m.paused
-		if !paused {
-			for _, itm := range m.queue {
-				if itm.Status == domain.StatusPending || itm.Status == domain.StatusDownloading || itm.Status == domain.StatusProcessing {
-					next = itm
-					break
-				}
-			}
+		if isCancelled(loopCtx) {
+			return
 		}
-		m.mu.RUnlock()
 
-		if paused || next == nil {
+		m.mu.Lock()
+		next := m.nextRunnableLocked()
+		var jobCtx context.Context
+		var jobCancel context.CancelFunc
+		if next != nil {
+			jobCtx, jobCancel = context.WithCancel(loopCtx)
+			next.CancelFunc = jobCancel
+			m.activeItems[next.ID] = next
+		}
+		m.mu.Unlock()
+
+		if next == nil {
 			select {
 			case <-m.newJobChan:
 				continue
@@ -268,30 +330,76 @@ func (m *QueueManager) Start(ctx context.Context) {
 			}
 		}
 
-		if isCancelled(loopCtx) {
-			return
-		}
+		wg.Add(1)
+		go func(item *domain.QueueItem, jobCtx context.Context, jobCancel context.CancelFunc) {
+			defer wg.Done()
 
-		m.mu.Lock()
-		m.activeItem = next
-		jobCtx, jobCancel := context.WithCancel(loopCtx)
-		next.CancelFunc = jobCancel
-		m.mu.Unlock()
+			workflow.Execute(jobCtx, item)
+			jobCancel()
 
-		workflow.Execute(jobCtx, next)
-		jobCancel()
+			m.mu.Lock()
+			delete(m.activeItems, item.ID)
+			m.mu.Unlock()
 
-		m.mu.Lock()
-		m.activeItem = nil
-		m.mu.Unlock()
+			m.signalNewJob()
+		}(next, jobCtx, jobCancel)
 	}
 }
 
-// GetActiveItem allows the UI to see what's currently running
-func (m *QueueManager) GetActiveItem() *domain.QueueItem {
+// nextRunnableLocked picks the next queued item that may start now, honouring
+// queue order, the global pause, the free-space guard and the concurrent
+// download limit. Force-priority items bypass the pause and the download limit
+// but not the free-space guard.
+func (m *QueueManager) nextRunnableLocked() *domain.QueueItem {
+	downloading := 0
+	for _, itm := range m.activeItems {
+		if itm.Status != domain.StatusProcessing {
+			downloading++
+		}
+	}
+	slotsFull := downloading >= m.maxActiveDownloads()
+
+	for _, itm := range m.queue {
+		if _, running := m.activeItems[itm.ID]; running {
+			continue
+		}
+		if itm.Status != domain.StatusPending && itm.Status != domain.StatusDownloading && itm.Status != domain.StatusProcessing {
+			continue
+		}
+		if m.diskPaused {
+			continue
+		}
+		// a requeued post-processing run needs no download slot.
+		if itm.Status == domain.StatusProcessing && !m.paused {
+			return itm
+		}
+		if itm.Priority != domain.PriorityForce && (m.paused || slotsFull) {
+			continue
+		}
+		return itm
+	}
+	return nil
+}
+
+func (m *QueueManager) maxActiveDownloads() int {
+	if m.config == nil || m.config.Download.MaxActiveDownloads <= 0 {
+		return 1
+	}
+	return m.config.Download.MaxActiveDownloads
+}
+
+// GetActiveItems returns every item currently hydrating, downloading or post-processing, in queue order.
+func (m *QueueManager) GetActiveItems() []*domain.QueueItem {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
-	return m.activeItem
+
+	items := make([]*domain.QueueItem, 0, len(m.activeItems))
+	for _, item := range m.queue {
+		if _, ok := m.activeItems[item.ID]; ok {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 // GetItem searches the queue for a specific ID.
@@ -341,10 +449,17 @@ func (m *QueueManager) Pause() bool {
 
 	m.paused = true
 
-	if m.activeItem != nil && m.activeItem.CancelFunc != nil {
-		m.pauseRequestedID = m.activeItem.ID
-		m.activeItem.CancelFunc()
-		m.recordEvent(context.Background(), m.activeItem.ID, "queue", "pause_requested", "Pause requested")
+	// force-priority jobs keep running through a global pause.
+	for _, item := range m.activeItems {
+		if item.CancelFunc == nil || item.Priority == domain.PriorityForce {
+			continue
+		}
+		if _, requested := m.pauseRequested[item.ID]; requested {
+			continue
+		}
+		m.pauseRequested[item.ID] = domain.StatusPending
+		item.CancelFunc()
+		m.recordEvent(context.Background(), item.ID, "queue", "pause_requested", "Pause requested")
 	}
 
 	return true
@@ -353,15 +468,16 @@ func (m *QueueManager) Pause() bool {
 // resume allows the queue loop to continue processing pending jobs.
 func (m *QueueManager) Resume() bool {
 	m.mu.Lock()
-	wasPaused := m.paused
+	wasPaused := m.paused || m.diskPaused
 	m.paused = false
+	// a manual resume overrides the free-space guard until its next check.
+	m.diskPaused = false
+	m.diskFull = false
+	m.diskPauseReason = ""
 	m.mu.Unlock()
 
 	if wasPaused {
-		select {
-		case m.newJobChan <- struct{}{}:
-		default:
-		}
+		m.signalNewJob()
 	}
 
 	return true
@@ -373,6 +489,71 @@ func (m *QueueManager) IsPaused() bool {
 	return m.paused
 }
 
+// PauseItem holds a single queue item until ResumeItem is called.
+// An active item is cooperatively cancelled; its .part files stay on disk for the resume.
+func (m *QueueManager) PauseItem(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx := m.indexOfLocked(id)
+	if idx < 0 {
+		return false
+	}
+
+	item := m.queue[idx]
+	if item.Status == domain.StatusPaused {
+		return true
+	}
+
+	if _, running := m.activeItems[item.ID]; running && item.CancelFunc != nil {
+		m.pauseRequested[item.ID] = domain.StatusPaused
+		item.CancelFunc()
+		m.recordEvent(context.Background(), item.ID, "queue", "pause_requested", "Pause requested")
+		return true
+	}
+
+	item.Status = domain.StatusPaused
+	item.UpdatedAt = time.Now().UTC()
+	if err := m.jobStore.SaveQueueItem(context.Background(), item); err != nil {
+		m.logger.Error("Failed to persist paused queue item %s: %v", item.ID, err)
+	}
+	m.recordEvent(context.Background(), item.ID, "queue", "paused", "Queue item paused")
+
+	return true
+}
+
+// ResumeItem returns a paused item to the pending queue.
+func (m *QueueManager) ResumeItem(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx := m.indexOfLocked(id)
+	if idx < 0 {
+		return false
+	}
+
+	item := m.queue[idx]
+
+	// a pause still in flight is withdrawn; the job returns to pending when it unwinds.
+	if target, requested := m.pauseRequested[item.ID]; requested && target == domain.StatusPaused {
+		m.pauseRequested[item.ID] = domain.StatusPending
+		return true
+	}
+	if item.Status != domain.StatusPaused {
+		return true
+	}
+
+	item.Status = domain.StatusPending
+	item.UpdatedAt = time.Now().UTC()
+	if err := m.jobStore.SaveQueueItem(context.Background(), item); err != nil {
+		m.logger.Error("Failed to persist resumed queue item %s: %v", item.ID, err)
+	}
+	m.recordEvent(context.Background(), item.ID, "queue", "resumed", "Queue item resumed")
+
+	m.signalNewJob()
+	return true
+}
+
 func (m *QueueManager) Cancel(id string) bool {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -380,7 +561,7 @@ func (m *QueueManager) Cancel(id string) bool {
 	for _, item := range m.queue {
 		if item.ID == id {
 			// 1. If it's already finished, don't bother
-			if item.Status == domain.StatusCompleted || item.Status == domain.StatusFailed {
+			if item.Status.IsTerminal() {
 				return false
 			}
 
@@ -421,7 +602,7 @@ func (m *QueueManager) Delete(id string) bool {
 	for _, item := range m.queue {
 		if item.ID == id {
 			// Do not allow deleting live items from in-memory queue.
-			if item.Status != domain.StatusCompleted && item.Status != domain.StatusFailed {
+			if !item.Status.IsTerminal() {
 				return false
 			}
 		}
@@ -446,10 +627,12 @@ func (m *QueueManager) Stop() {
 		m.stopFunc()
 	}
 
-	// 2. Kill the currently active task (Hydrate, Download, or PostProcess)
-	if m.activeItem != nil && m.activeItem.CancelFunc != nil {
-		m.logger.Debug("QueueManager: Cancelling active job: %s", releaseTitle(m.activeItem))
-		m.activeItem.CancelFunc()
+	// 2. Kill the currently active tasks (Hydrate, Download, or PostProcess)
+	for _, item := range m.activeItems {
+		if item.CancelFunc != nil {
+			m.logger.Debug("QueueManager: Cancelling active job: %s", releaseTitle(item))
+			item.CancelFunc()
+		}
 	}
 }
 
@@ -488,6 +671,11 @@ func (m *QueueManager) UpdateStatus(ctx context.Context, item *domain.QueueItem,
 		m.logger.Error("Failed to persist status %s for queue item %s: %v", status, item.ID, err)
 	}
 	m.recordEvent(ctx, item.ID, "queue", string(status), "Status updated")
+
+	// entering post-processing frees a download slot for the next item.
+	if status == domain.StatusProcessing {
+		m.signalNewJob()
+	}
 }
 
 func (m *QueueManager) finalizeJob(ctx context.Context, item *domain.QueueItem, err error) {
@@ -495,9 +683,15 @@ func (m *QueueManager) finalizeJob(ctx context.Context, item *domain.QueueItem,
 
 	now := time.Now().UTC()
 
+	diskFull := platform.IsDiskFull(err)
+	if diskFull {
+		m.holdForDiskFullLocked(item)
+	}
+
 	// a paused active job is cooperatively requeued instead of failed.
-	if errors.Is(err, context.Canceled) && m.pauseRequestedID == item.ID {
-		item.Status = domain.StatusPending
+	pauseStatus, pauseRequested := m.pauseRequested[item.ID]
+	if (errors.Is(err, context.Canceled) || diskFull) && pauseRequested {
+		item.Status = pauseStatus
 		item.Error = nil
 		item.UpdatedAt = now
 		item.CompletedAt = time.Time{}
@@ -514,9 +708,15 @@ func (m *QueueManager) finalizeJob(ctx context.Context, item *domain.QueueItem,
 			m.logger.Error("Failed to persist paused queue item %s: %v", item.ID, persistErr)
 		}
 
-		m.recordEvent(ctx, item.ID, "queue", "paused", "Queue item paused")
-		m.pauseRequestedID = ""
-		m.activeItem = nil
+		if pauseStatus == domain.StatusPaused {
+			m.recordEvent(ctx, item.ID, "queue", "paused", "Queue item paused")
+		} else if diskFull || m.diskPaused {
+			m.recordEvent(ctx, item.ID, "queue", "requeued", "Queue item requeued: "+m.diskPauseReason)
+		} else {
+			m.recordEvent(ctx, item.ID, "queue", "requeued", "Queue item requeued by global pause")
+		}
+		delete(m.pauseRequested, item.ID)
+		delete(m.activeItems, item.ID)
 		m.mu.Unlock()
 		return
 	}
@@ -542,6 +742,9 @@ func (m *QueueManager) finalizeJob(ctx context.Context, item *domain.QueueItem,
 
 	if err != nil {
 		item.Status = domain.StatusFailed
+		if errors.Is(err, processor.ErrArchivePassword) {
+			item.Status = domain.StatusPasswordRequired
+		}
 		var errorMsg string
 		if errors.Is(err, context.Canceled) {
 			errorMsg = "Cancelled by user"
@@ -558,14 +761,25 @@ func (m *QueueManager) finalizeJob(ctx context.Context, item *domain.QueueItem,
 	if err := m.jobStore.SaveQueueItem(ctx, item); err != nil {
 		m.logger.Error("Failed to persist final state for queue item %s: %v", item.ID, err)
 	}
+	if m.queueFiles != nil {
+		if err := m.queueFiles.DeleteSegmentJournals(ctx, item.ID); err != nil {
+			m.logger.Debug("Failed to clear segment journal for %s: %v", item.ID, err)
+		}
+	}
 
 	if item.Status == domain.StatusCompleted {
 		m.recordEvent(ctx, item.ID, "finalize", "completed", "Queue item completed")
+	} else if errors.Is(err, processor.ErrPar2Unrepairable) {
+		m.recordEvent(ctx, item.ID, "finalize", "unrepairable", *item.Error)
+	} else if errors.Is(err, processor.ErrUnsupportedArchive) {
+		m.recordEvent(ctx, item.ID, "finalize", "unsupported_archive", *item.Error)
+	} else if item.Status == domain.StatusPasswordRequired {
+		m.recordEvent(ctx, item.ID, "finalize", string(domain.StatusPasswordRequired), "Encrypted archive: password required")
 	} else {
 		m.recordEvent(ctx, item.ID, "finalize", "failed", "Queue item failed")
 	}
 
-	m.activeItem = nil
+	delete(m.activeItems, item.ID)
 	m.removeFromLiveQueue(item.ID)
 
 	notifier := m.arrNotifier
@@ -674,11 +888,23 @@ func (m *QueueManager) HydrateItem(ctx context.Context, item *domain.QueueItem)
 	}
 	defer reader.Close()
 
-	// 3. Parse NZB and prepare downloader tasks.
-	nzbModel, err := m.parser.Parse(reader)
+	// 3. Parse NZB and prepare downloader tasks, hashing the bytes on the way.
+	hash := sha256.New()
+	nzbModel, err := m.parser.Parse(io.TeeReader(reader, hash))
 	if err != nil {
 		return fmt.Errorf("failed to parse nzb payload: %w", err)
 	}
+	if _, err := io.Copy(hash, reader); err != nil {
+		return fmt.Errorf("failed to read nzb payload: %w", err)
+	}
+	if err := m.checkHashDuplicate(ctx, item, hex.EncodeToString(hash.Sum(nil))); err != nil {
+		return err
+	}
+
+	// Optional STAT pass before any space is pre-allocated.
+	if err := m.checkAvailability(ctx, item, nzbModel); err != nil {
+		return err
+	}
 
 	prepRes, err := m.processor.Prepare(ctx, item, nzbModel, item.Release.Title)
 	if err != nil {
@@ -704,9 +930,51 @@ func (m *QueueManager) HydrateItem(ctx context.Context, item *domain.QueueItem)
 		m.logger.Warn("failed to save queue files: %v", err)
 	}
 
+	// 6. Resume partially downloaded files at segment granularity.
+	m.restoreSegmentJournals(ctx, item)
+
+	// Items queued without a known size are measured against free space here.
+	if item.ReleaseSize <= 0 {
+		if err := m.checkFits(item.ID, item.Release.Title, remainingBytes(item)); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// restoreSegmentJournals applies persisted segment bitmaps to files whose .part survived.
+// A journal without its .part file is stale (the part was re-created empty) and is ignored.
+func (m *QueueManager) restoreSegmentJournals(ctx context.Context, item *domain.QueueItem) {
+	journals, err := m.queueFiles.GetSegmentJournals(ctx, item.ID)
+	if err != nil {
+		m.logger.Warn("failed to load segment journal for %s: %v", item.ID, err)
+		return
+	}
+	if len(journals) == 0 {
+		return
+	}
+
+	byIndex := make(map[int]*domain.SegmentJournal, len(journals))
+	for _, journal := range journals {
+		byIndex[journal.FileIndex] = journal
+	}
+
+	resumed := 0
+	for _, task := range item.Tasks {
+		journal, ok := byIndex[task.Index]
+		if !ok || task.IsComplete || !task.PartExists {
+			continue
+		}
+		task.RestoreSegmentJournal(journal)
+		resumed++
+	}
+
+	if resumed > 0 {
+		m.logger.Info("Resuming %d partially downloaded file(s) for: %s", resumed, releaseTitle(item))
+	}
+}
+
 func (m *QueueManager) isDownloadAlreadyFinished(item *domain.QueueItem) bool {
 	if len(item.Tasks) == 0 {
 		return false
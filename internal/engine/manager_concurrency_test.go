@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/datallboy/gonzb/internal/domain"
+)
+
+func TestNextRunnableHonoursMaxActiveDownloads(t *testing.T) {
+	m, _ := newOrderTestManager(t)
+	m.config.Download.MaxActiveDownloads = 2
+	items := addOrderTestItems(t, m, "a", "b", "c")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.activeItems[items["a"].ID] = items["a"]
+	if next := m.nextRunnableLocked(); next != items["b"] {
+		t.Fatalf("expected b to fill the second slot, got %v", next)
+	}
+
+	m.activeItems[items["b"].ID] = items["b"]
+	if next := m.nextRunnableLocked(); next != nil {
+		t.Fatalf("expected no runnable item with all slots busy, got %s", next.ReleaseTitle)
+	}
+
+	// post-processing items release their download slot.
+	items["a"].Status = domain.StatusProcessing
+	if next := m.nextRunnableLocked(); next != items["c"] {
+		t.Fatalf("expected c once a moved to post-processing, got %v", next)
+	}
+}
+
+func TestNextRunnableForceBypassesPauseAndSlots(t *testing.T) {
+	m, _ := newOrderTestManager(t)
+	items := addOrderTestItems(t, m, "a", "b", "c")
+	m.SetPriority(items["c"].ID, domain.PriorityForce)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.paused = true
+	if next := m.nextRunnableLocked(); next != items["c"] {
+		t.Fatalf("expected force item to start while paused, got %v", next)
+	}
+	m.activeItems[items["c"].ID] = items["c"]
+	if next := m.nextRunnableLocked(); next != nil {
+		t.Fatalf("expected paused queue to hold normal items, got %s", next.ReleaseTitle)
+	}
+
+	m.paused = false
+	if next := m.nextRunnableLocked(); next != nil {
+		t.Fatalf("expected force item to occupy the only slot, got %s", next.ReleaseTitle)
+	}
+
+	// with the only slot taken by a normal item, force still starts.
+	delete(m.activeItems, items["c"].ID)
+	m.activeItems[items["a"].ID] = items["a"]
+	if next := m.nextRunnableLocked(); next != items["c"] {
+		t.Fatalf("expected force item to start with all slots busy, got %v", next)
+	}
+}
@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/datallboy/gonzb/internal/app"
+	"github.com/datallboy/gonzb/internal/domain"
+)
+
+func TestPauseItemSkipsDispatchAndSurvivesReload(t *testing.T) {
+	m, store := newOrderTestManager(t)
+	m.config.Store.PayloadCacheEnabled = true
+	items := addOrderTestItems(t, m, "a", "b")
+
+	if !m.PauseItem(items["a"].ID) {
+		t.Fatal("expected pause of queued item to succeed")
+	}
+
+	m.mu.Lock()
+	next := m.nextRunnableLocked()
+	m.mu.Unlock()
+	if next != items["b"] {
+		t.Fatalf("expected paused a to be skipped, got %v", next)
+	}
+
+	appCtx, err := app.NewContext(m.config, m.logger)
+	if err != nil {
+		t.Fatalf("new app context: %v", err)
+	}
+	appCtx.JobStore = store
+
+	reloaded := NewQueueManager(appCtx, true)
+	item, ok := reloaded.GetItem(t.Context(), items["a"].ID)
+	if !ok || item.Status != domain.StatusPaused {
+		t.Fatalf("expected a to reload as paused, got %v", item)
+	}
+
+	if !reloaded.ResumeItem(items["a"].ID) {
+		t.Fatal("expected resume to succeed")
+	}
+	if item.Status != domain.StatusPending {
+		t.Fatalf("expected resumed item to be pending, got %s", item.Status)
+	}
+}
+
+func TestPauseItemRequeuesActiveJobAsPaused(t *testing.T) {
+	m, _ := newOrderTestManager(t)
+	items := addOrderTestItems(t, m, "a")
+	item := items["a"]
+
+	cancelled := false
+	m.mu.Lock()
+	item.CancelFunc = func() { cancelled = true }
+	m.activeItems[item.ID] = item
+	m.mu.Unlock()
+
+	if !m.PauseItem(item.ID) {
+		t.Fatal("expected pause of active item to succeed")
+	}
+	if !cancelled {
+		t.Fatal("expected active job to be cancelled")
+	}
+
+	m.finalizeJob(t.Context(), item, context.Canceled)
+	if item.Status != domain.StatusPaused {
+		t.Fatalf("expected cancelled job to land in paused, got %s", item.Status)
+	}
+	if len(m.GetActiveItems()) != 0 {
+		t.Fatal("expected paused job to release its active slot")
+	}
+}
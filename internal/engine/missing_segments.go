@@ -0,0 +1,98 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/processor"
+)
+
+// missingSegmentsMeta is the event payload for a file with permanent gaps.
+type missingSegmentsMeta struct {
+	FileIndex int   `json:"file_index"`
+	Missing   int   `json:"missing"`
+	Total     int   `json:"total"`
+	Segments  []int `json:"segments"`
+}
+
+// reportMissingSegments persists the segments no provider could deliver and
+// records one queue event per affected file. Without a PAR2 set the gaps
+// cannot be repaired, so that case is returned as an error.
+func (s *Downloader) reportMissingSegments(item *domain.QueueItem) error {
+	missing := make(map[int][]int)
+	missingTotal := 0
+	hasPar2 := false
+	for _, t := range item.Tasks {
+		if t.IsPars {
+			hasPar2 = true
+		}
+		if segments := t.MissingSegmentNumbers(); len(segments) > 0 {
+			missing[t.Index] = segments
+			missingTotal += len(segments)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if store := s.ctx.QueueFileStore; store != nil {
+		if err := store.ReplaceMissingSegments(ctx, item.ID, missing); err != nil {
+			s.ctx.Logger.Warn("Failed to persist missing segments for %s: %v", item.ID, err)
+		}
+	}
+
+	if missingTotal == 0 {
+		return nil
+	}
+
+	for _, t := range item.Tasks {
+		segments, ok := missing[t.Index]
+		if !ok {
+			continue
+		}
+		s.recordEvent(ctx, item, "download", "missing_segments",
+			fmt.Sprintf("%s: %d of %d segments missing", t.FileName, len(segments), len(t.Segments)),
+			missingSegmentsMeta{FileIndex: t.Index, Missing: len(segments), Total: len(t.Segments), Segments: segments})
+	}
+
+	if !hasPar2 {
+		return fmt.Errorf("unrepairable: %w: %d segments missing across %d files and no PAR2 set to repair them",
+			processor.ErrPar2Unrepairable, missingTotal, len(missing))
+	}
+
+	s.ctx.Logger.Warn("%d segments missing across %d files, leaving them to PAR2 repair: %s", missingTotal, len(missing), item.Release.Title)
+	return nil
+}
+
+// reportAbandonedSegments still records the gaps when the health check gave
+// up on an item, so history shows what made it unrepairable.
+func (s *Downloader) reportAbandonedSegments(item *domain.QueueItem, err error) {
+	if errors.Is(err, processor.ErrPar2Unrepairable) {
+		_ = s.reportMissingSegments(item)
+	}
+}
+
+func (s *Downloader) recordEvent(ctx context.Context, item *domain.QueueItem, stage, status, message string, meta any) {
+	if s.ctx.JobStore == nil {
+		return
+	}
+
+	ev := &domain.QueueItemEvent{
+		QueueID: item.ID,
+		Stage:   stage,
+		Status:  status,
+		Message: message,
+	}
+	if meta != nil {
+		if raw, err := json.Marshal(meta); err == nil {
+			ev.MetaJSON = string(raw)
+		}
+	}
+	if err := s.ctx.JobStore.SaveQueueEvent(ctx, ev); err != nil {
+		s.ctx.Logger.Debug("Failed to persist queue event for %s: %v", item.ID, err)
+	}
+}
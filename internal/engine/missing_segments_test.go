@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/datallboy/gonzb/internal/app"
+	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/infra/config"
+	"github.com/datallboy/gonzb/internal/infra/logger"
+	"github.com/datallboy/gonzb/internal/nntp"
+)
+
+func TestNextSegmentAttemptPolicy(t *testing.T) {
+	var job DownloadJob
+
+	if d, retry := nextSegmentAttempt(&job, fmt.Errorf("fetch failed: %w", nntp.ErrProviderBusy)); !retry || d <= 0 || job.RetryCount != 0 {
+		t.Fatalf("busy: delay=%s retry=%v count=%d, want short uncounted retry", d, retry, job.RetryCount)
+	}
+	if _, retry := nextSegmentAttempt(&job, fmt.Errorf("fetch failed: %w", &nntp.ArticleNotFoundError{})); retry {
+		t.Fatal("not found after every provider should be permanent")
+	}
+	if d, retry := nextSegmentAttempt(&job, fmt.Errorf("%w by p1", errSegmentRejected)); !retry || d != 0 || job.RetryCount != 0 {
+		t.Fatalf("rejected: delay=%s retry=%v count=%d, want immediate uncounted retry", d, retry, job.RetryCount)
+	}
+
+	transient := errors.New("read: connection reset")
+	for i := 1; i <= maxSegmentRetries; i++ {
+		if _, retry := nextSegmentAttempt(&job, transient); !retry || job.RetryCount != i {
+			t.Fatalf("attempt %d: retry=%v count=%d", i, retry, job.RetryCount)
+		}
+	}
+	if _, retry := nextSegmentAttempt(&job, transient); retry {
+		t.Fatal("expected transient errors to give up after the retry budget")
+	}
+}
+
+func TestReportMissingSegmentsPersistsAndRecordsEvents(t *testing.T) {
+	m, store := newOrderTestManager(t)
+	item := addOrderTestItems(t, m, "a")["a"]
+	item.Release = &domain.Release{Title: "a"}
+
+	log, err := logger.New("/dev/null", logger.ParseLevel("error"), false)
+	if err != nil {
+		t.Fatalf("new logger: %v", err)
+	}
+	appCtx, err := app.NewContext(&config.Config{}, log)
+	if err != nil {
+		t.Fatalf("new app context: %v", err)
+	}
+	appCtx.JobStore = store
+	appCtx.QueueFileStore = store
+	s := &Downloader{ctx: appCtx}
+
+	dir := t.TempDir()
+	segments := []domain.Segment{{Number: 1, Bytes: 10}, {Number: 2, Bytes: 10}, {Number: 3, Bytes: 10}}
+	data := domain.NewDownloadFile("movie.mkv", 30, 0, segments, dir, "")
+	item.Tasks = []*domain.DownloadFile{data}
+	if err := store.SaveQueueItemFiles(context.Background(), item.ID, item.Tasks); err != nil {
+		t.Fatalf("save queue item files: %v", err)
+	}
+
+	data.MarkSegmentMissing(1)
+	err = s.reportMissingSegments(item)
+	if err == nil || !strings.Contains(err.Error(), "no PAR2 set") {
+		t.Fatalf("expected unrepairable error without PAR2, got %v", err)
+	}
+
+	// With a PAR2 set the gap is left for repair.
+	par := domain.NewDownloadFile("movie.par2", 10, 1, nil, dir, "")
+	par.IsPars = true
+	item.Tasks = append(item.Tasks, par)
+	if err := s.reportMissingSegments(item); err != nil {
+		t.Fatalf("expected missing segments to be left for PAR2, got %v", err)
+	}
+
+	files, err := store.GetQueueItemFiles(context.Background(), item.ID)
+	if err != nil {
+		t.Fatalf("get queue item files: %v", err)
+	}
+	if len(files) != 1 || len(files[0].MissingSegments) != 1 || files[0].MissingSegments[0] != 2 {
+		t.Fatalf("expected segment 2 missing on movie.mkv, got %+v", files)
+	}
+
+	events, err := store.GetQueueEvents(context.Background(), item.ID)
+	if err != nil {
+		t.Fatalf("get queue events: %v", err)
+	}
+	found := false
+	for _, ev := range events {
+		if ev.Status == "missing_segments" && strings.Contains(ev.Message, "1 of 3 segments missing") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected a missing_segments event, got %+v", events)
+	}
+
+	data.ResetMissingSegments()
+	if err := s.reportMissingSegments(item); err != nil {
+		t.Fatalf("report after reset: %v", err)
+	}
+	files, err = store.GetQueueItemFiles(context.Background(), item.ID)
+	if err != nil {
+		t.Fatalf("get queue item files: %v", err)
+	}
+	if len(files[0].MissingSegments) != 0 {
+		t.Fatalf("expected missing segments cleared, got %v", files[0].MissingSegments)
+	}
+}
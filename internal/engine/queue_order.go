@@ -0,0 +1,176 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/datallboy/gonzb/internal/domain"
+)
+
+// SetPriority changes an item's priority and places it at the end of its new priority band.
+// Returns the item's new index in the live queue.
+func (m *QueueManager) SetPriority(id string, priority domain.QueuePriority) (int, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx := m.indexOfLocked(id)
+	if idx < 0 {
+		return -1, false
+	}
+
+	item := m.queue[idx]
+	if item.Priority != priority {
+		item.Priority = priority
+		item.SortPosition = m.nextSortPositionLocked()
+		m.sortQueueLocked()
+		m.persistQueueOrderLocked()
+		m.recordEvent(context.Background(), item.ID, "queue", "priority_changed", "Priority set to "+priority.String())
+	}
+
+	m.signalNewJob()
+	return m.indexOfLocked(id), true
+}
+
+// Move reorders an item relative to its neighbours inside its own priority band.
+func (m *QueueManager) Move(id string, move domain.QueueMove) (int, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx := m.indexOfLocked(id)
+	if idx < 0 {
+		return -1, false
+	}
+
+	lo, hi := m.bandLocked(idx)
+	target := idx
+	switch move {
+	case domain.QueueMoveTop:
+		target = lo
+	case domain.QueueMoveBottom:
+		target = hi
+	case domain.QueueMoveUp:
+		target = max(lo, idx-1)
+	case domain.QueueMoveDown:
+		target = min(hi, idx+1)
+	default:
+		return -1, false
+	}
+
+	m.moveLocked(idx, target)
+	return target, true
+}
+
+// MoveToPosition places an item at an absolute queue index, SAB "switch" style.
+// The item keeps its priority, so the target is clamped to its own priority band.
+func (m *QueueManager) MoveToPosition(id string, position int) (int, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx := m.indexOfLocked(id)
+	if idx < 0 {
+		return -1, false
+	}
+	return m.moveWithinBandLocked(idx, position), true
+}
+
+// MoveToItem places an item at the current index of targetID, SAB "switch" style.
+// Both ids are resolved against the live queue under one lock.
+func (m *QueueManager) MoveToItem(id, targetID string) (int, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	idx := m.indexOfLocked(id)
+	target := m.indexOfLocked(targetID)
+	if idx < 0 || target < 0 {
+		return -1, false
+	}
+	return m.moveWithinBandLocked(idx, target), true
+}
+
+// moveWithinBandLocked moves the item at idx as close to position as its priority band allows.
+func (m *QueueManager) moveWithinBandLocked(idx, position int) int {
+	lo, hi := m.bandLocked(idx)
+	target := min(max(position, lo), hi)
+
+	m.moveLocked(idx, target)
+	m.signalNewJob()
+	return target
+}
+
+// bandLocked returns the first and last index of the priority band holding idx.
+func (m *QueueManager) bandLocked(idx int) (int, int) {
+	priority := m.queue[idx].Priority
+	lo, hi := idx, idx
+	for lo > 0 && m.queue[lo-1].Priority == priority {
+		lo--
+	}
+	for hi < len(m.queue)-1 && m.queue[hi+1].Priority == priority {
+		hi++
+	}
+	return lo, hi
+}
+
+// moveLocked shifts the item at from to index to, renumbers sort positions and persists them.
+func (m *QueueManager) moveLocked(from, to int) {
+	if from != to {
+		item := m.queue[from]
+		m.queue = append(m.queue[:from], m.queue[from+1:]...)
+		m.queue = append(m.queue[:to], append([]*domain.QueueItem{item}, m.queue[to:]...)...)
+		m.recordEvent(context.Background(), item.ID, "queue", "reordered", fmt.Sprintf("Moved to position %d", to))
+	}
+
+	for i, itm := range m.queue {
+		itm.SortPosition = int64(i + 1)
+	}
+	m.persistQueueOrderLocked()
+}
+
+func (m *QueueManager) indexOfLocked(id string) int {
+	for i, itm := range m.queue {
+		if itm.ID == id {
+			if itm.Status.IsTerminal() {
+				return -1
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *QueueManager) nextSortPositionLocked() int64 {
+	var maxPos int64
+	for _, itm := range m.queue {
+		if itm.SortPosition > maxPos {
+			maxPos = itm.SortPosition
+		}
+	}
+	return maxPos + 1
+}
+
+// sortQueueLocked keeps the live queue in dispatch order: priority desc, then sort position.
+func (m *QueueManager) sortQueueLocked() {
+	sort.SliceStable(m.queue, func(i, j int) bool {
+		if m.queue[i].Priority != m.queue[j].Priority {
+			return m.queue[i].Priority > m.queue[j].Priority
+		}
+		return m.queue[i].SortPosition < m.queue[j].SortPosition
+	})
+}
+
+func (m *QueueManager) persistQueueOrderLocked() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := m.jobStore.UpdateQueueItemOrder(ctx, m.queue); err != nil {
+		m.logger.Error("Failed to persist queue order: %v", err)
+	}
+}
+
+func (m *QueueManager) signalNewJob() {
+	select {
+	case m.newJobChan <- struct{}{}:
+	default:
+	}
+}
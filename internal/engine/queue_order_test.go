@@ -0,0 +1,149 @@
+package engine
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/datallboy/gonzb/internal/app"
+	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/infra/config"
+	"github.com/datallboy/gonzb/internal/infra/logger"
+	"github.com/datallboy/gonzb/internal/store/sqlitejob"
+)
+
+func newOrderTestManager(t *testing.T) (*QueueManager, *sqlitejob.Store) {
+	t.Helper()
+
+	dir := t.TempDir()
+	store, err := sqlitejob.NewStore(filepath.Join(dir, "gonzb.db"), filepath.Join(dir, "blobs"))
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	log, err := logger.New("/dev/null", logger.ParseLevel("error"), false)
+	if err != nil {
+		t.Fatalf("new logger: %v", err)
+	}
+
+	appCtx, err := app.NewContext(&config.Config{}, log)
+	if err != nil {
+		t.Fatalf("new app context: %v", err)
+	}
+	appCtx.JobStore = store
+
+	return NewQueueManager(appCtx, false), store
+}
+
+func addOrderTestItems(t *testing.T, m *QueueManager, ids ...string) map[string]*domain.QueueItem {
+	t.Helper()
+
+	out := make(map[string]*domain.QueueItem, len(ids))
+	for _, id := range ids {
+		item, err := m.Add(context.Background(), app.QueueAddRequest{
+			SourceKind:      "manual",
+			SourceReleaseID: id,
+			Title:           id,
+		})
+		if err != nil {
+			t.Fatalf("add %s: %v", id, err)
+		}
+		out[id] = item
+	}
+	return out
+}
+
+func queueTitles(m *QueueManager) []string {
+	items := m.GetAllItems()
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		out = append(out, item.ReleaseTitle)
+	}
+	return out
+}
+
+func assertQueueOrder(t *testing.T, m *QueueManager, want ...string) {
+	t.Helper()
+
+	got := queueTitles(m)
+	if len(got) != len(want) {
+		t.Fatalf("expected order %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected order %v, got %v", want, got)
+		}
+	}
+}
+
+func TestSetPriorityMovesItemIntoBand(t *testing.T) {
+	m, _ := newOrderTestManager(t)
+	items := addOrderTestItems(t, m, "a", "b", "c", "d")
+
+	if idx, ok := m.SetPriority(items["c"].ID, domain.PriorityHigh); !ok || idx != 0 {
+		t.Fatalf("expected c at index 0, got %d ok=%v", idx, ok)
+	}
+	if idx, ok := m.SetPriority(items["a"].ID, domain.PriorityLow); !ok || idx != 3 {
+		t.Fatalf("expected a at index 3, got %d ok=%v", idx, ok)
+	}
+	assertQueueOrder(t, m, "c", "b", "d", "a")
+}
+
+func TestMoveStaysWithinPriorityBand(t *testing.T) {
+	m, _ := newOrderTestManager(t)
+	items := addOrderTestItems(t, m, "a", "b", "c", "d")
+	m.SetPriority(items["a"].ID, domain.PriorityHigh)
+
+	if idx, ok := m.Move(items["d"].ID, domain.QueueMoveTop); !ok || idx != 1 {
+		t.Fatalf("expected d at index 1, got %d ok=%v", idx, ok)
+	}
+	assertQueueOrder(t, m, "a", "d", "b", "c")
+
+	m.Move(items["d"].ID, domain.QueueMoveDown)
+	assertQueueOrder(t, m, "a", "b", "d", "c")
+
+	m.Move(items["b"].ID, domain.QueueMoveBottom)
+	assertQueueOrder(t, m, "a", "d", "c", "b")
+
+	if _, ok := m.Move("missing", domain.QueueMoveUp); ok {
+		t.Fatal("expected move of unknown id to fail")
+	}
+}
+
+func TestMoveToPositionKeepsPriorityAndPersists(t *testing.T) {
+	m, store := newOrderTestManager(t)
+	items := addOrderTestItems(t, m, "a", "b", "c")
+	m.SetPriority(items["a"].ID, domain.PriorityHigh)
+
+	if idx, ok := m.MoveToPosition(items["c"].ID, 0); !ok || idx != 1 {
+		t.Fatalf("expected c clamped to index 1, got %d ok=%v", idx, ok)
+	}
+	if items["c"].Priority != domain.PriorityNormal {
+		t.Fatalf("expected c to keep normal priority, got %s", items["c"].Priority)
+	}
+	assertQueueOrder(t, m, "a", "c", "b")
+
+	persisted, err := store.GetActiveQueueItems(context.Background())
+	if err != nil {
+		t.Fatalf("get active queue items: %v", err)
+	}
+	if len(persisted) != 3 || persisted[1].ID != items["c"].ID || persisted[2].ID != items["b"].ID {
+		t.Fatalf("expected persisted order to match live queue, got %#v", persisted)
+	}
+}
+
+func TestMoveToItemResolvesTargetInLiveQueue(t *testing.T) {
+	m, _ := newOrderTestManager(t)
+	items := addOrderTestItems(t, m, "a", "b", "c", "d")
+	m.PauseItem(items["b"].ID)
+
+	if idx, ok := m.MoveToItem(items["d"].ID, items["b"].ID); !ok || idx != 1 {
+		t.Fatalf("expected d at paused b's index 1, got %d ok=%v", idx, ok)
+	}
+	assertQueueOrder(t, m, "a", "d", "b", "c")
+
+	if _, ok := m.MoveToItem(items["d"].ID, "missing"); ok {
+		t.Fatal("expected move to unknown target to fail")
+	}
+}
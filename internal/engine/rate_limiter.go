@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"context"
+	"io"
+
+	"github.com/datallboy/gonzb/internal/app"
+	"golang.org/x/time/rate"
+)
+
+// minLimiterBurst keeps the bucket large enough to admit a typical article read in one wait.
+const minLimiterBurst = 256 * 1024
+
+// RateLimiter is the global download token bucket shared by every active item.
+// A limit of 0 disables throttling.
+type RateLimiter struct {
+	limiter *rate.Limiter
+}
+
+func NewRateLimiter(bytesPerSec int64) *RateLimiter {
+	l := &RateLimiter{limiter: rate.NewLimiter(rate.Inf, minLimiterBurst)}
+	l.SetLimit(bytesPerSec)
+	return l
+}
+
+func (l *RateLimiter) SetLimit(bytesPerSec int64) {
+	if bytesPerSec <= 0 {
+		l.limiter.SetLimit(rate.Inf)
+		l.limiter.SetBurst(minLimiterBurst)
+		return
+	}
+	l.limiter.SetLimit(rate.Limit(bytesPerSec))
+	l.limiter.SetBurst(int(max(bytesPerSec, minLimiterBurst)))
+}
+
+// Limit returns the current limit in bytes per second, or 0 when unlimited.
+func (l *RateLimiter) Limit() int64 {
+	limit := l.limiter.Limit()
+	if limit == rate.Inf {
+		return 0
+	}
+	return int64(limit)
+}
+
+// WaitN blocks until n bytes may be consumed, splitting requests larger than the burst.
+func (l *RateLimiter) WaitN(ctx context.Context, n int) error {
+	for n > 0 {
+		chunk := min(n, l.limiter.Burst())
+		if err := l.limiter.WaitN(ctx, chunk); err != nil {
+			return err
+		}
+		n -= chunk
+	}
+	return nil
+}
+
+// throttledReader charges every read against the shared limiter.
+type throttledReader struct {
+	ctx     context.Context
+	reader  io.Reader
+	limiter app.SpeedLimiter
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		if waitErr := r.limiter.WaitN(r.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
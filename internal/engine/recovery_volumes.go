@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/processor"
+)
+
+// holdRecoveryVolumes marks the PAR2 recovery volumes that are not on disk yet
+// as skipped so the first pass only fetches data files and the index .par2.
+func holdRecoveryVolumes(tasks []*domain.DownloadFile) []*domain.DownloadFile {
+	var held []*domain.DownloadFile
+	for _, v := range processor.RecoveryVolumes(tasks) {
+		v.Skipped = !v.IsComplete
+		if v.Skipped {
+			held = append(held, v)
+		}
+	}
+	return held
+}
+
+// fetchNeededRecoveryVolumes verifies the first pass and downloads just enough
+// held volumes to cover the damage, re-verifying after each round in case a
+// volume itself came down short. Volumes that were never needed are discarded.
+func (s *Downloader) fetchNeededRecoveryVolumes(ctx context.Context, item *domain.QueueItem, held []*domain.DownloadFile) error {
+	for len(held) > 0 {
+		shortfall, err := s.processor.RecoveryShortfall(ctx, item.Tasks)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			s.ctx.Logger.Warn("PAR2 verify failed for %s, fetching all recovery volumes: %v", item.Release.Title, err)
+			shortfall = math.MaxInt
+		}
+		if shortfall == 0 {
+			break
+		}
+
+		picked := processor.SelectRecoveryVolumes(held, shortfall)
+		if len(picked) == 0 {
+			break
+		}
+		for _, v := range picked {
+			v.Skipped = false
+		}
+		held = remainingSkipped(held)
+
+		s.ctx.Logger.Info("Fetching %d recovery volume(s) for %d missing block(s): %s", len(picked), shortfall, item.Release.Title)
+		if err := s.runWorkerPool(ctx, item, nil); err != nil {
+			return err
+		}
+		if err := s.processor.Finalize(ctx, item.Tasks); err != nil {
+			return fmt.Errorf("post-processing failed: %w", err)
+		}
+	}
+
+	s.discardSkippedVolumes(item, held)
+	return nil
+}
+
+// discardSkippedVolumes drops the pre-allocated .part files of volumes that
+// were never fetched and records them on the queue item's file set.
+func (s *Downloader) discardSkippedVolumes(item *domain.QueueItem, skipped []*domain.DownloadFile) {
+	indexes := make([]int, 0, len(skipped))
+	for _, v := range skipped {
+		_ = s.writer.CloseFile(v.PartPath, 0)
+		if err := os.Remove(v.PartPath); err != nil && !os.IsNotExist(err) {
+			s.ctx.Logger.Debug("Failed to remove unused recovery volume %s: %v", v.PartPath, err)
+		}
+		indexes = append(indexes, v.Index)
+	}
+	if len(skipped) > 0 {
+		s.ctx.Logger.Info("Skipped %d unneeded recovery volume(s) for: %s", len(skipped), item.Release.Title)
+	}
+
+	store := s.ctx.QueueFileStore
+	if store == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := store.SetQueueItemFilesSkipped(ctx, item.ID, indexes); err != nil {
+		s.ctx.Logger.Warn("Failed to record skipped recovery volumes for %s: %v", item.ID, err)
+	}
+}
+
+func remainingSkipped(tasks []*domain.DownloadFile) []*domain.DownloadFile {
+	out := tasks[:0:0]
+	for _, t := range tasks {
+		if t.Skipped {
+			out = append(out, t)
+		}
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/datallboy/gonzb/internal/domain"
+)
+
+func TestHoldRecoveryVolumesAndRecordSkipped(t *testing.T) {
+	m, store := newOrderTestManager(t)
+	item := addOrderTestItems(t, m, "a")["a"]
+
+	dir := t.TempDir()
+	data := domain.NewDownloadFile("movie.mkv", 100, 0, nil, dir, "")
+	index := domain.NewDownloadFile("movie.par2", 10, 1, nil, dir, "")
+	fetched := domain.NewDownloadFile("movie.vol00+01.par2", 10, 2, nil, dir, "")
+	fetched.IsComplete = true
+	unneeded := domain.NewDownloadFile("movie.vol01+02.par2", 20, 3, nil, dir, "")
+	tasks := []*domain.DownloadFile{data, index, fetched, unneeded}
+
+	held := holdRecoveryVolumes(tasks)
+	if len(held) != 1 || held[0] != unneeded || !unneeded.Skipped {
+		t.Fatalf("expected only the missing volume to be held, got %v", held)
+	}
+	if data.Skipped || index.Skipped || fetched.Skipped {
+		t.Fatal("expected data files, index and fetched volumes to stay scheduled")
+	}
+
+	if err := store.SaveQueueItemFiles(context.Background(), item.ID, tasks); err != nil {
+		t.Fatalf("save queue item files: %v", err)
+	}
+	if err := store.SetQueueItemFilesSkipped(context.Background(), item.ID, []int{unneeded.Index}); err != nil {
+		t.Fatalf("record skipped files: %v", err)
+	}
+
+	files, err := store.GetQueueItemFiles(context.Background(), item.ID)
+	if err != nil {
+		t.Fatalf("get queue item files: %v", err)
+	}
+	for _, f := range files {
+		if want := f.Index == unneeded.Index; f.Skipped != want {
+			t.Fatalf("file %s skipped=%v, want %v", f.FileName, f.Skipped, want)
+		}
+	}
+}
+
+func TestSkippedFilesAreKeptPerQueueItem(t *testing.T) {
+	m, store := newOrderTestManager(t)
+	items := addOrderTestItems(t, m, "a", "b")
+
+	// both items come from the same NZB, so they share one file set.
+	dir := t.TempDir()
+	tasks := []*domain.DownloadFile{
+		domain.NewDownloadFile("movie.par2", 10, 0, nil, dir, ""),
+		domain.NewDownloadFile("movie.vol00+01.par2", 10, 1, nil, dir, ""),
+	}
+	for _, item := range items {
+		if err := store.SaveQueueItemFiles(context.Background(), item.ID, tasks); err != nil {
+			t.Fatalf("save queue item files: %v", err)
+		}
+	}
+	if err := store.SetQueueItemFilesSkipped(context.Background(), items["a"].ID, []int{1}); err != nil {
+		t.Fatalf("record skipped files: %v", err)
+	}
+
+	files, err := store.GetQueueItemFiles(context.Background(), items["b"].ID)
+	if err != nil {
+		t.Fatalf("get queue item files: %v", err)
+	}
+	for _, f := range files {
+		if f.Skipped {
+			t.Fatalf("expected %s to stay scheduled for the other item", f.FileName)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/processor"
+)
+
+// ErrNotRetryable is returned when an item cannot be sent back through
+// post-processing: it is still queued, completed, or has nothing on disk.
+var ErrNotRetryable = errors.New("queue item cannot be retried")
+
+// Reprocess sends a failed or password_required history item back through
+// repair, extraction and the move to the completed dir, reusing the files in
+// its work dir instead of downloading it again.
+func (m *QueueManager) Reprocess(ctx context.Context, id string) (*domain.QueueItem, error) {
+	return m.requeuePostProcessing(ctx, id, "", "Re-running post-processing from files on disk")
+}
+
+// RetryWithPassword sends a failed or password_required history item back
+// through post-processing with password tried before the configured lists.
+// The downloaded files are reused from the item's work dir.
+func (m *QueueManager) RetryWithPassword(ctx context.Context, id, password string) (*domain.QueueItem, error) {
+	if password == "" {
+		return nil, fmt.Errorf("password is required")
+	}
+	return m.requeuePostProcessing(ctx, id, password, "Retrying post-processing with a supplied password")
+}
+
+// requeuePostProcessing rebuilds a history item's tasks from the files on disk
+// and puts it back in the live queue in processing state, so the dispatcher
+// skips hydration and download and only re-runs repair/extract/move. Events
+// keep landing on the same queue item.
+func (m *QueueManager) requeuePostProcessing(ctx context.Context, id, password, reason string) (*domain.QueueItem, error) {
+	m.mu.RLock()
+	live := m.indexOfLocked(id) >= 0
+	m.mu.RUnlock()
+	if live {
+		return nil, fmt.Errorf("%w: %s is still in the queue", ErrNotRetryable, id)
+	}
+
+	item, err := m.jobStore.GetQueueItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, fmt.Errorf("%w: %s not found", ErrNotRetryable, id)
+	}
+	if item.Status != domain.StatusFailed && item.Status != domain.StatusPasswordRequired {
+		return nil, fmt.Errorf("%w: %s is %s", ErrNotRetryable, id, item.Status)
+	}
+
+	if item.Release == nil {
+		item.Release = hydrateReleaseFromSnapshot(item)
+	}
+	tasks, err := m.tasksFromDisk(ctx, item, password)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	item.Tasks = tasks
+	item.Status = domain.StatusProcessing
+	item.Error = nil
+	item.CompletedAt = time.Time{}
+	item.ProcessingStartedAt = now
+	item.PostProcessSeconds = 0
+	item.UpdatedAt = now
+	// finalizeJob recomputes DownloadedBytes from the live counter.
+	item.BytesWritten.Store(item.DownloadedBytes)
+
+	if err := m.jobStore.SaveQueueItem(ctx, item); err != nil {
+		return nil, fmt.Errorf("failed to persist queue item: %w", err)
+	}
+
+	// a concurrent retry may have requeued the item while the lock was dropped.
+	m.mu.Lock()
+	if m.indexOfLocked(id) >= 0 {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s is still in the queue", ErrNotRetryable, id)
+	}
+	m.queue = append(m.queue, item)
+	m.sortQueueLocked()
+	m.mu.Unlock()
+
+	m.recordEvent(ctx, item.ID, "queue", "reprocess", reason)
+	m.logger.Info("Re-running post-processing for: %s", releaseTitle(item))
+	m.signalNewJob()
+
+	return item, nil
+}
+
+// tasksFromDisk rebuilds download tasks from the persisted file list, marking
+// whatever is present in the work dir as complete. Files a failed move already
+// put in the completed dir are picked up from there. Files that were never
+// finished stay incomplete so PAR2 repair can account for them.
+func (m *QueueManager) tasksFromDisk(ctx context.Context, item *domain.QueueItem, password string) ([]*domain.DownloadFile, error) {
+	if m.queueFiles == nil {
+		return nil, fmt.Errorf("queue file store is unavailable")
+	}
+	if item.OutDir == "" {
+		return nil, fmt.Errorf("%w: %s has no work directory", ErrNotRetryable, item.ID)
+	}
+
+	files, err := m.queueFiles.GetQueueItemFiles(ctx, item.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load queue files: %w", err)
+	}
+
+	if password == "" {
+		password = m.releasePassword(ctx, item, files)
+	}
+
+	completedDir := ""
+	if m.processor != nil {
+		completedDir = m.processor.CompletedDir(item)
+	}
+
+	present := 0
+	for _, f := range files {
+		f.Prepare(item.OutDir)
+		f.Password = password
+		_, err := os.Stat(f.FinalPath)
+		if err != nil && completedDir != "" {
+			moved := filepath.Join(completedDir, f.FileName)
+			if _, err = os.Stat(moved); err == nil {
+				f.FinalPath = moved
+			}
+		}
+		if err == nil {
+			f.IsComplete = true
+			present++
+		}
+	}
+	if present == 0 {
+		return nil, fmt.Errorf("%w: no downloaded files for %s in %s", ErrNotRetryable, item.ID, item.OutDir)
+	}
+	return files, nil
+}
+
+// releasePassword recovers the password Prepare found when the item was
+// hydrated, which is not persisted: the cached NZB's meta tag first, then a
+// {{password}} in the release title or first subject.
+func (m *QueueManager) releasePassword(ctx context.Context, item *domain.QueueItem, files []*domain.DownloadFile) string {
+	if item.PayloadMode == domain.PayloadModeCached && m.payloadFetcher != nil && m.parser != nil && item.Release != nil {
+		if reader, err := m.payloadFetcher.GetNZB(ctx, item.SourceKind, item.Release); err == nil {
+			model, parseErr := m.parser.Parse(reader)
+			reader.Close()
+			if parseErr == nil {
+				if password := model.GetPassword(); password != "" {
+					return password
+				}
+			}
+		}
+	}
+
+	if password := processor.ExtractPassword(item.ReleaseTitle); password != "" {
+		return password
+	}
+	if len(files) > 0 {
+		return processor.ExtractPassword(files[0].Subject)
+	}
+	return ""
+}
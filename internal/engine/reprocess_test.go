@@ -0,0 +1,284 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/datallboy/gonzb/internal/app"
+	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/infra/config"
+	"github.com/datallboy/gonzb/internal/infra/logger"
+	"github.com/datallboy/gonzb/internal/processor"
+)
+
+func TestPasswordFailureIsRetriedFromDiskWithSuppliedPassword(t *testing.T) {
+	m, store := newOrderTestManager(t)
+	m.queueFiles = store
+	item := addOrderTestItems(t, m, "encrypted")["encrypted"]
+
+	item.OutDir = t.TempDir()
+	files := []*domain.DownloadFile{
+		{FileName: "release.rar", Size: 4, Index: 0},
+		{FileName: "release.r00", Size: 4, Index: 1},
+	}
+	if err := store.SaveQueueItemFiles(t.Context(), item.ID, files); err != nil {
+		t.Fatalf("save files: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(item.OutDir, "release.rar"), []byte("rar!"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	item.BytesWritten.Store(4)
+
+	m.finalizeJob(t.Context(), item, fmt.Errorf("Archive extraction failed: release.rar: %w", processor.ErrArchivePassword))
+	if item.Status != domain.StatusPasswordRequired {
+		t.Fatalf("status = %s, want %s", item.Status, domain.StatusPasswordRequired)
+	}
+	if len(m.GetAllItems()) != 0 {
+		t.Fatal("expected password_required item to leave the live queue")
+	}
+
+	retried, err := m.RetryWithPassword(t.Context(), item.ID, "secret")
+	if err != nil {
+		t.Fatalf("RetryWithPassword() error = %v", err)
+	}
+	if retried.Status != domain.StatusProcessing || retried.Error != nil {
+		t.Fatalf("retried item = %s / %v, want processing without error", retried.Status, retried.Error)
+	}
+	if retried.GetBytes() != 4 {
+		t.Fatalf("bytes = %d, want the persisted download size", retried.GetBytes())
+	}
+	if len(retried.Tasks) != 2 || !retried.Tasks[0].IsComplete || retried.Tasks[1].IsComplete {
+		t.Fatalf("tasks not rebuilt from disk: %+v", retried.Tasks)
+	}
+	for _, task := range retried.Tasks {
+		if task.Password != "secret" {
+			t.Fatalf("task %s password = %q", task.FileName, task.Password)
+		}
+	}
+
+	m.mu.Lock()
+	next := m.nextRunnableLocked()
+	m.mu.Unlock()
+	if next != retried {
+		t.Fatalf("expected retried item to be dispatched next, got %v", next)
+	}
+
+	if _, err := m.RetryWithPassword(t.Context(), item.ID, "again"); err == nil {
+		t.Fatal("expected a queued item to refuse a second retry")
+	}
+}
+
+func TestReprocessRecoversSubjectPasswordAndKeepsTimeline(t *testing.T) {
+	m, store := newOrderTestManager(t)
+	m.queueFiles = store
+	item := addOrderTestItems(t, m, "broken")["broken"]
+
+	item.OutDir = t.TempDir()
+	files := []*domain.DownloadFile{
+		{FileName: "release.mkv", Subject: `"release.mkv" {{hunter2}} yEnc (1/1)`, Size: 5, Index: 0},
+	}
+	if err := store.SaveQueueItemFiles(t.Context(), item.ID, files); err != nil {
+		t.Fatalf("save files: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(item.OutDir, "release.mkv"), []byte("video"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m.finalizeJob(t.Context(), item, fmt.Errorf("move failed"))
+	if item.Status != domain.StatusFailed {
+		t.Fatalf("status = %s, want %s", item.Status, domain.StatusFailed)
+	}
+
+	retried, err := m.Reprocess(t.Context(), item.ID)
+	if err != nil {
+		t.Fatalf("Reprocess() error = %v", err)
+	}
+	if retried.ID != item.ID || retried.Status != domain.StatusProcessing {
+		t.Fatalf("reprocessed item = %s / %s, want %s processing", retried.ID, retried.Status, item.ID)
+	}
+	if len(retried.Tasks) != 1 || !retried.Tasks[0].IsComplete || retried.Tasks[0].Password != "hunter2" {
+		t.Fatalf("tasks not rebuilt with the subject password: %+v", retried.Tasks)
+	}
+
+	events, err := store.GetQueueEvents(t.Context(), item.ID)
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	var failed, requeued bool
+	for _, ev := range events {
+		failed = failed || ev.Status == "failed"
+		requeued = requeued || (ev.Stage == "queue" && ev.Status == "reprocess")
+	}
+	if !failed || !requeued {
+		t.Fatalf("expected failure and reprocess events on the same timeline, got %+v", events)
+	}
+}
+
+func TestConcurrentPasswordRetriesQueueTheItemOnce(t *testing.T) {
+	m, item := newFailedRetryItem(t, fmt.Errorf("Archive extraction failed: release.rar: %w", processor.ErrArchivePassword))
+	assertRequeuedOnce(t, m, item.ID, func() error {
+		_, err := m.RetryWithPassword(t.Context(), item.ID, "secret")
+		return err
+	})
+}
+
+func TestConcurrentReprocessQueuesTheItemOnce(t *testing.T) {
+	m, item := newFailedRetryItem(t, fmt.Errorf("move failed"))
+	assertRequeuedOnce(t, m, item.ID, func() error {
+		_, err := m.Reprocess(t.Context(), item.ID)
+		return err
+	})
+}
+
+// newFailedRetryItem finalizes a one-file item with cause and leaves its file
+// on disk so it can be sent back through post-processing.
+func newFailedRetryItem(t *testing.T, cause error) (*QueueManager, *domain.QueueItem) {
+	t.Helper()
+	m, store := newOrderTestManager(t)
+	m.queueFiles = store
+	item := addOrderTestItems(t, m, "release")["release"]
+
+	item.OutDir = t.TempDir()
+	files := []*domain.DownloadFile{{FileName: "release.rar", Size: 4, Index: 0}}
+	if err := store.SaveQueueItemFiles(t.Context(), item.ID, files); err != nil {
+		t.Fatalf("save files: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(item.OutDir, "release.rar"), []byte("rar!"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m.finalizeJob(t.Context(), item, cause)
+	return m, item
+}
+
+// assertRequeuedOnce runs retry from several goroutines at once and expects
+// exactly one to put the item back in the live queue.
+func assertRequeuedOnce(t *testing.T, m *QueueManager, id string, retry func() error) {
+	t.Helper()
+
+	const callers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- retry()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, ErrNotRetryable):
+			t.Fatalf("unexpected retry error: %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("expected exactly one retry to succeed, got %d", succeeded)
+	}
+
+	live := 0
+	for _, queued := range m.GetAllItems() {
+		if queued.ID == id {
+			live++
+		}
+	}
+	if live != 1 {
+		t.Fatalf("expected one live copy of the item, got %d", live)
+	}
+}
+
+func TestReprocessPicksUpFilesAFailedMoveLeftInCompletedDir(t *testing.T) {
+	m, store := newOrderTestManager(t)
+	m.queueFiles = store
+	item := addOrderTestItems(t, m, "release")["release"]
+
+	root := t.TempDir()
+	item.OutDir = filepath.Join(root, "work", "release")
+	log, err := logger.New("/dev/null", logger.ParseLevel("error"), false)
+	if err != nil {
+		t.Fatalf("new logger: %v", err)
+	}
+	proc := processor.New(&app.Context{
+		Config: &config.Config{Download: config.DownloadConfig{
+			OutDir:       filepath.Join(root, "work"),
+			CompletedDir: filepath.Join(root, "completed"),
+			Categories: []config.CategoryConfig{{
+				Name:              config.DefaultCategory,
+				PostProcessing:    config.PostProcessNone,
+				CleanupExtensions: []string{"par2"},
+			}},
+		}},
+		Logger: log,
+	}, nil)
+	m.processor = proc
+
+	files := []*domain.DownloadFile{
+		{FileName: "release.par2", Size: 4, Index: 0},
+		{FileName: "a.mkv", Size: 5, Index: 1},
+		{FileName: "b.mkv", Size: 5, Index: 2},
+	}
+	if err := store.SaveQueueItemFiles(t.Context(), item.ID, files); err != nil {
+		t.Fatalf("save files: %v", err)
+	}
+	if err := os.MkdirAll(item.OutDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		f.Prepare(item.OutDir)
+		f.IsComplete = true
+		if err := os.WriteFile(f.FinalPath, []byte(f.FileName), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a directory squatting on b.mkv's destination fails the move halfway.
+	completedDir := proc.CompletedDir(item)
+	blocker := filepath.Join(completedDir, "b.mkv")
+	if err := os.MkdirAll(filepath.Join(blocker, "busy"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	err = proc.PostProcess(t.Context(), item, files)
+	if err == nil {
+		t.Fatal("expected the move to fail")
+	}
+	if _, err := os.Stat(filepath.Join(item.OutDir, "release.par2")); err != nil {
+		t.Fatalf("cleanup ran before every move succeeded: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(completedDir, "a.mkv")); err != nil {
+		t.Fatalf("expected a.mkv to have moved before the failure: %v", err)
+	}
+	m.finalizeJob(t.Context(), item, err)
+
+	if err := os.RemoveAll(blocker); err != nil {
+		t.Fatal(err)
+	}
+	retried, err := m.Reprocess(t.Context(), item.ID)
+	if err != nil {
+		t.Fatalf("Reprocess() error = %v", err)
+	}
+	for _, task := range retried.Tasks {
+		if !task.IsComplete {
+			t.Fatalf("%s not found on disk: %s", task.FileName, task.FinalPath)
+		}
+	}
+	if err := proc.PostProcess(t.Context(), retried, retried.Tasks); err != nil {
+		t.Fatalf("PostProcess() after reprocess error = %v", err)
+	}
+	for _, name := range []string{"a.mkv", "b.mkv"} {
+		if got, err := os.ReadFile(filepath.Join(completedDir, name)); err != nil || string(got) != name {
+			t.Fatalf("completed %s = %q, %v", name, got, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(item.OutDir, "release.par2")); !os.IsNotExist(err) {
+		t.Fatalf("expected release.par2 to be cleaned up after the move, got %v", err)
+	}
+}
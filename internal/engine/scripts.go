@@ -0,0 +1,195 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/integrations/scripts"
+)
+
+const defaultScriptTimeout = 10 * time.Minute
+
+// maxPreQueueScriptTimeout bounds pre-queue scripts, which run inside the add request.
+const maxPreQueueScriptTimeout = 30 * time.Second
+
+// ErrRejectedByScript is returned by Add when the pre-queue script refuses an NZB.
+var ErrRejectedByScript = errors.New("rejected by pre-queue script")
+
+// preQueueOutcome is recorded as the meta of the "prequeue" event.
+type preQueueOutcome struct {
+	*scripts.Result
+	Decision scripts.PreQueueDecision `json:"decision"`
+	Error    string                   `json:"error,omitempty"`
+}
+
+func (m *QueueManager) scriptTimeout() time.Duration {
+	if m.config == nil || m.config.Download.ScriptTimeoutSeconds <= 0 {
+		return defaultScriptTimeout
+	}
+	return time.Duration(m.config.Download.ScriptTimeoutSeconds) * time.Second
+}
+
+func (m *QueueManager) preQueueScriptTimeout() time.Duration {
+	return min(m.scriptTimeout(), maxPreQueueScriptTimeout)
+}
+
+// runPreQueueScript lets the configured pre-queue script accept, reject,
+// rename or recategorise a release before it is queued. A script that fails
+// to run accepts the release unchanged, as SABnzbd does.
+func (m *QueueManager) runPreQueueScript(ctx context.Context, release *domain.Release) (*preQueueOutcome, error) {
+	if m.config == nil || strings.TrimSpace(m.config.Download.PreQueueScript) == "" {
+		return nil, nil
+	}
+	path, err := m.config.Download.ResolveScript(m.config.Download.PreQueueScript)
+	if err != nil {
+		m.logger.Warn("Pre-queue script skipped for %s: %v", release.Title, err)
+		return &preQueueOutcome{
+			Result:   &scripts.Result{Script: m.config.Download.PreQueueScript, ExitCode: -1},
+			Decision: scripts.PreQueueDecision{Accept: true},
+			Error:    err.Error(),
+		}, nil
+	}
+
+	category, _ := m.config.Download.ResolveCategory(release.Category)
+	priority, _ := domain.ParseQueuePriority(category.Priority)
+	args, env := scripts.PreQueueArgs(scripts.PreQueueJob{
+		NZBName:  release.Title,
+		PP:       category.PostProcessLevel(),
+		Category: release.Category,
+		Priority: int(priority),
+		Bytes:    release.Size,
+	})
+
+	res, err := scripts.Run(ctx, path, args, env, m.preQueueScriptTimeout())
+	outcome := &preQueueOutcome{Result: res, Decision: scripts.PreQueueDecision{Accept: true}}
+	if err != nil {
+		m.logger.Warn("Pre-queue script failed for %s, accepting as-is: %v", release.Title, err)
+		outcome.Error = err.Error()
+		return outcome, nil
+	}
+	if res.ExitCode != 0 {
+		m.logger.Warn("Pre-queue script exited with %d for %s, accepting as-is", res.ExitCode, release.Title)
+		return outcome, nil
+	}
+
+	outcome.Decision = scripts.ParsePreQueueOutput(res.Output)
+	if !outcome.Decision.Accept {
+		m.logger.Info("Pre-queue script rejected: %s", release.Title)
+		return outcome, fmt.Errorf("%w: %s", ErrRejectedByScript, release.Title)
+	}
+	if name := outcome.Decision.Name; name != "" && name != release.Title {
+		m.logger.Info("Pre-queue script renamed %s to %s", release.Title, name)
+		release.Title = name
+	}
+	if cat := outcome.Decision.Category; cat != "" {
+		resolved, known := m.config.Download.ResolveCategory(cat)
+		if known {
+			cat = resolved.Name
+		}
+		release.Category = cat
+	}
+	return outcome, nil
+}
+
+// apply gives a new item the priority the script asked for. SAB's -2 adds it
+// paused; -100 (default) and -3 (duplicate) leave it unchanged.
+func (o *preQueueOutcome) apply(item *domain.QueueItem) {
+	if o == nil {
+		return
+	}
+	switch raw := o.Decision.Priority; raw {
+	case "", "-100", "-3":
+	case "-2":
+		item.Status = domain.StatusPaused
+	default:
+		if priority, ok := domain.ParseQueuePriority(raw); ok {
+			item.Priority = priority
+		}
+	}
+}
+
+func (m *QueueManager) recordPreQueueEvent(ctx context.Context, queueID string, outcome *preQueueOutcome) {
+	if outcome == nil {
+		return
+	}
+	status := "ok"
+	message := fmt.Sprintf("Pre-queue script %s exited with %d", filepath.Base(outcome.Script), outcome.ExitCode)
+	if outcome.Error != "" {
+		status = "failed"
+		message = "Pre-queue script failed: " + outcome.Error
+	} else if outcome.ExitCode != 0 {
+		status = "failed"
+	}
+	m.recordEventMeta(ctx, queueID, "prequeue", status, message, outcome)
+}
+
+// runPostProcessScript runs the item's category script once the job has
+// finished, successfully or not. Like SABnzbd, a script that fails on a
+// completed job fails the job; a failed job keeps its original error.
+func (m *QueueManager) runPostProcessScript(ctx context.Context, item *domain.QueueItem, jobErr error) error {
+	if m.config == nil || ctx.Err() != nil {
+		return jobErr
+	}
+
+	categoryName := ""
+	if item.Release != nil {
+		categoryName = item.Release.Category
+	}
+	category, _ := m.config.Download.ResolveCategory(categoryName)
+	path := strings.TrimSpace(category.Script)
+	if path == "" {
+		return jobErr
+	}
+
+	job := scripts.PostProcessJob{
+		ID:       item.ID,
+		FinalDir: item.OutDir,
+		NZBName:  releaseTitle(item) + ".nzb",
+		JobName:  releaseTitle(item),
+		Category: categoryName,
+		Bytes:    item.GetBytes(),
+		Failed:   jobErr != nil,
+	}
+	if jobErr != nil {
+		job.FailMsg = jobErr.Error()
+	}
+	if item.OutDir != "" {
+		job.JobName = filepath.Base(item.OutDir)
+	}
+	if len(item.Tasks) > 0 && len(item.Tasks[0].Groups) > 0 {
+		job.Group = item.Tasks[0].Groups[0]
+	}
+
+	args, env := scripts.PostProcessArgs(job)
+	m.recordEvent(ctx, item.ID, "script", "start", "Running post-processing script "+filepath.Base(path))
+
+	res := &scripts.Result{Script: path, ExitCode: -1}
+	resolved, err := m.config.Download.ResolveScript(path)
+	if err == nil {
+		res, err = scripts.Run(ctx, resolved, args, env, m.scriptTimeout())
+	}
+	switch {
+	case err != nil:
+		m.recordEventMeta(ctx, item.ID, "script", "failed", err.Error(), res)
+		m.logger.Warn("Post-processing script failed for %s: %v", releaseTitle(item), err)
+		if jobErr == nil {
+			return fmt.Errorf("post-processing script: %w", err)
+		}
+	case res.ExitCode != 0:
+		message := fmt.Sprintf("Post-processing script %s exited with %d", filepath.Base(path), res.ExitCode)
+		m.recordEventMeta(ctx, item.ID, "script", "failed", message, res)
+		m.logger.Warn("%s for %s", message, releaseTitle(item))
+		if jobErr == nil {
+			return errors.New(message)
+		}
+	default:
+		message := fmt.Sprintf("Post-processing script %s exited with 0", filepath.Base(path))
+		m.recordEventMeta(ctx, item.ID, "script", "ok", message, res)
+	}
+	return jobErr
+}
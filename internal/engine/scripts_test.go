@@ -0,0 +1,163 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/datallboy/gonzb/internal/app"
+	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/infra/config"
+)
+
+// writeTestScript writes an executable script into the manager's scripts dir
+// and returns its name relative to it.
+func writeTestScript(t *testing.T, m *QueueManager, name, body string) string {
+	t.Helper()
+
+	if m.config.Download.ScriptsDir == "" {
+		m.config.Download.ScriptsDir = t.TempDir()
+	}
+	path := filepath.Join(m.config.Download.ScriptsDir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	return name
+}
+
+func TestPreQueueScriptRenamesAndRejects(t *testing.T) {
+	m, store := newOrderTestManager(t)
+	m.config.Download.PreQueueScript = writeTestScript(t, m, "prequeue.sh", `
+if [ "$1" = "reject-me" ]; then echo 0; exit 0; fi
+printf '1\nrenamed-%s\n\nTV\n' "$SAB_FILENAME"`)
+	m.config.Download.Categories = []config.CategoryConfig{{Name: "tv"}}
+
+	item := addOrderTestItems(t, m, "a")["a"]
+	if item.Release.Title != "renamed-a" || item.Release.Category != "tv" {
+		t.Fatalf("expected script rename and category, got %q/%q", item.Release.Title, item.Release.Category)
+	}
+
+	events, err := store.GetQueueEvents(context.Background(), item.ID)
+	if err != nil {
+		t.Fatalf("get queue events: %v", err)
+	}
+	found := false
+	for _, ev := range events {
+		if ev.Stage == "prequeue" && ev.Status == "ok" && strings.Contains(ev.MetaJSON, "renamed-a") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected a prequeue event with captured output, got %+v", events)
+	}
+
+	_, err = m.Add(context.Background(), app.QueueAddRequest{
+		SourceKind:      "manual",
+		SourceReleaseID: "b",
+		Title:           "reject-me",
+	})
+	if !errors.Is(err, ErrRejectedByScript) {
+		t.Fatalf("expected rejection, got %v", err)
+	}
+}
+
+func TestPostProcessScriptFailsCompletedJobOnNonZeroExit(t *testing.T) {
+	m, store := newOrderTestManager(t)
+	argsFile := filepath.Join(t.TempDir(), "args")
+	m.config.Download.Categories = []config.CategoryConfig{{
+		Name:   "tv",
+		Script: writeTestScript(t, m, "post.sh", `echo "$1|$3|$5|$7|$SAB_STATUS" > `+argsFile+`; echo done; exit 3`),
+	}}
+
+	item := &domain.QueueItem{
+		ID:      "job-1",
+		OutDir:  "/completed/tv/Show.S01E01",
+		Release: &domain.Release{Title: "Show.S01E01", Category: "tv"},
+	}
+	err := m.runPostProcessScript(context.Background(), item, nil)
+	if err == nil || !strings.Contains(err.Error(), "exited with 3") {
+		t.Fatalf("expected script exit code to fail the job, got %v", err)
+	}
+
+	raw, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("read script args: %v", err)
+	}
+	if got, want := strings.TrimSpace(string(raw)), "/completed/tv/Show.S01E01|Show.S01E01|tv|0|Completed"; got != want {
+		t.Fatalf("script args = %q, want %q", got, want)
+	}
+
+	jobErr := errors.New("download failed")
+	if err := m.runPostProcessScript(context.Background(), item, jobErr); err != jobErr {
+		t.Fatalf("expected the original job error to be kept, got %v", err)
+	}
+
+	events, err := store.GetQueueEvents(context.Background(), item.ID)
+	if err != nil {
+		t.Fatalf("get queue events: %v", err)
+	}
+	failed := 0
+	for _, ev := range events {
+		if ev.Stage == "script" && ev.Status == "failed" && strings.Contains(ev.MetaJSON, `"output":"done"`) {
+			failed++
+		}
+	}
+	if failed != 2 {
+		t.Fatalf("expected two failed script events with captured output, got %+v", events)
+	}
+}
+
+func TestPreQueueScriptAppliesPriorityAndIgnoresUnsupportedOverrides(t *testing.T) {
+	m, _ := newOrderTestManager(t)
+	m.config.Download.ScriptTimeoutSeconds = 600
+	m.config.Download.Categories = []config.CategoryConfig{{Name: "tv", PostProcessing: "delete"}}
+	// pp, script and group overrides (lines 3, 5 and 7) are not supported.
+	m.config.Download.PreQueueScript = writeTestScript(t, m, "prequeue.sh", `
+if [ "$1" = "hold-me" ]; then printf '1\n\n\n\n\n-2\n'; exit 0; fi
+printf '1\n\n0\ntv\nother.sh\n1\nalt.binaries.other\n'`)
+
+	if got := m.preQueueScriptTimeout(); got != maxPreQueueScriptTimeout {
+		t.Fatalf("expected pre-queue timeout capped at %s, got %s", maxPreQueueScriptTimeout, got)
+	}
+
+	item := addOrderTestItems(t, m, "a")["a"]
+	if item.Priority != domain.PriorityHigh || item.Status != domain.StatusPending {
+		t.Fatalf("expected script priority high on a pending item, got %s/%s", item.Priority, item.Status)
+	}
+	if item.Release.Category != "tv" || item.Release.Title != "a" {
+		t.Fatalf("expected only category and priority overrides, got %q/%q", item.Release.Title, item.Release.Category)
+	}
+
+	held := addOrderTestItems(t, m, "hold-me")["hold-me"]
+	if held.Status != domain.StatusPaused || held.Priority != domain.PriorityNormal {
+		t.Fatalf("expected -2 to add the item paused at normal priority, got %s/%s", held.Status, held.Priority)
+	}
+}
+
+func TestScriptsOutsideScriptsDirAreNotRun(t *testing.T) {
+	m, _ := newOrderTestManager(t)
+	marker := filepath.Join(t.TempDir(), "ran")
+	writeTestScript(t, m, "inside.sh", "true")
+	outside := filepath.Join(t.TempDir(), "outside.sh")
+	if err := os.WriteFile(outside, []byte("#!/bin/sh\necho 0\ntouch "+marker+"\n"), 0755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+
+	m.config.Download.PreQueueScript = outside
+	item := addOrderTestItems(t, m, "a")["a"]
+	if item == nil {
+		t.Fatal("expected the release to be queued when the pre-queue script is refused")
+	}
+
+	m.config.Download.Categories = []config.CategoryConfig{{Name: "tv", Script: "../" + filepath.Base(filepath.Dir(outside)) + "/outside.sh"}}
+	job := &domain.QueueItem{ID: "job-1", Release: &domain.Release{Title: "Show", Category: "tv"}}
+	if err := m.runPostProcessScript(context.Background(), job, nil); err == nil || !strings.Contains(err.Error(), "outside download.scripts_dir") {
+		t.Fatalf("expected post-processing script outside scripts_dir to fail the job, got %v", err)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatalf("expected no script outside scripts_dir to run, stat err %v", err)
+	}
+}
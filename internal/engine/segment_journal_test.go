@@ -0,0 +1,51 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/datallboy/gonzb/internal/domain"
+)
+
+func TestRestoreSegmentJournalsOnlyTrustsSurvivingPartFiles(t *testing.T) {
+	m, store := newOrderTestManager(t)
+	m.queueFiles = store
+	item := addOrderTestItems(t, m, "a")["a"]
+
+	segments := []domain.Segment{{Number: 1, Bytes: 100}, {Number: 2, Bytes: 100}, {Number: 3, Bytes: 100}}
+	kept := domain.NewDownloadFile("kept.bin", 0, 0, segments, t.TempDir(), "")
+	kept.PartExists = true
+	recreated := domain.NewDownloadFile("recreated.bin", 0, 1, segments, t.TempDir(), "")
+	item.Tasks = []*domain.DownloadFile{kept, recreated}
+
+	// simulate a previous run that wrote segments 0 and 2 of both files.
+	for _, task := range item.Tasks {
+		task.MarkSegmentDone(0)
+		task.MarkSegmentDone(2)
+		task.SetActualSize(250)
+	}
+	journals := []*domain.SegmentJournal{kept.TakeSegmentJournal(), recreated.TakeSegmentJournal()}
+	if err := store.SaveSegmentJournals(context.Background(), item.ID, journals); err != nil {
+		t.Fatalf("save segment journals: %v", err)
+	}
+
+	kept = domain.NewDownloadFile("kept.bin", 0, 0, segments, t.TempDir(), "")
+	kept.PartExists = true
+	recreated = domain.NewDownloadFile("recreated.bin", 0, 1, segments, t.TempDir(), "")
+	item.Tasks = []*domain.DownloadFile{kept, recreated}
+
+	m.restoreSegmentJournals(context.Background(), item)
+
+	if got := kept.PendingSegments(); got != 1 || kept.IsSegmentDone(1) {
+		t.Fatalf("expected only segment 1 pending for kept file, got %d pending", got)
+	}
+	if got := kept.DoneSegmentBytes(); got != 200 {
+		t.Fatalf("expected 200 resumed bytes, got %d", got)
+	}
+	if got := kept.GetActualSize(); got != 250 {
+		t.Fatalf("expected actual size to be restored, got %d", got)
+	}
+	if got := recreated.PendingSegments(); got != 3 {
+		t.Fatalf("expected recreated part to restart from scratch, got %d pending", got)
+	}
+}
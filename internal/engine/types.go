@@ -5,11 +5,12 @@ import (
 )
 
 type DownloadJob struct {
-	Segment    domain.Segment
-	File       *domain.DownloadFile
-	Groups     []string
-	Offset     int64
-	RetryCount int
+	Segment      domain.Segment
+	SegmentIndex int // position in File.Segments, used by the resume journal
+	File         *domain.DownloadFile
+	Groups       []string
+	Offset       int64
+	RetryCount   int
 }
 
 type DownloadResult struct {
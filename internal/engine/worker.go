@@ -10,17 +10,21 @@ import (
 	"time"
 
 	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/infra/platform"
 	"github.com/datallboy/gonzb/internal/nntp"
 	"github.com/datallboy/gonzb/internal/nzb"
+	"github.com/datallboy/gonzb/internal/processor"
 )
 
-// runWorkerPool orchestrates the lifecycle of the download process.
-func (s *Downloader) runWorkerPool(ctx context.Context, item *domain.QueueItem) error {
+// runWorkerPool orchestrates the lifecycle of the download process. With a
+// direct unpack running, each file is finalized and handed to it as soon as its
+// last segment lands.
+func (s *Downloader) runWorkerPool(ctx context.Context, item *domain.QueueItem, unpack *processor.DirectUnpack) error {
 	totalSegments := 0
 	for _, f := range item.Tasks {
-		// Only count segments for files that aren't already finished
-		if !f.IsComplete {
-			totalSegments += len(f.Segments)
+		// Only count segments for files that aren't already finished, journaled or held back
+		if !f.IsComplete && !f.Skipped {
+			totalSegments += f.PendingSegments()
 		}
 	}
 
@@ -33,6 +37,11 @@ func (s *Downloader) runWorkerPool(ctx context.Context, item *domain.QueueItem)
 	workerCtx, cancelWorkers := context.WithCancel(ctx)
 	defer cancelWorkers()
 
+	// Persist segment progress periodically and on exit so an interrupted item resumes mid-file.
+	journalTicker := time.NewTicker(segmentJournalFlushInterval)
+	defer journalTicker.Stop()
+	defer s.flushSegmentJournal(item)
+
 	// Ask the manager for the connection limit
 	capacity := s.ctx.NNTP.TotalCapacity()
 	if capacity <= 0 {
@@ -63,36 +72,23 @@ func (s *Downloader) runWorkerPool(ctx context.Context, item *domain.QueueItem)
 
 	// Collect Results
 	completedCount := 0
-	var finalErr error
-
+	repair := &repairCheck{}
 	for completedCount < totalSegments {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-journalTicker.C:
+			s.flushSegmentJournal(item)
 		case res := <-results:
+			// a full disk fails every write; hand it to the manager to pause the queue.
+			if platform.IsDiskFull(res.Error) {
+				return res.Error
+			}
 			if res.Error != nil {
-				// Identify the error type
-				isBusy := errors.Is(res.Error, nntp.ErrProviderBusy)
-
-				if isBusy {
-					go func(j DownloadJob) {
-						time.Sleep(250 * time.Millisecond) // Short delay to let a slot open
-						select {
-						case <-workerCtx.Done():
-						case jobs <- j:
-						}
-					}(res.Job)
-					continue
-				}
-
-				// If we have retires left, put it back in the pipeline
-				if res.Job.RetryCount < 3 {
-					res.Job.RetryCount++
-
-					delay := time.Duration(math.Pow(2, float64(res.Job.RetryCount))) * time.Second
-
-					s.ctx.Logger.Debug("[Retry] Segment %s: Attempt %d/3 - Error: %v",
-						res.Job.Segment.MessageID, res.Job.RetryCount, res.Error)
+				delay, retry := nextSegmentAttempt(&res.Job, res.Error)
+				if retry {
+					s.ctx.Logger.Debug("[Retry] Segment %s: attempt %d/%d in %s - Error: %v",
+						res.Job.Segment.MessageID, res.Job.RetryCount, maxSegmentRetries, delay, res.Error)
 
 					go func(j DownloadJob, d time.Duration) {
 						time.Sleep(d)
@@ -101,12 +97,20 @@ func (s *Downloader) runWorkerPool(ctx context.Context, item *domain.QueueItem)
 						case jobs <- j:
 						}
 					}(res.Job, delay)
-
 					continue // Do not count as completed yet
 				}
-				// Permanent failure
-				s.ctx.Logger.Error("[FAIL] Segment %s permanently failed: %v", res.Job.Segment.MessageID, res.Error)
-				finalErr = fmt.Errorf("one or more segments failed permanently")
+
+				// Permanent failure: leave the gap for PAR2 and keep going.
+				s.ctx.Logger.Warn("[FAIL] Segment %d of %s permanently missing: %v",
+					res.Job.Segment.Number, res.Job.File.FileName, res.Error)
+				res.Job.File.MarkSegmentMissing(res.Job.SegmentIndex)
+
+				// Stop walking a release PAR2 can no longer save.
+				if err := s.checkRepairable(item, repair); err != nil {
+					return err
+				}
+			} else if unpack != nil {
+				s.finalizeForUnpack(ctx, res.Job.File, unpack)
 			}
 			completedCount++
 		}
@@ -114,7 +118,47 @@ func (s *Downloader) runWorkerPool(ctx context.Context, item *domain.QueueItem)
 
 	cancelWorkers()
 	wg.Wait()
-	return finalErr
+	return nil
+}
+
+// finalizeForUnpack renames a file whose segments are all written and gives
+// it to the direct unpack. Only the collector loop calls it, so a file is
+// finalized once.
+func (s *Downloader) finalizeForUnpack(ctx context.Context, file *domain.DownloadFile, unpack *processor.DirectUnpack) {
+	if file.IsComplete || file.PendingSegments() > 0 {
+		return
+	}
+	if err := s.processor.Finalize(ctx, []*domain.DownloadFile{file}); err != nil || !file.IsComplete {
+		return
+	}
+	unpack.VolumeDone(file)
+}
+
+const maxSegmentRetries = 3
+
+// errSegmentRejected marks an article whose body failed verification. The
+// serving provider has already been added to the segment's MissingFrom set.
+var errSegmentRejected = errors.New("segment rejected")
+
+// nextSegmentAttempt is the retry policy for a failed segment. The manager
+// walks primaries by priority and then backups before reporting a 430, so a
+// not-found is final. Corrupt bodies move on to the next provider straight
+// away, busy providers are retried shortly, and anything else (timeouts,
+// dropped connections) gets a few attempts with exponential backoff.
+func nextSegmentAttempt(job *DownloadJob, err error) (time.Duration, bool) {
+	switch {
+	case errors.Is(err, nntp.ErrProviderBusy):
+		return 250 * time.Millisecond, true // Short delay to let a slot open
+	case errors.Is(err, nntp.ErrArticleNotFound):
+		return 0, false
+	case errors.Is(err, errSegmentRejected):
+		return 0, true
+	case job.RetryCount < maxSegmentRetries:
+		job.RetryCount++
+		return time.Duration(math.Pow(2, float64(job.RetryCount))) * time.Second, true
+	default:
+		return 0, false
+	}
 }
 
 // worker pulls jobs from the channel and executes them until channel is closed
@@ -151,6 +195,13 @@ func (s *Downloader) processSegment(ctx context.Context, item *domain.QueueItem,
 		defer closer.Close()
 	}
 
+	providerID := nntp.ReaderProviderID(rawReader)
+
+	// Throttle the raw article stream so the global speed limit counts wire bytes.
+	if limiter := s.ctx.SpeedLimiter; limiter != nil {
+		rawReader = &throttledReader{ctx: ctx, reader: rawReader, limiter: limiter}
+	}
+
 	// Decode yEnc stream
 	decoder := nzb.NewYencDecoder(rawReader)
 
@@ -180,6 +231,14 @@ func (s *Downloader) processSegment(ctx context.Context, item *domain.QueueItem,
 
 	// Verify CRC32
 	if err := decoder.Verify(); err != nil {
+		// Exclude the provider that served the bad body so the retry goes elsewhere.
+		if providerID != "" {
+			if job.Segment.MissingFrom == nil {
+				job.Segment.MissingFrom = make(map[string]bool)
+			}
+			job.Segment.MissingFrom[providerID] = true
+			return fmt.Errorf("%w by %s: integrity check failed: %v", errSegmentRejected, providerID, err)
+		}
 		return fmt.Errorf("integrity check failed: %w", err)
 	}
 
@@ -194,31 +253,42 @@ func (s *Downloader) processSegment(ctx context.Context, item *domain.QueueItem,
 		item.BytesWritten.Add(int64(n))
 	}
 
+	job.File.MarkSegmentDone(job.SegmentIndex)
 	return nil
 }
 
 // dispatchJobs translates the NZB structure into individual segment jobs.
 func (s *Downloader) dispatchJobs(ctx context.Context, tasks []*domain.DownloadFile, jobs chan<- DownloadJob) {
-	for _, task := range tasks {
+	for _, task := range downloadOrder(tasks) {
 		if task.IsComplete {
 			s.ctx.Logger.Debug("Skipping segment dispatch: %s (already on disk)", task.FileName)
 			continue
 		}
+		if task.Skipped {
+			continue
+		}
 
 		var currentOffset int64 = 0
 
 		groups := task.Groups
 
-		for _, seg := range task.Segments {
+		for i, seg := range task.Segments {
+			// journaled segments are already on disk; keep the offset moving past them.
+			if task.IsSegmentDone(i) {
+				currentOffset += seg.Bytes
+				continue
+			}
+
 			select {
 			case <-ctx.Done():
 				return // stop dispatching if job is cancelled
 			case jobs <- DownloadJob{
-				Segment:    seg,
-				File:       task,
-				Groups:     groups,
-				Offset:     currentOffset,
-				RetryCount: 0,
+				Segment:      seg,
+				SegmentIndex: i,
+				File:         task,
+				Groups:       groups,
+				Offset:       currentOffset,
+				RetryCount:   0,
 			}:
 				currentOffset += seg.Bytes
 
@@ -227,3 +297,35 @@ func (s *Downloader) dispatchJobs(ctx context.Context, tasks []*domain.DownloadF
 		}
 	}
 }
+
+const segmentJournalFlushInterval = 5 * time.Second
+
+// flushSegmentJournal syncs touched .part files, then records which segments they hold.
+// Syncing first keeps the journal from claiming data that never reached the disk.
+func (s *Downloader) flushSegmentJournal(item *domain.QueueItem) {
+	store := s.ctx.QueueFileStore
+	if store == nil {
+		return
+	}
+
+	journals := make([]*domain.SegmentJournal, 0)
+	paths := make([]string, 0)
+	for _, task := range item.Tasks {
+		if journal := task.TakeSegmentJournal(); journal != nil {
+			journals = append(journals, journal)
+			paths = append(paths, task.PartPath)
+		}
+	}
+	if len(journals) == 0 {
+		return
+	}
+
+	s.writer.SyncFiles(paths)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := store.SaveSegmentJournals(ctx, item.ID, journals); err != nil {
+		s.ctx.Logger.Warn("Failed to persist segment journal for %s: %v", item.ID, err)
+	}
+}
@@ -28,6 +28,7 @@ func (w *queueWorkflow) Execute(ctx context.Context, item *domain.QueueItem) {
 	if jobErr == nil {
 		jobErr = w.runPostProcessing(ctx, item)
 	}
+	jobErr = w.manager.runPostProcessScript(ctx, item, jobErr)
 
 	w.manager.finalizeJob(ctx, item, jobErr)
 }
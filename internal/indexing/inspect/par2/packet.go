@@ -1,7 +1,10 @@
 package par2
 
 import (
+	"bytes"
+	"crypto/md5"
 	"encoding/binary"
+	"io"
 	"math"
 	"strings"
 	"unicode"
@@ -9,37 +12,211 @@ import (
 )
 
 const (
-	par2PacketHeaderSize = 64
+	// PacketHeaderSize is the fixed header in front of every PAR2 packet body.
+	PacketHeaderSize = 64
+
 	par2FileDescBodySize = 56
 	maxPAR2TargetSize    = uint64(1 << 50) // 1 PiB, far above useful Usenet payloads.
+	maxMetaPacketSize    = 64 << 20        // metadata packets are small; anything larger is corrupt.
 )
 
 var (
-	par2PacketMagic    = []byte("PAR2\x00PKT")
-	par2FileDescPacket = "PAR 2.0\x00FileDesc"
+	// PacketMagic starts every PAR2 packet, whatever the file is called.
+	PacketMagic = []byte("PAR2\x00PKT")
+
+	TypeMain     = packetType("PAR 2.0\x00Main")
+	TypeFileDesc = packetType("PAR 2.0\x00FileDesc")
+	TypeIFSC     = packetType("PAR 2.0\x00IFSC")
+	TypeRecvSlic = packetType("PAR 2.0\x00RecvSlic")
 )
 
+func packetType(name string) [16]byte {
+	var t [16]byte
+	copy(t[:], name)
+	return t
+}
+
+// PacketHeader is the decoded header of one PAR2 packet.
+type PacketHeader struct {
+	Length uint64 // header plus body
+	Hash   [16]byte
+	SetID  [16]byte
+	Type   [16]byte
+}
+
+// FileDesc is the body of a file description packet.
+type FileDesc struct {
+	ID      [16]byte
+	Hash    [16]byte
+	Hash16k [16]byte // MD5 of the first 16 KiB, used to identify renamed files
+	Length  uint64
+	Name    string
+}
+
+// SliceChecksum is one input slice entry of an IFSC packet.
+type SliceChecksum struct {
+	MD5   [16]byte
+	CRC32 uint32
+}
+
+// HasMagic reports whether data starts with a PAR2 packet header.
+func HasMagic(data []byte) bool {
+	return bytes.HasPrefix(data, PacketMagic)
+}
+
+// ScanPackets walks every packet in r whose MD5 checks out, resyncing on the
+// magic when it meets garbage. Recovery slice bodies are hashed but only their
+// 4-byte exponent is handed to visit; bodyOffset locates the rest.
+func ScanPackets(r io.ReaderAt, size int64, visit func(h PacketHeader, body []byte, bodyOffset int64)) error {
+	var raw [PacketHeaderSize]byte
+	for offset := int64(0); offset+PacketHeaderSize <= size; {
+		if _, err := r.ReadAt(raw[:], offset); err != nil {
+			return err
+		}
+		if !HasMagic(raw[:]) {
+			next, ok := findMagic(r, offset+1, size)
+			if !ok {
+				return nil
+			}
+			offset = next
+			continue
+		}
+
+		h := parseHeader(raw[:])
+		if h.Length < PacketHeaderSize || h.Length%4 != 0 || h.Length > uint64(size-offset) {
+			offset++
+			continue
+		}
+
+		bodyLen := int64(h.Length) - PacketHeaderSize
+		bodyOffset := offset + PacketHeaderSize
+		isRecovery := h.Type == TypeRecvSlic
+		if !isRecovery && bodyLen > maxMetaPacketSize {
+			offset++
+			continue
+		}
+
+		hasher := md5.New()
+		hasher.Write(raw[32:])
+		var body []byte
+		if isRecovery {
+			if bodyLen < 4 {
+				offset++
+				continue
+			}
+			body = make([]byte, 4)
+			if _, err := r.ReadAt(body, bodyOffset); err != nil {
+				return err
+			}
+			if _, err := io.Copy(hasher, io.NewSectionReader(r, bodyOffset, bodyLen)); err != nil {
+				return err
+			}
+		} else {
+			body = make([]byte, bodyLen)
+			if _, err := r.ReadAt(body, bodyOffset); err != nil {
+				return err
+			}
+			hasher.Write(body)
+		}
+
+		if !bytes.Equal(hasher.Sum(nil), h.Hash[:]) {
+			offset++
+			continue
+		}
+
+		visit(h, body, bodyOffset)
+		offset += int64(h.Length)
+	}
+	return nil
+}
+
+func parseHeader(raw []byte) PacketHeader {
+	h := PacketHeader{Length: binary.LittleEndian.Uint64(raw[8:16])}
+	copy(h.Hash[:], raw[16:32])
+	copy(h.SetID[:], raw[32:48])
+	copy(h.Type[:], raw[48:64])
+	return h
+}
+
+func findMagic(r io.ReaderAt, from, size int64) (int64, bool) {
+	buf := make([]byte, 64<<10)
+	for offset := from; offset < size; {
+		n, err := r.ReadAt(buf, offset)
+		if n <= 0 {
+			return 0, false
+		}
+		if idx := bytes.Index(buf[:n], PacketMagic); idx >= 0 {
+			return offset + int64(idx), true
+		}
+		if err != nil {
+			return 0, false
+		}
+		// overlap so a magic split across reads is still found.
+		offset += int64(n - len(PacketMagic) + 1)
+	}
+	return 0, false
+}
+
+// ParseFileDesc decodes a file description packet body.
+func ParseFileDesc(body []byte) (FileDesc, bool) {
+	if len(body) < par2FileDescBodySize {
+		return FileDesc{}, false
+	}
+	var f FileDesc
+	copy(f.ID[:], body[0:16])
+	copy(f.Hash[:], body[16:32])
+	copy(f.Hash16k[:], body[32:48])
+	f.Length = binary.LittleEndian.Uint64(body[48:56])
+
+	name := body[56:]
+	if idx := bytes.IndexByte(name, 0); idx >= 0 {
+		name = name[:idx]
+	}
+	f.Name = string(name)
+	if f.Name == "" {
+		return FileDesc{}, false
+	}
+	return f, true
+}
+
+// ParseIFSC decodes an input file slice checksum packet body.
+func ParseIFSC(body []byte) ([16]byte, []SliceChecksum, bool) {
+	var id [16]byte
+	if len(body) < 16 || (len(body)-16)%20 != 0 {
+		return id, nil, false
+	}
+	copy(id[:], body[:16])
+	entries := body[16:]
+	sums := make([]SliceChecksum, len(entries)/20)
+	for i := range sums {
+		entry := entries[i*20:]
+		copy(sums[i].MD5[:], entry[:16])
+		sums[i].CRC32 = binary.LittleEndian.Uint32(entry[16:20])
+	}
+	return id, sums, true
+}
+
 type targetFile struct {
 	Name string
 	Size uint64
 }
 
+// parseTargetFiles lists the files a PAR2 prefix sample describes. Samples
+// may be cut mid-packet, so packet hashes are not checked here.
 func parseTargetFiles(data []byte) []targetFile {
 	out := make([]targetFile, 0)
 	seen := map[string]struct{}{}
-	for offset := 0; offset+par2PacketHeaderSize <= len(data); {
-		if string(data[offset:offset+8]) != string(par2PacketMagic) {
+	for offset := 0; offset+PacketHeaderSize <= len(data); {
+		if !HasMagic(data[offset:]) {
 			offset++
 			continue
 		}
-		packetLen := binary.LittleEndian.Uint64(data[offset+8 : offset+16])
-		if packetLen < par2PacketHeaderSize || packetLen > uint64(len(data)-offset) {
+		h := parseHeader(data[offset:])
+		if h.Length < PacketHeaderSize || h.Length > uint64(len(data)-offset) {
 			break
 		}
-		packet := data[offset : offset+int(packetLen)]
-		packetType := strings.TrimRight(string(packet[48:64]), "\x00")
-		if packetType == par2FileDescPacket {
-			if target, ok := parseFileDescPacket(packet); ok {
+		if h.Type == TypeFileDesc {
+			if target, ok := parseFileDescPacket(data[offset+PacketHeaderSize : offset+int(h.Length)]); ok {
 				key := strings.ToLower(target.Name)
 				if _, exists := seen[key]; !exists {
 					seen[key] = struct{}{}
@@ -47,29 +224,24 @@ func parseTargetFiles(data []byte) []targetFile {
 				}
 			}
 		}
-		offset += int(packetLen)
+		offset += int(h.Length)
 	}
 	return out
 }
 
-func parseFileDescPacket(packet []byte) (targetFile, bool) {
-	if len(packet) < par2PacketHeaderSize+par2FileDescBodySize {
+func parseFileDescPacket(body []byte) (targetFile, bool) {
+	desc, ok := ParseFileDesc(body)
+	if !ok {
 		return targetFile{}, false
 	}
-	body := packet[par2PacketHeaderSize:]
-	size := binary.LittleEndian.Uint64(body[48:56])
-	if size == 0 || size > uint64(math.MaxInt64) || size > maxPAR2TargetSize {
+	if desc.Length == 0 || desc.Length > uint64(math.MaxInt64) || desc.Length > maxPAR2TargetSize {
 		return targetFile{}, false
 	}
-	nameBytes := body[56:]
-	if idx := indexByte(nameBytes, 0); idx >= 0 {
-		nameBytes = nameBytes[:idx]
-	}
-	name := strings.TrimSpace(string(nameBytes))
+	name := strings.TrimSpace(desc.Name)
 	if !validTargetName(name) {
 		return targetFile{}, false
 	}
-	return targetFile{Name: name, Size: size}, true
+	return targetFile{Name: name, Size: desc.Length}, true
 }
 
 func validTargetName(name string) bool {
@@ -89,12 +261,3 @@ func validTargetName(name string) bool {
 	}
 	return hasGraphic
 }
-
-func indexByte(in []byte, target byte) int {
-	for i, b := range in {
-		if b == target {
-			return i
-		}
-	}
-	return -1
-}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Post-processing levels, in increasing order of work done after download.
+const (
+	PostProcessNone   = "none"   // move files as downloaded
+	PostProcessRepair = "repair" // PAR2 verify/repair
+	PostProcessUnpack = "unpack" // repair, then extract archives
+	PostProcessDelete = "delete" // repair, extract, then drop the archives
+)
+
+// Duplicate handling policies for releases already queued or downloaded.
+const (
+	DuplicateAllow   = "allow"   // queue it, flagged as a duplicate
+	DuplicatePause   = "pause"   // queue it paused for review
+	DuplicateReject  = "reject"  // refuse to queue it
+	DuplicateReplace = "replace" // queue it only if larger, dropping smaller queued copies
+)
+
+// DefaultCategory is the SABnzbd-style catch-all category name.
+const DefaultCategory = "*"
+
+var postProcessLevels = map[string]int{
+	PostProcessNone:   0,
+	PostProcessRepair: 1,
+	PostProcessUnpack: 2,
+	PostProcessDelete: 3,
+}
+
+var duplicatePolicies = map[string]bool{
+	DuplicateAllow:   true,
+	DuplicatePause:   true,
+	DuplicateReject:  true,
+	DuplicateReplace: true,
+}
+
+var categoryPriorities = map[string]bool{
+	"low":    true,
+	"normal": true,
+	"high":   true,
+	"force":  true,
+}
+
+// PostProcessLevel maps PostProcessing onto SABnzbd's 0-3 pp scale. Empty or
+// unknown values mean full post-processing.
+func (c CategoryConfig) PostProcessLevel() int {
+	if level, ok := postProcessLevels[strings.ToLower(strings.TrimSpace(c.PostProcessing))]; ok {
+		return level
+	}
+	return postProcessLevels[PostProcessDelete]
+}
+
+// ResolveCategory returns the settings for name, matched case-insensitively.
+// The bool reports whether name is a configured category; otherwise the "*"
+// category (if configured) and the global download settings fill in, and Name
+// is the trimmed input so completed paths keep the caller's label.
+func (d DownloadConfig) ResolveCategory(name string) (CategoryConfig, bool) {
+	name = strings.TrimSpace(name)
+
+	resolved := CategoryConfig{Name: name}
+	found := false
+	if cat, ok := d.findCategory(name); ok && name != "" {
+		resolved = cat
+		found = true
+	} else if cat, ok := d.findCategory(DefaultCategory); ok {
+		resolved = cat
+		resolved.Name = name
+	}
+
+	if strings.TrimSpace(resolved.PostProcessing) == "" {
+		resolved.PostProcessing = PostProcessDelete
+	}
+	if strings.TrimSpace(resolved.Priority) == "" {
+		resolved.Priority = "normal"
+	}
+	if strings.TrimSpace(resolved.DuplicatePolicy) == "" {
+		resolved.DuplicatePolicy = DuplicateAllow
+	}
+	if resolved.CleanupExtensions == nil {
+		resolved.CleanupExtensions = d.CleanupExtensions
+	}
+	return resolved, found
+}
+
+func (d DownloadConfig) findCategory(name string) (CategoryConfig, bool) {
+	for _, cat := range d.Categories {
+		if strings.EqualFold(strings.TrimSpace(cat.Name), name) {
+			cat.Name = strings.TrimSpace(cat.Name)
+			return cat, true
+		}
+	}
+	return CategoryConfig{}, false
+}
+
+// Validate checks a single category definition.
+func (c CategoryConfig) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if pp := strings.ToLower(strings.TrimSpace(c.PostProcessing)); pp != "" {
+		if _, ok := postProcessLevels[pp]; !ok {
+			return fmt.Errorf("post_processing %q must be one of none, repair, unpack, delete", c.PostProcessing)
+		}
+	}
+	if prio := strings.ToLower(strings.TrimSpace(c.Priority)); prio != "" && !categoryPriorities[prio] {
+		return fmt.Errorf("priority %q must be one of low, normal, high, force", c.Priority)
+	}
+	if policy := strings.ToLower(strings.TrimSpace(c.DuplicatePolicy)); policy != "" && !duplicatePolicies[policy] {
+		return fmt.Errorf("duplicate_policy %q must be one of allow, pause, reject, replace", c.DuplicatePolicy)
+	}
+	return nil
+}
+
+func validateCategories(categories []CategoryConfig) error {
+	seen := make(map[string]bool, len(categories))
+	for i, cat := range categories {
+		if err := cat.Validate(); err != nil {
+			return fmt.Errorf("download.categories[%d]: %w", i, err)
+		}
+		key := strings.ToLower(strings.TrimSpace(cat.Name))
+		if seen[key] {
+			return fmt.Errorf("download.categories[%d]: duplicate name %q", i, cat.Name)
+		}
+		seen[key] = true
+	}
+	return nil
+}
@@ -49,12 +49,78 @@ type IndexerConfig struct {
 	ApiPath  string `mapstructure:"api_path" yaml:"api_path"`
 	ApiKey   string `mapstructure:"api_key" yaml:"api_key"`
 	Redirect bool   `mapstructure:"redirect" yaml:"redirect"`
+	// Search timeout in seconds; 0 uses the aggregator default.
+	TimeoutSeconds int `mapstructure:"timeout_seconds" yaml:"timeout_seconds"`
 }
 
 type DownloadConfig struct {
 	OutDir            string   `mapstructure:"out_dir" yaml:"out_dir"`
 	CompletedDir      string   `mapstructure:"completed_dir" yaml:"completed_dir"`
 	CleanupExtensions []string `mapstructure:"cleanup_extensions" yaml:"cleanup_extensions"`
+
+	// number of queue items allowed to hydrate/download at once; post-processing does not hold a slot.
+	MaxActiveDownloads int `mapstructure:"max_active_downloads" yaml:"max_active_downloads"`
+
+	// global download bandwidth cap in KB/s; 0 means unlimited.
+	SpeedLimitKBps int `mapstructure:"speed_limit_kbps" yaml:"speed_limit_kbps"`
+	// time-of-day windows that override SpeedLimitKBps; first match wins.
+	SpeedSchedules []SpeedScheduleConfig `mapstructure:"speed_schedules" yaml:"speed_schedules"`
+
+	// STAT a sample of each item's articles on every provider before downloading.
+	AvailabilityCheck bool `mapstructure:"availability_check" yaml:"availability_check"`
+	// message IDs checked per item; 0 checks every segment.
+	AvailabilitySample int `mapstructure:"availability_sample" yaml:"availability_sample"`
+
+	// user-defined categories; unknown categories fall back to the global settings.
+	Categories []CategoryConfig `mapstructure:"categories" yaml:"categories"`
+
+	// extract RAR sets while the rest of the release downloads.
+	DirectUnpack bool `mapstructure:"direct_unpack" yaml:"direct_unpack"`
+
+	// directory scripts are run from; script settings name files inside it.
+	// Only read from config.yaml so runtime settings cannot point scripts elsewhere.
+	ScriptsDir string `mapstructure:"scripts_dir" yaml:"scripts_dir"`
+	// SABnzbd-style script run before an NZB is queued; it may reject or rename it.
+	PreQueueScript string `mapstructure:"pre_queue_script" yaml:"pre_queue_script"`
+	// wall-clock limit for post-processing scripts; 0 uses the 10 minute default.
+	// Pre-queue scripts hold up the add request and are capped at 30 seconds.
+	ScriptTimeoutSeconds int `mapstructure:"script_timeout_seconds" yaml:"script_timeout_seconds"`
+
+	// passwords tried in order against encrypted archives after the NZB's own
+	// password and the category's list.
+	ArchivePasswords []string `mapstructure:"archive_passwords" yaml:"archive_passwords"`
+
+	// free space in MB kept on the out_dir and completed_dir filesystems; the
+	// queue pauses below either threshold. 0 disables the guard for that path.
+	MinFreeIncompleteMB int64 `mapstructure:"min_free_incomplete_mb" yaml:"min_free_incomplete_mb"`
+	MinFreeCompleteMB   int64 `mapstructure:"min_free_complete_mb" yaml:"min_free_complete_mb"`
+	// extra out_dir space, as a percentage of the release size, reserved for
+	// unpacking when deciding whether a new item fits.
+	UnpackHeadroomPercent int `mapstructure:"unpack_headroom_percent" yaml:"unpack_headroom_percent"`
+
+	// folders polled for .nzb, .nzb.gz and zipped NZBs; a subfolder's name
+	// selects the category. Imported files move to processed/ or failed/.
+	WatchDirs []string `mapstructure:"watch_dirs" yaml:"watch_dirs"`
+	// seconds between watch folder scans; 0 uses the 5 second default.
+	WatchIntervalSeconds int `mapstructure:"watch_interval_seconds" yaml:"watch_interval_seconds"`
+}
+
+type CategoryConfig struct {
+	Name              string   `mapstructure:"name" yaml:"name"`
+	CompletedDir      string   `mapstructure:"completed_dir" yaml:"completed_dir"`           // empty means <completed_dir>/<name>
+	PostProcessing    string   `mapstructure:"post_processing" yaml:"post_processing"`       // none, repair, unpack, delete; empty means delete
+	Priority          string   `mapstructure:"priority" yaml:"priority"`                     // low, normal, high, force; empty means normal
+	CleanupExtensions []string `mapstructure:"cleanup_extensions" yaml:"cleanup_extensions"` // nil inherits download.cleanup_extensions
+	Script            string   `mapstructure:"script" yaml:"script"`                         // post-processing script in download.scripts_dir; empty runs none
+	Passwords         []string `mapstructure:"passwords" yaml:"passwords"`                   // tried before download.archive_passwords
+	DuplicatePolicy   string   `mapstructure:"duplicate_policy" yaml:"duplicate_policy"`     // allow, pause, reject, replace; empty means allow
+}
+
+type SpeedScheduleConfig struct {
+	Days      []string `mapstructure:"days" yaml:"days"`             // mon..sun; empty means every day
+	Start     string   `mapstructure:"start" yaml:"start"`           // HH:MM, server local time
+	End       string   `mapstructure:"end" yaml:"end"`               // HH:MM; may wrap past midnight
+	LimitKBps int      `mapstructure:"limit_kbps" yaml:"limit_kbps"` // 0 means unlimited
 }
 
 type LogConfig struct {
@@ -288,6 +354,14 @@ func Load(path string) (*Config, error) {
 	v.SetDefault("download.out_dir", "./downloads")
 	v.SetDefault("download.completed_dir", "./downloads/completed")
 	v.SetDefault("download.cleanup_extensions", []string{"nzb", "par2", "sfv", "nfo"}) // sane default for completed cleanup
+	v.SetDefault("download.max_active_downloads", 1)
+	v.SetDefault("download.speed_limit_kbps", 0)
+	v.SetDefault("download.availability_sample", 100)
+	v.SetDefault("download.categories", []map[string]any{{"name": "movies"}, {"name": "tv"}})
+	v.SetDefault("download.scripts_dir", "")
+	v.SetDefault("download.script_timeout_seconds", 600)
+	v.SetDefault("download.unpack_headroom_percent", 100)
+	v.SetDefault("download.watch_interval_seconds", 5)
 	v.SetDefault("log.level", "info")
 	v.SetDefault("log.include_stdout", true)
 	v.SetDefault("log.max_size_mb", 32)
@@ -493,6 +567,35 @@ func (c *Config) validate() error {
 	if c.Download.OutDir == "" {
 		c.Download.OutDir = "./downloads"
 	}
+	if c.Download.SpeedLimitKBps < 0 {
+		return errors.New("download.speed_limit_kbps must be greater than or equal to 0")
+	}
+	if c.Download.AvailabilitySample < 0 {
+		return errors.New("download.availability_sample must be greater than or equal to 0")
+	}
+	for i, schedule := range c.Download.SpeedSchedules {
+		if _, err := schedule.Window(); err != nil {
+			return fmt.Errorf("download.speed_schedules[%d]: %w", i, err)
+		}
+	}
+	if c.Download.ScriptTimeoutSeconds < 0 {
+		return errors.New("download.script_timeout_seconds must be greater than or equal to 0")
+	}
+	if c.Download.MinFreeIncompleteMB < 0 || c.Download.MinFreeCompleteMB < 0 {
+		return errors.New("download.min_free_incomplete_mb and download.min_free_complete_mb must be greater than or equal to 0")
+	}
+	if c.Download.UnpackHeadroomPercent < 0 {
+		return errors.New("download.unpack_headroom_percent must be greater than or equal to 0")
+	}
+	if c.Download.WatchIntervalSeconds < 0 {
+		return errors.New("download.watch_interval_seconds must be greater than or equal to 0")
+	}
+	if err := validateCategories(c.Download.Categories); err != nil {
+		return err
+	}
+	if err := validateScripts(c.Download); err != nil {
+		return err
+	}
 	if err := validateIndexingStageConfig("indexing.scrape_latest", c.Indexing.ScrapeLatest); err != nil {
 		return err
 	}
@@ -1,6 +1,13 @@
 package config
 
-import "testing"
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestAggregatorBootstrapDoesNotRequireSource(t *testing.T) {
 	cfg := minimalAggregatorConfig()
@@ -30,6 +37,119 @@ func TestReleaseExpectedFileCoverageValidation(t *testing.T) {
 	}
 }
 
+func TestSpeedScheduleWindowWrapsPastMidnight(t *testing.T) {
+	window, err := SpeedScheduleConfig{Days: []string{"fri"}, Start: "22:00", End: "06:00"}.Window()
+	if err != nil {
+		t.Fatalf("parse window: %v", err)
+	}
+
+	friday := time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC)
+	if !window.Contains(friday) {
+		t.Fatal("expected friday 23:00 to be inside the window")
+	}
+	if !window.Contains(friday.Add(6 * time.Hour)) {
+		t.Fatal("expected saturday 05:00 to belong to friday's window")
+	}
+	if window.Contains(friday.Add(8 * time.Hour)) {
+		t.Fatal("expected saturday 07:00 to be outside the window")
+	}
+	if window.Contains(friday.Add(-24 * time.Hour)) {
+		t.Fatal("expected thursday 23:00 to be outside the window")
+	}
+}
+
+func TestSpeedScheduleValidation(t *testing.T) {
+	cfg := minimalAggregatorConfig()
+	cfg.Download.SpeedSchedules = []SpeedScheduleConfig{{Start: "9am", End: "17:00"}}
+
+	if err := cfg.ValidateEffective(); err == nil {
+		t.Fatal("expected speed schedule validation error")
+	}
+}
+
+func TestResolveCategoryFallsBackToDefaults(t *testing.T) {
+	download := DownloadConfig{
+		CleanupExtensions: []string{"nfo"},
+		Categories: []CategoryConfig{
+			{Name: "Movies", PostProcessing: PostProcessRepair, Priority: "high"},
+			{Name: DefaultCategory, CompletedDir: "/data/misc", CleanupExtensions: []string{}},
+		},
+	}
+
+	movies, ok := download.ResolveCategory(" movies ")
+	if !ok || movies.Name != "Movies" || movies.PostProcessLevel() != 1 || movies.Priority != "high" {
+		t.Fatalf("unexpected movies category: %+v (found=%v)", movies, ok)
+	}
+	if len(movies.CleanupExtensions) != 1 || movies.CleanupExtensions[0] != "nfo" {
+		t.Fatalf("expected movies to inherit the global cleanup list, got %v", movies.CleanupExtensions)
+	}
+
+	other, ok := download.ResolveCategory("Movies > HD")
+	if ok || other.Name != "Movies > HD" || other.CompletedDir != "/data/misc" || other.PostProcessLevel() != 3 {
+		t.Fatalf("unexpected fallback category: %+v (found=%v)", other, ok)
+	}
+	if other.CleanupExtensions == nil || len(other.CleanupExtensions) != 0 {
+		t.Fatalf("expected the \"*\" category's empty cleanup list to win, got %v", other.CleanupExtensions)
+	}
+}
+
+func TestCategoryValidation(t *testing.T) {
+	cfg := minimalAggregatorConfig()
+	cfg.Download.Categories = []CategoryConfig{{Name: "tv"}, {Name: "TV"}}
+	if err := cfg.ValidateEffective(); err == nil {
+		t.Fatal("expected duplicate category validation error")
+	}
+
+	cfg.Download.Categories = []CategoryConfig{{Name: "tv", PostProcessing: "unrar"}}
+	if err := cfg.ValidateEffective(); err == nil {
+		t.Fatal("expected post_processing validation error")
+	}
+
+	cfg.Download.Categories = []CategoryConfig{{Name: "tv", DuplicatePolicy: "skip"}}
+	if err := cfg.ValidateEffective(); err == nil {
+		t.Fatal("expected duplicate_policy validation error")
+	}
+}
+
+func TestResolveScriptStaysInsideScriptsDir(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Symlink(filepath.Join(outside, "evil.sh"), filepath.Join(dir, "link.sh")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "evil.sh"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+
+	download := DownloadConfig{ScriptsDir: dir}
+	if path, err := download.ResolveScript(" notify.sh "); err != nil || path != filepath.Join(dir, "notify.sh") {
+		t.Fatalf("expected script name to resolve inside scripts_dir, got %q (%v)", path, err)
+	}
+	if _, err := download.ResolveScript(filepath.Join(dir, "sub", "notify.sh")); err != nil {
+		t.Fatalf("expected absolute path inside scripts_dir to be accepted, got %v", err)
+	}
+	for _, name := range []string{"../notify.sh", filepath.Join(outside, "evil.sh"), "link.sh", "."} {
+		if _, err := download.ResolveScript(name); err == nil {
+			t.Fatalf("expected %q to be rejected", name)
+		}
+	}
+	if _, err := (DownloadConfig{}).ResolveScript("notify.sh"); !errors.Is(err, ErrScriptsDisabled) {
+		t.Fatalf("expected scripts to be disabled without scripts_dir, got %v", err)
+	}
+
+	cfg := minimalAggregatorConfig()
+	cfg.Download.PreQueueScript = "/usr/bin/env"
+	if err := cfg.ValidateEffective(); err == nil {
+		t.Fatal("expected pre_queue_script without scripts_dir to fail validation")
+	}
+	cfg.Download.ScriptsDir = dir
+	cfg.Download.PreQueueScript = "notify.sh"
+	cfg.Download.Categories = []CategoryConfig{{Name: "tv", Script: "../tv.sh"}}
+	if err := cfg.ValidateEffective(); err == nil || !strings.Contains(err.Error(), "download.categories[0].script") {
+		t.Fatalf("expected category script outside scripts_dir to fail validation, got %v", err)
+	}
+}
+
 func minimalAggregatorConfig() *Config {
 	return &Config{
 		Modules: ModulesConfig{
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ErrScriptsDisabled is returned when a script is configured without download.scripts_dir.
+var ErrScriptsDisabled = errors.New("download.scripts_dir is not set in config.yaml")
+
+// ResolveScript maps a configured script onto a path inside ScriptsDir. Names
+// are relative to the directory; absolute paths and symlinks must stay inside it.
+func (d DownloadConfig) ResolveScript(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	dir := strings.TrimSpace(d.ScriptsDir)
+	if dir == "" {
+		return "", ErrScriptsDisabled
+	}
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("download.scripts_dir: %w", err)
+	}
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	path = filepath.Clean(path)
+	if !insideDir(dir, path) {
+		return "", fmt.Errorf("script %q is outside download.scripts_dir", name)
+	}
+
+	// the script may not exist yet; only follow links we can resolve.
+	if realDir, err := filepath.EvalSymlinks(dir); err == nil {
+		if realPath, err := filepath.EvalSymlinks(path); err == nil && !insideDir(realDir, realPath) {
+			return "", fmt.Errorf("script %q links outside download.scripts_dir", name)
+		}
+	}
+	return path, nil
+}
+
+func insideDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == "." {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// validateScripts checks the pre-queue and category scripts against ScriptsDir.
+func validateScripts(d DownloadConfig) error {
+	if name := strings.TrimSpace(d.PreQueueScript); name != "" {
+		if _, err := d.ResolveScript(name); err != nil {
+			return fmt.Errorf("download.pre_queue_script: %w", err)
+		}
+	}
+	for i, cat := range d.Categories {
+		if name := strings.TrimSpace(cat.Script); name != "" {
+			if _, err := d.ResolveScript(name); err != nil {
+				return fmt.Errorf("download.categories[%d].script: %w", i, err)
+			}
+		}
+	}
+	return nil
+}
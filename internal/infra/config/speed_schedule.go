@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var scheduleWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// SpeedWindow is a parsed SpeedScheduleConfig.
+type SpeedWindow struct {
+	Days        [7]bool // indexed by time.Weekday
+	StartMinute int
+	EndMinute   int
+	LimitKBps   int
+}
+
+// Window parses the schedule's days and HH:MM bounds.
+func (s SpeedScheduleConfig) Window() (SpeedWindow, error) {
+	w := SpeedWindow{LimitKBps: s.LimitKBps}
+	if s.LimitKBps < 0 {
+		return w, fmt.Errorf("limit_kbps must be >= 0")
+	}
+
+	var err error
+	if w.StartMinute, err = parseClockMinute(s.Start); err != nil {
+		return w, fmt.Errorf("start: %w", err)
+	}
+	if w.EndMinute, err = parseClockMinute(s.End); err != nil {
+		return w, fmt.Errorf("end: %w", err)
+	}
+
+	if len(s.Days) == 0 {
+		for i := range w.Days {
+			w.Days[i] = true
+		}
+		return w, nil
+	}
+	for _, raw := range s.Days {
+		key := strings.ToLower(strings.TrimSpace(raw))
+		if len(key) > 3 {
+			key = key[:3]
+		}
+		day, ok := scheduleWeekdays[key]
+		if !ok {
+			return w, fmt.Errorf("unknown day %q", raw)
+		}
+		w.Days[day] = true
+	}
+	return w, nil
+}
+
+// Contains reports whether t falls inside the window. End is exclusive; a window whose
+// end is before its start runs past midnight, and equal bounds cover the whole day.
+func (w SpeedWindow) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	today := t.Weekday()
+
+	switch {
+	case w.StartMinute == w.EndMinute:
+		return w.Days[today]
+	case w.StartMinute < w.EndMinute:
+		return w.Days[today] && minute >= w.StartMinute && minute < w.EndMinute
+	default:
+		if minute >= w.StartMinute {
+			return w.Days[today]
+		}
+		yesterday := (today + 6) % 7
+		return minute < w.EndMinute && w.Days[yesterday]
+	}
+}
+
+func parseClockMinute(value string) (int, error) {
+	parsed, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("must be in HH:MM format")
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
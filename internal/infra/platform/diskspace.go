@@ -0,0 +1,37 @@
+package platform
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DiskUsage is the free and total space of the filesystem holding a path.
+type DiskUsage struct {
+	Path       string
+	FreeBytes  int64
+	TotalBytes int64
+	// Visible is false when the platform cannot report the filesystem.
+	Visible bool
+}
+
+// StatDisk reports the filesystem holding path. A directory that has not been
+// created yet is measured at its nearest existing parent.
+func StatDisk(path string) (DiskUsage, error) {
+	dir := filepath.Clean(path)
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	usage := DiskUsage{Path: filepath.Clean(path)}
+	if err := statDisk(dir, &usage); err != nil {
+		return usage, err
+	}
+	return usage, nil
+}
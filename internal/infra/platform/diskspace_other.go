@@ -0,0 +1,17 @@
+//go:build !unix
+
+package platform
+
+import (
+	"errors"
+	"syscall"
+)
+
+func statDisk(dir string, usage *DiskUsage) error {
+	return nil
+}
+
+// IsDiskFull reports whether err comes from a write that ran out of space.
+func IsDiskFull(err error) bool {
+	return errors.Is(err, syscall.ENOSPC)
+}
@@ -0,0 +1,30 @@
+//go:build unix
+
+package platform
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+func statDisk(dir string, usage *DiskUsage) error {
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(dir, &fs); err != nil {
+		if os.IsNotExist(err) || err == syscall.EPERM || err == syscall.EACCES {
+			return nil
+		}
+		return fmt.Errorf("stat filesystem %s: %w", dir, err)
+	}
+	blockSize := uint64(fs.Bsize)
+	usage.FreeBytes = int64(fs.Bavail * blockSize)
+	usage.TotalBytes = int64(fs.Blocks * blockSize)
+	usage.Visible = true
+	return nil
+}
+
+// IsDiskFull reports whether err comes from a write that ran out of space.
+func IsDiskFull(err error) bool {
+	return errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EDQUOT)
+}
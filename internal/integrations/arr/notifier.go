@@ -51,7 +51,7 @@ func (n *Notifier) NotifyQueueTerminal(ctx context.Context, item *domain.QueueIt
 	if n == nil || item == nil {
 		return nil
 	}
-	if item.Status != domain.StatusCompleted && item.Status != domain.StatusFailed {
+	if !item.Status.IsTerminal() {
 		return nil
 	}
 
@@ -0,0 +1,181 @@
+// Package scripts runs user-supplied pre-queue and post-processing scripts
+// with SABnzbd-compatible arguments and environment.
+package scripts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxOutputBytes caps the captured script output kept for queue events.
+const maxOutputBytes = 16 * 1024
+
+// Result is the outcome of one script run.
+type Result struct {
+	Script     string   `json:"script"`
+	Args       []string `json:"args"`
+	ExitCode   int      `json:"exit_code"`
+	Output     string   `json:"output"`
+	Truncated  bool     `json:"truncated,omitempty"`
+	DurationMS int64    `json:"duration_ms"`
+}
+
+// Run executes path with args and extra environment, capturing combined
+// stdout/stderr. A non-zero exit is reported in Result, not as an error;
+// the error is for scripts that could not be started or timed out.
+func Run(ctx context.Context, path string, args, env []string, timeout time.Duration) (*Result, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	started := time.Now()
+	err := cmd.Run()
+	res := &Result{
+		Script:     path,
+		Args:       args,
+		DurationMS: time.Since(started).Milliseconds(),
+	}
+	res.Output, res.Truncated = capOutput(out.String())
+
+	if ctx.Err() != nil {
+		res.ExitCode = -1
+		return res, fmt.Errorf("script %s: %w", path, ctx.Err())
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		res.ExitCode = exitErr.ExitCode()
+		return res, nil
+	}
+	if err != nil {
+		res.ExitCode = -1
+		return res, fmt.Errorf("script %s: %w", path, err)
+	}
+	return res, nil
+}
+
+func capOutput(s string) (string, bool) {
+	s = strings.TrimRight(s, "\r\n")
+	if len(s) <= maxOutputBytes {
+		return s, false
+	}
+	return s[len(s)-maxOutputBytes:], true
+}
+
+// PostProcessJob describes a finished queue item for a post-processing script.
+type PostProcessJob struct {
+	ID       string
+	FinalDir string
+	NZBName  string
+	JobName  string
+	Category string
+	Group    string
+	Bytes    int64
+	Failed   bool
+	FailMsg  string
+}
+
+// PostProcessArgs builds SABnzbd's eight positional arguments and SAB_*
+// variables: final dir, NZB name, job name, report number, category, group,
+// pp status (0 ok, -1 failed) and URL.
+func PostProcessArgs(job PostProcessJob) (args, env []string) {
+	status := "0"
+	sabStatus := "Completed"
+	if job.Failed {
+		status = "-1"
+		sabStatus = "Failed"
+	}
+
+	args = []string{job.FinalDir, job.NZBName, job.JobName, "", job.Category, job.Group, status, ""}
+	env = []string{
+		"SAB_COMPLETE_DIR=" + job.FinalDir,
+		"SAB_FILENAME=" + job.NZBName,
+		"SAB_FINAL_NAME=" + job.JobName,
+		"SAB_CAT=" + job.Category,
+		"SAB_GROUP=" + job.Group,
+		"SAB_PP_STATUS=" + status,
+		"SAB_STATUS=" + sabStatus,
+		"SAB_FAIL_MSG=" + job.FailMsg,
+		"SAB_NZO_ID=" + job.ID,
+		"SAB_BYTES=" + strconv.FormatInt(job.Bytes, 10),
+	}
+	return args, env
+}
+
+// PreQueueJob describes an incoming NZB for a pre-queue script.
+type PreQueueJob struct {
+	NZBName  string
+	PP       int
+	Category string
+	Priority int
+	Bytes    int64
+	Groups   []string
+}
+
+// PreQueueArgs builds SABnzbd's pre-queue positional arguments and SAB_*
+// variables: NZB name, pp, category, script, priority, size and groups.
+func PreQueueArgs(job PreQueueJob) (args, env []string) {
+	pp := strconv.Itoa(job.PP)
+	priority := strconv.Itoa(job.Priority)
+	size := strconv.FormatInt(job.Bytes, 10)
+	groups := strings.Join(job.Groups, " ")
+
+	args = []string{job.NZBName, pp, job.Category, "", priority, size, groups}
+	env = []string{
+		"SAB_FILENAME=" + job.NZBName,
+		"SAB_PP=" + pp,
+		"SAB_CAT=" + job.Category,
+		"SAB_PRIORITY=" + priority,
+		"SAB_BYTES=" + size,
+		"SAB_GROUP=" + groups,
+	}
+	return args, env
+}
+
+// PreQueueDecision is the parsed output of a pre-queue script.
+type PreQueueDecision struct {
+	Accept   bool   `json:"accept"`
+	Name     string `json:"name,omitempty"`
+	Category string `json:"category,omitempty"`
+	Priority string `json:"priority,omitempty"`
+}
+
+// ParsePreQueueOutput reads SABnzbd's pre-queue protocol: line 1 is 1 to
+// accept or 0 to reject, line 2 an optional new name, line 4 an optional new
+// category and line 6 an optional new priority. Empty output accepts the job
+// unchanged.
+//
+// The pp (line 3), script (line 5) and group (line 7) overrides are not
+// supported: post-processing and scripts come from the category, and
+// articles are fetched from every configured server regardless of group.
+// Those lines are ignored.
+func ParsePreQueueOutput(output string) PreQueueDecision {
+	lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	line := func(i int) string {
+		if i < len(lines) {
+			return strings.TrimSpace(lines[i])
+		}
+		return ""
+	}
+
+	return PreQueueDecision{
+		Accept:   line(0) != "0",
+		Name:     line(1),
+		Category: line(3),
+		Priority: line(5),
+	}
+}
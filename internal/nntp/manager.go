@@ -229,9 +229,12 @@ func normalizeProviderRoles(roles []string) map[string]bool {
 			out["inspection"] = true
 		case "download", "downloader":
 			out["download"] = true
+		case "backup":
+			out["backup"] = true
 		}
 	}
-	if len(out) == 0 {
+	// backup only changes fetch order, so on its own it keeps every capability.
+	if len(out) == 0 || (len(out) == 1 && out["backup"]) {
 		out["scrape"] = true
 		out["yenc_recovery"] = true
 		out["inspection"] = true
@@ -421,34 +424,40 @@ func (m *Manager) fetch(ctx context.Context, scope string, policy CapacityPolicy
 
 	var lastErr error
 
-	for _, mp := range m.providers {
-		// Skip if this provider already reported 430 for this article
-		if seg.MissingFrom[mp.ID()] {
-			continue
-		}
-
-		// If we already have some 430s for this segment, log that we are trying a failover
-		if len(seg.MissingFrom) > 0 {
-			m.debugProviderFetch(mp, "[Failover] Segment %s missing on %d providers, trying %s (Priority %d)",
-				seg.MessageID, len(seg.MissingFrom), mp.Label(), mp.Priority())
-		}
+	// fetchCandidates already drops providers that reported 430 for this article
+	candidates := m.fetchCandidates(scope, seg)
+	for len(candidates) > 0 {
+		for _, mp := range candidates {
+			// If we already have some 430s for this segment, log that we are trying a failover
+			if len(seg.MissingFrom) > 0 {
+				m.debugProviderFetch(mp, "[Failover] Segment %s missing on %d providers, trying %s (Priority %d)",
+					seg.MessageID, len(seg.MissingFrom), mp.Label(), mp.Priority())
+			}
 
-		acquired, err := m.acquire(ctx, scope, mp)
-		if err != nil {
-			return nil, err
-		}
-		if acquired {
-			m.debugProviderFetch(mp, "Segment %s: Attempting fetch from %s", seg.MessageID, mp.Label())
-			reader, err := m.fetchFromAcquiredProvider(ctx, scope, mp, seg, groups)
-			if err == nil {
-				return reader, nil
+			acquired, err := m.acquire(ctx, scope, mp)
+			if err != nil {
+				return nil, err
 			}
-			if errors.Is(err, ErrArticleNotFound) {
+			if acquired {
+				m.debugProviderFetch(mp, "Segment %s: Attempting fetch from %s", seg.MessageID, mp.Label())
+				reader, err := m.fetchFromAcquiredProvider(ctx, scope, mp, seg, groups)
+				if err == nil {
+					return reader, nil
+				}
+				if errors.Is(err, ErrArticleNotFound) {
+					continue
+				}
+				lastErr = err
 				continue
 			}
-			lastErr = err
-			continue
 		}
+
+		// Once every primary has answered 430, the backups become candidates
+		next := m.fetchCandidates(scope, seg)
+		if len(next) == 0 || candidates[0].isBackup() || !next[0].isBackup() {
+			break
+		}
+		candidates = next
 	}
 
 	eligible := m.eligibleProviders(scope)
@@ -518,7 +527,8 @@ func (m *Manager) fetchFromAcquiredProvider(ctx context.Context, scope string, m
 	}
 
 	return &releaseReader{
-		Reader: reader,
+		Reader:     reader,
+		providerID: mp.ID(),
 		onClose: func() {
 			m.releaseForScope(scope, mp)
 		},
@@ -771,21 +781,10 @@ func (m *Manager) waitForProvider(ctx context.Context, scope string) (*managedPr
 }
 
 func (m *Manager) waitForFetchProvider(ctx context.Context, scope string, seg *domain.Segment) (*managedProvider, error) {
-	eligible := 0
-	providers := make([]*managedProvider, 0, len(m.providers))
-	for _, mp := range m.providers {
-		if !mp.allowsScope(scope) {
-			continue
-		}
-		eligible++
-		if seg != nil && seg.MissingFrom != nil && seg.MissingFrom[mp.ID()] {
-			continue
-		}
-		providers = append(providers, mp)
-	}
-	if eligible == 0 {
+	if len(m.eligibleProviders(scope)) == 0 {
 		return nil, ErrProviderBusy
 	}
+	providers := m.fetchCandidates(scope, seg)
 	if len(providers) == 0 {
 		return nil, m.articleNotFoundError(seg)
 	}
@@ -842,6 +841,30 @@ func (m *Manager) firstFetchProvider(scope string, seg *domain.Segment) *managed
 	return nil
 }
 
+// fetchCandidates lists the providers an article can still be requested from,
+// in priority order. Backup providers are only offered once every eligible
+// primary has reported the article missing.
+func (m *Manager) fetchCandidates(scope string, seg *domain.Segment) []*managedProvider {
+	var primaries, backups []*managedProvider
+	for _, mp := range m.providers {
+		if !mp.allowsScope(scope) {
+			continue
+		}
+		if seg != nil && seg.MissingFrom != nil && seg.MissingFrom[mp.ID()] {
+			continue
+		}
+		if mp.isBackup() {
+			backups = append(backups, mp)
+			continue
+		}
+		primaries = append(primaries, mp)
+	}
+	if len(primaries) > 0 {
+		return primaries
+	}
+	return backups
+}
+
 func (m *Manager) eligibleProviders(scope string) []*managedProvider {
 	out := make([]*managedProvider, 0, len(m.providers))
 	for _, mp := range m.providers {
@@ -1033,6 +1056,10 @@ func roleForScope(scope string) string {
 	}
 }
 
+func (mp *managedProvider) isBackup() bool {
+	return mp != nil && mp.roles["backup"]
+}
+
 func (mp *managedProvider) allowsScope(scope string) bool {
 	if mp == nil {
 		return false
@@ -1160,7 +1187,17 @@ func (m *Manager) tryFetch(ctx context.Context, p *managedProvider, msgID string
 
 type releaseReader struct {
 	io.Reader
-	onClose func()
+	providerID string
+	onClose    func()
+}
+
+// ReaderProviderID reports which provider served a reader returned by Fetch,
+// so callers can exclude it when the article body turns out to be corrupt.
+func ReaderProviderID(r io.Reader) string {
+	if rr, ok := r.(*releaseReader); ok {
+		return rr.providerID
+	}
+	return ""
 }
 
 func (r *releaseReader) Read(p []byte) (n int, err error) {
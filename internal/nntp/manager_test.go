@@ -8,6 +8,8 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/datallboy/gonzb/internal/domain"
 )
 
 func TestManagerReturnBusyPolicyDoesNotWaitForCapacity(t *testing.T) {
@@ -208,6 +210,72 @@ func TestManagerArticleNotFoundIncludesProviderAttempts(t *testing.T) {
 	}
 }
 
+func TestManagerFetchOnlyUsesBackupProvidersAfterPrimariesReportMissing(t *testing.T) {
+	backup := &roleTestProvider{id: "backup"}
+	healthy := &roleTestProvider{id: "primary"}
+	manager := newManagerWithProviders(nil, []*managedProvider{
+		{Provider: backup, semaphore: make(chan struct{}, 1), roles: normalizeProviderRoles([]string{"backup"})},
+		{Provider: healthy, semaphore: make(chan struct{}, 1), roles: normalizeProviderRoles(nil)},
+	}, ManagerOptions{CapacityPolicy: CapacityReturnBusy})
+
+	reader, err := manager.Fetch(context.Background(), &domain.Segment{MessageID: "seg@example"}, nil)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if got := ReaderProviderID(reader); got != "primary" {
+		t.Fatalf("expected primary to serve while it has the article, got %q", got)
+	}
+	_ = reader.(io.Closer).Close()
+
+	missing := &missingProvider{id: "primary-missing"}
+	manager = newManagerWithProviders(nil, []*managedProvider{
+		{Provider: backup, semaphore: make(chan struct{}, 1), roles: normalizeProviderRoles([]string{"backup"})},
+		newManagedProvider(missing),
+	}, ManagerOptions{CapacityPolicy: CapacityReturnBusy})
+
+	seg := &domain.Segment{MessageID: "seg@example"}
+	reader, err = manager.Fetch(context.Background(), seg, nil)
+	if err != nil {
+		t.Fatalf("fetch with missing primary: %v", err)
+	}
+	defer reader.(io.Closer).Close()
+	if got := ReaderProviderID(reader); got != "backup" {
+		t.Fatalf("expected backup to serve after primary 430, got %q", got)
+	}
+	if !seg.MissingFrom["primary-missing"] {
+		t.Fatal("expected primary to be recorded as missing the article")
+	}
+}
+
+func TestManagerStatReportsAvailabilityPerProvider(t *testing.T) {
+	manager := newManagerWithProviders(nil, []*managedProvider{
+		newManagedProvider(&roleTestProvider{id: "full"}),
+		newManagedProvider(&missingProvider{id: "empty"}),
+		{Provider: newBlockingProvider(2), semaphore: make(chan struct{}, 2), roles: normalizeProviderRoles([]string{"backup"})},
+	}, ManagerOptions{CapacityPolicy: CapacityReturnBusy})
+
+	report, err := manager.Stat(context.Background(), []string{"a@example", "b@example", "c@example"})
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if report.Checked != 3 || report.Available != 3 || report.Percent != 100 {
+		t.Fatalf("unexpected combined availability: %+v", report)
+	}
+	if len(report.Providers) != 3 {
+		t.Fatalf("expected one result per provider, got %+v", report.Providers)
+	}
+	byID := map[string]float64{}
+	for _, p := range report.Providers {
+		byID[p.ProviderID] = p.Percent
+		if p.ProviderID == "test-provider" && !p.Backup {
+			t.Fatal("expected backup provider to be flagged")
+		}
+	}
+	if byID["full"] != 100 || byID["empty"] != 0 || byID["test-provider"] != 100 {
+		t.Fatalf("unexpected per-provider availability: %v", byID)
+	}
+}
+
 func TestManagerClientExposesIndexerStyleCalls(t *testing.T) {
 	provider := newBlockingProvider(1)
 	manager := newManagerWithProviders(nil, []*managedProvider{newManagedProvider(provider)}, ManagerOptions{CapacityPolicy: CapacityWaitQueue})
@@ -417,6 +485,9 @@ func (p *roleTestProvider) FetchBodyPrefix(context.Context, string, []string, in
 	p.prefixCalls++
 	return append([]byte(nil), p.prefix...), nil
 }
+func (p *roleTestProvider) Stat(context.Context, string) (bool, error) {
+	return true, nil
+}
 func (p *roleTestProvider) GroupStats(_ context.Context, group string) (GroupStats, error) {
 	return GroupStats{Group: group, Low: 1, High: 10, Count: 10}, nil
 }
@@ -446,6 +517,10 @@ func (p *missingProvider) FetchBodyPrefix(context.Context, string, []string, int
 	return nil, ErrArticleNotFound
 }
 
+func (p *missingProvider) Stat(context.Context, string) (bool, error) {
+	return false, nil
+}
+
 func (p *missingProvider) GroupStats(context.Context, string) (GroupStats, error) {
 	return GroupStats{Low: 1, High: 1}, nil
 }
@@ -487,6 +562,12 @@ func (p *blockingProvider) FetchBodyPrefix(context.Context, string, []string, in
 	return []byte("body"), nil
 }
 
+func (p *blockingProvider) Stat(context.Context, string) (bool, error) {
+	p.enter()
+	defer p.leave()
+	return true, nil
+}
+
 func (p *blockingProvider) GroupStats(context.Context, string) (GroupStats, error) {
 	p.enter()
 	defer p.leave()
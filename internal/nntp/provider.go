@@ -146,6 +146,7 @@ type Provider interface {
 	IdleConnectionCount() int
 	Fetch(ctx context.Context, msgID string, groups []string) (io.Reader, error)
 	FetchBodyPrefix(ctx context.Context, msgID string, groups []string, maxBytes int64) ([]byte, error)
+	Stat(ctx context.Context, msgID string) (bool, error)
 	GroupStats(ctx context.Context, group string) (GroupStats, error)
 	ListGroups(ctx context.Context, pattern string) ([]GroupListing, error)
 	XOver(ctx context.Context, group string, from, to int64) ([]OverviewHeader, error)
@@ -234,6 +235,39 @@ func (p *nntpProvider) FetchBodyPrefix(ctx context.Context, msgID string, groups
 	return nil, lastErr
 }
 
+// Stat issues STAT for a message ID and reports whether the server holds the
+// article, without transferring its body.
+func (p *nntpProvider) Stat(ctx context.Context, msgID string) (bool, error) {
+	formattedID := strings.TrimSpace(msgID)
+	if !strings.HasPrefix(formattedID, "<") {
+		formattedID = "<" + formattedID + ">"
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < 2; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		conn, err := p.getConn()
+		if err != nil {
+			return false, err
+		}
+
+		found, retry, err := p.statWithConn(conn, formattedID)
+		if err == nil {
+			return found, nil
+		}
+		lastErr = err
+		if retry && attempt == 0 {
+			p.logRecoverableRetry("stat", err, formattedID)
+			continue
+		}
+		return false, err
+	}
+
+	return false, lastErr
+}
+
 // GroupStats issues GROUP and parses high/low/count.
 func (p *nntpProvider) GroupStats(ctx context.Context, group string) (GroupStats, error) {
 	if err := ctx.Err(); err != nil {
@@ -708,6 +742,29 @@ func (p *nntpProvider) fetchWithConn(ctx context.Context, conn *nntpConn, format
 	}, false, nil
 }
 
+func (p *nntpProvider) statWithConn(conn *nntpConn, formattedID string) (bool, bool, error) {
+	if _, err := conn.tp.Cmd("STAT %s", formattedID); err != nil {
+		conn.Close()
+		return false, isRecoverableConnError(err), err
+	}
+
+	code, msg, err := conn.tp.ReadCodeLine(223)
+	if err != nil {
+		if code == 430 || strings.Contains(strings.ToLower(msg), "no such article") {
+			p.returnConn(conn)
+			return false, false, nil
+		}
+		conn.Close()
+		if isRecoverableConnError(err) {
+			return false, true, err
+		}
+		return false, false, fmt.Errorf("NNTP error %d: %s", code, msg)
+	}
+
+	p.returnConn(conn)
+	return true, false, nil
+}
+
 func (p *nntpProvider) fetchBodyPrefixWithConn(ctx context.Context, conn *nntpConn, formattedID string, groups []string, maxBytes int64) ([]byte, bool, error) {
 	reader, retry, err := p.fetchWithConn(ctx, conn, formattedID, groups)
 	if err != nil {
@@ -0,0 +1,125 @@
+package nntp
+
+import (
+	"context"
+	"sync"
+
+	"github.com/datallboy/gonzb/internal/app"
+)
+
+// statWorkersPerProvider caps the connections one availability pass may hold
+// on a provider, so a check never starves running downloads.
+const statWorkersPerProvider = 4
+
+// Stat issues STAT for each message ID on every provider allowed to serve
+// downloads, primaries and backups alike, and reports per-provider coverage.
+func (m *Manager) Stat(ctx context.Context, msgIDs []string) (*app.AvailabilityReport, error) {
+	providers := m.eligibleProviders("downloader")
+	report := &app.AvailabilityReport{
+		Checked:   len(msgIDs),
+		Providers: make([]app.ProviderAvailability, len(providers)),
+	}
+	if len(msgIDs) == 0 || len(providers) == 0 {
+		return report, nil
+	}
+
+	found := make([][]bool, len(providers))
+	var wg sync.WaitGroup
+	for i, mp := range providers {
+		found[i] = make([]bool, len(msgIDs))
+		wg.Add(1)
+		go func(i int, mp *managedProvider) {
+			defer wg.Done()
+			report.Providers[i] = m.statProvider(ctx, mp, msgIDs, found[i])
+		}(i, mp)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for j := range msgIDs {
+		for i := range providers {
+			if found[i][j] {
+				report.Available++
+				break
+			}
+		}
+	}
+	report.Percent = availabilityPercent(report.Available, report.Checked)
+	return report, nil
+}
+
+func (m *Manager) statProvider(ctx context.Context, mp *managedProvider, msgIDs []string, found []bool) app.ProviderAvailability {
+	result := app.ProviderAvailability{
+		ProviderID: mp.ID(),
+		Provider:   mp.Label(),
+		Backup:     mp.isBackup(),
+		Checked:    len(msgIDs),
+	}
+
+	workers := min(statWorkersPerProvider, max(1, mp.MaxConnection()), len(msgIDs))
+	next := make(chan int)
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	statCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range next {
+				if err := m.waitAcquire(statCtx, "downloader", mp); err != nil {
+					return
+				}
+				ok, err := mp.Provider.Stat(statCtx, msgIDs[j])
+				m.releaseForScope("downloader", mp)
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					cancel()
+					return
+				}
+				found[j] = ok
+			}
+		}()
+	}
+
+feed:
+	for j := range msgIDs {
+		select {
+		case <-statCtx.Done():
+			break feed
+		case next <- j:
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	if firstErr != nil && ctx.Err() == nil {
+		m.recordOperationError("downloader", firstErr)
+		result.Error = firstErr.Error()
+	}
+	for _, ok := range found {
+		if ok {
+			result.Available++
+		}
+	}
+	result.Percent = availabilityPercent(result.Available, result.Checked)
+	return result
+}
+
+func availabilityPercent(available, checked int) float64 {
+	if checked == 0 {
+		return 0
+	}
+	return float64(available) * 100 / float64(checked)
+}
@@ -34,6 +34,12 @@ func (z *CLI7z) Name() string {
 }
 
 func (z *CLI7z) CanExtract(filePath string) (bool, error) {
+	return canExtract7z(filePath)
+}
+
+// canExtract7z accepts name.7z, the .7z.001 volume of a split set or an
+// extensionless file with a 7z signature.
+func canExtract7z(filePath string) (bool, error) {
 	lower := strings.ToLower(filepath.Base(filePath))
 
 	switch {
@@ -0,0 +1,44 @@
+package processor
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var splitVolumeRE = regexp.MustCompile(`(?i)^(.*\.(?:7z|zip))\.(\d{3})$`)
+
+// ArchiveVolume places a file name within a multi-volume archive set. set is
+// shared by every volume of the set and number orders them: name.rar is 0 and
+// name.r00 is 1 in old-style RAR sets, name.partN.rar is N, and split 7z/zip
+// volumes use their .001 suffix. ok is false for anything else.
+func ArchiveVolume(name string) (set string, number int, ok bool) {
+	base := filepath.Base(name)
+	lower := strings.ToLower(base)
+
+	if m := rarPartVolumeRE.FindStringSubmatch(base); m != nil {
+		n, _ := strconv.Atoi(m[2])
+		return strings.ToLower(m[1][:len(m[1])-len(".part")]), n, true
+	}
+	if strings.HasSuffix(lower, ".rar") {
+		return strings.TrimSuffix(lower, ".rar"), 0, true
+	}
+	if m := rarOldVolumeRE.FindStringSubmatch(base); m != nil {
+		letter := strings.ToLower(m[2])[0]
+		n, _ := strconv.Atoi(m[3])
+		return strings.ToLower(strings.TrimSuffix(m[1], ".")), int(letter-'r')*100 + n + 1, true
+	}
+	if m := splitVolumeRE.FindStringSubmatch(base); m != nil {
+		n, _ := strconv.Atoi(m[2])
+		return strings.ToLower(m[1]), n, true
+	}
+	return "", 0, false
+}
+
+// isRarVolume reports whether name belongs to a RAR set rather than a split
+// 7z or zip.
+func isRarVolume(name string) bool {
+	_, _, ok := ArchiveVolume(name)
+	return ok && !splitVolumeRE.MatchString(filepath.Base(name))
+}
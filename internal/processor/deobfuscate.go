@@ -0,0 +1,317 @@
+package processor
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/indexing/inspect"
+	"github.com/datallboy/gonzb/internal/indexing/inspect/par2"
+)
+
+const (
+	deobfuscateEventStage = "deobfuscate"
+	par2Hash16kSize       = 16 << 10
+)
+
+// a stem of 12+ letters/digits with no separators is a random post name,
+// not a release name like "Show.S01E01" or "Album - Track".
+var obfuscatedStemRE = regexp.MustCompile(`^[A-Za-z0-9]{12,}$`)
+
+var mediaExtensions = map[string]struct{}{
+	".mkv": {}, ".mp4": {}, ".m4v": {}, ".avi": {}, ".mov": {}, ".wmv": {},
+	".ts": {}, ".m2ts": {}, ".mpg": {}, ".mpeg": {}, ".webm": {},
+	".mp3": {}, ".flac": {}, ".m4a": {}, ".m4b": {}, ".ogg": {}, ".opus": {},
+}
+
+// sniffedMediaExtensions maps inspect.DetectSignature results to extensions.
+var sniffedMediaExtensions = map[string]string{
+	"matroska": ".mkv",
+	"mp4":      ".mp4",
+	"avi":      ".avi",
+	"flac":     ".flac",
+}
+
+// renameFromPar2 gives downloaded files their real names from the PAR2 file
+// description packets, matched by the MD5 of each file's first 16 KiB. It runs
+// before repair, which looks files up by those names. The bool reports whether
+// the job carried any PAR2 file descriptions at all.
+func (p *Processor) renameFromPar2(ctx context.Context, item *domain.QueueItem, tasks []*domain.DownloadFile) bool {
+	descs := readPar2FileDescs(ctx, tasks)
+	if len(descs) == 0 {
+		return false
+	}
+
+	byHash := make(map[[16]byte][]*par2File, len(descs))
+	known := make(map[string]bool, len(descs))
+	for _, desc := range descs {
+		byHash[desc.Hash16k] = append(byHash[desc.Hash16k], desc)
+		known[filepath.Clean(filepath.FromSlash(desc.Name))] = true
+	}
+
+	renamed := map[string]string{}
+	claimed := map[*par2File]bool{}
+	for _, task := range tasks {
+		if task == nil || task.FinalPath == "" {
+			continue
+		}
+		dir := filepath.Dir(task.FinalPath)
+		name := filepath.Base(task.FinalPath)
+		if isPar2File(task.FinalPath) {
+			// an obfuscated PAR2 file gets its extension back so repair finds it.
+			if !isPar2Path(name) {
+				if err := p.renameTask(task, task.FinalPath+".par2"); err != nil {
+					p.ctx.Logger.Warn("Deobfuscate: could not rename %s to %s.par2: %v", name, name, err)
+					continue
+				}
+				renamed[name] = name + ".par2"
+			}
+			continue
+		}
+		if known[name] {
+			continue
+		}
+
+		info, err := os.Stat(task.FinalPath)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		head, err := hash16k(task.FinalPath)
+		if err != nil {
+			continue
+		}
+
+		desc := pickPar2Desc(byHash[head], info.Size(), claimed)
+		if desc == nil {
+			continue
+		}
+		target := filepath.Clean(filepath.FromSlash(desc.Name))
+		if !filepath.IsLocal(target) {
+			continue
+		}
+		if err := p.renameTask(task, filepath.Join(dir, target)); err != nil {
+			p.ctx.Logger.Warn("Deobfuscate: could not rename %s to %s: %v", name, target, err)
+			continue
+		}
+		claimed[desc] = true
+		renamed[name] = target
+	}
+
+	if len(renamed) > 0 {
+		p.ctx.Logger.Info("Deobfuscate: renamed %d file(s) from PAR2 metadata", len(renamed))
+		p.recordEvent(ctx, item, deobfuscateEventStage, "ok",
+			fmt.Sprintf("Renamed %d file(s) from PAR2 metadata", len(renamed)), map[string]any{"renamed": renamed})
+		p.saveRenamedTasks(ctx, item, tasks)
+	}
+	return true
+}
+
+// renameLargestMedia names the job's largest media file after the job when
+// the job has no PAR2 metadata to recover real names from and the file's own
+// name looks obfuscated.
+func (p *Processor) renameLargestMedia(ctx context.Context, item *domain.QueueItem, tasks []*domain.DownloadFile) {
+	jobName := deobfuscatedJobName(item)
+	if jobName == "" || obfuscatedStemRE.MatchString(jobName) {
+		return
+	}
+
+	var (
+		largest     *domain.DownloadFile
+		largestSize int64
+	)
+	var largestExt string
+	for _, task := range tasks {
+		if task == nil || task.FinalPath == "" {
+			continue
+		}
+		info, err := os.Stat(task.FinalPath)
+		if err != nil || info.IsDir() || info.Size() <= largestSize {
+			continue
+		}
+		ext := mediaExtension(task.FinalPath)
+		if ext == "" {
+			continue
+		}
+		largest, largestSize, largestExt = task, info.Size(), ext
+	}
+	if largest == nil {
+		return
+	}
+
+	name := filepath.Base(largest.FinalPath)
+	if !obfuscatedStemRE.MatchString(strings.TrimSuffix(name, filepath.Ext(name))) {
+		return
+	}
+
+	target := jobName + largestExt
+	if err := p.renameTask(largest, filepath.Join(filepath.Dir(largest.FinalPath), target)); err != nil {
+		p.ctx.Logger.Warn("Deobfuscate: could not rename %s to %s: %v", name, target, err)
+		return
+	}
+	p.ctx.Logger.Info("Deobfuscate: renamed %s to %s", name, target)
+	p.recordEvent(ctx, item, deobfuscateEventStage, "ok", "Renamed "+name+" after the job",
+		map[string]any{"renamed": map[string]string{name: target}})
+}
+
+// renameTask moves a downloaded file without overwriting anything already
+// there and points the task at its new name.
+func (p *Processor) renameTask(task *domain.DownloadFile, dest string) error {
+	if _, err := os.Lstat(dest); err == nil {
+		return os.ErrExist
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(task.FinalPath, dest); err != nil {
+		return err
+	}
+	task.FinalPath = dest
+	task.PartPath = dest + ".part"
+	task.FileName = filepath.Base(dest)
+	return nil
+}
+
+// saveRenamedTasks persists the new names so a later reprocess finds the files.
+// The renamed list is a new file set, so held-back recovery volumes are
+// recorded against it again.
+func (p *Processor) saveRenamedTasks(ctx context.Context, item *domain.QueueItem, tasks []*domain.DownloadFile) {
+	if item == nil || item.ID == "" || p.ctx.QueueFileStore == nil {
+		return
+	}
+	if err := p.ctx.QueueFileStore.SaveQueueItemFiles(ctx, item.ID, tasks); err != nil {
+		p.ctx.Logger.Warn("Failed to persist deobfuscated file names for %s: %v", item.ID, err)
+		return
+	}
+
+	skipped := make([]int, 0)
+	for _, task := range tasks {
+		if task != nil && task.Skipped {
+			skipped = append(skipped, task.Index)
+		}
+	}
+	if err := p.ctx.QueueFileStore.SetQueueItemFilesSkipped(ctx, item.ID, skipped); err != nil {
+		p.ctx.Logger.Warn("Failed to keep skipped recovery volumes for %s: %v", item.ID, err)
+	}
+}
+
+// readPar2FileDescs collects the file description packets of every PAR2 file
+// in the job, whatever recovery set they belong to.
+func readPar2FileDescs(ctx context.Context, tasks []*domain.DownloadFile) []*par2File {
+	seen := map[[16]byte]bool{}
+	var descs []*par2File
+	for _, task := range tasks {
+		if task == nil || ctx.Err() != nil || !isPar2File(task.FinalPath) {
+			continue
+		}
+		_ = scanPar2Packets(task.FinalPath, func(_ string, h par2.PacketHeader, body []byte, _ int64) {
+			if h.Type != par2.TypeFileDesc {
+				return
+			}
+			if f, ok := parsePar2FileDesc(body); ok && !seen[f.ID] {
+				seen[f.ID] = true
+				descs = append(descs, f)
+			}
+		})
+	}
+	return descs
+}
+
+// pickPar2Desc prefers the unclaimed description whose length matches; a lone
+// candidate is taken even when the length differs, since repair fixes that.
+func pickPar2Desc(candidates []*par2File, size int64, claimed map[*par2File]bool) *par2File {
+	var open []*par2File
+	for _, desc := range candidates {
+		if claimed[desc] {
+			continue
+		}
+		if desc.Length == size {
+			return desc
+		}
+		open = append(open, desc)
+	}
+	if len(open) == 1 {
+		return open[0]
+	}
+	return nil
+}
+
+func hash16k(path string) ([16]byte, error) {
+	var sum [16]byte
+	f, err := os.Open(path)
+	if err != nil {
+		return sum, err
+	}
+	defer f.Close()
+
+	h := md5.New()
+	if _, err := io.CopyN(h, f, par2Hash16kSize); err != nil && err != io.EOF {
+		return sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+func isPar2Path(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".par2")
+}
+
+// isPar2File goes by the packet magic, since obfuscated posts often drop the
+// .par2 extension.
+func isPar2File(path string) bool {
+	head, err := readFileHead(path, len(par2.PacketMagic))
+	return err == nil && par2.HasMagic(head)
+}
+
+// mediaExtension is the file's media extension, lowercased. A file without
+// an extension is sniffed for a known container instead.
+func mediaExtension(path string) string {
+	if ext := strings.ToLower(filepath.Ext(path)); ext != "" {
+		if _, ok := mediaExtensions[ext]; ok {
+			return ext
+		}
+		return ""
+	}
+	head, err := readFileHead(path, 16)
+	if err != nil {
+		return ""
+	}
+	return sniffedMediaExtensions[inspect.DetectSignature(head, "")]
+}
+
+func readFileHead(path string, n int) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	head := make([]byte, n)
+	read, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	return head[:read], nil
+}
+
+// deobfuscatedJobName is the release title as a file name, without an
+// embedded {{password}} or .nzb suffix.
+func deobfuscatedJobName(item *domain.QueueItem) string {
+	if item == nil {
+		return ""
+	}
+	title := item.ReleaseTitle
+	if item.Release != nil && item.Release.Title != "" {
+		title = item.Release.Title
+	}
+	title = sanitizeFileName(title)
+	if strings.EqualFold(filepath.Ext(title), ".nzb") {
+		title = strings.TrimSuffix(title, filepath.Ext(title))
+	}
+	return strings.Trim(title, ". ")
+}
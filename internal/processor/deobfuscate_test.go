@@ -0,0 +1,248 @@
+package processor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/datallboy/gonzb/internal/app"
+	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/infra/config"
+	"github.com/datallboy/gonzb/internal/infra/logger"
+	"github.com/datallboy/gonzb/internal/store/sqlitejob"
+)
+
+func newDeobfuscateTestProcessor(t *testing.T, workRoot string) *Processor {
+	t.Helper()
+
+	log, err := logger.New("none", logger.LevelError, false)
+	if err != nil {
+		t.Fatalf("logger.New() error = %v", err)
+	}
+	return New(&app.Context{
+		Config: &config.Config{
+			Download: config.DownloadConfig{
+				OutDir:       filepath.Join(workRoot, "work"),
+				CompletedDir: filepath.Join(workRoot, "completed"),
+				Categories:   []config.CategoryConfig{{Name: "movies", PostProcessing: config.PostProcessRepair}},
+			},
+		},
+		Logger: log,
+	}, nil)
+}
+
+func TestPostProcessRestoresNamesFromPar2BeforeRepair(t *testing.T) {
+	workRoot := t.TempDir()
+	outDir := filepath.Join(workRoot, "work", "job")
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	video := randomBytes(1, 40<<10)
+	info := randomBytes(2, 3000)
+	writeTestPar2Set(t, outDir, map[string][]byte{"Real.Movie.2024.mkv": video, "Real.Movie.2024.nfo": info}, 4096, 2)
+
+	obfuscated := map[string]string{
+		"Real.Movie.2024.mkv": "a8f3k2j4h5g6q9w1",
+		"Real.Movie.2024.nfo": "z7x6c5v4b3n2m1l0",
+	}
+	var tasks []*domain.DownloadFile
+	for real, random := range obfuscated {
+		if err := os.Rename(filepath.Join(outDir, real), filepath.Join(outDir, random)); err != nil {
+			t.Fatal(err)
+		}
+		tasks = append(tasks, &domain.DownloadFile{FileName: random, FinalPath: filepath.Join(outDir, random)})
+	}
+	// damage the video so repair has to find it under its real name.
+	corrupt(t, filepath.Join(outDir, "a8f3k2j4h5g6q9w1"), 20000)
+	for _, name := range []string{"set.par2", "set.vol00+02.par2"} {
+		tasks = append(tasks, &domain.DownloadFile{FileName: name, FinalPath: filepath.Join(outDir, name)})
+	}
+
+	item := &domain.QueueItem{OutDir: outDir, Release: &domain.Release{Title: "Real.Movie.2024", Category: "movies"}}
+	if err := newDeobfuscateTestProcessor(t, workRoot).PostProcess(context.Background(), item, tasks); err != nil {
+		t.Fatalf("PostProcess() error = %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(item.OutDir, "Real.Movie.2024.mkv"))
+	if err != nil {
+		t.Fatalf("real name not restored: %v", err)
+	}
+	if string(got) != string(video) {
+		t.Fatal("renamed video was not repaired")
+	}
+	if _, err := os.Stat(filepath.Join(item.OutDir, "Real.Movie.2024.nfo")); err != nil {
+		t.Fatalf("nfo not renamed: %v", err)
+	}
+}
+
+func TestPostProcessFindsPar2FilesByMagicWithoutExtension(t *testing.T) {
+	workRoot := t.TempDir()
+	outDir := filepath.Join(workRoot, "work", "job")
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	video := randomBytes(3, 40<<10)
+	writeTestPar2Set(t, outDir, map[string][]byte{"Real.Movie.2024.mkv": video}, 4096, 2)
+	obfuscated := map[string]string{
+		"Real.Movie.2024.mkv": "a8f3k2j4h5g6q9w1",
+		"set.par2":            "p0q9r8s7t6u5v4w3",
+		"set.vol00+02.par2":   "k1l2m3n4o5p6q7r8",
+	}
+	var tasks []*domain.DownloadFile
+	for real, random := range obfuscated {
+		if err := os.Rename(filepath.Join(outDir, real), filepath.Join(outDir, random)); err != nil {
+			t.Fatal(err)
+		}
+		tasks = append(tasks, &domain.DownloadFile{FileName: random, FinalPath: filepath.Join(outDir, random)})
+	}
+	corrupt(t, filepath.Join(outDir, "a8f3k2j4h5g6q9w1"), 20000)
+
+	item := &domain.QueueItem{OutDir: outDir, Release: &domain.Release{Title: "Real.Movie.2024", Category: "movies"}}
+	if err := newDeobfuscateTestProcessor(t, workRoot).PostProcess(context.Background(), item, tasks); err != nil {
+		t.Fatalf("PostProcess() error = %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(item.OutDir, "Real.Movie.2024.mkv"))
+	if err != nil {
+		t.Fatalf("real name not restored from extensionless PAR2 files: %v", err)
+	}
+	if string(got) != string(video) {
+		t.Fatal("renamed video was not repaired")
+	}
+	for _, name := range []string{"p0q9r8s7t6u5v4w3.par2", "k1l2m3n4o5p6q7r8.par2"} {
+		if _, err := os.Stat(filepath.Join(item.OutDir, name)); err != nil {
+			t.Fatalf("expected PAR2 file to get its extension back as %s: %v", name, err)
+		}
+	}
+}
+
+func TestPostProcessSniffsExtensionlessMediaForTheJobName(t *testing.T) {
+	workRoot := t.TempDir()
+	outDir := filepath.Join(workRoot, "work", "job")
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	matroska := append([]byte{0x1a, 0x45, 0xdf, 0xa3}, make([]byte, 5000)...)
+	files := map[string][]byte{
+		"Qm9ZbXk3TnRwZk": matroska,
+		"Zm9vYmFyYmF6cQ": make([]byte, 9000), // larger, but nothing it could be named after
+	}
+	var tasks []*domain.DownloadFile
+	for name, data := range files {
+		writeFile(t, filepath.Join(outDir, name), data)
+		tasks = append(tasks, &domain.DownloadFile{FileName: name, FinalPath: filepath.Join(outDir, name)})
+	}
+
+	item := &domain.QueueItem{OutDir: outDir, Release: &domain.Release{Title: "Some Film (2023)", Category: "movies"}}
+	if err := newDeobfuscateTestProcessor(t, workRoot).PostProcess(context.Background(), item, tasks); err != nil {
+		t.Fatalf("PostProcess() error = %v", err)
+	}
+	for _, name := range []string{"Some Film (2023).mkv", "Zm9vYmFyYmF6cQ"} {
+		if _, err := os.Stat(filepath.Join(item.OutDir, name)); err != nil {
+			t.Fatalf("expected %s after deobfuscation: %v", name, err)
+		}
+	}
+}
+
+func TestPostProcessNamesLargestObfuscatedMediaAfterJob(t *testing.T) {
+	run := func(t *testing.T, files map[string]int) string {
+		t.Helper()
+
+		workRoot := t.TempDir()
+		outDir := filepath.Join(workRoot, "work", "job")
+		if err := os.MkdirAll(outDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		var tasks []*domain.DownloadFile
+		for name, size := range files {
+			if err := os.WriteFile(filepath.Join(outDir, name), make([]byte, size), 0644); err != nil {
+				t.Fatal(err)
+			}
+			tasks = append(tasks, &domain.DownloadFile{FileName: name, FinalPath: filepath.Join(outDir, name)})
+		}
+
+		item := &domain.QueueItem{OutDir: outDir, Release: &domain.Release{Title: "Some Film (2023) {{secret}}.nzb", Category: "movies"}}
+		if err := newDeobfuscateTestProcessor(t, workRoot).PostProcess(context.Background(), item, tasks); err != nil {
+			t.Fatalf("PostProcess() error = %v", err)
+		}
+		return item.OutDir
+	}
+
+	completed := run(t, map[string]int{
+		"Qm9ZbXk3TnRw.mkv":   5000,
+		"Xk2PqL8nVw4R.mkv":   1000,
+		"c0ffee1234abcd.nfo": 9000,
+	})
+	for _, name := range []string{"Some Film (2023).mkv", "Xk2PqL8nVw4R.mkv", "c0ffee1234abcd.nfo"} {
+		if _, err := os.Stat(filepath.Join(completed, name)); err != nil {
+			t.Fatalf("expected %s after deobfuscation: %v", name, err)
+		}
+	}
+
+	// a largest media file with a real name is left alone.
+	completed = run(t, map[string]int{"Featurette.mkv": 9000, "Qm9ZbXk3TnRw.mkv": 5000})
+	for _, name := range []string{"Featurette.mkv", "Qm9ZbXk3TnRw.mkv"} {
+		if _, err := os.Stat(filepath.Join(completed, name)); err != nil {
+			t.Fatalf("expected %s to keep its name: %v", name, err)
+		}
+	}
+}
+
+func TestRenameFromPar2KeepsHeldRecoveryVolumesSkipped(t *testing.T) {
+	workRoot := t.TempDir()
+	outDir := filepath.Join(workRoot, "work", "job")
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestPar2Set(t, outDir, map[string][]byte{"Real.Movie.2024.mkv": randomBytes(1, 40<<10)}, 4096, 2)
+	if err := os.Rename(filepath.Join(outDir, "Real.Movie.2024.mkv"), filepath.Join(outDir, "a8f3k2j4h5g6q9w1")); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := sqlitejob.NewStore(filepath.Join(workRoot, "gonzb.db"), filepath.Join(workRoot, "blobs"))
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	item := &domain.QueueItem{ID: "job", Status: domain.StatusProcessing, OutDir: outDir, Release: &domain.Release{Title: "Real.Movie.2024"}}
+	if err := store.SaveQueueItem(context.Background(), item); err != nil {
+		t.Fatalf("save queue item: %v", err)
+	}
+
+	held := &domain.DownloadFile{FileName: "set.vol02+04.par2", Index: 3, FinalPath: filepath.Join(outDir, "set.vol02+04.par2"), Skipped: true}
+	tasks := []*domain.DownloadFile{
+		{FileName: "a8f3k2j4h5g6q9w1", Index: 0, FinalPath: filepath.Join(outDir, "a8f3k2j4h5g6q9w1")},
+		{FileName: "set.par2", Index: 1, FinalPath: filepath.Join(outDir, "set.par2")},
+		{FileName: "set.vol00+02.par2", Index: 2, FinalPath: filepath.Join(outDir, "set.vol00+02.par2")},
+		held,
+	}
+	if err := store.SaveQueueItemFiles(context.Background(), item.ID, tasks); err != nil {
+		t.Fatalf("save queue item files: %v", err)
+	}
+	if err := store.SetQueueItemFilesSkipped(context.Background(), item.ID, []int{held.Index}); err != nil {
+		t.Fatalf("record skipped files: %v", err)
+	}
+
+	p := newDeobfuscateTestProcessor(t, workRoot)
+	p.ctx.QueueFileStore = store
+	if !p.renameFromPar2(context.Background(), item, tasks) {
+		t.Fatal("expected PAR2 file descriptions to be found")
+	}
+
+	files, err := store.GetQueueItemFiles(context.Background(), item.ID)
+	if err != nil {
+		t.Fatalf("get queue item files: %v", err)
+	}
+	for _, f := range files {
+		if f.Index == 0 && f.FileName != "Real.Movie.2024.mkv" {
+			t.Fatalf("expected the renamed file to be persisted, got %s", f.FileName)
+		}
+		if want := f.Index == held.Index; f.Skipped != want {
+			t.Fatalf("file %s skipped=%v after rename, want %v", f.FileName, f.Skipped, want)
+		}
+	}
+}
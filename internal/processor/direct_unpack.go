@@ -0,0 +1,457 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/datallboy/gonzb/internal/domain"
+)
+
+const directUnpackEventStage = "direct_unpack"
+
+var errVolumeNotDownloaded = errors.New("volume was not downloaded intact")
+
+// DirectUnpack extracts a release's RAR sets while the rest of it downloads.
+// The downloader hands over each volume as its file completes and the sets are
+// walked volume by volume, so extraction trails the download instead of
+// starting after it. Only RAR streams this way: 7z and zip keep their index
+// at the end of the archive and are left to post-processing.
+type DirectUnpack struct {
+	p        *Processor
+	item     *domain.QueueItem
+	heads    []*domain.DownloadFile // first volume of each RAR set, in download order
+	password string
+	cancel   context.CancelFunc
+
+	mu       sync.Mutex
+	ready    map[string]string // lower-cased volume name -> path on disk
+	finished bool              // the download ended; no more volumes will arrive
+	changed  chan struct{}
+
+	done    chan struct{}
+	results map[*domain.DownloadFile][]string
+}
+
+// StartDirectUnpack begins extracting item's RAR sets in the background. It
+// returns nil when direct unpack is off, the category does not unpack, or the
+// release has no RAR set; every DirectUnpack method accepts a nil receiver.
+func (p *Processor) StartDirectUnpack(ctx context.Context, item *domain.QueueItem) *DirectUnpack {
+	if item == nil || !p.ctx.Config.Download.DirectUnpack || !p.ctx.ExtractionEnabled {
+		return nil
+	}
+	if p.category(item).PostProcessLevel() < ppUnpack {
+		return nil
+	}
+
+	heads := rarSetHeads(item.Tasks)
+	if len(heads) == 0 {
+		return nil
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	d := &DirectUnpack{
+		p:        p,
+		item:     item,
+		heads:    heads,
+		password: heads[0].Password,
+		cancel:   cancel,
+		ready:    make(map[string]string),
+		changed:  make(chan struct{}),
+		done:     make(chan struct{}),
+		results:  make(map[*domain.DownloadFile][]string),
+	}
+	for _, task := range item.Tasks {
+		if task.IsComplete {
+			d.ready[strings.ToLower(task.FileName)] = task.FinalPath
+		}
+	}
+
+	p.directMu.Lock()
+	if p.direct == nil {
+		p.direct = make(map[string]*DirectUnpack)
+	}
+	if old := p.direct[item.ID]; old != nil {
+		old.cancel()
+	}
+	p.direct[item.ID] = d
+	p.directMu.Unlock()
+
+	go d.run(runCtx)
+	return d
+}
+
+// VolumeDone makes a finished, finalized file available to the extractor.
+func (d *DirectUnpack) VolumeDone(task *domain.DownloadFile) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.ready[strings.ToLower(task.FileName)] = task.FinalPath
+	d.notifyLocked()
+	d.mu.Unlock()
+}
+
+// DownloadDone tells the extractor no more volumes are coming; a set still
+// waiting for one is left to post-processing.
+func (d *DirectUnpack) DownloadDone() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.finished = true
+	d.notifyLocked()
+	d.mu.Unlock()
+}
+
+// Abort stops the extraction and discards its work, for a download that
+// failed or was paused.
+func (d *DirectUnpack) Abort() {
+	if d == nil {
+		return
+	}
+	d.cancel()
+	<-d.done
+
+	// the download restarts from scratch, and so does its unpack.
+	for _, files := range d.results {
+		for _, path := range files {
+			os.Remove(path)
+		}
+	}
+
+	d.p.directMu.Lock()
+	if d.p.direct[d.item.ID] == d {
+		delete(d.p.direct, d.item.ID)
+	}
+	d.p.directMu.Unlock()
+}
+
+func (d *DirectUnpack) notifyLocked() {
+	close(d.changed)
+	d.changed = make(chan struct{})
+}
+
+// volume waits until the volume at path has been downloaded and returns where
+// it is on disk.
+func (d *DirectUnpack) volume(ctx context.Context, path string) (string, error) {
+	name := strings.ToLower(filepath.Base(path))
+	for {
+		d.mu.Lock()
+		found, ok := d.ready[name]
+		finished, changed := d.finished, d.changed
+		d.mu.Unlock()
+
+		switch {
+		case ok:
+			return found, nil
+		case finished:
+			return "", fmt.Errorf("%s: %w", filepath.Base(path), errVolumeNotDownloaded)
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+func (d *DirectUnpack) run(ctx context.Context) {
+	defer close(d.done)
+
+	for _, head := range d.heads {
+		first, err := d.volume(ctx, head.FinalPath)
+		if err == nil {
+			var files []string
+			files, err = runNativeExtract(ctx, first, filepath.Dir(head.FinalPath), nil, func(sink *extractSink) error {
+				return extractRarStream(ctx, first, func(next string) (string, error) {
+					return d.volume(ctx, next)
+				}, d.password, sink)
+			})
+			if err == nil {
+				d.results[head] = files
+				d.p.recordEvent(ctx, d.item, directUnpackEventStage, "ok",
+					fmt.Sprintf("Unpacked %d file(s) from %s during download", len(files), head.FileName), nil)
+				continue
+			}
+		}
+
+		// whatever stopped the stream, post-processing extracts the rest as usual.
+		if ctx.Err() == nil {
+			d.p.ctx.Logger.Debug("Direct unpack of %s stopped: %v", head.FileName, err)
+			d.p.recordEvent(ctx, d.item, directUnpackEventStage, "skipped", head.FileName+": "+err.Error(), nil)
+		}
+		return
+	}
+}
+
+// directUnpacked waits for item's direct unpack and returns the files each RAR
+// set produced, keyed by the set's first volume.
+func (p *Processor) directUnpacked(ctx context.Context, item *domain.QueueItem) map[*domain.DownloadFile][]string {
+	if item == nil {
+		return nil
+	}
+
+	p.directMu.Lock()
+	d := p.direct[item.ID]
+	delete(p.direct, item.ID)
+	p.directMu.Unlock()
+	if d == nil {
+		return nil
+	}
+
+	d.DownloadDone()
+	select {
+	case <-d.done:
+	case <-ctx.Done():
+		d.cancel()
+		<-d.done
+		return nil
+	}
+	return d.results
+}
+
+func ignoreEOF(err error) error {
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// rarSetHeads returns the first volume of every RAR set in tasks, ordered by
+// where the set starts in the NZB.
+func rarSetHeads(tasks []*domain.DownloadFile) []*domain.DownloadFile {
+	type head struct {
+		task   *domain.DownloadFile
+		number int
+		order  int
+	}
+	sets := make(map[string]*head)
+	for i, task := range tasks {
+		if task.Skipped || !isRarVolume(task.FileName) {
+			continue
+		}
+		set, number, _ := ArchiveVolume(task.FileName)
+		h, ok := sets[set]
+		if !ok {
+			sets[set] = &head{task: task, number: number, order: i}
+			continue
+		}
+		if number < h.number {
+			h.task, h.number = task, number
+		}
+	}
+
+	heads := make([]*head, 0, len(sets))
+	for _, h := range sets {
+		// a set whose first volume is missing from the NZB cannot stream.
+		if h.number > 1 || (h.number == 1 && !rarPartVolumeRE.MatchString(h.task.FileName)) {
+			continue
+		}
+		heads = append(heads, h)
+	}
+	sort.Slice(heads, func(i, j int) bool { return heads[i].order < heads[j].order })
+
+	out := make([]*domain.DownloadFile, len(heads))
+	for i, h := range heads {
+		out[i] = h.task
+	}
+	return out
+}
+
+// rarStreamEntry is a member whose stored bytes arrive volume by volume.
+type rarStreamEntry struct {
+	*rarEntry
+	parts chan rarPart
+}
+
+// rarPartStream reads a member's parts as the walker delivers them. With
+// discard set it drops them instead and only reports EOF once the last one
+// has been delivered.
+type rarPartStream struct {
+	ctx     context.Context
+	parts   <-chan rarPart
+	cur     io.Reader
+	discard bool
+}
+
+func (r *rarPartStream) Read(p []byte) (int, error) {
+	for {
+		if r.discard {
+			r.cur = nil
+		}
+		if r.cur != nil {
+			n, err := r.cur.Read(p)
+			if err == io.EOF {
+				r.cur = nil
+				if n > 0 {
+					return n, nil
+				}
+				continue
+			}
+			return n, err
+		}
+		select {
+		case <-r.ctx.Done():
+			return 0, r.ctx.Err()
+		case part, ok := <-r.parts:
+			if !ok {
+				return 0, io.EOF
+			}
+			r.cur = io.NewSectionReader(part.file, part.offset, part.size)
+		}
+	}
+}
+
+// extractRarStream extracts the stored RAR set starting at first, asking next
+// for each following volume only once the members before it are written.
+func extractRarStream(ctx context.Context, first string, next func(path string) (string, error), password string, sink *extractSink) error {
+	walkCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var files []*os.File
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+
+	entries := make(chan *rarStreamEntry)
+	walkErr := make(chan error, 1)
+	go func() {
+		defer close(entries)
+		err := walkRarStream(walkCtx, first, next, password, &files, entries)
+		if err != nil {
+			// unblock a writer still waiting on the member's remaining parts.
+			cancel()
+		}
+		walkErr <- err
+	}()
+
+	var err error
+	for e := range entries {
+		if err != nil {
+			continue
+		}
+		err = writeRarStreamEntry(walkCtx, e, sink)
+		if err != nil {
+			cancel()
+		}
+	}
+	// whichever side failed first cancelled the other; report the real cause.
+	if werr := <-walkErr; err == nil || (werr != nil && !errors.Is(werr, context.Canceled)) {
+		err = werr
+	}
+	return err
+}
+
+func writeRarStreamEntry(ctx context.Context, e *rarStreamEntry, sink *extractSink) error {
+	rest := &rarPartStream{ctx: ctx, parts: e.parts, discard: true}
+	switch {
+	case e.skip:
+		_, err := rest.Read(nil)
+		return ignoreEOF(err)
+	case e.dir:
+		return sink.mkdir(e.name)
+	case !e.stored:
+		return fmt.Errorf("%s: %w: compressed RAR data", e.name, ErrUnsupportedArchive)
+	case e.encrypted && e.key == nil:
+		return fmt.Errorf("%s: %w", e.name, ErrArchivePassword)
+	}
+
+	// cipher padding may trail the contents; the remaining parts are drained
+	// so the final one, which carries the member's CRC, has arrived before
+	// verify runs.
+	data := io.MultiReader(e.decode(&rarPartStream{ctx: ctx, parts: e.parts}), rest)
+	err := sink.writeFile(e.name, data, func(crc uint32) bool {
+		return e.verify() == nil || e.verify()(crc)
+	})
+	if e.encrypted && errors.Is(err, errArchiveChecksum) {
+		err = fmt.Errorf("%s: %w", e.name, ErrArchivePassword)
+	}
+	return err
+}
+
+// walkRarStream parses the set one volume at a time, sending each member
+// before its data and each part of the data as its volume is read.
+func walkRarStream(ctx context.Context, path string, next func(string) (string, error), password string, files *[]*os.File, entries chan<- *rarStreamEntry) error {
+	keys := &rarKeyCache{password: password}
+	var open *rarStreamEntry // member continuing into the next volume
+
+	send := func(e *rarStreamEntry, parts []rarPart) error {
+		for _, part := range parts {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case e.parts <- part:
+			}
+		}
+		if !e.splitAfter {
+			close(e.parts)
+		}
+		return nil
+	}
+
+	for {
+		f, vol, err := readRarVolume(path, keys)
+		if f != nil {
+			*files = append(*files, f)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, e := range vol.entries {
+			if e.splitBefore {
+				if open == nil || open.name != e.name {
+					return fmt.Errorf("%s: %s starts in an earlier volume", filepath.Base(path), e.name)
+				}
+				// only the final part's checksum covers the whole member.
+				open.crc, open.hasCRC, open.splitAfter = e.crc, e.hasCRC, e.splitAfter
+				if err := send(open, e.parts); err != nil {
+					return err
+				}
+				if !e.splitAfter {
+					open = nil
+				}
+				continue
+			}
+			if open != nil {
+				return fmt.Errorf("%s: missing next RAR volume", open.name)
+			}
+
+			se := &rarStreamEntry{rarEntry: e, parts: make(chan rarPart, 1)}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case entries <- se:
+			}
+			if err := send(se, e.parts); err != nil {
+				return err
+			}
+			if e.splitAfter {
+				open = se
+			}
+		}
+
+		if !vol.hasNext() {
+			break
+		}
+		path, err = next(vol.nextName(path))
+		if err != nil {
+			return err
+		}
+	}
+
+	if open != nil {
+		return fmt.Errorf("%s: missing next RAR volume", open.name)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package processor
+
+import (
+	"bytes"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/datallboy/gonzb/internal/app"
+	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/infra/config"
+	"github.com/datallboy/gonzb/internal/infra/logger"
+)
+
+func newDirectUnpackTestProcessor(t *testing.T, workRoot string) *Processor {
+	t.Helper()
+
+	log, err := logger.New("none", logger.LevelError, false)
+	if err != nil {
+		t.Fatalf("logger.New() error = %v", err)
+	}
+	return New(&app.Context{
+		Config: &config.Config{
+			Download: config.DownloadConfig{
+				OutDir:       filepath.Join(workRoot, "work"),
+				CompletedDir: filepath.Join(workRoot, "completed"),
+				DirectUnpack: true,
+				Categories:   []config.CategoryConfig{{Name: "movies", PostProcessing: config.PostProcessUnpack}},
+			},
+		},
+		Logger:            log,
+		ExtractionEnabled: true,
+	}, nil)
+}
+
+// writeTwoVolumeSet writes a stored RAR5 set whose movie spans both volumes.
+func writeTwoVolumeSet(t *testing.T, dir string) (movie []byte, tasks []*domain.DownloadFile) {
+	t.Helper()
+
+	movie = bytes.Repeat([]byte("0123456789"), 10)
+	part1 := newRar5Archive(0x1 | 0x2)
+	part1.file("movie.mkv", movie[:60], len(movie), crc32.ChecksumIEEE(movie[:60]), 0x10, nil)
+	part1.end(true)
+	part2 := newRar5Archive(0x1 | 0x2)
+	part2.file("movie.mkv", movie[60:], len(movie), crc32.ChecksumIEEE(movie), 0x08, nil)
+	part2.end(false)
+
+	for i, data := range [][]byte{part1.Bytes(), part2.Bytes()} {
+		name := []string{"set.part1.rar", "set.part2.rar"}[i]
+		writeFile(t, filepath.Join(dir, name), data)
+		tasks = append(tasks, &domain.DownloadFile{FileName: name, FinalPath: filepath.Join(dir, name)})
+	}
+	return movie, tasks
+}
+
+func TestDirectUnpackExtractsVolumesAsTheyArrive(t *testing.T) {
+	workRoot := t.TempDir()
+	dir := filepath.Join(workRoot, "work", "job")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	movie, tasks := writeTwoVolumeSet(t, dir)
+	item := &domain.QueueItem{ID: "job", Release: &domain.Release{Category: "movies"}, Tasks: tasks}
+
+	p := newDirectUnpackTestProcessor(t, workRoot)
+	d := p.StartDirectUnpack(t.Context(), item)
+	if d == nil {
+		t.Fatal("expected direct unpack to start for a RAR set")
+	}
+
+	d.VolumeDone(tasks[0])
+	select {
+	case <-d.done:
+		t.Fatal("expected direct unpack to wait for the second volume")
+	default:
+	}
+	d.VolumeDone(tasks[1])
+
+	direct := p.directUnpacked(t.Context(), item)
+	assertExtracted(t, dir, direct[tasks[0]], map[string]string{"movie.mkv": string(movie)})
+}
+
+func TestDirectUnpackLeavesIncompleteSetToPostProcessing(t *testing.T) {
+	workRoot := t.TempDir()
+	dir := filepath.Join(workRoot, "work", "job")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	_, tasks := writeTwoVolumeSet(t, dir)
+	item := &domain.QueueItem{ID: "job", Release: &domain.Release{Category: "movies"}, Tasks: tasks}
+
+	p := newDirectUnpackTestProcessor(t, workRoot)
+	d := p.StartDirectUnpack(t.Context(), item)
+	d.VolumeDone(tasks[0])
+	d.DownloadDone()
+
+	if direct := p.directUnpacked(t.Context(), item); len(direct) != 0 {
+		t.Fatalf("expected no direct results without the second volume, got %v", direct)
+	}
+}
+
+func TestStartDirectUnpackRequiresUnpackingCategory(t *testing.T) {
+	workRoot := t.TempDir()
+	p := newDirectUnpackTestProcessor(t, workRoot)
+	tasks := []*domain.DownloadFile{{FileName: "set.part1.rar"}}
+
+	p.ctx.Config.Download.Categories[0].PostProcessing = config.PostProcessRepair
+	item := &domain.QueueItem{ID: "job", Release: &domain.Release{Category: "movies"}, Tasks: tasks}
+	if d := p.StartDirectUnpack(t.Context(), item); d != nil {
+		d.Abort()
+		t.Fatal("expected no direct unpack for a repair-only category")
+	}
+
+	p.ctx.Config.Download.Categories[0].PostProcessing = config.PostProcessUnpack
+	item.Tasks = []*domain.DownloadFile{{FileName: "movie.mkv"}}
+	if d := p.StartDirectUnpack(t.Context(), item); d != nil {
+		d.Abort()
+		t.Fatal("expected no direct unpack without a RAR set")
+	}
+}
+
+func TestArchiveVolumeNumbersSets(t *testing.T) {
+	cases := []struct {
+		name   string
+		set    string
+		number int
+		ok     bool
+	}{
+		{"Show.S01.part01.rar", "show.s01", 1, true},
+		{"Show.S01.PART12.RAR", "show.s01", 12, true},
+		{"movie.rar", "movie", 0, true},
+		{"movie.r00", "movie", 1, true},
+		{"movie.s02", "movie", 103, true},
+		{"backup.7z.003", "backup.7z", 3, true},
+		{"movie.mkv", "", 0, false},
+	}
+	for _, tc := range cases {
+		set, number, ok := ArchiveVolume(tc.name)
+		if set != tc.set || number != tc.number || ok != tc.ok {
+			t.Errorf("ArchiveVolume(%q) = %q, %d, %v; want %q, %d, %v", tc.name, set, number, ok, tc.set, tc.number, tc.ok)
+		}
+	}
+}
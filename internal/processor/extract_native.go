@@ -0,0 +1,340 @@
+package processor
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/datallboy/gonzb/internal/domain"
+)
+
+var (
+	// ErrUnsupportedArchive is returned by the native extractors for archive
+	// features they do not implement, e.g. compressed RAR data. The manager
+	// retries such archives with the CLI tool when one is installed.
+	ErrUnsupportedArchive = errors.New("unsupported archive format")
+
+	// ErrArchivePassword is returned when an encrypted archive has no password
+	// or the password does not match.
+	ErrArchivePassword = errors.New("archive password missing or incorrect")
+
+	errArchiveChecksum = errors.New("checksum mismatch")
+)
+
+const extractEventStage = "extract"
+
+// progressEvery is how many bytes a native extractor writes between progress
+// callbacks inside a single large member.
+const progressEvery = 64 << 20
+
+// ExtractProgress is emitted by native extractors as archive members are written.
+type ExtractProgress struct {
+	Archive    string `json:"archive"`
+	File       string `json:"file"`
+	Files      int    `json:"files"` // members finished so far
+	TotalFiles int    `json:"total_files"`
+	Done       int64  `json:"done"` // bytes written across the archive
+	Total      int64  `json:"total"`
+}
+
+// ProgressExtractor is an Extractor that reports per-file progress.
+type ProgressExtractor interface {
+	Extractor
+	ExtractWithProgress(ctx context.Context, archivePath, destDir, password string, onProgress func(ExtractProgress)) ([]string, error)
+}
+
+// fallbackExtractor runs the native extractor and hands archives it cannot
+// decode to the CLI tool for the same format, when one is installed.
+type fallbackExtractor struct {
+	native ProgressExtractor
+	cli    Extractor
+	tool   string // binary named in the error when cli is missing
+}
+
+func (f *fallbackExtractor) Name() string {
+	return f.native.Name()
+}
+
+func (f *fallbackExtractor) CanExtract(filePath string) (bool, error) {
+	return f.native.CanExtract(filePath)
+}
+
+func (f *fallbackExtractor) Extract(ctx context.Context, archivePath, destDir, password string) ([]string, error) {
+	return f.ExtractWithProgress(ctx, archivePath, destDir, password, nil)
+}
+
+func (f *fallbackExtractor) ExtractWithProgress(ctx context.Context, archivePath, destDir, password string, onProgress func(ExtractProgress)) ([]string, error) {
+	files, err := f.native.ExtractWithProgress(ctx, archivePath, destDir, password, onProgress)
+	if errors.Is(err, ErrUnsupportedArchive) {
+		if f.cli != nil {
+			return f.cli.Extract(ctx, archivePath, destDir, password)
+		}
+		if f.tool != "" {
+			err = fmt.Errorf("%w (install %s to extract it)", err, f.tool)
+		}
+	}
+	return files, err
+}
+
+// extractOne runs a single archive through its extractor, turning native
+// progress callbacks into queue events.
+func (p *Processor) extractOne(ctx context.Context, item *domain.QueueItem, archive Extractor, archivePath, destDir, password string) ([]string, error) {
+	pe, ok := archive.(ProgressExtractor)
+	if !ok {
+		return archive.Extract(ctx, archivePath, destDir, password)
+	}
+
+	lastFiles, lastStep := -1, int64(-1)
+	onProgress := func(progress ExtractProgress) {
+		// one event per finished member, plus one per 10% inside large ones.
+		step := int64(0)
+		if progress.Total > 0 {
+			step = progress.Done * 10 / progress.Total
+		}
+		if progress.Files == lastFiles && step == lastStep {
+			return
+		}
+		lastFiles, lastStep = progress.Files, step
+		p.recordEvent(ctx, item, extractEventStage, "progress",
+			fmt.Sprintf("%s: %d/%d files (%s)", progress.Archive, progress.Files, progress.TotalFiles, progress.File),
+			progress)
+	}
+	return pe.ExtractWithProgress(ctx, archivePath, destDir, password, onProgress)
+}
+
+// extractSink writes archive members into a private work dir so a failed
+// extraction never leaves partial files next to the download.
+type extractSink struct {
+	ctx        context.Context
+	workDir    string
+	onProgress func(ExtractProgress)
+	progress   ExtractProgress
+	lastEmit   int64
+}
+
+// runNativeExtract gives fn a sink rooted in a fresh work dir under destDir
+// and moves the result into destDir once fn succeeds.
+func runNativeExtract(ctx context.Context, archivePath, destDir string, onProgress func(ExtractProgress), fn func(*extractSink) error) ([]string, error) {
+	workDir, err := os.MkdirTemp(destDir, "_extracted_*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create extraction workdir: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	sink := &extractSink{
+		ctx:        ctx,
+		workDir:    workDir,
+		onProgress: onProgress,
+		progress:   ExtractProgress{Archive: filepath.Base(archivePath)},
+	}
+	if err := fn(sink); err != nil {
+		return nil, err
+	}
+	return collectExtracted(ctx, workDir, destDir)
+}
+
+func (s *extractSink) setTotals(files int, bytes int64) {
+	s.progress.TotalFiles = files
+	s.progress.Total = bytes
+}
+
+// entryPath maps an archive member name onto the work dir, rejecting
+// absolute paths and anything that escapes it.
+func (s *extractSink) entryPath(name string) (string, error) {
+	clean := path.Clean("/" + strings.ReplaceAll(name, "\\", "/"))
+	if clean == "/" {
+		return "", fmt.Errorf("invalid archive member name %q", name)
+	}
+	rel := filepath.FromSlash(strings.TrimPrefix(clean, "/"))
+	if filepath.IsAbs(rel) || filepath.VolumeName(rel) != "" {
+		return "", fmt.Errorf("invalid archive member name %q", name)
+	}
+	return filepath.Join(s.workDir, rel), nil
+}
+
+func (s *extractSink) mkdir(name string) error {
+	dir, err := s.entryPath(name)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(dir, 0755)
+}
+
+// writeFile streams r into the member's path. A non-nil verify is given the
+// CRC32 of the written bytes and fails the member when it returns false.
+func (s *extractSink) writeFile(name string, r io.Reader, verify func(crc uint32) bool) error {
+	dest, err := s.entryPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+
+	s.progress.File = name
+	hash := crc32.NewIEEE()
+	_, err = io.Copy(io.MultiWriter(out, hash, progressWriter{s}), ctxReader{ctx: s.ctx, r: r})
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if verify != nil && !verify(hash.Sum32()) {
+		return fmt.Errorf("%s: %w", name, errArchiveChecksum)
+	}
+
+	s.progress.Files++
+	s.emit()
+	return nil
+}
+
+// crcEquals is the usual verify func for writeFile.
+func crcEquals(want uint32) func(uint32) bool {
+	return func(got uint32) bool { return got == want }
+}
+
+func (s *extractSink) emit() {
+	s.lastEmit = s.progress.Done
+	if s.onProgress != nil {
+		s.onProgress(s.progress)
+	}
+}
+
+type progressWriter struct{ s *extractSink }
+
+func (w progressWriter) Write(p []byte) (int, error) {
+	w.s.progress.Done += int64(len(p))
+	if w.s.progress.Done-w.s.lastEmit >= progressEvery {
+		w.s.emit()
+	}
+	return len(p), nil
+}
+
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// findVolume resolves a volume name case-insensitively, since posters mix
+// .RAR and .rar within one set.
+func findVolume(path string) (string, bool) {
+	if path == "" {
+		return "", false
+	}
+	if _, err := os.Stat(path); err == nil {
+		return path, true
+	}
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", false
+	}
+	for _, e := range entries {
+		if !e.IsDir() && strings.EqualFold(e.Name(), base) {
+			return filepath.Join(dir, e.Name()), true
+		}
+	}
+	return "", false
+}
+
+// utf16LE encodes a password the way RAR3 and 7z feed it to their KDFs.
+func utf16LE(s string) []byte {
+	var out []byte
+	for _, r := range utf16.Encode([]rune(s)) {
+		out = binary.LittleEndian.AppendUint16(out, r)
+	}
+	return out
+}
+
+// multiFile is one logical stream stored across several files, e.g. the
+// .7z.001, .7z.002 ... volumes of a split archive.
+type multiFile struct {
+	files []*os.File
+	sizes []int64
+	size  int64
+}
+
+func openMultiFile(paths []string) (*multiFile, error) {
+	m := &multiFile{}
+	for _, p := range paths {
+		f, err := os.Open(p)
+		if err != nil {
+			m.Close()
+			return nil, err
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			m.Close()
+			return nil, err
+		}
+		m.files = append(m.files, f)
+		m.sizes = append(m.sizes, info.Size())
+		m.size += info.Size()
+	}
+	return m, nil
+}
+
+func (m *multiFile) ReadAt(p []byte, off int64) (int, error) {
+	read := 0
+	for i, f := range m.files {
+		if off >= m.sizes[i] {
+			off -= m.sizes[i]
+			continue
+		}
+		for read < len(p) && off < m.sizes[i] {
+			n, err := f.ReadAt(p[read:], off)
+			read += n
+			off += int64(n)
+			if err != nil && err != io.EOF {
+				return read, err
+			}
+			if n == 0 {
+				break
+			}
+		}
+		if read == len(p) {
+			return read, nil
+		}
+		off = 0
+	}
+	return read, io.EOF
+}
+
+func (m *multiFile) Size() int64 {
+	return m.size
+}
+
+func (m *multiFile) Close() error {
+	var firstErr error
+	for _, f := range m.files {
+		if err := f.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -18,21 +18,24 @@ func NewManager() *Manager {
 		extractors: make([]Extractor, 0),
 	}
 
-	// Try to initialize each extractor
-	// If the binary isn't available, skip it
-
+	// Native extractors always exist. When the matching binary is installed
+	// it takes over archives the native code cannot decode.
+	rar := &fallbackExtractor{native: NewNativeRar(), tool: "unrar"}
 	if unrar, err := NewCLIUnrar(); err == nil {
-		m.extractors = append(m.extractors, unrar)
+		rar.cli = unrar
 	}
 
+	zip := &fallbackExtractor{native: NewNativeZip(), tool: "unzip"}
 	if unzip, err := NewCLIUnzip(); err == nil {
-		m.extractors = append(m.extractors, unzip)
+		zip.cli = unzip
 	}
 
-	if sevenZ, err := NewCLI7z(); err == nil {
-		m.extractors = append(m.extractors, sevenZ)
+	sevenZ := &fallbackExtractor{native: NewNativeSevenZip(), tool: "7z"}
+	if cli7z, err := NewCLI7z(); err == nil {
+		sevenZ.cli = cli7z
 	}
 
+	m.extractors = append(m.extractors, rar, zip, sevenZ)
 	return m
 }
 
@@ -37,12 +37,21 @@ func baseExtract(ctx context.Context, archivePath, destDir string, factory CmdFa
 	cmd := factory(workDir)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
+		if isCLIPasswordFailure(output) {
+			return nil, fmt.Errorf("extraction failed: %w", ErrArchivePassword)
+		}
 		return nil, fmt.Errorf("extraction failed: %w\nOutput: %s", err, string(output))
 	}
 
+	return collectExtracted(ctx, workDir, destDir)
+}
+
+// collectExtracted moves everything under workDir into destDir, keeping
+// relative paths, and returns the final file paths.
+func collectExtracted(ctx context.Context, workDir, destDir string) ([]string, error) {
 	// Recursive walk and move extracted files
 	var finalPaths []string
-	err = filepath.WalkDir(workDir, func(path string, d os.DirEntry, err error) error {
+	err := filepath.WalkDir(workDir, func(path string, d os.DirEntry, err error) error {
 
 		select {
 		case <-ctx.Done():
@@ -0,0 +1,606 @@
+package processor
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// A small LZMA/LZMA2 decoder for 7z folders, following the reference
+// decoder in the LZMA SDK (LzmaSpec.cpp). Output is pulled: each Read
+// decodes symbols into the dictionary until a batch of bytes is pending.
+
+const (
+	lzmaProbBits      = 11
+	lzmaProbInit      = 1 << lzmaProbBits / 2
+	lzmaMoveBits      = 5
+	lzmaTopValue      = 1 << 24
+	lzmaStates        = 12
+	lzmaPosBitsMax    = 4
+	lzmaEndPosModel   = 14
+	lzmaFullDistances = 1 << (lzmaEndPosModel >> 1)
+	lzmaAlignBits     = 4
+	lzmaMatchMinLen   = 2
+	lzmaMinWindow     = 1 << 12
+	lzmaBatch         = 32 * 1024
+)
+
+var errLZMAData = errors.New("corrupt LZMA data")
+
+type lzmaRangeDecoder struct {
+	r    io.ByteReader
+	rng  uint32
+	code uint32
+	err  error
+}
+
+func (rc *lzmaRangeDecoder) init(r io.ByteReader) error {
+	rc.r, rc.rng, rc.code, rc.err = r, 0xFFFFFFFF, 0, nil
+	first := rc.readByte()
+	for i := 0; i < 4; i++ {
+		rc.code = rc.code<<8 | uint32(rc.readByte())
+	}
+	if rc.err != nil {
+		return rc.err
+	}
+	if first != 0 || rc.code == rc.rng {
+		return errLZMAData
+	}
+	return nil
+}
+
+func (rc *lzmaRangeDecoder) readByte() byte {
+	b, err := rc.r.ReadByte()
+	if err != nil && rc.err == nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		rc.err = err
+	}
+	return b
+}
+
+func (rc *lzmaRangeDecoder) normalize() {
+	if rc.rng < lzmaTopValue {
+		rc.rng <<= 8
+		rc.code = rc.code<<8 | uint32(rc.readByte())
+	}
+}
+
+func (rc *lzmaRangeDecoder) bit(prob *uint16) uint32 {
+	v := uint32(*prob)
+	bound := (rc.rng >> lzmaProbBits) * v
+	var symbol uint32
+	if rc.code < bound {
+		v += (1<<lzmaProbBits - v) >> lzmaMoveBits
+		rc.rng = bound
+	} else {
+		v -= v >> lzmaMoveBits
+		rc.code -= bound
+		rc.rng -= bound
+		symbol = 1
+	}
+	*prob = uint16(v)
+	rc.normalize()
+	return symbol
+}
+
+func (rc *lzmaRangeDecoder) directBits(n uint) uint32 {
+	var res uint32
+	for ; n > 0; n-- {
+		rc.rng >>= 1
+		rc.code -= rc.rng
+		t := 0 - (rc.code >> 31)
+		rc.code += rc.rng & t
+		if rc.code == rc.rng && rc.err == nil {
+			rc.err = errLZMAData
+		}
+		rc.normalize()
+		res = res<<1 + t + 1
+	}
+	return res
+}
+
+func (rc *lzmaRangeDecoder) bitTree(probs []uint16, numBits uint) uint32 {
+	m := uint32(1)
+	for i := uint(0); i < numBits; i++ {
+		m = m<<1 + rc.bit(&probs[m])
+	}
+	return m - 1<<numBits
+}
+
+func (rc *lzmaRangeDecoder) reverseBitTree(probs []uint16, numBits uint) uint32 {
+	m := uint32(1)
+	var symbol uint32
+	for i := uint(0); i < numBits; i++ {
+		bit := rc.bit(&probs[m])
+		m = m<<1 + bit
+		symbol |= bit << i
+	}
+	return symbol
+}
+
+type lzmaLenDecoder struct {
+	choice  uint16
+	choice2 uint16
+	low     [1 << lzmaPosBitsMax][1 << 3]uint16
+	mid     [1 << lzmaPosBitsMax][1 << 3]uint16
+	high    [1 << 8]uint16
+}
+
+func (l *lzmaLenDecoder) reset() {
+	l.choice, l.choice2 = lzmaProbInit, lzmaProbInit
+	for i := range l.low {
+		fillProbs(l.low[i][:])
+		fillProbs(l.mid[i][:])
+	}
+	fillProbs(l.high[:])
+}
+
+func (l *lzmaLenDecoder) decode(rc *lzmaRangeDecoder, posState uint32) uint32 {
+	if rc.bit(&l.choice) == 0 {
+		return rc.bitTree(l.low[posState][:], 3)
+	}
+	if rc.bit(&l.choice2) == 0 {
+		return 8 + rc.bitTree(l.mid[posState][:], 3)
+	}
+	return 16 + rc.bitTree(l.high[:], 8)
+}
+
+func fillProbs(probs []uint16) {
+	for i := range probs {
+		probs[i] = lzmaProbInit
+	}
+}
+
+// lzmaWindow is the sliding dictionary; bytes written to it are also
+// queued in out until the reader hands them on.
+type lzmaWindow struct {
+	buf    []byte
+	pos    int
+	full   bool
+	total  uint64
+	out    []byte
+	outPos int
+}
+
+func (w *lzmaWindow) reset() {
+	w.pos, w.full, w.total = 0, false, 0
+}
+
+func (w *lzmaWindow) put(b byte) {
+	w.buf[w.pos] = b
+	w.out = append(w.out, b)
+	w.total++
+	if w.pos++; w.pos == len(w.buf) {
+		w.pos, w.full = 0, true
+	}
+}
+
+// get returns the byte dist positions back; dist 1 is the last byte written.
+func (w *lzmaWindow) get(dist uint32) byte {
+	i := w.pos - int(dist)
+	if i < 0 {
+		i += len(w.buf)
+	}
+	return w.buf[i]
+}
+
+// hasDistance reports whether a match distance of rep+1 is inside the window.
+func (w *lzmaWindow) hasDistance(rep uint32) bool {
+	return int64(rep) < int64(w.pos) || (w.full && int64(rep) < int64(len(w.buf)))
+}
+
+func (w *lzmaWindow) copyMatch(dist uint32, n int) {
+	for ; n > 0; n-- {
+		w.put(w.get(dist))
+	}
+}
+
+func (w *lzmaWindow) pending() int {
+	return len(w.out) - w.outPos
+}
+
+func (w *lzmaWindow) read(p []byte) int {
+	n := copy(p, w.out[w.outPos:])
+	w.outPos += n
+	if w.outPos == len(w.out) {
+		w.out, w.outPos = w.out[:0], 0
+	}
+	return n
+}
+
+type lzmaDecoder struct {
+	rc         lzmaRangeDecoder
+	win        *lzmaWindow
+	lc, lp, pb uint
+
+	literal     []uint16
+	posSlot     [4][1 << 6]uint16
+	posDecoders [1 + lzmaFullDistances - lzmaEndPosModel]uint16
+	align       [1 << lzmaAlignBits]uint16
+	lenDec      lzmaLenDecoder
+	repLenDec   lzmaLenDecoder
+	isMatch     [lzmaStates << lzmaPosBitsMax]uint16
+	isRep       [lzmaStates]uint16
+	isRepG0     [lzmaStates]uint16
+	isRepG1     [lzmaStates]uint16
+	isRepG2     [lzmaStates]uint16
+	isRep0Long  [lzmaStates << lzmaPosBitsMax]uint16
+	state       uint32
+	rep         [4]uint32
+}
+
+// lzmaWindowSize caps the dictionary at the output size; small files often
+// declare dictionaries far larger than themselves.
+func lzmaWindowSize(dictSize uint32, unpackSize uint64) int {
+	size := uint64(dictSize)
+	if unpackSize < size {
+		size = unpackSize
+	}
+	return int(max(size, lzmaMinWindow))
+}
+
+func newLZMADecoder(windowSize int) *lzmaDecoder {
+	return &lzmaDecoder{win: &lzmaWindow{buf: make([]byte, windowSize)}}
+}
+
+func (d *lzmaDecoder) setProps(b byte) error {
+	if b >= 9*5*5 {
+		return fmt.Errorf("%w: bad properties", errLZMAData)
+	}
+	d.lc, d.lp, d.pb = uint(b%9), uint(b/9%5), uint(b/45)
+	return nil
+}
+
+func (d *lzmaDecoder) resetState() {
+	size := 0x300 << (d.lc + d.lp)
+	if cap(d.literal) >= size {
+		d.literal = d.literal[:size]
+	} else {
+		d.literal = make([]uint16, size)
+	}
+	fillProbs(d.literal)
+	for i := range d.posSlot {
+		fillProbs(d.posSlot[i][:])
+	}
+	fillProbs(d.posDecoders[:])
+	fillProbs(d.align[:])
+	d.lenDec.reset()
+	d.repLenDec.reset()
+	fillProbs(d.isMatch[:])
+	fillProbs(d.isRep[:])
+	fillProbs(d.isRepG0[:])
+	fillProbs(d.isRepG1[:])
+	fillProbs(d.isRepG2[:])
+	fillProbs(d.isRep0Long[:])
+	d.state = 0
+	d.rep = [4]uint32{}
+}
+
+func (d *lzmaDecoder) decodeLiteral() {
+	w := d.win
+	var prev byte
+	if w.total > 0 {
+		prev = w.get(1)
+	}
+	litState := (uint32(w.total)&(1<<d.lp-1))<<d.lc + uint32(prev)>>(8-d.lc)
+	probs := d.literal[0x300*litState:][:0x300]
+
+	symbol := uint32(1)
+	if d.state >= 7 {
+		matchByte := uint32(w.get(d.rep[0] + 1))
+		for symbol < 0x100 {
+			matchBit := (matchByte >> 7) & 1
+			matchByte <<= 1
+			bit := d.rc.bit(&probs[(1+matchBit)<<8+symbol])
+			symbol = symbol<<1 | bit
+			if matchBit != bit {
+				break
+			}
+		}
+	}
+	for symbol < 0x100 {
+		symbol = symbol<<1 | d.rc.bit(&probs[symbol])
+	}
+	w.put(byte(symbol))
+}
+
+func (d *lzmaDecoder) decodeDistance(length uint32) uint32 {
+	posSlot := d.rc.bitTree(d.posSlot[min(length, 3)][:], 6)
+	if posSlot < 4 {
+		return posSlot
+	}
+	numDirectBits := uint(posSlot>>1) - 1
+	dist := (2 | posSlot&1) << numDirectBits
+	if posSlot < lzmaEndPosModel {
+		return dist + d.rc.reverseBitTree(d.posDecoders[dist-posSlot:], numDirectBits)
+	}
+	dist += d.rc.directBits(numDirectBits-lzmaAlignBits) << lzmaAlignBits
+	return dist + d.rc.reverseBitTree(d.align[:], lzmaAlignBits)
+}
+
+// decodeSymbol decodes one literal or match of at most limit bytes and
+// returns how many bytes it produced; end is set on an end marker.
+func (d *lzmaDecoder) decodeSymbol(limit uint64) (n uint64, end bool, err error) {
+	rc, w := &d.rc, d.win
+	posState := uint32(w.total) & (1<<d.pb - 1)
+	s := d.state
+
+	if rc.bit(&d.isMatch[s<<lzmaPosBitsMax+posState]) == 0 {
+		d.decodeLiteral()
+		switch {
+		case s < 4:
+			d.state = 0
+		case s < 10:
+			d.state = s - 3
+		default:
+			d.state = s - 6
+		}
+		return 1, false, rc.err
+	}
+
+	var length uint32
+	if rc.bit(&d.isRep[s]) != 0 {
+		if w.total == 0 {
+			return 0, false, errLZMAData
+		}
+		if rc.bit(&d.isRepG0[s]) == 0 {
+			if rc.bit(&d.isRep0Long[s<<lzmaPosBitsMax+posState]) == 0 {
+				d.state = 11
+				if s < 7 {
+					d.state = 9
+				}
+				w.put(w.get(d.rep[0] + 1))
+				return 1, false, rc.err
+			}
+		} else {
+			var dist uint32
+			if rc.bit(&d.isRepG1[s]) == 0 {
+				dist = d.rep[1]
+			} else {
+				if rc.bit(&d.isRepG2[s]) == 0 {
+					dist = d.rep[2]
+				} else {
+					dist = d.rep[3]
+					d.rep[3] = d.rep[2]
+				}
+				d.rep[2] = d.rep[1]
+			}
+			d.rep[1] = d.rep[0]
+			d.rep[0] = dist
+		}
+		length = d.repLenDec.decode(rc, posState)
+		d.state = 11
+		if s < 7 {
+			d.state = 8
+		}
+	} else {
+		d.rep[3], d.rep[2], d.rep[1] = d.rep[2], d.rep[1], d.rep[0]
+		length = d.lenDec.decode(rc, posState)
+		d.state = 10
+		if s < 7 {
+			d.state = 7
+		}
+		d.rep[0] = d.decodeDistance(length)
+		if d.rep[0] == 0xFFFFFFFF {
+			return 0, true, rc.err
+		}
+		if rc.err == nil && !w.hasDistance(d.rep[0]) {
+			return 0, false, errLZMAData
+		}
+	}
+	if rc.err != nil {
+		return 0, false, rc.err
+	}
+
+	length += lzmaMatchMinLen
+	if uint64(length) > limit {
+		return 0, false, errLZMAData
+	}
+	w.copyMatch(d.rep[0]+1, int(length))
+	return uint64(length), false, nil
+}
+
+func asByteReader(r io.Reader) interface {
+	io.Reader
+	io.ByteReader
+} {
+	if br, ok := r.(interface {
+		io.Reader
+		io.ByteReader
+	}); ok {
+		return br
+	}
+	return bufio.NewReader(r)
+}
+
+// lzmaReader decodes a raw LZMA stream of known size, as stored in 7z.
+type lzmaReader struct {
+	d         *lzmaDecoder
+	remaining uint64
+	err       error
+}
+
+func newLZMAReader(r io.Reader, props []byte, unpackSize uint64) (io.Reader, error) {
+	if len(props) < 5 {
+		return nil, fmt.Errorf("%w: short properties", errLZMAData)
+	}
+	d := newLZMADecoder(lzmaWindowSize(binary.LittleEndian.Uint32(props[1:]), unpackSize))
+	if err := d.setProps(props[0]); err != nil {
+		return nil, err
+	}
+	d.resetState()
+	if err := d.rc.init(asByteReader(r)); err != nil {
+		return nil, err
+	}
+	return &lzmaReader{d: d, remaining: unpackSize}, nil
+}
+
+func (z *lzmaReader) Read(p []byte) (int, error) {
+	w := z.d.win
+	for w.pending() == 0 {
+		if z.err != nil {
+			return 0, z.err
+		}
+		if z.remaining == 0 {
+			z.err = io.EOF
+			continue
+		}
+		for z.remaining > 0 && w.pending() < lzmaBatch {
+			n, end, err := z.d.decodeSymbol(z.remaining)
+			z.remaining -= n
+			if err == nil && end {
+				err = io.ErrUnexpectedEOF
+			}
+			if err != nil {
+				z.err = err
+				break
+			}
+		}
+	}
+	return w.read(p), nil
+}
+
+// lzma2Reader decodes LZMA2: a sequence of LZMA and stored chunks that can
+// reset the state, properties or dictionary between them.
+type lzma2Reader struct {
+	r       io.Reader
+	d       *lzmaDecoder
+	chunk   *io.LimitedReader
+	stored  bool
+	left    uint64 // unpacked bytes left in the current chunk
+	started bool
+	err     error
+}
+
+// lzma2DictSize decodes the one-byte LZMA2 dictionary size property.
+func lzma2DictSize(b byte) (uint32, error) {
+	switch {
+	case b > 40:
+		return 0, fmt.Errorf("%w: bad LZMA2 dictionary size", errLZMAData)
+	case b == 40:
+		return 0xFFFFFFFF, nil
+	default:
+		return (2 | uint32(b)&1) << (b/2 + 11), nil
+	}
+}
+
+func newLZMA2Reader(r io.Reader, props []byte, unpackSize uint64) (io.Reader, error) {
+	if len(props) < 1 {
+		return nil, fmt.Errorf("%w: short properties", errLZMAData)
+	}
+	dictSize, err := lzma2DictSize(props[0])
+	if err != nil {
+		return nil, err
+	}
+	return &lzma2Reader{r: bufio.NewReader(r), d: newLZMADecoder(lzmaWindowSize(dictSize, unpackSize))}, nil
+}
+
+func (z *lzma2Reader) nextChunk() error {
+	if z.chunk != nil {
+		// the range coder may stop short of the chunk's last padding byte.
+		if _, err := io.Copy(io.Discard, z.chunk); err != nil {
+			return err
+		}
+	}
+
+	var head [6]byte
+	if _, err := io.ReadFull(z.r, head[:1]); err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	control := head[0]
+	switch {
+	case control == 0x00:
+		return io.EOF
+	case control == 0x01 || control == 0x02:
+		if _, err := io.ReadFull(z.r, head[1:3]); err != nil {
+			return io.ErrUnexpectedEOF
+		}
+		if control == 0x01 {
+			z.d.win.reset()
+		} else if !z.started {
+			return errLZMAData
+		}
+		z.stored = true
+		z.left = uint64(binary.BigEndian.Uint16(head[1:])) + 1
+		z.chunk = nil
+		z.started = true
+		return nil
+	case control < 0x80:
+		return errLZMAData
+	}
+
+	mode := (control >> 5) & 0x3
+	n := 4
+	if mode >= 2 {
+		n = 5
+	}
+	if _, err := io.ReadFull(z.r, head[1:1+n]); err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	if !z.started && mode != 3 {
+		return errLZMAData
+	}
+	if mode == 3 {
+		z.d.win.reset()
+	}
+	if mode >= 2 {
+		if err := z.d.setProps(head[5]); err != nil {
+			return err
+		}
+		if z.d.lc+z.d.lp > 4 {
+			return errLZMAData
+		}
+	}
+	if mode >= 1 {
+		z.d.resetState()
+	}
+
+	z.left = uint64(control&0x1F)<<16 + uint64(binary.BigEndian.Uint16(head[1:])) + 1
+	z.chunk = &io.LimitedReader{R: z.r, N: int64(binary.BigEndian.Uint16(head[3:])) + 1}
+	z.stored = false
+	z.started = true
+	return z.d.rc.init(bufio.NewReaderSize(z.chunk, 16))
+}
+
+func (z *lzma2Reader) Read(p []byte) (int, error) {
+	w := z.d.win
+	for w.pending() == 0 {
+		if z.err != nil {
+			return 0, z.err
+		}
+		if z.left == 0 {
+			z.err = z.nextChunk()
+			continue
+		}
+
+		if z.stored {
+			buf := make([]byte, min(z.left, lzmaBatch))
+			if _, err := io.ReadFull(z.r, buf); err != nil {
+				z.err = io.ErrUnexpectedEOF
+				continue
+			}
+			for _, b := range buf {
+				w.put(b)
+			}
+			z.left -= uint64(len(buf))
+			continue
+		}
+
+		for z.left > 0 && w.pending() < lzmaBatch {
+			n, end, err := z.d.decodeSymbol(z.left)
+			z.left -= n
+			if err == nil && end {
+				err = errLZMAData
+			}
+			if err != nil {
+				z.err = err
+				break
+			}
+		}
+	}
+	return w.read(p), nil
+}
@@ -0,0 +1,816 @@
+package processor
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestNativeZipExtractsPlainAndEncryptedMembers(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "release.zip")
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("plain/readme.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(strings.Repeat("plain text ", 50)))
+	writeZipCryptoMember(t, zw, "crypto.bin", []byte("zipcrypto payload"), "secret")
+	writeZipAESMember(t, zw, "aes.bin", []byte("winzip aes payload, longer than one block"), "secret")
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, archivePath, buf.Bytes())
+
+	var progress []ExtractProgress
+	files, err := NewNativeZip().ExtractWithProgress(context.Background(), archivePath, dir, "secret", func(p ExtractProgress) {
+		progress = append(progress, p)
+	})
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	assertExtracted(t, dir, files, map[string]string{
+		"plain/readme.txt": strings.Repeat("plain text ", 50),
+		"crypto.bin":       "zipcrypto payload",
+		"aes.bin":          "winzip aes payload, longer than one block",
+	})
+	if len(progress) != 3 || progress[2].Files != 3 || progress[2].TotalFiles != 3 {
+		t.Fatalf("expected one progress event per member, got %+v", progress)
+	}
+
+	_, err = NewNativeZip().Extract(context.Background(), archivePath, t.TempDir(), "wrong")
+	if !errors.Is(err, ErrArchivePassword) {
+		t.Fatalf("expected password error, got %v", err)
+	}
+}
+
+func TestNativeRar5ExtractsMultiVolumeAndEncryptedSets(t *testing.T) {
+	dir := t.TempDir()
+	movie := bytes.Repeat([]byte("0123456789"), 10)
+
+	part1 := newRar5Archive(0x1 | 0x2)
+	part1.file("movie.mkv", movie[:60], len(movie), crc32.ChecksumIEEE(movie[:60]), 0x10, nil)
+	part1.end(true)
+	part2 := newRar5Archive(0x1 | 0x2)
+	part2.file("movie.mkv", movie[60:], len(movie), crc32.ChecksumIEEE(movie), 0x08, nil)
+	part2.dir("extras")
+	part2.file("extras/readme.txt", []byte("hello"), 5, crc32.ChecksumIEEE([]byte("hello")), 0, nil)
+	part2.end(false)
+	writeFile(t, filepath.Join(dir, "set.part1.rar"), part1.Bytes())
+	writeFile(t, filepath.Join(dir, "set.PART2.RAR"), part2.Bytes())
+
+	files, err := NewNativeRar().Extract(context.Background(), filepath.Join(dir, "set.part1.rar"), dir, "")
+	if err != nil {
+		t.Fatalf("extract multi-volume: %v", err)
+	}
+	assertExtracted(t, dir, files, map[string]string{
+		"movie.mkv":         string(movie),
+		"extras/readme.txt": "hello",
+	})
+
+	encDir := t.TempDir()
+	secret := []byte("encrypted rar5 payload spanning a few AES blocks")
+	enc := newRar5Archive(0)
+	enc.file("secret.bin", secret, len(secret), crc32.ChecksumIEEE(secret), 0, &rar5TestCrypt{password: "pw"})
+	enc.end(false)
+	encPath := filepath.Join(encDir, "enc.rar")
+	writeFile(t, encPath, enc.Bytes())
+
+	if _, err := NewNativeRar().Extract(context.Background(), encPath, encDir, "nope"); !errors.Is(err, ErrArchivePassword) {
+		t.Fatalf("expected password error, got %v", err)
+	}
+	files, err = NewNativeRar().Extract(context.Background(), encPath, encDir, "pw")
+	if err != nil {
+		t.Fatalf("extract encrypted: %v", err)
+	}
+	assertExtracted(t, encDir, files, map[string]string{"secret.bin": string(secret)})
+}
+
+func TestNativeRar5ReadsEncryptedHeaders(t *testing.T) {
+	dir := t.TempDir()
+	payload := []byte("payload behind encrypted headers")
+
+	archive := newRar5Archive(0)
+	archive.encryptHeaders("hp")
+	archive.file("hidden.txt", payload, len(payload), crc32.ChecksumIEEE(payload), 0, &rar5TestCrypt{password: "hp"})
+	archive.end(false)
+	path := filepath.Join(dir, "hidden.rar")
+	writeFile(t, path, archive.Bytes())
+
+	if _, err := NewNativeRar().Extract(context.Background(), path, dir, ""); !errors.Is(err, ErrArchivePassword) {
+		t.Fatalf("expected password error without a password, got %v", err)
+	}
+	files, err := NewNativeRar().Extract(context.Background(), path, dir, "hp")
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	assertExtracted(t, dir, files, map[string]string{"hidden.txt": string(payload)})
+}
+
+func TestNativeRar4ExtractsOldStyleVolumesAndEncryption(t *testing.T) {
+	dir := t.TempDir()
+	data := bytes.Repeat([]byte("rar4 volume data "), 8)
+
+	first := newRar4Archive(0x0001)
+	first.file("dir\\video.avi", data[:50], len(data), crc32.ChecksumIEEE(data[:50]), 0x0002, "", nil)
+	first.end(true)
+	second := newRar4Archive(0x0001)
+	second.file("dir\\video.avi", data[50:], len(data), crc32.ChecksumIEEE(data), 0x0001, "", nil)
+	second.end(false)
+	writeFile(t, filepath.Join(dir, "old.rar"), first.Bytes())
+	writeFile(t, filepath.Join(dir, "old.r00"), second.Bytes())
+
+	files, err := NewNativeRar().Extract(context.Background(), filepath.Join(dir, "old.rar"), dir, "")
+	if err != nil {
+		t.Fatalf("extract old-style volumes: %v", err)
+	}
+	assertExtracted(t, dir, files, map[string]string{"dir/video.avi": string(data)})
+
+	encDir := t.TempDir()
+	secret := []byte("rar3 aes-128 payload")
+	enc := newRar4Archive(0)
+	enc.file("secret.txt", secret, len(secret), crc32.ChecksumIEEE(secret), 0, "pass", []byte("saltsalt"))
+	enc.end(false)
+	encPath := filepath.Join(encDir, "enc.rar")
+	writeFile(t, encPath, enc.Bytes())
+
+	if _, err := NewNativeRar().Extract(context.Background(), encPath, encDir, "wrong"); !errors.Is(err, ErrArchivePassword) {
+		t.Fatalf("expected password error, got %v", err)
+	}
+	files, err = NewNativeRar().Extract(context.Background(), encPath, encDir, "pass")
+	if err != nil {
+		t.Fatalf("extract encrypted: %v", err)
+	}
+	assertExtracted(t, encDir, files, map[string]string{"secret.txt": string(secret)})
+}
+
+func TestDecodeRar4UnicodeName(t *testing.T) {
+	// 'a' and U+00E9 as single bytes, then copy "b.txt" from the ASCII name.
+	raw := append([]byte("a_b.txt\x00"), 0x00, 0x0C, 'a', 0xE9, 3)
+	if got := decodeRar4Name(raw); got != "aéb.txt" {
+		t.Fatalf("decodeRar4Name() = %q", got)
+	}
+}
+
+func TestNextRarVolumeName(t *testing.T) {
+	cases := map[string]string{
+		"/d/x.part1.rar":   "/d/x.part2.rar",
+		"/d/x.part09.rar":  "/d/x.part10.rar",
+		"/d/x.rar":         "/d/x.r00",
+		"/d/x.r07":         "/d/x.r08",
+		"/d/x.r99":         "/d/x.s00",
+		"/d/opaque-volume": "",
+	}
+	for in, want := range cases {
+		if got := nextRarVolumeName(in, true); got != want {
+			t.Errorf("nextRarVolumeName(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := nextRarVolumeName("/d/x.part1.rar", false); got != "/d/x.part1.r00" {
+		t.Errorf("old numbering should ignore .partN, got %q", got)
+	}
+}
+
+func TestNativeSevenZipExtractsCopyLZMAAndLZMA2Folders(t *testing.T) {
+	dir := t.TempDir()
+	text := lzmaFixtureText()
+	lzma1, _ := hex.DecodeString(lzmaFixtureHex)
+	lzma2, _ := hex.DecodeString(lzma2FixtureHex)
+
+	b := &sevenZipTestArchive{}
+	b.folder([]byte("firstsecond!"), []byte{0x00}, nil, 12, []uint64{5, 7}, []uint32{
+		crc32.ChecksumIEEE([]byte("first")), crc32.ChecksumIEEE([]byte("second!")),
+	})
+	b.folder(lzma1, []byte{0x03, 0x01, 0x01}, []byte{0x5D, 0, 0, 1, 0}, uint64(len(text)), nil,
+		[]uint32{crc32.ChecksumIEEE(text)})
+	b.folder(lzma2, []byte{0x21}, []byte{8}, uint64(len(text)), nil, []uint32{crc32.ChecksumIEEE(text)})
+	b.files = []sevenZipTestFile{
+		{name: "a.txt", stream: true},
+		{name: "dir/b.txt", stream: true},
+		{name: "lzma.txt", stream: true},
+		{name: "lzma2.txt", stream: true},
+		{name: "dir"},
+		{name: "empty.txt", emptyFile: true},
+	}
+	archive := b.build(true)
+
+	// split across two volumes to exercise the .7z.001 reader.
+	writeFile(t, filepath.Join(dir, "set.7z.001"), archive[:40])
+	writeFile(t, filepath.Join(dir, "set.7z.002"), archive[40:])
+
+	files, err := NewNativeSevenZip().Extract(context.Background(), filepath.Join(dir, "set.7z.001"), dir, "")
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	assertExtracted(t, dir, files, map[string]string{
+		"a.txt":     "first",
+		"dir/b.txt": "second!",
+		"lzma.txt":  string(text),
+		"lzma2.txt": string(text),
+		"empty.txt": "",
+	})
+}
+
+func TestNativeSevenZipDecryptsAES(t *testing.T) {
+	dir := t.TempDir()
+	plain := []byte("7z aes payload that needs padding")
+	salt := []byte("salt1234")
+	iv := bytes.Repeat([]byte{7}, 16)
+
+	padded := append([]byte(nil), plain...)
+	for len(padded)%aes.BlockSize != 0 {
+		padded = append(padded, 0)
+	}
+	block, _ := aes.NewCipher(deriveSevenZipKey("pw", salt, 6))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(padded, padded)
+
+	props := append([]byte{0xC0 | 6, 0x7F}, salt...)
+	props = append(props, iv...)
+	b := &sevenZipTestArchive{}
+	b.folder(padded, []byte{0x06, 0xF1, 0x07, 0x01}, props, uint64(len(plain)), nil,
+		[]uint32{crc32.ChecksumIEEE(plain)})
+	b.files = []sevenZipTestFile{{name: "secret.txt", stream: true}}
+	path := filepath.Join(dir, "enc.7z")
+	writeFile(t, path, b.build(false))
+
+	if _, err := NewNativeSevenZip().Extract(context.Background(), path, dir, "bad"); !errors.Is(err, ErrArchivePassword) {
+		t.Fatalf("expected password error, got %v", err)
+	}
+	files, err := NewNativeSevenZip().Extract(context.Background(), path, dir, "pw")
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	assertExtracted(t, dir, files, map[string]string{"secret.txt": string(plain)})
+}
+
+type stubExtractor struct {
+	calls int
+}
+
+func (s *stubExtractor) Extract(ctx context.Context, archivePath, destDir, password string) ([]string, error) {
+	s.calls++
+	return []string{filepath.Join(destDir, "from-cli")}, nil
+}
+func (s *stubExtractor) CanExtract(string) (bool, error) { return true, nil }
+func (s *stubExtractor) Name() string                    { return "stub" }
+
+func TestFallbackExtractorHandsUnsupportedArchivesToCLI(t *testing.T) {
+	dir := t.TempDir()
+	compressed := newRar5Archive(0)
+	compressed.fileWithCompression("packed.bin", []byte("not really lzss"), 3<<7)
+	compressed.end(false)
+	path := filepath.Join(dir, "packed.rar")
+	writeFile(t, path, compressed.Bytes())
+
+	if _, err := NewNativeRar().Extract(context.Background(), path, dir, ""); !errors.Is(err, ErrUnsupportedArchive) {
+		t.Fatalf("expected unsupported error from the native extractor, got %v", err)
+	}
+
+	cli := &stubExtractor{}
+	files, err := (&fallbackExtractor{native: NewNativeRar(), cli: cli}).Extract(context.Background(), path, dir, "")
+	if err != nil || cli.calls != 1 || len(files) != 1 {
+		t.Fatalf("expected CLI fallback, got files=%v err=%v calls=%d", files, err, cli.calls)
+	}
+	if _, err := (&fallbackExtractor{native: NewNativeRar()}).Extract(context.Background(), path, dir, ""); !errors.Is(err, ErrUnsupportedArchive) {
+		t.Fatalf("expected unsupported error without a CLI, got %v", err)
+	}
+}
+
+// The fixtures hold LZ-compressed members: rar5_compressed.rar came out of
+// rar 5, rar4_compressed.rar is a RAR 2.9 stream verified against rardecode.
+func TestNativeRarReportsCompressedFixturesUnsupported(t *testing.T) {
+	for _, name := range []string{"rar4_compressed.rar", "rar5_compressed.rar"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join("testdata", name)
+			if ok, err := NewNativeRar().CanExtract(path); err != nil || !ok {
+				t.Fatalf("CanExtract() = %v, %v", ok, err)
+			}
+			_, err := (&fallbackExtractor{native: NewNativeRar(), tool: "unrar"}).Extract(context.Background(), path, t.TempDir(), "")
+			if !errors.Is(err, ErrUnsupportedArchive) || !strings.Contains(err.Error(), "install unrar") {
+				t.Fatalf("expected unsupported error naming unrar, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNativeExtractRejectsPathTraversal(t *testing.T) {
+	dir := t.TempDir()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("../../escape.txt")
+	w.Write([]byte("x"))
+	zw.Close()
+	path := filepath.Join(dir, "evil.zip")
+	writeFile(t, path, buf.Bytes())
+
+	dest := filepath.Join(dir, "out")
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files, err := NewNativeZip().Extract(context.Background(), path, dest, "")
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	if len(files) != 1 || files[0] != filepath.Join(dest, "escape.txt") {
+		t.Fatalf("expected member to be confined to dest, got %v", files)
+	}
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func assertExtracted(t *testing.T, destDir string, files []string, want map[string]string) {
+	t.Helper()
+	var got []string
+	for _, f := range files {
+		rel, _ := filepath.Rel(destDir, f)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	var wantNames []string
+	for name := range want {
+		wantNames = append(wantNames, name)
+	}
+	sort.Strings(got)
+	sort.Strings(wantNames)
+	if strings.Join(got, ",") != strings.Join(wantNames, ",") {
+		t.Fatalf("extracted %v, want %v", got, wantNames)
+	}
+	for name, content := range want {
+		raw, err := os.ReadFile(filepath.Join(destDir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		if string(raw) != content {
+			t.Fatalf("%s = %q, want %q", name, raw, content)
+		}
+	}
+}
+
+func writeZipCryptoMember(t *testing.T, zw *zip.Writer, name string, data []byte, password string) {
+	t.Helper()
+	crc := crc32.ChecksumIEEE(data)
+	plain := append(make([]byte, 11), byte(crc>>24))
+	plain = append(plain, data...)
+
+	keys := newZipCryptoKeys(password)
+	enc := make([]byte, len(plain))
+	for i, b := range plain {
+		enc[i] = b ^ keys.stream()
+		keys.update(b)
+	}
+
+	w, err := zw.CreateRaw(&zip.FileHeader{
+		Name:               name,
+		Method:             zip.Store,
+		Flags:              zipFlagEncrypted,
+		CRC32:              crc,
+		CompressedSize64:   uint64(len(enc)),
+		UncompressedSize64: uint64(len(data)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(enc)
+}
+
+func writeZipAESMember(t *testing.T, zw *zip.Writer, name string, data []byte, password string) {
+	t.Helper()
+	salt := bytes.Repeat([]byte{0x42}, 16)
+	derived, err := pbkdf2.Key(sha1.New, password, salt, 1000, 2*32+2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, _ := aes.NewCipher(derived[:32])
+	enc := make([]byte, len(data))
+	var counter, stream [16]byte
+	for i := range data {
+		if i%16 == 0 {
+			binary.LittleEndian.PutUint64(counter[:], uint64(i/16+1))
+			block.Encrypt(stream[:], counter[:])
+		}
+		enc[i] = data[i] ^ stream[i%16]
+	}
+	mac := hmac.New(sha1.New, derived[32:64])
+	mac.Write(enc)
+
+	payload := append(append(append([]byte(nil), salt...), derived[64:]...), enc...)
+	payload = append(payload, mac.Sum(nil)[:10]...)
+
+	extra := []byte{0x01, 0x99, 7, 0, 2, 0, 'A', 'E', 3, 0, 0}
+	w, err := zw.CreateRaw(&zip.FileHeader{
+		Name:               name,
+		Method:             zipMethodWinZipAES,
+		Flags:              zipFlagEncrypted,
+		Extra:              extra,
+		CompressedSize64:   uint64(len(payload)),
+		UncompressedSize64: uint64(len(data)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(payload)
+}
+
+func appendVint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+type rar5TestCrypt struct {
+	password string
+}
+
+// rar5TestArchive writes just enough RAR5 structure for stored members.
+type rar5TestArchive struct {
+	bytes.Buffer
+	headerKey []byte
+}
+
+func newRar5Archive(archiveFlags uint64) *rar5TestArchive {
+	a := &rar5TestArchive{}
+	a.Write(rarSignatures[1])
+	body := appendVint(nil, archiveFlags)
+	if archiveFlags&0x2 != 0 {
+		body = appendVint(body, 0)
+	}
+	a.block(rar5HeadMain, 0, body, nil, nil)
+	return a
+}
+
+func (a *rar5TestArchive) encryptHeaders(password string) {
+	salt := bytes.Repeat([]byte{0x11}, 16)
+	key, _, check := deriveRar5Keys(password, salt, 4)
+	sum := sha256.Sum256(check)
+	body := appendVint(appendVint(nil, 0), 0x1)
+	body = append(body, 4)
+	body = append(body, salt...)
+	body = append(append(body, check...), sum[:4]...)
+
+	// the encryption header itself goes before the main header, in the clear.
+	raw := a.Bytes()[len(rarSignatures[1]):]
+	main := append([]byte(nil), raw...)
+	a.Reset()
+	a.Write(rarSignatures[1])
+	a.block(rar5HeadEncryption, 0, body, nil, nil)
+	a.headerKey = key
+	a.writeHeader(main)
+}
+
+func (a *rar5TestArchive) block(headType, flags uint64, body, extra, data []byte) {
+	h := appendVint(nil, headType)
+	if extra != nil {
+		flags |= rar5FlagExtra
+	}
+	if data != nil {
+		flags |= rar5FlagData
+	}
+	h = appendVint(h, flags)
+	if extra != nil {
+		h = appendVint(h, uint64(len(extra)))
+	}
+	if data != nil {
+		h = appendVint(h, uint64(len(data)))
+	}
+	h = append(append(h, body...), extra...)
+
+	full := appendVint(nil, uint64(len(h)))
+	full = append(full, h...)
+	header := binary.LittleEndian.AppendUint32(nil, crc32.ChecksumIEEE(full))
+	a.writeHeader(append(header, full...))
+	a.Write(data)
+}
+
+func (a *rar5TestArchive) writeHeader(header []byte) {
+	if a.headerKey == nil {
+		a.Write(header)
+		return
+	}
+	iv := bytes.Repeat([]byte{0x22}, 16)
+	padded := append([]byte(nil), header...)
+	for len(padded)%16 != 0 {
+		padded = append(padded, 0)
+	}
+	block, _ := aes.NewCipher(a.headerKey)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(padded, padded)
+	a.Write(iv)
+	a.Write(padded)
+}
+
+func (a *rar5TestArchive) file(name string, data []byte, size int, crc uint32, headFlags uint64, crypt *rar5TestCrypt) {
+	body := appendVint(nil, rar5FileCRC)
+	body = appendVint(body, uint64(size))
+	body = appendVint(body, 0)
+	var extra []byte
+	if crypt != nil {
+		salt := bytes.Repeat([]byte{0x33}, 16)
+		iv := bytes.Repeat([]byte{0x44}, 16)
+		key, hashKey, check := deriveRar5Keys(crypt.password, salt, 4)
+		crc = rar5MACCRC(hashKey, crc)
+		sum := sha256.Sum256(check)
+
+		record := appendVint(appendVint(appendVint(nil, rar5ExtraCrypt), 0), 0x1|0x2)
+		record = append(record, 4)
+		record = append(append(append(record, salt...), iv...), check...)
+		record = append(record, sum[:4]...)
+		extra = append(appendVint(nil, uint64(len(record))), record...)
+
+		padded := append([]byte(nil), data...)
+		for len(padded)%16 != 0 {
+			padded = append(padded, 0)
+		}
+		block, _ := aes.NewCipher(key)
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(padded, padded)
+		data = padded
+	}
+	body = binary.LittleEndian.AppendUint32(body, crc)
+	body = appendVint(body, 0) // stored
+	body = appendVint(body, 0)
+	body = appendVint(body, uint64(len(name)))
+	body = append(body, name...)
+	a.block(rar5HeadFile, headFlags, body, extra, data)
+}
+
+func (a *rar5TestArchive) fileWithCompression(name string, data []byte, compression uint64) {
+	body := appendVint(nil, 0)
+	body = appendVint(body, uint64(len(data)))
+	body = appendVint(body, 0)
+	body = appendVint(body, compression)
+	body = appendVint(body, 0)
+	body = appendVint(body, uint64(len(name)))
+	body = append(body, name...)
+	a.block(rar5HeadFile, 0, body, nil, data)
+}
+
+func (a *rar5TestArchive) dir(name string) {
+	body := appendVint(nil, rar5FileDir)
+	body = appendVint(body, 0)
+	body = appendVint(body, 0)
+	body = appendVint(body, 0)
+	body = appendVint(body, 0)
+	body = appendVint(body, uint64(len(name)))
+	body = append(body, name...)
+	a.block(rar5HeadFile, 0, body, nil, nil)
+}
+
+func (a *rar5TestArchive) end(more bool) {
+	flags := uint64(0)
+	if more {
+		flags = 1
+	}
+	a.block(rar5HeadEnd, 0, appendVint(nil, flags), nil, nil)
+}
+
+type rar4TestArchive struct {
+	bytes.Buffer
+}
+
+func newRar4Archive(mainFlags uint16) *rar4TestArchive {
+	a := &rar4TestArchive{}
+	a.Write(rarSignatures[0])
+	a.block(rar4HeadMain, mainFlags, make([]byte, 6))
+	return a
+}
+
+func (a *rar4TestArchive) block(headType byte, flags uint16, body []byte) {
+	h := []byte{0, 0, headType}
+	h = binary.LittleEndian.AppendUint16(h, flags)
+	h = binary.LittleEndian.AppendUint16(h, uint16(7+len(body)))
+	h = append(h, body...)
+	binary.LittleEndian.PutUint16(h, uint16(crc32.ChecksumIEEE(h[2:])))
+	a.Write(h)
+}
+
+func (a *rar4TestArchive) file(name string, data []byte, size int, crc uint32, flags uint16, password string, salt []byte) {
+	flags |= rar4LongBlock
+	if password != "" {
+		flags |= rar4FilePassword | rar4FileSalt
+		key, iv := deriveRar3Key(password, salt)
+		padded := append([]byte(nil), data...)
+		for len(padded)%16 != 0 {
+			padded = append(padded, 0)
+		}
+		block, _ := aes.NewCipher(key)
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(padded, padded)
+		data = padded
+	}
+
+	body := binary.LittleEndian.AppendUint32(nil, uint32(len(data)))
+	body = binary.LittleEndian.AppendUint32(body, uint32(size))
+	body = append(body, 0)
+	body = binary.LittleEndian.AppendUint32(body, crc)
+	body = binary.LittleEndian.AppendUint32(body, 0)
+	body = append(body, 29, rar4MethodStore)
+	body = binary.LittleEndian.AppendUint16(body, uint16(len(name)))
+	body = binary.LittleEndian.AppendUint32(body, 0)
+	body = append(body, name...)
+	if password != "" {
+		body = append(body, salt...)
+	}
+	a.block(rar4HeadFile, flags, body)
+	a.Write(data)
+}
+
+func (a *rar4TestArchive) end(more bool) {
+	flags := uint16(0)
+	if more {
+		flags = 1
+	}
+	a.block(rar4HeadEnd, flags, nil)
+}
+
+// lzmaFixtureText is the input of the LZMA fixtures below, which were made
+// with liblzma (Python's lzma module, FORMAT_RAW, 64 KiB dictionary).
+func lzmaFixtureText() []byte {
+	var b strings.Builder
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(&b, "GoNZB native 7z line %d: the quick brown fox\n", i)
+	}
+	return []byte(b.String())
+}
+
+const lzmaFixtureHex = "00239bc5c5adc51579ddce1d21aa97d6c43e96d63609c1aacd6e5b8601875148df03e3a10b32ce9de038abc0ac5fad6e89da45247bac57fdf1e046dedf9e0dcc6ab6c3586c0b40ddf594176e8c24880e50971ead32733ecd7ce414fc923c1a5c29386fbb7e8c9662038e7efea7b0e31679585b24ad0eee7323674f19210633b26635a3f81b714c86fb4619b76b59516941fb98168208c1ef3b5e59561040b38fc26abcdc14b35583ae2d80554144a1d010465fd8634dfc5a5ae8e2be80231728da5eeb4fb0e36b37c2ff55574670723203a5dc66b049f2270057b931fc6dd1a55d904017c6302f4f5f46ff295e575048086791888906c56fde07e029ec12bc6f162a728d4914f1ca888e428a98d89ce51a3275bffed18764"
+
+const lzma2FixtureHex = "e0357901125d00239bc5c5adc51579ddce1d21aa97d6c43e96d63609c1aacd6e5b8601875148df03e3a10b32ce9de038abc0ac5fad6e89da45247bac57fdf1e046dedf9e0dcc6ab6c3586c0b40ddf594176e8c24880e50971ead32733ecd7ce414fc923c1a5c29386fbb7e8c9662038e7efea7b0e31679585b24ad0eee7323674f19210633b26635a3f81b714c86fb4619b76b59516941fb98168208c1ef3b5e59561040b38fc26abcdc14b35583ae2d80554144a1d010465fd8634dfc5a5ae8e2be80231728da5eeb4fb0e36b37c2ff55574670723203a5dc66b049f2270057b931fc6dd1a55d904017c6302f4f5f46ff295e575048086791888906c56fde07e029ec12bc6f162a728d4914f1ca888e428a98d89ce089dc0000"
+
+type sevenZipTestFolder struct {
+	packed   []byte
+	method   []byte
+	props    []byte
+	size     uint64
+	subSizes []uint64
+	crcs     []uint32
+}
+
+type sevenZipTestFile struct {
+	name      string
+	stream    bool
+	emptyFile bool
+}
+
+// sevenZipTestArchive writes a 7z container with one single-coder folder per
+// call to folder.
+type sevenZipTestArchive struct {
+	folders []sevenZipTestFolder
+	files   []sevenZipTestFile
+}
+
+func (b *sevenZipTestArchive) folder(packed, method, props []byte, size uint64, subSizes []uint64, crcs []uint32) {
+	b.folders = append(b.folders, sevenZipTestFolder{packed, method, props, size, subSizes, crcs})
+}
+
+func sevenZipNumber(v uint64) []byte {
+	for n := 0; n < 8; n++ {
+		if v < 1<<(7*(n+1)) {
+			first := byte(0xFF<<(8-n)) | byte(v>>(8*n))
+			out := []byte{first}
+			for i := 0; i < n; i++ {
+				out = append(out, byte(v>>(8*i)))
+			}
+			return out
+		}
+	}
+	return append([]byte{0xFF}, binary.LittleEndian.AppendUint64(nil, v)...)
+}
+
+func sevenZipBits(bits []bool) []byte {
+	out := make([]byte, (len(bits)+7)/8)
+	for i, set := range bits {
+		if set {
+			out[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return out
+}
+
+func sevenZipProperty(out []byte, id byte, data []byte) []byte {
+	out = append(out, id)
+	out = append(out, sevenZipNumber(uint64(len(data)))...)
+	return append(out, data...)
+}
+
+func sevenZipFolderRecord(method, props []byte) []byte {
+	flags := byte(len(method))
+	if props != nil {
+		flags |= 0x20
+	}
+	out := append(sevenZipNumber(1), flags)
+	out = append(out, method...)
+	if props != nil {
+		out = append(out, sevenZipNumber(uint64(len(props)))...)
+		out = append(out, props...)
+	}
+	return out
+}
+
+func (b *sevenZipTestArchive) build(encodeHeader bool) []byte {
+	var packed []byte
+	h := []byte{sevenZipHeader, sevenZipMainStreams, sevenZipPackInfo}
+	h = append(h, sevenZipNumber(0)...)
+	h = append(h, sevenZipNumber(uint64(len(b.folders)))...)
+	h = append(h, sevenZipSize)
+	for _, f := range b.folders {
+		packed = append(packed, f.packed...)
+		h = append(h, sevenZipNumber(uint64(len(f.packed)))...)
+	}
+	h = append(h, sevenZipEnd, sevenZipUnpackInfo, sevenZipFolderID)
+	h = append(h, sevenZipNumber(uint64(len(b.folders)))...)
+	h = append(h, 0)
+	for _, f := range b.folders {
+		h = append(h, sevenZipFolderRecord(f.method, f.props)...)
+	}
+	h = append(h, sevenZipCodersUnpack)
+	for _, f := range b.folders {
+		h = append(h, sevenZipNumber(f.size)...)
+	}
+	h = append(h, sevenZipEnd, sevenZipSubStreams, sevenZipNumUnpackStream)
+	for _, f := range b.folders {
+		h = append(h, sevenZipNumber(uint64(max(len(f.subSizes), 1)))...)
+	}
+	h = append(h, sevenZipSize)
+	for _, f := range b.folders {
+		for i := 0; i+1 < len(f.subSizes); i++ {
+			h = append(h, sevenZipNumber(f.subSizes[i])...)
+		}
+	}
+	h = append(h, sevenZipCRC, 1)
+	for _, f := range b.folders {
+		for _, crc := range f.crcs {
+			h = binary.LittleEndian.AppendUint32(h, crc)
+		}
+	}
+	h = append(h, sevenZipEnd, sevenZipEnd)
+
+	h = append(h, sevenZipFilesInfo)
+	h = append(h, sevenZipNumber(uint64(len(b.files)))...)
+	var emptyStream, emptyFile []bool
+	names := []byte{0}
+	for _, f := range b.files {
+		emptyStream = append(emptyStream, !f.stream)
+		if !f.stream {
+			emptyFile = append(emptyFile, f.emptyFile)
+		}
+		names = append(names, utf16LE(f.name)...)
+		names = append(names, 0, 0)
+	}
+	h = sevenZipProperty(h, sevenZipEmptyStream, sevenZipBits(emptyStream))
+	h = sevenZipProperty(h, sevenZipEmptyFile, sevenZipBits(emptyFile))
+	h = sevenZipProperty(h, sevenZipName, names)
+	h = append(h, sevenZipEnd, sevenZipEnd)
+
+	if encodeHeader {
+		pos := uint64(len(packed))
+		packed = append(packed, h...)
+		enc := []byte{sevenZipEncodedHeader, sevenZipPackInfo}
+		enc = append(enc, sevenZipNumber(pos)...)
+		enc = append(enc, sevenZipNumber(1)...)
+		enc = append(enc, sevenZipSize)
+		enc = append(enc, sevenZipNumber(uint64(len(h)))...)
+		enc = append(enc, sevenZipEnd, sevenZipUnpackInfo, sevenZipFolderID)
+		enc = append(enc, sevenZipNumber(1)...)
+		enc = append(enc, 0)
+		enc = append(enc, sevenZipFolderRecord([]byte{0x00}, nil)...)
+		enc = append(enc, sevenZipCodersUnpack)
+		enc = append(enc, sevenZipNumber(uint64(len(h)))...)
+		enc = append(enc, sevenZipCRC, 1)
+		enc = binary.LittleEndian.AppendUint32(enc, crc32.ChecksumIEEE(h))
+		enc = append(enc, sevenZipEnd, sevenZipEnd)
+		h = enc
+	}
+
+	start := make([]byte, sevenZipStartHeaderSize)
+	copy(start, sevenZipSignature)
+	start[7] = 4
+	binary.LittleEndian.PutUint64(start[12:], uint64(len(packed)))
+	binary.LittleEndian.PutUint64(start[20:], uint64(len(h)))
+	binary.LittleEndian.PutUint32(start[28:], crc32.ChecksumIEEE(h))
+	binary.LittleEndian.PutUint32(start[8:], crc32.ChecksumIEEE(start[12:]))
+
+	out := append(start, packed...)
+	return append(out, h...)
+}
@@ -0,0 +1,127 @@
+package processor
+
+// PAR2 Reed-Solomon arithmetic works in GF(2^16) with the generator
+// polynomial x^16 + x^12 + x^3 + x + 1. Slices are treated as little-endian
+// 16-bit words.
+const (
+	gf16Poly  = 0x1100B
+	gf16Order = 65535
+)
+
+var (
+	gf16Log [1 << 16]uint16
+	// gf16Exp is doubled so log sums never need a modulo in the hot loop.
+	gf16Exp [2 * gf16Order]uint16
+)
+
+func init() {
+	x := 1
+	for i := 0; i < gf16Order; i++ {
+		gf16Exp[i] = uint16(x)
+		gf16Exp[i+gf16Order] = uint16(x)
+		gf16Log[x] = uint16(i)
+		x <<= 1
+		if x&0x10000 != 0 {
+			x ^= gf16Poly
+		}
+	}
+}
+
+func gf16Mul(a, b uint16) uint16 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gf16Exp[int(gf16Log[a])+int(gf16Log[b])]
+}
+
+func gf16Div(a, b uint16) uint16 {
+	if a == 0 {
+		return 0
+	}
+	return gf16Exp[int(gf16Log[a])+gf16Order-int(gf16Log[b])]
+}
+
+func gf16Pow(base uint16, exp uint32) uint16 {
+	if exp == 0 {
+		return 1
+	}
+	if base == 0 {
+		return 0
+	}
+	return gf16Exp[(uint64(gf16Log[base])*uint64(exp))%gf16Order]
+}
+
+// par2InputConstants returns the per-slice base constants defined by the
+// PAR2 spec: successive powers of two whose logs are coprime to 65535.
+func par2InputConstants(count int) []uint16 {
+	out := make([]uint16, 0, count)
+	for n := 1; len(out) < count && n < gf16Order; n++ {
+		if n%3 == 0 || n%5 == 0 || n%17 == 0 || n%257 == 0 {
+			continue
+		}
+		out = append(out, gf16Exp[n])
+	}
+	return out
+}
+
+// gf16MulAdd computes dst ^= c * src word by word.
+func gf16MulAdd(dst, src []byte, c uint16) {
+	if c == 0 {
+		return
+	}
+	lc := int(gf16Log[c])
+	n := len(src) &^ 1
+	for i := 0; i < n; i += 2 {
+		w := uint16(src[i]) | uint16(src[i+1])<<8
+		if w == 0 {
+			continue
+		}
+		p := gf16Exp[lc+int(gf16Log[w])]
+		dst[i] ^= byte(p)
+		dst[i+1] ^= byte(p >> 8)
+	}
+}
+
+// gf16Invert inverts a square matrix in place using Gauss-Jordan elimination.
+// It reports false when the matrix is singular.
+func gf16Invert(m [][]uint16) ([][]uint16, bool) {
+	n := len(m)
+	inv := make([][]uint16, n)
+	for i := range inv {
+		inv[i] = make([]uint16, n)
+		inv[i][i] = 1
+	}
+
+	for col := 0; col < n; col++ {
+		pivot := -1
+		for row := col; row < n; row++ {
+			if m[row][col] != 0 {
+				pivot = row
+				break
+			}
+		}
+		if pivot < 0 {
+			return nil, false
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		inv[col], inv[pivot] = inv[pivot], inv[col]
+
+		scale := m[col][col]
+		for k := 0; k < n; k++ {
+			m[col][k] = gf16Div(m[col][k], scale)
+			inv[col][k] = gf16Div(inv[col][k], scale)
+		}
+
+		for row := 0; row < n; row++ {
+			if row == col || m[row][col] == 0 {
+				continue
+			}
+			factor := m[row][col]
+			for k := 0; k < n; k++ {
+				m[row][k] ^= gf16Mul(factor, m[col][k])
+				inv[row][k] ^= gf16Mul(factor, inv[col][k])
+			}
+		}
+	}
+	return inv, true
+}
@@ -0,0 +1,557 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/datallboy/gonzb/internal/indexing/inspect/par2"
+)
+
+// ErrPar2Unrepairable is returned when a set has fewer recovery blocks than
+// damaged input blocks.
+var ErrPar2Unrepairable = errors.New("not enough PAR2 recovery blocks")
+
+const (
+	Par2FileOK      = "ok"
+	Par2FileDamaged = "damaged"
+	Par2FileMissing = "missing"
+
+	Par2PhaseVerify = "verify"
+	Par2PhaseRepair = "repair"
+)
+
+// Par2Progress is emitted while a set is verified or repaired. Verify counts
+// bytes, repair counts blocks.
+type Par2Progress struct {
+	Phase string
+	Done  int64
+	Total int64
+}
+
+type Par2FileReport struct {
+	Name          string `json:"name"`
+	Status        string `json:"status"`
+	Size          int64  `json:"size"`
+	TotalBlocks   int    `json:"total_blocks"`
+	DamagedBlocks int    `json:"damaged_blocks"`
+}
+
+// Par2Report is the outcome of verifying a recovery set.
+type Par2Report struct {
+	Files           []Par2FileReport `json:"files"`
+	BlockSize       int64            `json:"block_size"`
+	BlocksNeeded    int              `json:"blocks_needed"`
+	BlocksAvailable int              `json:"blocks_available"`
+
+	set     *par2Set
+	missing []int
+	rewrite []*par2File
+}
+
+func (r *Par2Report) Healthy() bool {
+	return r != nil && len(r.rewrite) == 0
+}
+
+func (r *Par2Report) Repairable() bool {
+	return r != nil && r.BlocksNeeded <= r.BlocksAvailable
+}
+
+// NativePar2 verifies and repairs PAR2 sets without an external binary.
+type NativePar2 struct {
+	OnProgress func(Par2Progress)
+}
+
+func NewNativePar2() *NativePar2 {
+	return &NativePar2{}
+}
+
+func (n *NativePar2) Verify(ctx context.Context, path string) (bool, error) {
+	report, err := n.Scan(ctx, path)
+	if err != nil {
+		return false, err
+	}
+	if !report.Repairable() {
+		return false, fmt.Errorf("%w: need %d, have %d", ErrPar2Unrepairable, report.BlocksNeeded, report.BlocksAvailable)
+	}
+	return report.Healthy(), nil
+}
+
+func (n *NativePar2) Repair(ctx context.Context, path string) error {
+	report, err := n.Scan(ctx, path)
+	if err != nil {
+		return err
+	}
+	return n.Reconstruct(ctx, report)
+}
+
+// Scan loads the recovery set referenced by path and checks every target
+// file block by block against the IFSC checksums.
+func (n *NativePar2) Scan(ctx context.Context, path string) (*Par2Report, error) {
+	set, err := loadPar2Set(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Par2Report{
+		BlockSize:       set.SliceSize,
+		BlocksAvailable: len(set.Recovery),
+		set:             set,
+	}
+
+	var total int64
+	for _, f := range set.Files {
+		total += f.Length
+	}
+	progress := &Par2Progress{Phase: Par2PhaseVerify, Total: total}
+
+	buf := make([]byte, set.SliceSize)
+	for _, f := range set.Files {
+		status, bad, err := n.verifyFile(ctx, set, f, buf, progress)
+		if err != nil {
+			return nil, err
+		}
+		report.Files = append(report.Files, Par2FileReport{
+			Name:          f.Name,
+			Status:        status,
+			Size:          f.Length,
+			TotalBlocks:   f.Slices,
+			DamagedBlocks: len(bad),
+		})
+		if status != Par2FileOK {
+			report.rewrite = append(report.rewrite, f)
+		}
+		report.missing = append(report.missing, bad...)
+	}
+	report.BlocksNeeded = len(report.missing)
+
+	return report, nil
+}
+
+func (n *NativePar2) verifyFile(ctx context.Context, set *par2Set, f *par2File, buf []byte, progress *Par2Progress) (string, []int, error) {
+	all := func() []int {
+		out := make([]int, f.Slices)
+		for i := range out {
+			out[i] = f.FirstSlice + i
+		}
+		return out
+	}
+
+	path, err := set.targetPath(f)
+	if err != nil {
+		return "", nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			progress.Done += f.Length
+			n.report(*progress)
+			return Par2FileMissing, all(), nil
+		}
+		return "", nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var bad []int
+	whole := md5.New()
+	for s := 0; s < f.Slices; s++ {
+		if err := ctx.Err(); err != nil {
+			return "", nil, err
+		}
+		want := sliceLength(set, f, s)
+		got, err := readPaddedSlice(file, buf, int64(s)*set.SliceSize, want)
+		if err != nil {
+			return "", nil, err
+		}
+		whole.Write(buf[:got])
+
+		if f.Checksums != nil && (got < want || !sliceMatches(buf, f.Checksums[s])) {
+			bad = append(bad, f.FirstSlice+s)
+		}
+
+		progress.Done += want
+		n.report(*progress)
+	}
+
+	// without IFSC data the only signal is the whole-file hash.
+	if f.Checksums == nil && !bytes.Equal(whole.Sum(nil), f.Hash[:]) {
+		return Par2FileDamaged, all(), nil
+	}
+	if len(bad) > 0 || info.Size() != f.Length {
+		return Par2FileDamaged, bad, nil
+	}
+	return Par2FileOK, nil, nil
+}
+
+// Reconstruct rebuilds every damaged block of a scanned set and trims target
+// files to their recorded length.
+func (n *NativePar2) Reconstruct(ctx context.Context, report *Par2Report) error {
+	if report == nil || report.Healthy() {
+		return nil
+	}
+	if !report.Repairable() {
+		return fmt.Errorf("%w: need %d, have %d", ErrPar2Unrepairable, report.BlocksNeeded, report.BlocksAvailable)
+	}
+
+	set := report.set
+	if err := n.rebuildSlices(ctx, set, report.missing); err != nil {
+		return err
+	}
+
+	for _, f := range report.rewrite {
+		path, err := set.targetPath(f)
+		if err != nil {
+			return err
+		}
+		if err := truncateTarget(path, f.Length); err != nil {
+			return fmt.Errorf("truncate %s: %w", f.Name, err)
+		}
+		if err := checkFileHash(path, f.Hash); err != nil {
+			return fmt.Errorf("repaired %s failed verification: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+// par2RepairBuffer caps the recovery data held in memory during repair. Each
+// slice is rebuilt in chunks of par2RepairBuffer/m bytes for m missing slices.
+var par2RepairBuffer int64 = 64 << 20
+
+func (n *NativePar2) rebuildSlices(ctx context.Context, set *par2Set, missing []int) error {
+	m := len(missing)
+	if m == 0 {
+		return nil
+	}
+
+	consts := par2InputConstants(set.TotalSlice)
+	recovery, inverse, err := pickRecoverySlices(set, missing, consts)
+	if err != nil {
+		return err
+	}
+
+	isMissing := make(map[int]struct{}, m)
+	for _, g := range missing {
+		isMissing[g] = struct{}{}
+	}
+
+	handles := map[string]*os.File{}
+	defer func() {
+		for _, h := range handles {
+			h.Close()
+		}
+	}()
+	open := func(path string, flag int) (*os.File, error) {
+		if h, ok := handles[path]; ok {
+			return h, nil
+		}
+		if flag&os.O_CREATE != 0 {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return nil, err
+			}
+		}
+		h, err := os.OpenFile(path, flag, 0644)
+		if err != nil {
+			return nil, err
+		}
+		handles[path] = h
+		return h, nil
+	}
+
+	// files with missing slices are read around them and written into; the
+	// rest are only read.
+	damaged := make(map[*par2File]bool, len(set.Files))
+	for _, g := range missing {
+		f, _ := set.locateSlice(g)
+		damaged[f] = true
+	}
+	targets := make(map[*par2File]*os.File, len(set.Files))
+	for _, f := range set.Files {
+		if f.Slices == 0 {
+			continue
+		}
+		path, err := set.targetPath(f)
+		if err != nil {
+			return err
+		}
+		flag := os.O_RDONLY
+		if damaged[f] {
+			flag = os.O_RDWR | os.O_CREATE
+		}
+		h, err := open(path, flag)
+		if err != nil {
+			return fmt.Errorf("open %s for repair: %w", f.Name, err)
+		}
+		targets[f] = h
+	}
+
+	chunk := min(set.SliceSize, max(4, (par2RepairBuffer/int64(m))&^3))
+	passes := (set.SliceSize + chunk - 1) / chunk
+	progress := Par2Progress{Phase: Par2PhaseRepair, Total: int64(set.TotalSlice) * passes}
+
+	acc := make([][]byte, m)
+	for j := range acc {
+		acc[j] = make([]byte, chunk)
+	}
+	buf := make([]byte, chunk)
+	crcs := make([]uint32, m)
+	sums := make([]hash.Hash, m)
+	for k := range sums {
+		sums[k] = md5.New()
+	}
+
+	for off := int64(0); off < set.SliceSize; off += chunk {
+		size := min(chunk, set.SliceSize-off)
+
+		for j, rs := range recovery {
+			h, err := open(rs.Path, os.O_RDONLY)
+			if err != nil {
+				return fmt.Errorf("open recovery volume: %w", err)
+			}
+			if _, err := h.ReadAt(acc[j][:size], rs.Offset+off); err != nil {
+				return fmt.Errorf("read recovery block %d: %w", rs.Exponent, err)
+			}
+		}
+
+		// strip every surviving slice out of the recovery blocks, leaving
+		// only the contribution of the missing ones.
+		for _, f := range set.Files {
+			if err := n.subtractFile(ctx, set, f, targets[f], isMissing, consts, recovery, acc, buf, off, size, &progress); err != nil {
+				return err
+			}
+		}
+
+		for k, g := range missing {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			out := buf[:size]
+			clear(out)
+			for j := range recovery {
+				gf16MulAdd(out, acc[j][:size], inverse[k][j])
+			}
+			crcs[k] = crc32.Update(crcs[k], crc32.IEEETable, out)
+			sums[k].Write(out)
+
+			f, s := set.locateSlice(g)
+			if want := sliceLength(set, f, s) - off; want > 0 {
+				if _, err := targets[f].WriteAt(out[:min(size, want)], int64(s)*set.SliceSize+off); err != nil {
+					return fmt.Errorf("write repaired block to %s: %w", f.Name, err)
+				}
+			}
+
+			progress.Done++
+			n.report(progress)
+		}
+	}
+
+	for k, g := range missing {
+		f, s := set.locateSlice(g)
+		if f.Checksums == nil {
+			continue
+		}
+		var digest [16]byte
+		sums[k].Sum(digest[:0])
+		if crcs[k] != f.Checksums[s].CRC32 || digest != f.Checksums[s].MD5 {
+			return fmt.Errorf("reconstructed block %d of %s does not match its checksum", s, f.Name)
+		}
+	}
+
+	for f, h := range targets {
+		if !damaged[f] {
+			continue
+		}
+		if err := h.Sync(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pickRecoverySlices chooses one readable recovery block per missing slice
+// whose rows for the missing slices are linearly independent, moving on to
+// further exponents when a block is unreadable or would make the matrix
+// singular. It returns the blocks and the inverse of their matrix.
+func pickRecoverySlices(set *par2Set, missing []int, consts []uint16) ([]par2RecoverySlice, [][]uint16, error) {
+	m := len(missing)
+	picked := make([]par2RecoverySlice, 0, m)
+	matrix := make([][]uint16, 0, m)
+	// reduced rows of the picked blocks, each scaled to 1 at its pivot.
+	type echelonRow struct {
+		row   []uint16
+		pivot int
+	}
+	basis := make([]echelonRow, 0, m)
+	sizes := map[string]int64{}
+
+	for _, rs := range set.Recovery {
+		if len(picked) == m {
+			break
+		}
+
+		size, ok := sizes[rs.Path]
+		if !ok {
+			if info, err := os.Stat(rs.Path); err == nil {
+				size = info.Size()
+			}
+			sizes[rs.Path] = size
+		}
+		if rs.Offset+set.SliceSize > size {
+			continue
+		}
+
+		// matrix rows hold the weight of each missing slice in this block.
+		row := make([]uint16, m)
+		for k, g := range missing {
+			row[k] = gf16Pow(consts[g], rs.Exponent)
+		}
+		reduced := append([]uint16(nil), row...)
+		for _, b := range basis {
+			if c := reduced[b.pivot]; c != 0 {
+				for k := range reduced {
+					reduced[k] ^= gf16Mul(c, b.row[k])
+				}
+			}
+		}
+		pivot := slices.IndexFunc(reduced, func(v uint16) bool { return v != 0 })
+		if pivot < 0 {
+			continue
+		}
+		scale := reduced[pivot]
+		for k := range reduced {
+			reduced[k] = gf16Div(reduced[k], scale)
+		}
+		basis = append(basis, echelonRow{row: reduced, pivot: pivot})
+		picked = append(picked, rs)
+		matrix = append(matrix, row)
+	}
+
+	if len(picked) < m {
+		return nil, nil, fmt.Errorf("%w: only %d of %d recovery blocks are usable", ErrPar2Unrepairable, len(picked), m)
+	}
+	inverse, ok := gf16Invert(matrix)
+	if !ok {
+		return nil, nil, fmt.Errorf("PAR2 recovery matrix is singular")
+	}
+	return picked, inverse, nil
+}
+
+// subtractFile removes the chunk at off of every surviving slice of f from
+// the recovery accumulators.
+func (n *NativePar2) subtractFile(ctx context.Context, set *par2Set, f *par2File, file *os.File, isMissing map[int]struct{}, consts []uint16, recovery []par2RecoverySlice, acc [][]byte, buf []byte, off, size int64, progress *Par2Progress) error {
+	for s := 0; s < f.Slices; s++ {
+		g := f.FirstSlice + s
+		if _, gone := isMissing[g]; gone {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// the zero padding past a short last slice adds nothing.
+		if want := min(size, sliceLength(set, f, s)-off); want > 0 {
+			if _, err := readPaddedSlice(file, buf[:size], int64(s)*set.SliceSize+off, want); err != nil {
+				return err
+			}
+			for j, rs := range recovery {
+				gf16MulAdd(acc[j][:size], buf[:size], gf16Pow(consts[g], rs.Exponent))
+			}
+		}
+		progress.Done++
+		n.report(*progress)
+	}
+	return nil
+}
+
+func (n *NativePar2) report(p Par2Progress) {
+	if n.OnProgress != nil {
+		n.OnProgress(p)
+	}
+}
+
+// targetPath resolves a file name from the set, refusing names that would
+// escape the set's directory.
+func (s *par2Set) targetPath(f *par2File) (string, error) {
+	path := filepath.Join(s.Dir, filepath.FromSlash(f.Name))
+	rel, err := filepath.Rel(s.Dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("PAR2 target %q escapes %s", f.Name, s.Dir)
+	}
+	return path, nil
+}
+
+func (s *par2Set) locateSlice(global int) (*par2File, int) {
+	for _, f := range s.Files {
+		if global < f.FirstSlice+f.Slices {
+			return f, global - f.FirstSlice
+		}
+	}
+	return nil, -1
+}
+
+func sliceLength(set *par2Set, f *par2File, s int) int64 {
+	return min(set.SliceSize, f.Length-int64(s)*set.SliceSize)
+}
+
+// readPaddedSlice reads up to want bytes into buf and zero-fills the rest, as
+// PAR2 checksums and parity are computed over zero-padded slices.
+func readPaddedSlice(r io.ReaderAt, buf []byte, offset, want int64) (int64, error) {
+	n, err := r.ReadAt(buf[:want], offset)
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	clear(buf[n:])
+	return int64(n), nil
+}
+
+func sliceMatches(buf []byte, sum par2.SliceChecksum) bool {
+	if crc32.ChecksumIEEE(buf) != sum.CRC32 {
+		return false
+	}
+	digest := md5.Sum(buf)
+	return digest == sum.MD5
+}
+
+// truncateTarget trims a repaired file to its recorded length, creating empty
+// targets that were missing entirely.
+func truncateTarget(path string, length int64) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Truncate(length)
+}
+
+func checkFileHash(path string, want [16]byte) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if !bytes.Equal(h.Sum(nil), want[:]) {
+		return fmt.Errorf("MD5 mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package processor
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/datallboy/gonzb/internal/indexing/inspect/par2"
+)
+
+type par2File struct {
+	ID        [16]byte
+	Name      string
+	Length    int64
+	Hash      [16]byte
+	Hash16k   [16]byte // MD5 of the first 16 KiB, used to identify renamed files
+	Checksums []par2.SliceChecksum
+
+	// FirstSlice is the global index of the file's first input slice.
+	FirstSlice int
+	Slices     int
+}
+
+type par2RecoverySlice struct {
+	Exponent uint32
+	Path     string
+	Offset   int64
+	Length   int64
+}
+
+// par2Set is one recovery set assembled from every PAR2 file in a directory.
+type par2Set struct {
+	Dir        string
+	SetID      [16]byte
+	SliceSize  int64
+	Files      []*par2File
+	Recovery   []par2RecoverySlice
+	TotalSlice int
+}
+
+// loadPar2Set parses the primary index and every sibling .par2 volume that
+// belongs to the same recovery set. Packets with a bad MD5 are skipped.
+func loadPar2Set(ctx context.Context, primary string) (*par2Set, error) {
+	dir := filepath.Dir(primary)
+	set := &par2Set{Dir: dir}
+
+	var (
+		setID     [16]byte
+		haveSetID bool
+		mainBody  []byte
+		descs     = map[[16]byte]*par2File{}
+		ifsc      = map[[16]byte][]par2.SliceChecksum{}
+		exponents = map[uint32]par2RecoverySlice{}
+	)
+
+	visit := func(path string, h par2.PacketHeader, body []byte, bodyOffset int64) {
+		if !haveSetID {
+			setID, haveSetID = h.SetID, true
+		}
+		if h.SetID != setID {
+			return
+		}
+		switch h.Type {
+		case par2.TypeMain:
+			if mainBody == nil {
+				mainBody = body
+			}
+		case par2.TypeFileDesc:
+			if f, ok := parsePar2FileDesc(body); ok {
+				descs[f.ID] = f
+			}
+		case par2.TypeIFSC:
+			if id, sums, ok := par2.ParseIFSC(body); ok {
+				ifsc[id] = sums
+			}
+		case par2.TypeRecvSlic:
+			exp := binary.LittleEndian.Uint32(body[:4])
+			if _, ok := exponents[exp]; !ok {
+				exponents[exp] = par2RecoverySlice{
+					Exponent: exp,
+					Path:     path,
+					Offset:   bodyOffset + 4,
+					Length:   int64(h.Length) - par2.PacketHeaderSize - 4,
+				}
+			}
+		}
+	}
+
+	// the primary goes first so its set ID wins over unrelated sets in the same folder.
+	paths := []string{primary}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read par2 directory: %w", err)
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() || path == primary || !strings.EqualFold(filepath.Ext(entry.Name()), ".par2") {
+			continue
+		}
+		paths = append(paths, path)
+	}
+
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := scanPar2Packets(path, visit); err != nil {
+			if path == primary {
+				return nil, err
+			}
+			continue
+		}
+	}
+
+	if len(mainBody) < 12 {
+		return nil, fmt.Errorf("no valid PAR2 main packet found in %s", filepath.Base(primary))
+	}
+	set.SetID = setID
+
+	set.SliceSize = int64(binary.LittleEndian.Uint64(mainBody[0:8]))
+	if set.SliceSize <= 0 || set.SliceSize%4 != 0 {
+		return nil, fmt.Errorf("invalid PAR2 slice size %d", set.SliceSize)
+	}
+	fileCount := int(binary.LittleEndian.Uint32(mainBody[8:12]))
+	if 12+fileCount*16 > len(mainBody) {
+		return nil, fmt.Errorf("truncated PAR2 main packet")
+	}
+
+	for i := 0; i < fileCount; i++ {
+		var id [16]byte
+		copy(id[:], mainBody[12+i*16:])
+		f, ok := descs[id]
+		if !ok {
+			return nil, fmt.Errorf("PAR2 set is missing the description for file %d", i)
+		}
+		f.FirstSlice = set.TotalSlice
+		f.Slices = int((f.Length + set.SliceSize - 1) / set.SliceSize)
+		if sums := ifsc[id]; len(sums) == f.Slices {
+			f.Checksums = sums
+		}
+		set.TotalSlice += f.Slices
+		set.Files = append(set.Files, f)
+	}
+	if set.TotalSlice > 32768 {
+		return nil, fmt.Errorf("PAR2 set has %d input slices, above the spec limit", set.TotalSlice)
+	}
+
+	for _, rs := range exponents {
+		if rs.Length != set.SliceSize {
+			continue
+		}
+		set.Recovery = append(set.Recovery, rs)
+	}
+	sort.Slice(set.Recovery, func(i, j int) bool { return set.Recovery[i].Exponent < set.Recovery[j].Exponent })
+
+	return set, nil
+}
+
+// scanPar2Packets runs par2.ScanPackets over one file on disk.
+func scanPar2Packets(path string, visit func(path string, h par2.PacketHeader, body []byte, bodyOffset int64)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open par2 file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return par2.ScanPackets(f, info.Size(), func(h par2.PacketHeader, body []byte, bodyOffset int64) {
+		visit(path, h, body, bodyOffset)
+	})
+}
+
+func parsePar2FileDesc(body []byte) (*par2File, bool) {
+	desc, ok := par2.ParseFileDesc(body)
+	if !ok || desc.Length > uint64(1<<62) {
+		return nil, false
+	}
+	return &par2File{
+		ID:      desc.ID,
+		Name:    desc.Name,
+		Length:  int64(desc.Length),
+		Hash:    desc.Hash,
+		Hash16k: desc.Hash16k,
+	}, true
+}
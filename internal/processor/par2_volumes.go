@@ -0,0 +1,109 @@
+package processor
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/indexing/inspect/par2"
+)
+
+var par2VolumeRE = regexp.MustCompile(`(?i)\.vol(\d+)\+(\d+)\.par2$`)
+
+// Par2VolumeBlocks returns the number of recovery blocks a volume carries
+// according to its .volNN+MM.par2 name.
+func Par2VolumeBlocks(name string) (int, bool) {
+	match := par2VolumeRE.FindStringSubmatch(name)
+	if match == nil {
+		return 0, false
+	}
+	blocks, err := strconv.Atoi(match[2])
+	if err != nil || blocks <= 0 {
+		return 0, false
+	}
+	return blocks, true
+}
+
+// RecoveryVolumes returns the PAR2 recovery volumes of a task list, if the
+// list also carries the index .par2 needed to verify without them.
+func RecoveryVolumes(tasks []*domain.DownloadFile) []*domain.DownloadFile {
+	if findPrimaryPar(tasks) == "" {
+		return nil
+	}
+
+	var volumes []*domain.DownloadFile
+	for _, task := range tasks {
+		if _, ok := Par2VolumeBlocks(task.FileName); ok {
+			volumes = append(volumes, task)
+		}
+	}
+	return volumes
+}
+
+// SelectRecoveryVolumes picks volumes covering at least blocks recovery
+// blocks, preferring the smallest volume that closes the remaining gap.
+func SelectRecoveryVolumes(volumes []*domain.DownloadFile, blocks int) []*domain.DownloadFile {
+	type candidate struct {
+		task   *domain.DownloadFile
+		blocks int
+	}
+	pool := make([]candidate, 0, len(volumes))
+	for _, v := range volumes {
+		if n, ok := Par2VolumeBlocks(v.FileName); ok {
+			pool = append(pool, candidate{task: v, blocks: n})
+		}
+	}
+	sort.SliceStable(pool, func(i, j int) bool { return pool[i].blocks < pool[j].blocks })
+
+	var picked []*domain.DownloadFile
+	for blocks > 0 && len(pool) > 0 {
+		// smallest volume that covers the rest, else the largest one left.
+		idx := sort.Search(len(pool), func(i int) bool { return pool[i].blocks >= blocks })
+		if idx == len(pool) {
+			idx = len(pool) - 1
+		}
+		picked = append(picked, pool[idx].task)
+		blocks -= pool[idx].blocks
+		pool = append(pool[:idx], pool[idx+1:]...)
+	}
+	return picked
+}
+
+// RecoveryShortfall verifies the downloaded files against the index .par2 and
+// returns how many recovery blocks are still missing from disk.
+func (p *Processor) RecoveryShortfall(ctx context.Context, tasks []*domain.DownloadFile) (int, error) {
+	primaryPar := findPrimaryPar(tasks)
+	if primaryPar == "" {
+		return 0, fmt.Errorf("no PAR2 index in file set")
+	}
+
+	report, err := NewNativePar2().Scan(ctx, primaryPar)
+	if err != nil {
+		return 0, err
+	}
+	return max(0, report.BlocksNeeded-report.BlocksAvailable), nil
+}
+
+// Par2SliceSize reads the slice size from the main packet of a single PAR2
+// file, which is all the download health check needs before the rest of the
+// set is on disk.
+func Par2SliceSize(path string) (int64, error) {
+	var sliceSize int64
+	err := scanPar2Packets(path, func(_ string, h par2.PacketHeader, body []byte, _ int64) {
+		if sliceSize == 0 && h.Type == par2.TypeMain && len(body) >= 8 {
+			sliceSize = int64(binary.LittleEndian.Uint64(body[0:8]))
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	if sliceSize <= 0 {
+		return 0, fmt.Errorf("no valid PAR2 main packet found in %s", filepath.Base(path))
+	}
+	return sliceSize, nil
+}
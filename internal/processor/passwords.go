@@ -0,0 +1,77 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/datallboy/gonzb/internal/domain"
+)
+
+// cliPasswordMarkers are fragments unrar, 7z and unzip print when an archive
+// is encrypted and the password is missing or wrong.
+var cliPasswordMarkers = []string{
+	"wrong password",
+	"incorrect password",
+	"password is incorrect",
+	"enter password",
+	"password required",
+	"can not open encrypted archive",
+	"data error in encrypted file",
+}
+
+// isCLIPasswordFailure reports whether CLI extractor output points at a
+// password problem rather than a damaged archive.
+func isCLIPasswordFailure(output []byte) bool {
+	lower := strings.ToLower(string(output))
+	for _, marker := range cliPasswordMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// archivePasswords lists the passwords to try against an archive: the NZB's
+// own (empty when it has none, which also covers unencrypted archives), then
+// each configured list in order, skipping repeats.
+func archivePasswords(nzbPassword string, lists ...[]string) []string {
+	passwords := []string{nzbPassword}
+	seen := map[string]bool{nzbPassword: true}
+	for _, list := range lists {
+		for _, password := range list {
+			if strings.TrimSpace(password) == "" || seen[password] {
+				continue
+			}
+			seen[password] = true
+			passwords = append(passwords, password)
+		}
+	}
+	return passwords
+}
+
+// extractWithPasswords tries passwords in order until the archive opens and
+// returns the one that worked. Only ErrArchivePassword moves on to the next
+// password; any other failure is returned as is.
+func (p *Processor) extractWithPasswords(ctx context.Context, item *domain.QueueItem, archive Extractor, archivePath, destDir string, passwords []string) ([]string, string, error) {
+	archiveName := filepath.Base(archivePath)
+
+	var err error
+	for i, password := range passwords {
+		var files []string
+		files, err = p.extractOne(ctx, item, archive, archivePath, destDir, password)
+		if errors.Is(err, ErrArchivePassword) {
+			p.ctx.Logger.Debug("Password %d/%d rejected for %s", i+1, len(passwords), archiveName)
+			continue
+		}
+		if err == nil && i > 0 {
+			// never log the password itself, only which entry matched.
+			p.recordEvent(ctx, item, extractEventStage, "password",
+				fmt.Sprintf("%s opened with password %d of %d", archiveName, i+1, len(passwords)), nil)
+		}
+		return files, password, err
+	}
+	return nil, "", err
+}
@@ -2,17 +2,27 @@ package processor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/datallboy/gonzb/internal/app"
 	"github.com/datallboy/gonzb/internal/domain"
+	"github.com/datallboy/gonzb/internal/infra/config"
 	"github.com/datallboy/gonzb/internal/nzb"
 )
 
+// Post-processing levels from config.CategoryConfig.PostProcessLevel.
+const (
+	ppRepair = 1
+	ppUnpack = 2
+	ppDelete = 3
+)
+
 type Closeable interface {
 	CloseFile(path string, finalSize int64) error
 	PreAllocate(path string, size int64) error
@@ -23,26 +33,42 @@ type Processor struct {
 	writer    Closeable
 	extractor *Manager
 
-	cleanupMap map[string]struct{}
+	// direct unpacks still running or awaiting post-processing, by queue item ID.
+	directMu sync.Mutex
+	direct   map[string]*DirectUnpack
 }
 
 func New(ctx *app.Context, w Closeable) *Processor {
-	p := &Processor{
-		ctx:        ctx,
-		writer:     w,
-		extractor:  NewManager(),
-		cleanupMap: make(map[string]struct{}),
+	return &Processor{
+		ctx:       ctx,
+		writer:    w,
+		extractor: NewManager(),
+	}
+}
+
+// category resolves the queue item's category against the configured ones.
+func (p *Processor) category(item *domain.QueueItem) config.CategoryConfig {
+	name := ""
+	if item != nil && item.Release != nil {
+		name = item.Release.Category
 	}
+	category, _ := p.ctx.Config.Download.ResolveCategory(name)
+	return category
+}
 
-	for _, ext := range ctx.Config.Download.CleanupExtensions {
-		normalized := strings.ToLower(ext)
+func buildCleanupMap(extensions []string) map[string]struct{} {
+	cleanupMap := make(map[string]struct{}, len(extensions))
+	for _, ext := range extensions {
+		normalized := strings.ToLower(strings.TrimSpace(ext))
+		if normalized == "" {
+			continue
+		}
 		if !strings.HasPrefix(normalized, ".") {
 			normalized = "." + normalized
 		}
-		p.cleanupMap[normalized] = struct{}{}
+		cleanupMap[normalized] = struct{}{}
 	}
-
-	return p
+	return cleanupMap
 }
 
 // CHANGED: Prepare now uses the queue item's job-specific work dir.
@@ -67,12 +93,12 @@ func (p *Processor) Prepare(ctx context.Context, item *domain.QueueItem, nzbMode
 
 	// Try NZB Filename fallback
 	if password == "" {
-		password = extractPassword(nzbFilename)
+		password = ExtractPassword(nzbFilename)
 	}
 
 	// Fallback to subject line
 	if password == "" && len(nzbModel.Files) > 0 {
-		password = extractPassword(nzbModel.Files[0].Subject)
+		password = ExtractPassword(nzbModel.Files[0].Subject)
 	}
 
 	for i, rawFile := range nzbModel.Files {
@@ -100,6 +126,9 @@ func (p *Processor) Prepare(ctx context.Context, item *domain.QueueItem, nzbMode
 				task.IsComplete = true
 			}
 		} else {
+			if _, err := os.Stat(task.PartPath); err == nil {
+				task.PartExists = true
+			}
 			if err := p.writer.PreAllocate(task.PartPath, task.Size); err != nil {
 				return nil, fmt.Errorf("failed to pre-allocate %s: %w", task.FileName, err)
 			}
@@ -117,7 +146,7 @@ func (p *Processor) Prepare(ctx context.Context, item *domain.QueueItem, nzbMode
 
 func (p *Processor) Finalize(ctx context.Context, tasks []*domain.DownloadFile) error {
 	for _, task := range tasks {
-		if task.IsComplete {
+		if task.IsComplete || task.Skipped {
 			continue
 		}
 
@@ -161,23 +190,45 @@ func (p *Processor) PostProcess(ctx context.Context, item *domain.QueueItem, tas
 		return nil
 	}
 
-	p.ctx.Logger.Info("Starting post-processing...")
+	category := p.category(item)
+	level := category.PostProcessLevel()
+	p.ctx.Logger.Info("Starting post-processing (category %q, %s)...", category.Name, category.PostProcessing)
 
-	if primaryPar := findPrimaryPar(tasks); primaryPar != "" {
-		if err := p.handleRepair(ctx, primaryPar); err != nil {
+	// "none" moves files exactly as downloaded.
+	hasPar2Names := false
+	if level >= ppRepair {
+		hasPar2Names = p.renameFromPar2(ctx, item, tasks)
+	}
+
+	if primaryPar := findPrimaryPar(tasks); primaryPar != "" && level >= ppRepair {
+		if err := p.handleRepair(ctx, item, primaryPar); err != nil {
 			p.ctx.Logger.Error("Post-repair health check failed: %v", err)
 			return fmt.Errorf("Post-repair health check failed: %v", err)
 		}
 	}
 
-	extractedTasks, err := p.extractArchives(ctx, tasks)
-	if err != nil {
-		p.ctx.Logger.Error("Archive extraction failed: %v", err)
-		return fmt.Errorf("Archive extraction failed: %v", err)
+	// sets a direct unpack already extracted during the download are reused.
+	direct := p.directUnpacked(ctx, item)
+
+	var extractedTasks []*domain.DownloadFile
+	if level >= ppUnpack {
+		var err error
+		extractedTasks, err = p.extractArchives(ctx, item, category, tasks, direct)
+		if err != nil {
+			p.ctx.Logger.Error("Archive extraction failed: %v", err)
+			return fmt.Errorf("Archive extraction failed: %w", err)
+		}
 	}
 
-	// CHANGED: if extraction succeeded, do not move the original archive set forward.
-	moveTasks := buildMoveTaskList(tasks, extractedTasks)
+	// unpack keeps the archive set next to its output; delete does not move it forward.
+	moveTasks := append(append([]*domain.DownloadFile(nil), tasks...), extractedTasks...)
+	if level >= ppDelete {
+		moveTasks = buildMoveTaskList(tasks, extractedTasks)
+	}
+
+	if level >= ppRepair && !hasPar2Names {
+		p.renameLargestMedia(ctx, item, moveTasks)
+	}
 
 	if p.ctx.Config.Download.CompletedDir != "" {
 		p.ctx.Logger.Info("Moving files to completed directory: %s", p.ctx.Config.Download.CompletedDir)
@@ -187,7 +238,7 @@ func (p *Processor) PostProcess(ctx context.Context, item *domain.QueueItem, tas
 			originalWorkDir = item.OutDir
 		}
 
-		completedDir, err := p.moveToCompleted(item, moveTasks)
+		completedDir, err := p.moveToCompleted(item, category, moveTasks)
 		if err != nil {
 			return fmt.Errorf("failed to move files: %w", err)
 		}
@@ -198,7 +249,7 @@ func (p *Processor) PostProcess(ctx context.Context, item *domain.QueueItem, tas
 		}
 
 		// clean up leftover files from the old work dir after a successful move.
-		if err := p.cleanupWorkDir(originalWorkDir, completedDir); err != nil {
+		if err := p.cleanupWorkDir(originalWorkDir, completedDir, buildCleanupMap(category.CleanupExtensions)); err != nil {
 			p.ctx.Logger.Warn("Failed to cleanup work directory %s: %v", originalWorkDir, err)
 		}
 	}
@@ -206,7 +257,7 @@ func (p *Processor) PostProcess(ctx context.Context, item *domain.QueueItem, tas
 	return nil
 }
 
-func (p *Processor) extractArchives(ctx context.Context, tasks []*domain.DownloadFile) ([]*domain.DownloadFile, error) {
+func (p *Processor) extractArchives(ctx context.Context, item *domain.QueueItem, category config.CategoryConfig, tasks []*domain.DownloadFile, direct map[*domain.DownloadFile][]string) ([]*domain.DownloadFile, error) {
 	if !p.ctx.ExtractionEnabled {
 		return nil, nil
 	}
@@ -223,7 +274,8 @@ func (p *Processor) extractArchives(ctx context.Context, tasks []*domain.Downloa
 	maxDepth := 3
 
 	for depth := 1; depth <= maxDepth; depth++ {
-		newTasks, err := p.extractBatch(ctx, currentBatch)
+		newTasks, err := p.extractBatch(ctx, item, category, currentBatch, direct)
+		direct = nil // only the downloaded archives were unpacked directly
 		if err != nil {
 			return allNewTasks, err
 		}
@@ -240,7 +292,7 @@ func (p *Processor) extractArchives(ctx context.Context, tasks []*domain.Downloa
 	return allNewTasks, nil
 }
 
-func (p *Processor) extractBatch(ctx context.Context, tasks []*domain.DownloadFile) ([]*domain.DownloadFile, error) {
+func (p *Processor) extractBatch(ctx context.Context, item *domain.QueueItem, category config.CategoryConfig, tasks []*domain.DownloadFile, direct map[*domain.DownloadFile][]string) ([]*domain.DownloadFile, error) {
 	archives, err := p.extractor.DetectArchives(tasks)
 	if err != nil {
 		return nil, fmt.Errorf("failed to detect archives: %w", err)
@@ -257,12 +309,37 @@ func (p *Processor) extractBatch(ctx context.Context, tasks []*domain.DownloadFi
 
 	for task, archive := range archives {
 		archiveName := filepath.Base(task.FinalPath)
+		if files, ok := direct[task]; ok {
+			p.ctx.Logger.Debug("Already unpacked during download: %s", archiveName)
+			for _, path := range files {
+				newTasks = append(newTasks, &domain.DownloadFile{
+					FinalPath: path,
+					FileName:  filepath.Base(path),
+					Password:  task.Password,
+				})
+			}
+			continue
+		}
 		p.ctx.Logger.Debug("Extracting %s with %s", archiveName, archive.Name())
 
 		destDir := filepath.Dir(task.FinalPath)
-		extractedFile, err := archive.Extract(ctx, task.FinalPath, destDir, task.Password)
+		extractedFile, password, err := p.extractWithPasswords(ctx, item, archive, task.FinalPath, destDir,
+			archivePasswords(task.Password, category.Passwords, p.ctx.Config.Download.ArchivePasswords))
+		if errors.Is(err, ErrArchivePassword) {
+			// nothing else in the job is usable without this archive's contents.
+			p.ctx.Logger.Error("No known password opens %s", archiveName)
+			p.recordEvent(ctx, item, extractEventStage, "password_required", archiveName+": "+err.Error(), nil)
+			return newTasks, fmt.Errorf("%s: %w", archiveName, err)
+		}
+		if errors.Is(err, ErrUnsupportedArchive) {
+			// no installed extractor can open it; completing would leave the job empty.
+			p.ctx.Logger.Error("No extractor can open %s: %v", archiveName, err)
+			p.recordEvent(ctx, item, extractEventStage, "unsupported", archiveName+": "+err.Error(), nil)
+			return newTasks, fmt.Errorf("%s: %w", archiveName, err)
+		}
 		if err != nil {
 			p.ctx.Logger.Error("Extraction failed for %s: %v", task.FileName, err)
+			p.recordEvent(ctx, item, extractEventStage, "failed", archiveName+": "+err.Error(), nil)
 			continue
 		}
 
@@ -270,44 +347,47 @@ func (p *Processor) extractBatch(ctx context.Context, tasks []*domain.DownloadFi
 			newTasks = append(newTasks, &domain.DownloadFile{
 				FinalPath: path,
 				FileName:  filepath.Base(path),
+				Password:  password,
 			})
 		}
 
 		p.ctx.Logger.Debug("Successfully extracted: %s", archiveName)
+		p.recordEvent(ctx, item, extractEventStage, "ok",
+			fmt.Sprintf("Extracted %d file(s) from %s", len(extractedFile), archiveName), nil)
 	}
 
 	return newTasks, nil
 }
 
-// CHANGED: move files into completed/<category>/<job-folder>/..., preserving relative paths.
-func (p *Processor) moveToCompleted(item *domain.QueueItem, tasks []*domain.DownloadFile) (string, error) {
+// CHANGED: move files into <category dir>/<job-folder>/..., preserving relative paths.
+func (p *Processor) moveToCompleted(item *domain.QueueItem, category config.CategoryConfig, tasks []*domain.DownloadFile) (string, error) {
 	if err := os.MkdirAll(p.ctx.Config.Download.CompletedDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create completed directory: %w", err)
 	}
 
-	baseDestDir := p.completedBaseDir(item)
+	baseDestDir := p.completedBaseDir(item, category)
 	if err := os.MkdirAll(baseDestDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create completed job directory: %w", err)
 	}
 
+	// cleanup files are only removed once everything else has moved, so a
+	// failed move leaves the PAR2 set around for a reprocess.
+	cleanupMap := buildCleanupMap(category.CleanupExtensions)
+	var cleanup []string
 	for _, task := range tasks {
 		fileName := filepath.Base(task.FinalPath)
 
-		if cleanupExtensions(fileName, p.cleanupMap) {
-			p.ctx.Logger.Debug("Cleanup: Removing %s", fileName)
-			_ = os.Remove(task.FinalPath)
+		if cleanupExtensions(fileName, cleanupMap) {
+			cleanup = append(cleanup, task.FinalPath)
 			continue
 		}
 
-		relPath := fileName
-		if item != nil && item.OutDir != "" {
-			if rel, err := filepath.Rel(item.OutDir, task.FinalPath); err == nil && rel != "." &&
-				rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
-				relPath = rel
-			}
+		// a reprocess may pick up files an earlier attempt already moved.
+		if isInsideDir(baseDestDir, task.FinalPath) {
+			continue
 		}
 
-		dest := filepath.Join(baseDestDir, relPath)
+		dest := filepath.Join(baseDestDir, completedRelPath(item, task.FinalPath))
 		p.ctx.Logger.Debug("Moving %s to completed folder", fileName)
 
 		if err := moveFile(task.FinalPath, dest); err != nil {
@@ -316,18 +396,56 @@ func (p *Processor) moveToCompleted(item *domain.QueueItem, tasks []*domain.Down
 		}
 	}
 
+	for _, path := range cleanup {
+		p.ctx.Logger.Debug("Cleanup: Removing %s", filepath.Base(path))
+		_ = os.Remove(path)
+	}
+
 	return baseDestDir, nil
 }
 
-func (p *Processor) completedBaseDir(item *domain.QueueItem) string {
+// CompletedDir is the directory PostProcess moves the item's files into, or
+// "" when files stay in the work dir.
+func (p *Processor) CompletedDir(item *domain.QueueItem) string {
+	if p.ctx.Config.Download.CompletedDir == "" {
+		return ""
+	}
+	return p.completedBaseDir(item, p.category(item))
+}
+
+// completedRelPath keeps a file's path below the work dir, or just its name
+// when it lives elsewhere.
+func completedRelPath(item *domain.QueueItem, path string) string {
+	if item != nil && item.OutDir != "" && isInsideDir(item.OutDir, path) {
+		if rel, err := filepath.Rel(item.OutDir, path); err == nil {
+			return rel
+		}
+	}
+	return filepath.Base(path)
+}
+
+func isInsideDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == "." {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// completedBaseDir is the category's completed_dir, or completed/<category>
+// when unset, plus the job folder.
+func (p *Processor) completedBaseDir(item *domain.QueueItem, cat config.CategoryConfig) string {
 	base := p.ctx.Config.Download.CompletedDir
 	category := "uncategorized"
 	jobFolder := "job"
 
+	if dir := strings.TrimSpace(cat.CompletedDir); dir != "" {
+		base, category = dir, ""
+	} else if strings.TrimSpace(cat.Name) != "" && cat.Name != config.DefaultCategory {
+		category = sanitizeCompletedPathPart(cat.Name)
+	}
+
 	if item != nil {
-		if item.Release != nil && strings.TrimSpace(item.Release.Category) != "" {
-			category = sanitizeCompletedPathPart(item.Release.Category)
-		}
 		if item.OutDir != "" {
 			jobFolder = sanitizeCompletedPathPart(filepath.Base(item.OutDir))
 		} else if item.ReleaseTitle != "" {
@@ -335,7 +453,7 @@ func (p *Processor) completedBaseDir(item *domain.QueueItem) string {
 		}
 	}
 
-	if category == "" {
+	if category == "" && strings.TrimSpace(cat.CompletedDir) == "" {
 		category = "uncategorized"
 	}
 	if jobFolder == "" {
@@ -379,7 +497,7 @@ func buildMoveTaskList(tasks, extractedTasks []*domain.DownloadFile) []*domain.D
 	return moveTasks
 }
 
-func (p *Processor) cleanupWorkDir(workDir, completedDir string) error {
+func (p *Processor) cleanupWorkDir(workDir, completedDir string, cleanupMap map[string]struct{}) error {
 	workDir = strings.TrimSpace(workDir)
 	completedDir = strings.TrimSpace(completedDir)
 
@@ -434,7 +552,7 @@ func (p *Processor) cleanupWorkDir(workDir, completedDir string) error {
 		}
 
 		// Remove known downloader leftovers from the original work dir.
-		if isArchiveArtifact(path) || cleanupExtensions(entry.Name(), p.cleanupMap) {
+		if isArchiveArtifact(path) || cleanupExtensions(entry.Name(), cleanupMap) {
 			p.ctx.Logger.Debug("Cleanup leftover work file: %s", path)
 			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
 				return err
@@ -544,7 +662,8 @@ func findPrimaryPar(tasks []*domain.DownloadFile) string {
 	return ""
 }
 
-func extractPassword(input string) string {
+// ExtractPassword returns the {{password}} embedded in an NZB name or subject.
+func ExtractPassword(input string) string {
 	re := regexp.MustCompile(`\{\{(.*?)\}\}`)
 	match := re.FindStringSubmatch(input)
 	if len(match) > 1 {
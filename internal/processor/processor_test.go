@@ -1,7 +1,10 @@
 package processor
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -277,6 +280,169 @@ func TestPostProcessExtractsExtensionless7zAndMovesExtractedFilesOnly(t *testing
 	}
 }
 
+func TestPostProcessUsesCategoryDirAndCleanupList(t *testing.T) {
+	workRoot := t.TempDir()
+	outDir := filepath.Join(workRoot, "downloads", "work", "job")
+	categoryDir := filepath.Join(workRoot, "tv-shows")
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		t.Fatalf("mkdir outDir: %v", err)
+	}
+	for _, name := range []string{"episode.mkv", "episode.nfo", "episode.sfv"} {
+		if err := os.WriteFile(filepath.Join(outDir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	log, err := logger.New("none", logger.LevelError, false)
+	if err != nil {
+		t.Fatalf("logger.New() error = %v", err)
+	}
+
+	processor := New(&app.Context{
+		Config: &config.Config{
+			Download: config.DownloadConfig{
+				OutDir:            filepath.Join(workRoot, "downloads", "work"),
+				CompletedDir:      filepath.Join(workRoot, "downloads", "completed"),
+				CleanupExtensions: []string{"nfo"},
+				Categories: []config.CategoryConfig{{
+					Name:              "TV",
+					CompletedDir:      categoryDir,
+					PostProcessing:    config.PostProcessNone,
+					CleanupExtensions: []string{"sfv"},
+				}},
+			},
+		},
+		Logger: log,
+	}, nil)
+
+	item := &domain.QueueItem{
+		OutDir:  outDir,
+		Release: &domain.Release{Title: "Show", Category: "tv"},
+	}
+	var tasks []*domain.DownloadFile
+	for _, name := range []string{"episode.mkv", "episode.nfo", "episode.sfv"} {
+		tasks = append(tasks, &domain.DownloadFile{FinalPath: filepath.Join(outDir, name), FileName: name})
+	}
+
+	if err := processor.PostProcess(context.Background(), item, tasks); err != nil {
+		t.Fatalf("PostProcess() error = %v", err)
+	}
+
+	if want := filepath.Join(categoryDir, "job"); item.OutDir != want {
+		t.Fatalf("completed dir = %s, want %s", item.OutDir, want)
+	}
+	for name, wantKept := range map[string]bool{"episode.mkv": true, "episode.nfo": true, "episode.sfv": false} {
+		_, err := os.Stat(filepath.Join(item.OutDir, name))
+		if kept := err == nil; kept != wantKept {
+			t.Fatalf("%s kept=%v, want %v", name, kept, wantKept)
+		}
+	}
+}
+
+func TestPostProcessTriesArchivePasswordLists(t *testing.T) {
+	outDir := t.TempDir()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	writeZipCryptoMember(t, zw, "movie.mkv", []byte("movie payload"), "global-secret")
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	archivePath := filepath.Join(outDir, "movie.zip")
+	writeFile(t, archivePath, buf.Bytes())
+
+	log, err := logger.New("none", logger.LevelError, false)
+	if err != nil {
+		t.Fatalf("logger.New() error = %v", err)
+	}
+
+	newProcessor := func(global []string) *Processor {
+		return New(&app.Context{
+			Config: &config.Config{
+				Download: config.DownloadConfig{
+					OutDir:           outDir,
+					ArchivePasswords: global,
+					Categories: []config.CategoryConfig{{
+						Name:           "movies",
+						PostProcessing: config.PostProcessUnpack,
+						Passwords:      []string{"category-guess"},
+					}},
+				},
+			},
+			Logger:            log,
+			ExtractionEnabled: true,
+		}, nil)
+	}
+	item := &domain.QueueItem{
+		OutDir:  outDir,
+		Release: &domain.Release{Title: "Movie", Category: "movies"},
+	}
+	tasks := []*domain.DownloadFile{{FinalPath: archivePath, FileName: "movie.zip", Password: "nzb-guess"}}
+
+	err = newProcessor([]string{"other"}).PostProcess(context.Background(), item, tasks)
+	if !errors.Is(err, ErrArchivePassword) {
+		t.Fatalf("PostProcess() error = %v, want ErrArchivePassword", err)
+	}
+
+	if err := newProcessor([]string{"other", "global-secret"}).PostProcess(context.Background(), item, tasks); err != nil {
+		t.Fatalf("PostProcess() error = %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(outDir, "movie.mkv"))
+	if err != nil || string(got) != "movie payload" {
+		t.Fatalf("extracted movie.mkv = %q, %v", got, err)
+	}
+}
+
+func TestPostProcessFailsArchivesNoExtractorCanOpen(t *testing.T) {
+	outDir := t.TempDir()
+	data, err := os.ReadFile(filepath.Join("testdata", "rar5_compressed.rar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	archivePath := filepath.Join(outDir, "release.rar")
+	writeFile(t, archivePath, data)
+
+	log, err := logger.New("none", logger.LevelError, false)
+	if err != nil {
+		t.Fatalf("logger.New() error = %v", err)
+	}
+	processor := New(&app.Context{
+		Config: &config.Config{
+			Download: config.DownloadConfig{
+				OutDir:       outDir,
+				CompletedDir: filepath.Join(outDir, "completed"),
+			},
+		},
+		Logger:            log,
+		ExtractionEnabled: true,
+	}, nil)
+	// pin the extractor to the native reader whether or not unrar is installed.
+	processor.extractor = &Manager{extractors: []Extractor{&fallbackExtractor{native: NewNativeRar(), tool: "unrar"}}}
+
+	item := &domain.QueueItem{OutDir: outDir, Release: &domain.Release{Title: "Release"}}
+	tasks := []*domain.DownloadFile{{FinalPath: archivePath, FileName: "release.rar"}}
+	err = processor.PostProcess(context.Background(), item, tasks)
+	if !errors.Is(err, ErrUnsupportedArchive) {
+		t.Fatalf("PostProcess() error = %v, want ErrUnsupportedArchive", err)
+	}
+	if _, err := os.Stat(archivePath); err != nil {
+		t.Fatalf("archive should stay in the work dir for a retry: %v", err)
+	}
+}
+
+func TestArchivePasswordsKeepOrderAndDropRepeats(t *testing.T) {
+	got := archivePasswords("nzb", []string{"cat", "", "nzb"}, []string{"global", "cat"})
+	want := []string{"nzb", "cat", "global"}
+	if len(got) != len(want) {
+		t.Fatalf("archivePasswords() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("archivePasswords() = %q, want %q", got, want)
+		}
+	}
+}
+
 func TestCleanupWorkDirRemovesNestedEmptyDirectories(t *testing.T) {
 	t.Parallel()
 
@@ -309,7 +475,7 @@ func TestCleanupWorkDirRemovesNestedEmptyDirectories(t *testing.T) {
 		ExtractionEnabled: true,
 	}, nil)
 
-	if err := processor.cleanupWorkDir(workDir, completedDir); err != nil {
+	if err := processor.cleanupWorkDir(workDir, completedDir, buildCleanupMap([]string{"nfo"})); err != nil {
 		t.Fatalf("cleanupWorkDir() error = %v", err)
 	}
 
@@ -0,0 +1,949 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// NativeRar extracts RAR4 and RAR5 archives in-process. It handles stored
+// (uncompressed) members, multi-volume sets and AES encryption, which covers
+// the usual Usenet release; compressed members are left to unrar.
+type NativeRar struct{}
+
+func NewNativeRar() *NativeRar {
+	return &NativeRar{}
+}
+
+func (r *NativeRar) Name() string {
+	return "RAR"
+}
+
+func (r *NativeRar) CanExtract(filePath string) (bool, error) {
+	return canExtractRar(filePath)
+}
+
+func (r *NativeRar) Extract(ctx context.Context, archivePath, destDir, password string) ([]string, error) {
+	return r.ExtractWithProgress(ctx, archivePath, destDir, password, nil)
+}
+
+func (r *NativeRar) ExtractWithProgress(ctx context.Context, archivePath, destDir, password string, onProgress func(ExtractProgress)) ([]string, error) {
+	set, err := openRarSet(archivePath, password)
+	if err != nil {
+		return nil, err
+	}
+	defer set.Close()
+
+	var total int64
+	members := 0
+	for _, e := range set.entries {
+		if e.dir || e.skip {
+			continue
+		}
+		switch {
+		case !e.stored:
+			return nil, fmt.Errorf("%s: %w: compressed RAR data", e.name, ErrUnsupportedArchive)
+		case e.splitAfter:
+			return nil, fmt.Errorf("%s: missing next RAR volume", e.name)
+		case e.encrypted && e.key == nil:
+			return nil, fmt.Errorf("%s: %w", e.name, ErrArchivePassword)
+		}
+		total += e.unpackedSize
+		members++
+	}
+
+	return runNativeExtract(ctx, archivePath, destDir, onProgress, func(sink *extractSink) error {
+		sink.setTotals(members, total)
+		for _, e := range set.entries {
+			switch {
+			case e.skip:
+				continue
+			case e.dir:
+				if err := sink.mkdir(e.name); err != nil {
+					return err
+				}
+				continue
+			}
+			err := sink.writeFile(e.name, e.reader(), e.verify())
+			if e.encrypted && errors.Is(err, errArchiveChecksum) {
+				// RAR4 has no password check value, so a wrong password
+				// shows up as a bad CRC.
+				err = fmt.Errorf("%s: %w", e.name, ErrArchivePassword)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+type rarPart struct {
+	file   *os.File
+	offset int64
+	size   int64
+}
+
+type rarEntry struct {
+	name         string
+	dir          bool
+	skip         bool // links and other entries with nothing to write
+	stored       bool
+	unpackedSize int64 // -1 when unknown
+	crc          uint32
+	hasCRC       bool
+	encrypted    bool
+	key          []byte
+	iv           []byte
+	hashKey      []byte // RAR5: CRCs are HMAC-ed with this key
+	splitBefore  bool
+	splitAfter   bool
+	parts        []rarPart
+}
+
+func (e *rarEntry) reader() io.Reader {
+	readers := make([]io.Reader, len(e.parts))
+	for i, p := range e.parts {
+		readers[i] = io.NewSectionReader(p.file, p.offset, p.size)
+	}
+	return e.decode(io.MultiReader(readers...))
+}
+
+// decode turns the member's raw stored bytes into its contents.
+func (e *rarEntry) decode(r io.Reader) io.Reader {
+	if e.encrypted {
+		block, _ := aes.NewCipher(e.key)
+		r = newCBCReader(r, cipher.NewCBCDecrypter(block, e.iv))
+	}
+	if e.unpackedSize >= 0 {
+		r = io.LimitReader(r, e.unpackedSize)
+	}
+	return r
+}
+
+func (e *rarEntry) verify() func(uint32) bool {
+	if !e.hasCRC {
+		return nil
+	}
+	if e.hashKey == nil {
+		return crcEquals(e.crc)
+	}
+	return func(got uint32) bool { return rar5MACCRC(e.hashKey, got) == e.crc }
+}
+
+type rarSet struct {
+	files   []*os.File
+	entries []*rarEntry
+}
+
+func (s *rarSet) Close() {
+	for _, f := range s.files {
+		f.Close()
+	}
+}
+
+// rarVolume is what one volume contributes to the set.
+type rarVolume struct {
+	entries      []*rarEntry
+	more         bool // the end header says another volume follows
+	sawEnd       bool
+	isVolume     bool
+	newNumbering bool
+	isRar5       bool
+}
+
+// openRarSet reads the headers of every volume starting at archivePath and
+// joins split members into single entries. No member data is read.
+func openRarSet(archivePath, password string) (*rarSet, error) {
+	set := &rarSet{}
+	keys := &rarKeyCache{password: password}
+	path := archivePath
+
+	for {
+		f, vol, err := readRarVolume(path, keys)
+		if f != nil {
+			set.files = append(set.files, f)
+		}
+		if err != nil {
+			set.Close()
+			return nil, err
+		}
+
+		for _, e := range vol.entries {
+			last := len(set.entries) - 1
+			if e.splitBefore && last >= 0 && set.entries[last].splitAfter && set.entries[last].name == e.name {
+				prev := set.entries[last]
+				prev.parts = append(prev.parts, e.parts...)
+				// only the final part's checksum covers the whole member.
+				prev.crc, prev.hasCRC, prev.splitAfter = e.crc, e.hasCRC, e.splitAfter
+				continue
+			}
+			if e.splitBefore {
+				set.Close()
+				return nil, fmt.Errorf("%s: %s starts in an earlier volume", filepath.Base(path), e.name)
+			}
+			set.entries = append(set.entries, e)
+		}
+
+		if !vol.hasNext() {
+			break
+		}
+		next, ok := findVolume(vol.nextName(path))
+		if !ok {
+			if vol.sawEnd {
+				set.Close()
+				return nil, fmt.Errorf("missing RAR volume after %s", filepath.Base(path))
+			}
+			break
+		}
+		path = next
+	}
+	return set, nil
+}
+
+// readRarVolume opens path and parses its headers. The file is returned even
+// when parsing fails so the caller can close it with the rest of the set.
+func readRarVolume(path string, keys *rarKeyCache) (*os.File, *rarVolume, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sig := make([]byte, 8)
+	n, _ := f.ReadAt(sig, 0)
+	var vol *rarVolume
+	switch {
+	case hasSignature(sig[:n], rarSignatures[1:]):
+		vol, err = parseRar5Volume(f, int64(len(rarSignatures[1])), keys)
+		if vol != nil {
+			vol.isRar5 = true
+		}
+	case hasSignature(sig[:n], rarSignatures[:1]):
+		vol, err = parseRar4Volume(f, int64(len(rarSignatures[0])), keys)
+	default:
+		err = fmt.Errorf("%s: not a RAR volume", filepath.Base(path))
+	}
+	if err != nil {
+		return f, nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return f, vol, nil
+}
+
+// hasNext reports whether another volume follows this one. Old volumes
+// without an end header only say whether they belong to a set.
+func (v *rarVolume) hasNext() bool {
+	if !v.sawEnd {
+		return v.isVolume
+	}
+	return v.more
+}
+
+// nextName is the file name the volume after path should have.
+func (v *rarVolume) nextName(path string) string {
+	return nextRarVolumeName(path, v.isRar5 || v.newNumbering)
+}
+
+var rarPartVolumeRE = regexp.MustCompile(`(?i)^(.*\.part)(\d+)(\.rar)$`)
+var rarOldVolumeRE = regexp.MustCompile(`(?i)^(.*\.)([r-z])(\d{2})$`)
+
+// nextRarVolumeName follows name.partN.rar numbering, or the older
+// name.rar, name.r00 ... name.r99, name.s00 scheme.
+func nextRarVolumeName(path string, partNumbering bool) string {
+	dir, base := filepath.Split(path)
+	if m := rarPartVolumeRE.FindStringSubmatch(base); m != nil && partNumbering {
+		n, _ := strconv.Atoi(m[2])
+		return dir + fmt.Sprintf("%s%0*d%s", m[1], len(m[2]), n+1, m[3])
+	}
+	if strings.HasSuffix(strings.ToLower(base), ".rar") {
+		return dir + base[:len(base)-3] + string(base[len(base)-3]) + "00"
+	}
+	if m := rarOldVolumeRE.FindStringSubmatch(base); m != nil {
+		letter := m[2][0]
+		n, _ := strconv.Atoi(m[3])
+		if n == 99 {
+			letter, n = letter+1, -1
+		}
+		return dir + fmt.Sprintf("%s%c%02d", m[1], letter, n+1)
+	}
+	return ""
+}
+
+// rarKeyCache memoises key derivation; every member normally shares one salt
+// and the KDFs are deliberately slow.
+type rarKeyCache struct {
+	password string
+	rar5     map[string][3][]byte
+	rar3     map[string][2][]byte
+}
+
+func (c *rarKeyCache) rar5Keys(salt []byte, lg2Count byte) (key, hashKey, check []byte) {
+	id := string(salt) + string(rune(lg2Count))
+	if c.rar5 == nil {
+		c.rar5 = make(map[string][3][]byte)
+	}
+	if k, ok := c.rar5[id]; ok {
+		return k[0], k[1], k[2]
+	}
+	key, hashKey, check = deriveRar5Keys(c.password, salt, lg2Count)
+	c.rar5[id] = [3][]byte{key, hashKey, check}
+	return key, hashKey, check
+}
+
+func (c *rarKeyCache) rar3Key(salt []byte) (key, iv []byte) {
+	if c.rar3 == nil {
+		c.rar3 = make(map[string][2][]byte)
+	}
+	if k, ok := c.rar3[string(salt)]; ok {
+		return k[0], k[1]
+	}
+	key, iv = deriveRar3Key(c.password, salt)
+	c.rar3[string(salt)] = [2][]byte{key, iv}
+	return key, iv
+}
+
+// deriveRar5Keys is RAR5's PBKDF2-HMAC-SHA256: the AES key after 2^lg2Count
+// rounds, then the checksum MAC key and password check 16 and 32 rounds on.
+func deriveRar5Keys(password string, salt []byte, lg2Count byte) (key, hashKey, check []byte) {
+	mac := hmac.New(sha256.New, []byte(password))
+	mac.Write(salt)
+	mac.Write([]byte{0, 0, 0, 1})
+	u := mac.Sum(nil)
+	fn := append([]byte(nil), u...)
+
+	var out [3][]byte
+	for i, rounds := range []int{1<<lg2Count - 1, 16, 16} {
+		for j := 0; j < rounds; j++ {
+			mac.Reset()
+			mac.Write(u)
+			u = mac.Sum(u[:0])
+			for k := range fn {
+				fn[k] ^= u[k]
+			}
+		}
+		out[i] = append([]byte(nil), fn...)
+	}
+
+	check = make([]byte, 8)
+	for i, b := range out[2] {
+		check[i%8] ^= b
+	}
+	return out[0], out[1], check
+}
+
+// rar5MACCRC is the CRC RAR5 stores for encrypted members with the MAC flag.
+func rar5MACCRC(hashKey []byte, crc uint32) uint32 {
+	raw := binary.LittleEndian.AppendUint32(nil, crc)
+	mac := hmac.New(sha256.New, hashKey)
+	mac.Write(raw)
+	var out uint32
+	for i, b := range mac.Sum(nil) {
+		out ^= uint32(b) << ((i & 3) * 8)
+	}
+	return out
+}
+
+// deriveRar3Key is the RAR 2.9-4.x AES-128 key schedule: SHA1 over 2^18
+// rounds of UTF-16LE password, salt and a 24-bit counter.
+func deriveRar3Key(password string, salt []byte) (key, iv []byte) {
+	const rounds = 0x40000
+
+	raw := append(utf16LE(password), salt...)
+
+	h := sha1.New()
+	buf := make([]byte, len(raw)+3)
+	copy(buf, raw)
+	iv = make([]byte, aes.BlockSize)
+	for i := 0; i < rounds; i++ {
+		buf[len(raw)], buf[len(raw)+1], buf[len(raw)+2] = byte(i), byte(i>>8), byte(i>>16)
+		h.Write(buf)
+		if i%(rounds/16) == 0 {
+			iv[i/(rounds/16)] = h.Sum(nil)[19]
+		}
+	}
+	digest := h.Sum(nil)
+
+	key = make([]byte, 16)
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			key[i*4+j] = digest[i*4+3-j]
+		}
+	}
+	return key, iv
+}
+
+// cbcReader decrypts a stream of whole AES blocks.
+type cbcReader struct {
+	r    io.Reader
+	mode cipher.BlockMode
+	buf  []byte
+	out  []byte
+	err  error
+}
+
+func newCBCReader(r io.Reader, mode cipher.BlockMode) *cbcReader {
+	return &cbcReader{r: r, mode: mode, buf: make([]byte, 32*1024)}
+}
+
+func (c *cbcReader) Read(p []byte) (int, error) {
+	for len(c.out) == 0 {
+		if c.err != nil {
+			return 0, c.err
+		}
+		n, err := io.ReadFull(c.r, c.buf)
+		if err == io.ErrUnexpectedEOF || err == io.EOF {
+			err = io.EOF
+		}
+		c.err = err
+		n -= n % aes.BlockSize
+		c.mode.CryptBlocks(c.buf[:n], c.buf[:n])
+		c.out = c.buf[:n]
+	}
+	n := copy(p, c.out)
+	c.out = c.out[n:]
+	return n, nil
+}
+
+// rarFields is a bounds-checked cursor over a header.
+type rarFields struct {
+	b   []byte
+	bad bool
+}
+
+func (r *rarFields) take(n int) []byte {
+	if n < 0 || n > len(r.b) {
+		r.bad = true
+		r.b = nil
+		return make([]byte, max(n, 0))
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *rarFields) u8() byte     { return r.take(1)[0] }
+func (r *rarFields) u16() uint16  { return binary.LittleEndian.Uint16(r.take(2)) }
+func (r *rarFields) u32() uint32  { return binary.LittleEndian.Uint32(r.take(4)) }
+func (r *rarFields) rest() []byte { return r.take(len(r.b)) }
+
+func (r *rarFields) vint() uint64 {
+	v, n := rar5Vint(r.b)
+	if n == 0 {
+		r.bad = true
+		r.b = nil
+		return 0
+	}
+	r.b = r.b[n:]
+	return v
+}
+
+// rar5Vint decodes RAR5's 7-bits-per-byte integers; n is 0 when truncated.
+func rar5Vint(b []byte) (v uint64, n int) {
+	for i := 0; i < len(b) && i < 10; i++ {
+		v |= uint64(b[i]&0x7f) << (7 * i)
+		if b[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
+
+var errRarHeader = errors.New("corrupt RAR header")
+
+const (
+	rar5HeadMain       = 1
+	rar5HeadFile       = 2
+	rar5HeadService    = 3
+	rar5HeadEncryption = 4
+	rar5HeadEnd        = 5
+
+	rar5FlagExtra       = 0x01
+	rar5FlagData        = 0x02
+	rar5FlagSplitBefore = 0x08
+	rar5FlagSplitAfter  = 0x10
+
+	rar5FileDir         = 0x01
+	rar5FileTime        = 0x02
+	rar5FileCRC         = 0x04
+	rar5FileUnknownSize = 0x08
+
+	rar5ExtraCrypt    = 0x01
+	rar5ExtraRedirect = 0x05
+
+	rar5MaxHeader = 2 << 20
+	rar5MaxKDF    = 24
+)
+
+func parseRar5Volume(f *os.File, pos int64, keys *rarKeyCache) (*rarVolume, error) {
+	vol := &rarVolume{}
+	var headerKey []byte
+
+	for {
+		header, dataOff, err := readRar5Header(f, pos, headerKey)
+		if err == io.EOF {
+			return vol, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		fields := &rarFields{b: header}
+		headType := fields.vint()
+		flags := fields.vint()
+		var extraSize, dataSize uint64
+		if flags&rar5FlagExtra != 0 {
+			extraSize = fields.vint()
+		}
+		if flags&rar5FlagData != 0 {
+			dataSize = fields.vint()
+		}
+		if fields.bad || extraSize > uint64(len(fields.b)) {
+			return nil, errRarHeader
+		}
+		extra := fields.b[len(fields.b)-int(extraSize):]
+		fields.b = fields.b[:len(fields.b)-int(extraSize)]
+
+		switch headType {
+		case rar5HeadEncryption:
+			if headerKey, err = rar5HeaderKey(fields, keys); err != nil {
+				return nil, err
+			}
+		case rar5HeadMain:
+			archiveFlags := fields.vint()
+			vol.isVolume = archiveFlags&0x1 != 0
+		case rar5HeadFile:
+			entry, err := parseRar5File(fields, extra, flags, keys)
+			if err != nil {
+				return nil, err
+			}
+			entry.parts = []rarPart{{file: f, offset: dataOff, size: int64(dataSize)}}
+			vol.entries = append(vol.entries, entry)
+		case rar5HeadEnd:
+			vol.sawEnd = true
+			vol.more = fields.vint()&0x1 != 0
+			return vol, nil
+		}
+		pos = dataOff + int64(dataSize)
+	}
+}
+
+// readRar5Header returns the header bytes after the CRC and size fields and
+// the offset of the data area that follows. With headerKey set, the header
+// is AES-256-CBC encrypted behind a 16-byte IV.
+func readRar5Header(f *os.File, pos int64, headerKey []byte) ([]byte, int64, error) {
+	var mode cipher.BlockMode
+	if headerKey != nil {
+		iv := make([]byte, aes.BlockSize)
+		if n, _ := f.ReadAt(iv, pos); n == 0 {
+			return nil, 0, io.EOF
+		} else if n < len(iv) {
+			return nil, 0, errRarHeader
+		}
+		block, _ := aes.NewCipher(headerKey)
+		mode = cipher.NewCBCDecrypter(block, iv)
+		pos += aes.BlockSize
+	}
+
+	head := make([]byte, 16)
+	n, _ := f.ReadAt(head, pos)
+	if n == 0 {
+		return nil, 0, io.EOF
+	}
+	if mode != nil {
+		if n < len(head) {
+			return nil, 0, errRarHeader
+		}
+		mode.CryptBlocks(head, head)
+	}
+	size, vlen := rar5Vint(head[4:n])
+	if vlen == 0 || size == 0 || size > rar5MaxHeader {
+		return nil, 0, errRarHeader
+	}
+
+	total := 4 + int64(vlen) + int64(size)
+	stored := total
+	if mode != nil {
+		stored = (total + aes.BlockSize - 1) / aes.BlockSize * aes.BlockSize
+	}
+	buf := make([]byte, stored)
+	if n, _ := f.ReadAt(buf, pos); int64(n) < stored {
+		return nil, 0, errRarHeader
+	}
+	if mode != nil {
+		copy(buf, head)
+		mode.CryptBlocks(buf[len(head):], buf[len(head):])
+	}
+	if crc32.ChecksumIEEE(buf[4:total]) != binary.LittleEndian.Uint32(buf) {
+		if mode != nil {
+			return nil, 0, ErrArchivePassword
+		}
+		return nil, 0, errRarHeader
+	}
+	return buf[4+vlen : total], pos + stored, nil
+}
+
+func rar5HeaderKey(fields *rarFields, keys *rarKeyCache) ([]byte, error) {
+	if version := fields.vint(); version != 0 {
+		return nil, fmt.Errorf("%w: RAR5 encryption version %d", ErrUnsupportedArchive, version)
+	}
+	flags := fields.vint()
+	lg2 := fields.u8()
+	salt := fields.take(16)
+	var check []byte
+	if flags&0x1 != 0 {
+		check = fields.take(8)
+	}
+	if fields.bad || lg2 > rar5MaxKDF {
+		return nil, errRarHeader
+	}
+	if keys.password == "" {
+		return nil, ErrArchivePassword
+	}
+	key, _, want := keys.rar5Keys(salt, lg2)
+	if check != nil && !bytes.Equal(check, want) {
+		return nil, ErrArchivePassword
+	}
+	return key, nil
+}
+
+func parseRar5File(fields *rarFields, extra []byte, headFlags uint64, keys *rarKeyCache) (*rarEntry, error) {
+	fileFlags := fields.vint()
+	entry := &rarEntry{
+		unpackedSize: int64(fields.vint()),
+		dir:          fileFlags&rar5FileDir != 0,
+		splitBefore:  headFlags&rar5FlagSplitBefore != 0,
+		splitAfter:   headFlags&rar5FlagSplitAfter != 0,
+	}
+	fields.vint() // attributes
+	if fileFlags&rar5FileTime != 0 {
+		fields.u32()
+	}
+	if fileFlags&rar5FileCRC != 0 {
+		entry.crc, entry.hasCRC = fields.u32(), true
+	}
+	compression := fields.vint()
+	fields.vint() // host OS
+	entry.name = string(fields.take(int(fields.vint())))
+	if fields.bad {
+		return nil, errRarHeader
+	}
+	if fileFlags&rar5FileUnknownSize != 0 {
+		entry.unpackedSize = -1
+	}
+	entry.stored = (compression>>7)&0x7 == 0
+
+	records := &rarFields{b: extra}
+	for len(records.b) > 0 && !records.bad {
+		size := records.vint()
+		record := &rarFields{b: records.take(int(size))}
+		switch record.vint() {
+		case rar5ExtraCrypt:
+			if err := parseRar5Crypt(record, entry, keys); err != nil {
+				return nil, fmt.Errorf("%s: %w", entry.name, err)
+			}
+		case rar5ExtraRedirect:
+			entry.skip = true
+		}
+	}
+	if records.bad {
+		return nil, errRarHeader
+	}
+	if entry.encrypted && entry.unpackedSize < 0 {
+		return nil, fmt.Errorf("%s: %w: encrypted member of unknown size", entry.name, ErrUnsupportedArchive)
+	}
+	return entry, nil
+}
+
+func parseRar5Crypt(record *rarFields, entry *rarEntry, keys *rarKeyCache) error {
+	if version := record.vint(); version != 0 {
+		return fmt.Errorf("%w: RAR5 encryption version %d", ErrUnsupportedArchive, version)
+	}
+	flags := record.vint()
+	lg2 := record.u8()
+	salt := record.take(16)
+	entry.iv = record.take(16)
+	var check []byte
+	if flags&0x1 != 0 {
+		check = record.take(8)
+	}
+	if record.bad || lg2 > rar5MaxKDF {
+		return errRarHeader
+	}
+
+	entry.encrypted = true
+	if keys.password == "" {
+		return nil
+	}
+	key, hashKey, want := keys.rar5Keys(salt, lg2)
+	if check != nil && !bytes.Equal(check, want) {
+		return ErrArchivePassword
+	}
+	entry.key = key
+	if flags&0x2 != 0 {
+		entry.hashKey = hashKey
+	}
+	return nil
+}
+
+const (
+	rar4HeadMain = 0x73
+	rar4HeadFile = 0x74
+	rar4HeadEnd  = 0x7b
+
+	rar4MainVolume       = 0x0001
+	rar4MainNewNumbering = 0x0010
+	rar4MainPassword     = 0x0080
+
+	rar4FileSplitBefore = 0x0001
+	rar4FileSplitAfter  = 0x0002
+	rar4FilePassword    = 0x0004
+	rar4FileDirMask     = 0x00e0
+	rar4FileLarge       = 0x0100
+	rar4FileUnicode     = 0x0200
+	rar4FileSalt        = 0x0400
+	rar4LongBlock       = 0x8000
+
+	rar4MethodStore = 0x30
+)
+
+func parseRar4Volume(f *os.File, pos int64, keys *rarKeyCache) (*rarVolume, error) {
+	vol := &rarVolume{}
+	encryptedHeaders := false
+
+	for {
+		header, dataOff, err := readRar4Header(f, pos, encryptedHeaders, keys)
+		if err == io.EOF {
+			return vol, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		fields := &rarFields{b: header}
+		fields.u16() // header CRC, checked below for file headers
+		headType := fields.u8()
+		flags := fields.u16()
+		fields.u16() // header size
+		var dataSize int64
+		if flags&rar4LongBlock != 0 {
+			dataSize = int64(fields.u32())
+		}
+
+		switch headType {
+		case rar4HeadMain:
+			vol.isVolume = flags&rar4MainVolume != 0
+			vol.newNumbering = flags&rar4MainNewNumbering != 0
+			encryptedHeaders = flags&rar4MainPassword != 0
+			if encryptedHeaders && keys.password == "" {
+				return nil, ErrArchivePassword
+			}
+		case rar4HeadFile:
+			if uint16(crc32.ChecksumIEEE(header[2:])) != binary.LittleEndian.Uint16(header) {
+				if encryptedHeaders {
+					return nil, ErrArchivePassword
+				}
+				return nil, errRarHeader
+			}
+			entry, err := parseRar4File(fields, flags, keys)
+			if err != nil {
+				return nil, err
+			}
+			if flags&rar4FileLarge != 0 {
+				dataSize |= int64(binary.LittleEndian.Uint32(header[32:])) << 32
+			}
+			entry.parts = []rarPart{{file: f, offset: dataOff, size: dataSize}}
+			vol.entries = append(vol.entries, entry)
+		case rar4HeadEnd:
+			vol.sawEnd = true
+			vol.more = flags&0x1 != 0
+			return vol, nil
+		}
+		if fields.bad {
+			return nil, errRarHeader
+		}
+		pos = dataOff + dataSize
+	}
+}
+
+// readRar4Header returns a whole RAR4 block header and the offset of its data.
+// Archives made with -hp prefix each header with an 8-byte salt and encrypt it.
+func readRar4Header(f *os.File, pos int64, encrypted bool, keys *rarKeyCache) ([]byte, int64, error) {
+	var mode cipher.BlockMode
+	if encrypted {
+		salt := make([]byte, 8)
+		if n, _ := f.ReadAt(salt, pos); n == 0 {
+			return nil, 0, io.EOF
+		} else if n < len(salt) {
+			return nil, 0, errRarHeader
+		}
+		key, iv := keys.rar3Key(salt)
+		block, _ := aes.NewCipher(key)
+		mode = cipher.NewCBCDecrypter(block, iv)
+		pos += int64(len(salt))
+	}
+
+	head := make([]byte, 16)
+	n, _ := f.ReadAt(head, pos)
+	if n == 0 {
+		return nil, 0, io.EOF
+	}
+	if mode != nil {
+		if n < len(head) {
+			return nil, 0, errRarHeader
+		}
+		mode.CryptBlocks(head, head)
+	} else if n < 7 {
+		return nil, 0, errRarHeader
+	}
+
+	size := int64(binary.LittleEndian.Uint16(head[5:]))
+	if size < 7 {
+		if mode != nil {
+			return nil, 0, ErrArchivePassword
+		}
+		return nil, 0, errRarHeader
+	}
+	stored := size
+	if mode != nil {
+		stored = (size + aes.BlockSize - 1) / aes.BlockSize * aes.BlockSize
+	}
+	buf := make([]byte, max(stored, int64(len(head))))
+	if n, _ := f.ReadAt(buf[:stored], pos); int64(n) < stored {
+		return nil, 0, errRarHeader
+	}
+	if mode != nil {
+		copy(buf, head)
+		if stored > int64(len(head)) {
+			mode.CryptBlocks(buf[len(head):stored], buf[len(head):stored])
+		}
+	}
+	return buf[:size], pos + stored, nil
+}
+
+func parseRar4File(fields *rarFields, flags uint16, keys *rarKeyCache) (*rarEntry, error) {
+	entry := &rarEntry{
+		unpackedSize: int64(fields.u32()),
+		dir:          flags&rar4FileDirMask == rar4FileDirMask,
+		splitBefore:  flags&rar4FileSplitBefore != 0,
+		splitAfter:   flags&rar4FileSplitAfter != 0,
+		encrypted:    flags&rar4FilePassword != 0,
+	}
+	fields.u8() // host OS
+	entry.crc, entry.hasCRC = fields.u32(), true
+	fields.u32() // DOS time
+	unpVer := fields.u8()
+	entry.stored = fields.u8() == rar4MethodStore
+	nameSize := int(fields.u16())
+	fields.u32() // attributes
+	if flags&rar4FileLarge != 0 {
+		fields.u32() // high pack size, read by the caller
+		entry.unpackedSize |= int64(fields.u32()) << 32
+	}
+	rawName := fields.take(nameSize)
+	var salt []byte
+	if flags&rar4FileSalt != 0 {
+		salt = fields.take(8)
+	}
+	if fields.bad {
+		return nil, errRarHeader
+	}
+
+	entry.name = string(rawName)
+	if flags&rar4FileUnicode != 0 {
+		entry.name = decodeRar4Name(rawName)
+	}
+
+	if entry.encrypted && !entry.dir {
+		if unpVer < 29 {
+			return nil, fmt.Errorf("%s: %w: RAR %d.%d encryption", entry.name, ErrUnsupportedArchive, unpVer/10, unpVer%10)
+		}
+		if keys.password != "" {
+			entry.key, entry.iv = keys.rar3Key(salt)
+		}
+	}
+	return entry, nil
+}
+
+// decodeRar4Name decodes RAR4's compact unicode file names: an ASCII name,
+// a zero byte, then a flag-driven encoding of the UTF-16 name against it.
+func decodeRar4Name(raw []byte) string {
+	sep := bytes.IndexByte(raw, 0)
+	if sep < 0 {
+		return string(raw)
+	}
+	name, enc := raw[:sep], raw[sep+1:]
+	if len(enc) == 0 {
+		return string(name)
+	}
+
+	var out []uint16
+	high := uint16(enc[0])
+	pos := 1
+	var flags byte
+	flagBits := 0
+	for pos < len(enc) {
+		if flagBits == 0 {
+			flags = enc[pos]
+			pos++
+			flagBits = 8
+		}
+		switch flags >> 6 {
+		case 0:
+			if pos >= len(enc) {
+				break
+			}
+			out = append(out, uint16(enc[pos]))
+			pos++
+		case 1:
+			if pos >= len(enc) {
+				break
+			}
+			out = append(out, uint16(enc[pos])+high<<8)
+			pos++
+		case 2:
+			if pos+1 >= len(enc) {
+				pos = len(enc)
+				break
+			}
+			out = append(out, uint16(enc[pos])|uint16(enc[pos+1])<<8)
+			pos += 2
+		case 3:
+			if pos >= len(enc) {
+				break
+			}
+			length := int(enc[pos])
+			pos++
+			if length&0x80 != 0 {
+				if pos >= len(enc) {
+					break
+				}
+				correction := enc[pos]
+				pos++
+				for length = length&0x7f + 2; length > 0 && len(out) < len(name); length-- {
+					out = append(out, uint16(name[len(out)]+correction)+high<<8)
+				}
+			} else {
+				for length += 2; length > 0 && len(out) < len(name); length-- {
+					out = append(out, uint16(name[len(out)]))
+				}
+			}
+		}
+		flags <<= 2
+		flagBits -= 2
+	}
+	return string(utf16.Decode(out))
+}
@@ -2,41 +2,108 @@ package processor
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"path/filepath"
+
+	"github.com/datallboy/gonzb/internal/domain"
 )
 
 // Repairer defines the behavior for verifying and fixing downloads
 type Repairer interface {
 	// Verify checks if the file in the directory are healthy.
 	// Returns true if healthy, false if repair is needed.
-	Verify(path string) (bool, error)
+	Verify(ctx context.Context, path string) (bool, error)
 
 	// Repair attempts to fix the files using available parity volumes.
-	Repair(path string) error
+	Repair(ctx context.Context, path string) error
 }
 
-func (p *Processor) handleRepair(ctx context.Context, primaryPar string) error {
+var (
+	_ Repairer = (*NativePar2)(nil)
+	_ Repairer = (*CLIPar2)(nil)
+)
+
+const repairEventStage = "repair"
+
+func (p *Processor) handleRepair(ctx context.Context, item *domain.QueueItem, primaryPar string) error {
 	p.ctx.Logger.Debug("PAR2 Index found: %s. Verifying...", filepath.Base(primaryPar))
+	p.recordEvent(ctx, item, repairEventStage, "verify", "Verifying "+filepath.Base(primaryPar), nil)
 
-	repairer, err := NewCLIPar2()
+	repairer := NewNativePar2()
+	lastStep := int64(-1)
+	repairer.OnProgress = func(progress Par2Progress) {
+		if progress.Phase != Par2PhaseRepair || progress.Total <= 0 {
+			return
+		}
+		// one event per 10% keeps the event log readable on large sets.
+		step := progress.Done * 10 / progress.Total
+		if step == lastStep {
+			return
+		}
+		lastStep = step
+		p.recordEvent(ctx, item, repairEventStage, "progress",
+			fmt.Sprintf("Repairing: %d/%d blocks", progress.Done, progress.Total),
+			map[string]int64{"done": progress.Done, "total": progress.Total})
+	}
+
+	report, err := repairer.Scan(ctx, primaryPar)
 	if err != nil {
-		return fmt.Errorf("cannot initialize repair engine: %w", err)
+		p.recordEvent(ctx, item, repairEventStage, "failed", err.Error(), nil)
+		return fmt.Errorf("cannot verify PAR2 set: %w", err)
+	}
 
+	for _, file := range report.Files {
+		if file.Status == Par2FileOK {
+			continue
+		}
+		p.recordEvent(ctx, item, repairEventStage, file.Status,
+			fmt.Sprintf("%s: %d of %d blocks damaged", file.Name, file.DamagedBlocks, file.TotalBlocks), file)
 	}
 
-	healthy, err := repairer.Verify(ctx, primaryPar)
-	if err == nil && healthy {
+	if report.Healthy() {
 		p.ctx.Logger.Info("All files verified healthy via PAR2.")
+		p.recordEvent(ctx, item, repairEventStage, "ok", "All files verified healthy", nil)
 		return nil
 	}
 
-	// Check for Exit Code 1 (Damanged but repairable)
-	p.ctx.Logger.Warn("Files are damanged. Attemting repair...")
-	if repairErr := repairer.Repair(ctx, primaryPar); repairErr != nil {
-		return fmt.Errorf("PAR2 repair failed: %w", repairErr)
+	p.recordEvent(ctx, item, repairEventStage, "blocks",
+		fmt.Sprintf("Need %d recovery blocks, %d available", report.BlocksNeeded, report.BlocksAvailable),
+		map[string]int{"needed": report.BlocksNeeded, "available": report.BlocksAvailable})
+
+	if !report.Repairable() {
+		p.recordEvent(ctx, item, repairEventStage, "failed", "Not enough recovery blocks", nil)
+		return fmt.Errorf("PAR2 repair failed: %w: need %d, have %d", ErrPar2Unrepairable, report.BlocksNeeded, report.BlocksAvailable)
+	}
+
+	p.ctx.Logger.Warn("Files are damaged, %d blocks to rebuild. Attempting repair...", report.BlocksNeeded)
+	if err := repairer.Reconstruct(ctx, report); err != nil {
+		p.recordEvent(ctx, item, repairEventStage, "failed", err.Error(), nil)
+		return fmt.Errorf("PAR2 repair failed: %w", err)
 	}
 
 	p.ctx.Logger.Info("Repair complete.")
+	p.recordEvent(ctx, item, repairEventStage, "repaired", fmt.Sprintf("Repaired %d blocks", report.BlocksNeeded), nil)
 	return nil
 }
+
+func (p *Processor) recordEvent(ctx context.Context, item *domain.QueueItem, stage, status, message string, meta any) {
+	if item == nil || p.ctx.JobStore == nil {
+		return
+	}
+
+	ev := &domain.QueueItemEvent{
+		QueueID: item.ID,
+		Stage:   stage,
+		Status:  status,
+		Message: message,
+	}
+	if meta != nil {
+		if raw, err := json.Marshal(meta); err == nil {
+			ev.MetaJSON = string(raw)
+		}
+	}
+	if err := p.ctx.JobStore.SaveQueueEvent(ctx, ev); err != nil {
+		p.ctx.Logger.Debug("Failed to persist queue event for %s: %v", item.ID, err)
+	}
+}
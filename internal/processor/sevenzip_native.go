@@ -0,0 +1,927 @@
+package processor
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// NativeSevenZip extracts 7z archives in-process: copy, LZMA, LZMA2 and
+// deflate coders, optionally AES encrypted, and split .7z.001 sets. Filters
+// such as BCJ are left to the 7z binary.
+type NativeSevenZip struct{}
+
+func NewNativeSevenZip() *NativeSevenZip {
+	return &NativeSevenZip{}
+}
+
+func (z *NativeSevenZip) Name() string {
+	return "7-Zip"
+}
+
+func (z *NativeSevenZip) CanExtract(filePath string) (bool, error) {
+	return canExtract7z(filePath)
+}
+
+func (z *NativeSevenZip) Extract(ctx context.Context, archivePath, destDir, password string) ([]string, error) {
+	return z.ExtractWithProgress(ctx, archivePath, destDir, password, nil)
+}
+
+func (z *NativeSevenZip) ExtractWithProgress(ctx context.Context, archivePath, destDir, password string, onProgress func(ExtractProgress)) ([]string, error) {
+	mf, err := openMultiFile(sevenZipVolumes(archivePath))
+	if err != nil {
+		return nil, err
+	}
+	defer mf.Close()
+
+	keys := &sevenZipKeyCache{password: password}
+	archive, err := readSevenZipArchive(mf, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, folder := range archive.streams.folders {
+		if _, err := folder.chain(); err != nil {
+			return nil, err
+		}
+		if folder.encrypted() && password == "" {
+			return nil, ErrArchivePassword
+		}
+	}
+
+	var total int64
+	members := 0
+	for _, size := range archive.streams.subSizes {
+		total += int64(size)
+		members++
+	}
+
+	return runNativeExtract(ctx, archivePath, destDir, onProgress, func(sink *extractSink) error {
+		sink.setTotals(members, total)
+		return archive.extract(sink, mf, keys)
+	})
+}
+
+// sevenZipVolumes lists name.7z.001, .002, ... for a split archive, or just
+// archivePath otherwise.
+func sevenZipVolumes(archivePath string) []string {
+	m := splitSevenZipVolumeRE.FindStringSubmatchIndex(archivePath)
+	if m == nil {
+		return []string{archivePath}
+	}
+	prefix := archivePath[:m[2]]
+	width := m[3] - m[2]
+	paths := []string{archivePath}
+	for i := 2; ; i++ {
+		next, ok := findVolume(fmt.Sprintf("%s%0*d", prefix, width, i))
+		if !ok {
+			return paths
+		}
+		paths = append(paths, next)
+	}
+}
+
+var (
+	sevenZipMethodCopy    = []byte{0x00}
+	sevenZipMethodLZMA    = []byte{0x03, 0x01, 0x01}
+	sevenZipMethodLZMA2   = []byte{0x21}
+	sevenZipMethodDeflate = []byte{0x04, 0x01, 0x08}
+	sevenZipMethodAES     = []byte{0x06, 0xF1, 0x07, 0x01}
+
+	errSevenZipHeader = errors.New("corrupt 7z header")
+)
+
+const (
+	sevenZipEnd             = 0x00
+	sevenZipHeader          = 0x01
+	sevenZipArchiveProps    = 0x02
+	sevenZipAdditional      = 0x03
+	sevenZipMainStreams     = 0x04
+	sevenZipFilesInfo       = 0x05
+	sevenZipPackInfo        = 0x06
+	sevenZipUnpackInfo      = 0x07
+	sevenZipSubStreams      = 0x08
+	sevenZipSize            = 0x09
+	sevenZipCRC             = 0x0A
+	sevenZipFolderID        = 0x0B
+	sevenZipCodersUnpack    = 0x0C
+	sevenZipNumUnpackStream = 0x0D
+	sevenZipEmptyStream     = 0x0E
+	sevenZipEmptyFile       = 0x0F
+	sevenZipAnti            = 0x10
+	sevenZipName            = 0x11
+	sevenZipAttributes      = 0x15
+	sevenZipEncodedHeader   = 0x17
+
+	sevenZipStartHeaderSize = 32
+	sevenZipAttrDirectory   = 0x10
+)
+
+type sevenZipCoder struct {
+	id     []byte
+	numIn  uint64
+	numOut uint64
+	props  []byte
+}
+
+type sevenZipFolder struct {
+	coders        []sevenZipCoder
+	bindPairs     [][2]uint64 // in index, out index
+	packedStreams []uint64
+	unpackSizes   []uint64
+	crc           uint32
+	hasCRC        bool
+	numStreams    uint64
+	firstPack     int
+}
+
+func (f *sevenZipFolder) encrypted() bool {
+	for _, c := range f.coders {
+		if bytes.Equal(c.id, sevenZipMethodAES) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *sevenZipFolder) unpackSize() uint64 {
+	for i := range f.unpackSizes {
+		bound := false
+		for _, bp := range f.bindPairs {
+			if bp[1] == uint64(i) {
+				bound = true
+			}
+		}
+		if !bound {
+			return f.unpackSizes[i]
+		}
+	}
+	return 0
+}
+
+// chain orders the folder's coders from the final output back to the packed
+// stream. Only straight single-input chains are supported.
+func (f *sevenZipFolder) chain() ([]int, error) {
+	for _, c := range f.coders {
+		if c.numIn != 1 || c.numOut != 1 {
+			return nil, fmt.Errorf("%w: 7z coder %X with %d inputs", ErrUnsupportedArchive, c.id, c.numIn)
+		}
+		switch {
+		case bytes.Equal(c.id, sevenZipMethodCopy),
+			bytes.Equal(c.id, sevenZipMethodLZMA),
+			bytes.Equal(c.id, sevenZipMethodLZMA2),
+			bytes.Equal(c.id, sevenZipMethodDeflate),
+			bytes.Equal(c.id, sevenZipMethodAES):
+		default:
+			return nil, fmt.Errorf("%w: 7z coder %X", ErrUnsupportedArchive, c.id)
+		}
+	}
+	if len(f.packedStreams) != 1 || len(f.unpackSizes) != len(f.coders) {
+		return nil, fmt.Errorf("%w: 7z folder layout", ErrUnsupportedArchive)
+	}
+
+	// with one-in/one-out coders, coder i owns in and out stream i.
+	main := -1
+	for i := range f.coders {
+		bound := false
+		for _, bp := range f.bindPairs {
+			if bp[1] == uint64(i) {
+				bound = true
+			}
+		}
+		if !bound {
+			main = i
+			break
+		}
+	}
+	if main < 0 {
+		return nil, errSevenZipHeader
+	}
+
+	order := []int{main}
+	for c := main; ; {
+		next := -1
+		for _, bp := range f.bindPairs {
+			if bp[0] == uint64(c) {
+				next = int(bp[1])
+			}
+		}
+		if next < 0 {
+			if f.packedStreams[0] != uint64(c) {
+				return nil, errSevenZipHeader
+			}
+			return order, nil
+		}
+		if next >= len(f.coders) || len(order) > len(f.coders) {
+			return nil, errSevenZipHeader
+		}
+		order = append(order, next)
+		c = next
+	}
+}
+
+type sevenZipStreams struct {
+	packPos   uint64
+	packSizes []uint64
+	folders   []*sevenZipFolder
+	subSizes  []uint64
+	subCRCs   []uint32
+	subHasCRC []bool
+}
+
+type sevenZipFile struct {
+	name      string
+	hasStream bool
+	dir       bool
+	anti      bool
+}
+
+type sevenZipArchive struct {
+	streams *sevenZipStreams
+	files   []sevenZipFile
+}
+
+// sevenZipKeyCache memoises the AES key; the SHA-256 schedule runs 2^19
+// rounds by default and every folder usually shares one salt.
+type sevenZipKeyCache struct {
+	password string
+	keys     map[string][]byte
+}
+
+func (c *sevenZipKeyCache) key(salt []byte, power byte) []byte {
+	id := string(salt) + string(rune(power))
+	if k, ok := c.keys[id]; ok {
+		return k
+	}
+	if c.keys == nil {
+		c.keys = make(map[string][]byte)
+	}
+	k := deriveSevenZipKey(c.password, salt, power)
+	c.keys[id] = k
+	return k
+}
+
+func deriveSevenZipKey(password string, salt []byte, power byte) []byte {
+	pw := utf16LE(password)
+	if power == 0x3F {
+		key := make([]byte, 32)
+		n := copy(key, salt)
+		copy(key[n:], pw)
+		return key
+	}
+
+	h := sha256.New()
+	buf := make([]byte, len(salt)+len(pw)+8)
+	copy(buf, salt)
+	copy(buf[len(salt):], pw)
+	counter := buf[len(salt)+len(pw):]
+	for i := uint64(0); i < 1<<power; i++ {
+		binary.LittleEndian.PutUint64(counter, i)
+		h.Write(buf)
+	}
+	return h.Sum(nil)
+}
+
+func readSevenZipArchive(ra *multiFile, keys *sevenZipKeyCache) (*sevenZipArchive, error) {
+	start := make([]byte, sevenZipStartHeaderSize)
+	if _, err := ra.ReadAt(start, 0); err != nil {
+		return nil, errSevenZipHeader
+	}
+	if !bytes.HasPrefix(start, sevenZipSignature) {
+		return nil, fmt.Errorf("%w: not a 7z archive", ErrUnsupportedArchive)
+	}
+	if crc32.ChecksumIEEE(start[12:]) != binary.LittleEndian.Uint32(start[8:]) {
+		return nil, errSevenZipHeader
+	}
+	offset := binary.LittleEndian.Uint64(start[12:])
+	size := binary.LittleEndian.Uint64(start[20:])
+	if size == 0 {
+		return &sevenZipArchive{streams: &sevenZipStreams{}}, nil
+	}
+	if offset+size+sevenZipStartHeaderSize > uint64(ra.Size()) || size > 1<<30 {
+		return nil, errSevenZipHeader
+	}
+
+	header := make([]byte, size)
+	if _, err := ra.ReadAt(header, int64(sevenZipStartHeaderSize+offset)); err != nil {
+		return nil, errSevenZipHeader
+	}
+	if crc32.ChecksumIEEE(header) != binary.LittleEndian.Uint32(start[28:]) {
+		return nil, errSevenZipHeader
+	}
+
+	for {
+		r := &sevenZipFields{b: header}
+		switch r.u8() {
+		case sevenZipHeader:
+			archive, err := r.header()
+			if err == nil && r.bad {
+				err = errSevenZipHeader
+			}
+			return archive, err
+		case sevenZipEncodedHeader:
+			streams, err := r.streamsInfo()
+			if err != nil {
+				return nil, err
+			}
+			if r.bad || len(streams.folders) == 0 {
+				return nil, errSevenZipHeader
+			}
+			folder := streams.folders[0]
+			if folder.encrypted() && keys.password == "" {
+				return nil, ErrArchivePassword
+			}
+			fr, err := streams.folderReader(ra, 0, keys)
+			if err != nil {
+				return nil, err
+			}
+			decoded, err := io.ReadAll(fr)
+			if err == nil && folder.hasCRC && crc32.ChecksumIEEE(decoded) != folder.crc {
+				err = errArchiveChecksum
+			}
+			if err != nil {
+				if folder.encrypted() {
+					return nil, ErrArchivePassword
+				}
+				return nil, fmt.Errorf("7z header: %w", err)
+			}
+			header = decoded
+		default:
+			return nil, errSevenZipHeader
+		}
+	}
+}
+
+// folderReader decodes folder i from its packed stream.
+func (s *sevenZipStreams) folderReader(ra io.ReaderAt, i int, keys *sevenZipKeyCache) (io.Reader, error) {
+	folder := s.folders[i]
+	order, err := folder.chain()
+	if err != nil {
+		return nil, err
+	}
+
+	offset := sevenZipStartHeaderSize + s.packPos
+	for _, size := range s.packSizes[:folder.firstPack] {
+		offset += size
+	}
+	var r io.Reader = io.NewSectionReader(ra, int64(offset), int64(s.packSizes[folder.firstPack]))
+
+	for j := len(order) - 1; j >= 0; j-- {
+		coder := folder.coders[order[j]]
+		size := folder.unpackSizes[order[j]]
+		switch {
+		case bytes.Equal(coder.id, sevenZipMethodCopy):
+		case bytes.Equal(coder.id, sevenZipMethodLZMA):
+			r, err = newLZMAReader(r, coder.props, size)
+		case bytes.Equal(coder.id, sevenZipMethodLZMA2):
+			r, err = newLZMA2Reader(r, coder.props, size)
+		case bytes.Equal(coder.id, sevenZipMethodDeflate):
+			r = flate.NewReader(r)
+		case bytes.Equal(coder.id, sevenZipMethodAES):
+			r, err = newSevenZipAESReader(r, coder.props, keys)
+		}
+		if err != nil {
+			return nil, err
+		}
+		r = io.LimitReader(r, int64(size))
+	}
+	return r, nil
+}
+
+func newSevenZipAESReader(r io.Reader, props []byte, keys *sevenZipKeyCache) (io.Reader, error) {
+	if len(props) < 1 {
+		return nil, errSevenZipHeader
+	}
+	power := props[0] & 0x3F
+	var salt, iv []byte
+	if props[0]&0xC0 != 0 {
+		if len(props) < 2 {
+			return nil, errSevenZipHeader
+		}
+		saltSize := int(props[0]>>7&1) + int(props[1]>>4)
+		ivSize := int(props[0]>>6&1) + int(props[1]&0x0F)
+		if len(props) < 2+saltSize+ivSize {
+			return nil, errSevenZipHeader
+		}
+		salt = props[2 : 2+saltSize]
+		iv = props[2+saltSize : 2+saltSize+ivSize]
+	}
+	if power > 24 && power != 0x3F {
+		return nil, fmt.Errorf("%w: 7z AES cycle power %d", ErrUnsupportedArchive, power)
+	}
+
+	fullIV := make([]byte, aes.BlockSize)
+	copy(fullIV, iv)
+	block, err := aes.NewCipher(keys.key(salt, power))
+	if err != nil {
+		return nil, err
+	}
+	return newCBCReader(r, cipher.NewCBCDecrypter(block, fullIV)), nil
+}
+
+func (a *sevenZipArchive) extract(sink *extractSink, ra io.ReaderAt, keys *sevenZipKeyCache) error {
+	s := a.streams
+	folderIdx, inFolder, stream := 0, uint64(0), 0
+	var folderR io.Reader
+
+	for _, f := range a.files {
+		switch {
+		case f.anti:
+			continue
+		case f.dir:
+			if err := sink.mkdir(f.name); err != nil {
+				return err
+			}
+			continue
+		case !f.hasStream:
+			if err := sink.writeFile(f.name, bytes.NewReader(nil), nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for folderIdx < len(s.folders) && inFolder >= s.folders[folderIdx].numStreams {
+			folderIdx, inFolder, folderR = folderIdx+1, 0, nil
+		}
+		if folderIdx >= len(s.folders) || stream >= len(s.subSizes) {
+			return errSevenZipHeader
+		}
+		folder := s.folders[folderIdx]
+		if folderR == nil {
+			var err error
+			if folderR, err = s.folderReader(ra, folderIdx, keys); err != nil {
+				return err
+			}
+		}
+
+		var verify func(uint32) bool
+		if s.subHasCRC[stream] {
+			verify = crcEquals(s.subCRCs[stream])
+		}
+		err := sink.writeFile(f.name, io.LimitReader(folderR, int64(s.subSizes[stream])), verify)
+		if err != nil && folder.encrypted() && (errors.Is(err, errArchiveChecksum) || errors.Is(err, errLZMAData)) {
+			// 7z stores no password check; a wrong key decodes to garbage.
+			return fmt.Errorf("%s: %w", f.name, ErrArchivePassword)
+		}
+		if err != nil {
+			return err
+		}
+		inFolder++
+		stream++
+	}
+	return nil
+}
+
+// sevenZipFields is a bounds-checked cursor over a 7z header.
+type sevenZipFields struct {
+	b   []byte
+	bad bool
+}
+
+func (r *sevenZipFields) take(n uint64) []byte {
+	if n > uint64(len(r.b)) {
+		r.bad = true
+		r.b = nil
+		return nil
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *sevenZipFields) u8() byte {
+	if v := r.take(1); v != nil {
+		return v[0]
+	}
+	return 0
+}
+
+func (r *sevenZipFields) u32() uint32 {
+	if v := r.take(4); v != nil {
+		return binary.LittleEndian.Uint32(v)
+	}
+	return 0
+}
+
+// number reads 7z's variable-length UINT64: the count of leading one bits in
+// the first byte gives the number of extra little-endian bytes.
+func (r *sevenZipFields) number() uint64 {
+	first := r.u8()
+	var value uint64
+	mask := byte(0x80)
+	for i := 0; i < 8; i++ {
+		if first&mask == 0 {
+			return value | uint64(first&(mask-1))<<(8*i)
+		}
+		value |= uint64(r.u8()) << (8 * i)
+		mask >>= 1
+	}
+	return value
+}
+
+// count reads a number used as an item count, rejecting absurd values
+// before they size an allocation.
+func (r *sevenZipFields) count() int {
+	n := r.number()
+	if n > uint64(len(r.b))*8+1 || n > 1<<24 {
+		r.bad = true
+		return 0
+	}
+	return int(n)
+}
+
+func (r *sevenZipFields) bitVector(n int) []bool {
+	out := make([]bool, n)
+	var b byte
+	for i := 0; i < n; i++ {
+		if i%8 == 0 {
+			b = r.u8()
+		}
+		out[i] = b&(0x80>>(i%8)) != 0
+	}
+	return out
+}
+
+func (r *sevenZipFields) optionalBitVector(n int) []bool {
+	if r.u8() != 0 {
+		out := make([]bool, n)
+		for i := range out {
+			out[i] = true
+		}
+		return out
+	}
+	return r.bitVector(n)
+}
+
+func (r *sevenZipFields) digests(n int) ([]uint32, []bool) {
+	defined := r.optionalBitVector(n)
+	crcs := make([]uint32, n)
+	for i := range crcs {
+		if defined[i] {
+			crcs[i] = r.u32()
+		}
+	}
+	return crcs, defined
+}
+
+func (r *sevenZipFields) skipProperty() {
+	r.take(r.number())
+}
+
+func (r *sevenZipFields) header() (*sevenZipArchive, error) {
+	archive := &sevenZipArchive{streams: &sevenZipStreams{}}
+	id := r.u8()
+	if id == sevenZipArchiveProps {
+		for r.number() != sevenZipEnd && !r.bad {
+			r.skipProperty()
+		}
+		id = r.u8()
+	}
+	if id == sevenZipAdditional {
+		if _, err := r.streamsInfo(); err != nil {
+			return nil, err
+		}
+		id = r.u8()
+	}
+	if id == sevenZipMainStreams {
+		streams, err := r.streamsInfo()
+		if err != nil {
+			return nil, err
+		}
+		archive.streams = streams
+		id = r.u8()
+	}
+	if id == sevenZipFilesInfo {
+		archive.files = r.filesInfo()
+		id = r.u8()
+	}
+	if id != sevenZipEnd || r.bad {
+		return nil, errSevenZipHeader
+	}
+
+	withStreams := 0
+	for _, f := range archive.files {
+		if f.hasStream {
+			withStreams++
+		}
+	}
+	if withStreams != len(archive.streams.subSizes) {
+		return nil, errSevenZipHeader
+	}
+	return archive, nil
+}
+
+func (r *sevenZipFields) streamsInfo() (*sevenZipStreams, error) {
+	s := &sevenZipStreams{}
+	id := r.u8()
+	if id == sevenZipPackInfo {
+		s.packPos = r.number()
+		s.packSizes = make([]uint64, r.count())
+		for id = r.u8(); id != sevenZipEnd && !r.bad; id = r.u8() {
+			switch id {
+			case sevenZipSize:
+				for i := range s.packSizes {
+					s.packSizes[i] = r.number()
+				}
+			case sevenZipCRC:
+				r.digests(len(s.packSizes))
+			default:
+				r.skipProperty()
+			}
+		}
+		id = r.u8()
+	}
+
+	if id == sevenZipUnpackInfo {
+		if err := r.unpackInfo(s); err != nil {
+			return nil, err
+		}
+		id = r.u8()
+	}
+
+	for _, f := range s.folders {
+		f.numStreams = 1
+	}
+	if id == sevenZipSubStreams {
+		r.subStreamsInfo(s)
+		id = r.u8()
+	} else {
+		for _, f := range s.folders {
+			s.subSizes = append(s.subSizes, f.unpackSize())
+			s.subCRCs = append(s.subCRCs, f.crc)
+			s.subHasCRC = append(s.subHasCRC, f.hasCRC)
+		}
+	}
+
+	if id != sevenZipEnd || r.bad {
+		return nil, errSevenZipHeader
+	}
+
+	pack := 0
+	for _, f := range s.folders {
+		f.firstPack = pack
+		pack += len(f.packedStreams)
+	}
+	if pack > len(s.packSizes) {
+		return nil, errSevenZipHeader
+	}
+	return s, nil
+}
+
+func (r *sevenZipFields) unpackInfo(s *sevenZipStreams) error {
+	if r.u8() != sevenZipFolderID {
+		return errSevenZipHeader
+	}
+	s.folders = make([]*sevenZipFolder, r.count())
+	if r.u8() != 0 {
+		return fmt.Errorf("%w: external 7z folders", ErrUnsupportedArchive)
+	}
+	for i := range s.folders {
+		s.folders[i] = r.folder()
+	}
+	if r.u8() != sevenZipCodersUnpack {
+		return errSevenZipHeader
+	}
+	for _, f := range s.folders {
+		for i := range f.unpackSizes {
+			f.unpackSizes[i] = r.number()
+		}
+	}
+	for id := r.u8(); id != sevenZipEnd && !r.bad; id = r.u8() {
+		if id != sevenZipCRC {
+			r.skipProperty()
+			continue
+		}
+		crcs, defined := r.digests(len(s.folders))
+		for i, f := range s.folders {
+			f.crc, f.hasCRC = crcs[i], defined[i]
+		}
+	}
+	if r.bad {
+		return errSevenZipHeader
+	}
+	return nil
+}
+
+func (r *sevenZipFields) folder() *sevenZipFolder {
+	f := &sevenZipFolder{coders: make([]sevenZipCoder, r.count())}
+	var totalIn, totalOut uint64
+	for i := range f.coders {
+		flags := r.u8()
+		c := sevenZipCoder{id: r.take(uint64(flags & 0x0F)), numIn: 1, numOut: 1}
+		if flags&0x10 != 0 {
+			c.numIn, c.numOut = r.number(), r.number()
+		}
+		if flags&0x20 != 0 {
+			c.props = r.take(r.number())
+		}
+		if flags&0x80 != 0 || c.numIn > 32 || c.numOut > 32 {
+			r.bad = true
+		}
+		f.coders[i] = c
+		totalIn += c.numIn
+		totalOut += c.numOut
+	}
+	if r.bad || totalOut == 0 || totalIn < totalOut-1 {
+		r.bad = true
+		return f
+	}
+
+	f.bindPairs = make([][2]uint64, totalOut-1)
+	for i := range f.bindPairs {
+		f.bindPairs[i] = [2]uint64{r.number(), r.number()}
+	}
+	numPacked := totalIn - (totalOut - 1)
+	if numPacked == 1 {
+		for i := uint64(0); i < totalIn; i++ {
+			bound := false
+			for _, bp := range f.bindPairs {
+				if bp[0] == i {
+					bound = true
+				}
+			}
+			if !bound {
+				f.packedStreams = append(f.packedStreams, i)
+				break
+			}
+		}
+	} else {
+		for i := uint64(0); i < numPacked; i++ {
+			f.packedStreams = append(f.packedStreams, r.number())
+		}
+	}
+	f.unpackSizes = make([]uint64, totalOut)
+	return f
+}
+
+func (r *sevenZipFields) subStreamsInfo(s *sevenZipStreams) {
+	id := r.u8()
+	if id == sevenZipNumUnpackStream {
+		for _, f := range s.folders {
+			f.numStreams = r.number()
+			if f.numStreams > 1<<24 {
+				r.bad = true
+				return
+			}
+		}
+		id = r.u8()
+	}
+
+	hasSizes := id == sevenZipSize
+	for _, f := range s.folders {
+		if f.numStreams == 0 {
+			continue
+		}
+		var sum uint64
+		for j := uint64(1); j < f.numStreams && hasSizes; j++ {
+			size := r.number()
+			s.subSizes = append(s.subSizes, size)
+			sum += size
+		}
+		if sum > f.unpackSize() {
+			r.bad = true
+			return
+		}
+		s.subSizes = append(s.subSizes, f.unpackSize()-sum)
+	}
+	if hasSizes {
+		id = r.u8()
+	}
+
+	// streams whose CRC is not already the folder's come next, in order.
+	unknown := 0
+	for _, f := range s.folders {
+		if f.numStreams != 1 || !f.hasCRC {
+			unknown += int(f.numStreams)
+		}
+	}
+	var crcs []uint32
+	var defined []bool
+	for ; id != sevenZipEnd && !r.bad; id = r.u8() {
+		if id == sevenZipCRC {
+			crcs, defined = r.digests(unknown)
+			continue
+		}
+		r.skipProperty()
+	}
+
+	k := 0
+	for _, f := range s.folders {
+		if f.numStreams == 1 && f.hasCRC {
+			s.subCRCs = append(s.subCRCs, f.crc)
+			s.subHasCRC = append(s.subHasCRC, true)
+			continue
+		}
+		for j := uint64(0); j < f.numStreams; j++ {
+			if k < len(crcs) {
+				s.subCRCs = append(s.subCRCs, crcs[k])
+				s.subHasCRC = append(s.subHasCRC, defined[k])
+			} else {
+				s.subCRCs = append(s.subCRCs, 0)
+				s.subHasCRC = append(s.subHasCRC, false)
+			}
+			k++
+		}
+	}
+}
+
+func (r *sevenZipFields) filesInfo() []sevenZipFile {
+	files := make([]sevenZipFile, r.count())
+	for i := range files {
+		files[i].hasStream = true
+	}
+	var emptyStream, emptyFile, anti []bool
+
+	for propType := r.number(); propType != sevenZipEnd && !r.bad; propType = r.number() {
+		data := &sevenZipFields{b: r.take(r.number())}
+		switch propType {
+		case sevenZipEmptyStream:
+			emptyStream = data.bitVector(len(files))
+			for i := range files {
+				files[i].hasStream = !emptyStream[i]
+			}
+		case sevenZipEmptyFile:
+			emptyFile = data.bitVector(countTrue(emptyStream))
+		case sevenZipAnti:
+			anti = data.bitVector(countTrue(emptyStream))
+		case sevenZipName:
+			if data.u8() != 0 {
+				r.bad = true
+				break
+			}
+			names := decodeUTF16Names(data.b)
+			for i := range files {
+				if i < len(names) {
+					files[i].name = names[i]
+				}
+			}
+		case sevenZipAttributes:
+			defined := data.optionalBitVector(len(files))
+			if data.u8() != 0 {
+				r.bad = true
+				break
+			}
+			for i := range files {
+				if defined[i] && data.u32()&sevenZipAttrDirectory != 0 {
+					files[i].dir = true
+				}
+			}
+		}
+		if data.bad {
+			r.bad = true
+		}
+	}
+
+	empty := 0
+	for i := range files {
+		if files[i].hasStream {
+			files[i].dir = false
+			continue
+		}
+		// an empty stream is a directory unless flagged as an empty file.
+		files[i].dir = empty >= len(emptyFile) || !emptyFile[empty]
+		files[i].anti = empty < len(anti) && anti[empty]
+		empty++
+	}
+	for i := range files {
+		if files[i].name == "" {
+			r.bad = true
+		}
+	}
+	return files
+}
+
+func countTrue(bits []bool) int {
+	n := 0
+	for _, b := range bits {
+		if b {
+			n++
+		}
+	}
+	return n
+}
+
+func decodeUTF16Names(b []byte) []string {
+	var names []string
+	var cur []uint16
+	for i := 0; i+1 < len(b); i += 2 {
+		c := binary.LittleEndian.Uint16(b[i:])
+		if c == 0 {
+			names = append(names, strings.ReplaceAll(string(utf16.Decode(cur)), "\\", "/"))
+			cur = cur[:0]
+			continue
+		}
+		cur = append(cur, c)
+	}
+	return names
+}
@@ -31,6 +31,12 @@ func (u *CLIUnrar) Name() string {
 }
 
 func (u *CLIUnrar) CanExtract(filePath string) (bool, error) {
+	return canExtractRar(filePath)
+}
+
+// canExtractRar accepts the first volume of a RAR set: name.rar,
+// name.part01.rar or an extensionless file with a RAR signature.
+func canExtractRar(filePath string) (bool, error) {
 	lower := strings.ToLower(filepath.Base(filePath))
 
 	switch {
@@ -34,6 +34,10 @@ func (u *CLIUnzip) Name() string {
 
 // CanExtract checks if the file is a ZIP archive
 func (u *CLIUnzip) CanExtract(filePath string) (bool, error) {
+	return canExtractZip(filePath)
+}
+
+func canExtractZip(filePath string) (bool, error) {
 	lower := strings.ToLower(filepath.Base(filePath))
 
 	if !strings.HasSuffix(lower, ".zip") && filepath.Ext(lower) != "" {
@@ -0,0 +1,315 @@
+package processor
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"strings"
+)
+
+const (
+	zipMethodWinZipAES = 99
+	zipExtraWinZipAES  = 0x9901
+	zipFlagEncrypted   = 0x1
+	zipFlagDescriptor  = 0x8
+)
+
+// NativeZip extracts ZIP archives in-process, including ZipCrypto and
+// WinZip AES encrypted members.
+type NativeZip struct{}
+
+func NewNativeZip() *NativeZip {
+	return &NativeZip{}
+}
+
+func (z *NativeZip) Name() string {
+	return "ZIP"
+}
+
+func (z *NativeZip) CanExtract(filePath string) (bool, error) {
+	return canExtractZip(filePath)
+}
+
+func (z *NativeZip) Extract(ctx context.Context, archivePath, destDir, password string) ([]string, error) {
+	return z.ExtractWithProgress(ctx, archivePath, destDir, password, nil)
+}
+
+func (z *NativeZip) ExtractWithProgress(ctx context.Context, archivePath, destDir, password string, onProgress func(ExtractProgress)) ([]string, error) {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedArchive, err)
+	}
+	defer r.Close()
+
+	// refuse the whole archive up front so the CLI fallback never sees a
+	// half-extracted work dir.
+	var total int64
+	members := 0
+	for _, f := range r.File {
+		if strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+		if _, err := zipMemberMethod(f); err != nil {
+			return nil, err
+		}
+		if f.Flags&zipFlagEncrypted != 0 && password == "" {
+			return nil, fmt.Errorf("%s: %w", f.Name, ErrArchivePassword)
+		}
+		total += int64(f.UncompressedSize64)
+		members++
+	}
+
+	return runNativeExtract(ctx, archivePath, destDir, onProgress, func(sink *extractSink) error {
+		sink.setTotals(members, total)
+		for _, f := range r.File {
+			if strings.HasSuffix(f.Name, "/") {
+				if err := sink.mkdir(f.Name); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := z.extractMember(sink, f, password); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (z *NativeZip) extractMember(sink *extractSink, f *zip.File, password string) error {
+	if f.Flags&zipFlagEncrypted == 0 {
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Name, err)
+		}
+		defer rc.Close()
+		// archive/zip verifies the CRC itself.
+		return sink.writeFile(f.Name, rc, nil)
+	}
+
+	method, err := zipMemberMethod(f)
+	if err != nil {
+		return err
+	}
+	raw, err := f.OpenRaw()
+	if err != nil {
+		return fmt.Errorf("%s: %w", f.Name, err)
+	}
+
+	var plain io.Reader
+	verify := crcEquals(f.CRC32)
+	if f.Method == zipMethodWinZipAES {
+		aesInfo, _ := zipAESExtra(f.Extra)
+		plain, err = newZipAESReader(raw, int64(f.CompressedSize64), aesInfo.strength, password)
+		// AE-2 stores no CRC; the HMAC covers integrity instead.
+		if aesInfo.version != 1 {
+			verify = nil
+		}
+	} else {
+		plain, err = newZipCryptoReader(raw, password, zipCryptoCheckByte(f))
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", f.Name, err)
+	}
+
+	if method == zip.Deflate {
+		fr := flate.NewReader(plain)
+		defer fr.Close()
+		plain = fr
+	}
+	return sink.writeFile(f.Name, plain, verify)
+}
+
+// zipMemberMethod returns the compression method of f, looking through the
+// WinZip AES wrapper, or ErrUnsupportedArchive for anything but store/deflate.
+func zipMemberMethod(f *zip.File) (uint16, error) {
+	method := f.Method
+	if method == zipMethodWinZipAES {
+		info, ok := zipAESExtra(f.Extra)
+		if !ok {
+			return 0, fmt.Errorf("%s: %w: missing WinZip AES header", f.Name, ErrUnsupportedArchive)
+		}
+		method = info.method
+	}
+	if method != zip.Store && method != zip.Deflate {
+		return 0, fmt.Errorf("%s: %w: compression method %d", f.Name, ErrUnsupportedArchive, method)
+	}
+	return method, nil
+}
+
+type zipAESInfo struct {
+	version  uint16
+	strength byte
+	method   uint16
+}
+
+func zipAESExtra(extra []byte) (zipAESInfo, bool) {
+	for len(extra) >= 4 {
+		id := binary.LittleEndian.Uint16(extra)
+		size := int(binary.LittleEndian.Uint16(extra[2:]))
+		extra = extra[4:]
+		if size > len(extra) {
+			break
+		}
+		if id == zipExtraWinZipAES && size >= 7 {
+			return zipAESInfo{
+				version:  binary.LittleEndian.Uint16(extra),
+				strength: extra[4],
+				method:   binary.LittleEndian.Uint16(extra[5:]),
+			}, true
+		}
+		extra = extra[size:]
+	}
+	return zipAESInfo{}, false
+}
+
+// zipCryptoCheckByte is the last byte of the 12-byte ZipCrypto header: the
+// CRC's high byte, or the DOS time's high byte when a data descriptor is used.
+func zipCryptoCheckByte(f *zip.File) byte {
+	if f.Flags&zipFlagDescriptor != 0 {
+		return byte(f.ModifiedTime >> 8)
+	}
+	return byte(f.CRC32 >> 24)
+}
+
+// zipCryptoKeys is the traditional PKWARE stream cipher state.
+type zipCryptoKeys [3]uint32
+
+func newZipCryptoKeys(password string) *zipCryptoKeys {
+	k := &zipCryptoKeys{0x12345678, 0x23456789, 0x34567890}
+	for i := 0; i < len(password); i++ {
+		k.update(password[i])
+	}
+	return k
+}
+
+func (k *zipCryptoKeys) update(b byte) {
+	k[0] = crc32.IEEETable[byte(k[0])^b] ^ (k[0] >> 8)
+	k[1] = (k[1]+(k[0]&0xff))*134775813 + 1
+	k[2] = crc32.IEEETable[byte(k[2])^byte(k[1]>>24)] ^ (k[2] >> 8)
+}
+
+func (k *zipCryptoKeys) stream() byte {
+	t := k[2] | 2
+	return byte((t * (t ^ 1)) >> 8)
+}
+
+func (k *zipCryptoKeys) decrypt(p []byte) {
+	for i := range p {
+		p[i] ^= k.stream()
+		k.update(p[i])
+	}
+}
+
+type zipCryptoReader struct {
+	r    io.Reader
+	keys *zipCryptoKeys
+}
+
+func newZipCryptoReader(r io.Reader, password string, check byte) (io.Reader, error) {
+	keys := newZipCryptoKeys(password)
+	header := make([]byte, 12)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	keys.decrypt(header)
+	if header[11] != check {
+		return nil, ErrArchivePassword
+	}
+	return &zipCryptoReader{r: r, keys: keys}, nil
+}
+
+func (z *zipCryptoReader) Read(p []byte) (int, error) {
+	n, err := z.r.Read(p)
+	z.keys.decrypt(p[:n])
+	return n, err
+}
+
+// zipAESReader decrypts WinZip AES data (AES-CTR with a little-endian
+// counter starting at 1) and checks the trailing HMAC-SHA1 at EOF.
+type zipAESReader struct {
+	raw     io.Reader
+	data    io.Reader
+	block   cipher.Block
+	counter [aes.BlockSize]byte
+	stream  [aes.BlockSize]byte
+	used    int
+	mac     hash.Hash
+}
+
+func newZipAESReader(raw io.Reader, compressedSize int64, strength byte, password string) (io.Reader, error) {
+	if strength < 1 || strength > 3 {
+		return nil, fmt.Errorf("%w: WinZip AES strength %d", ErrUnsupportedArchive, strength)
+	}
+	keyLen := 8 + 8*int(strength)
+	saltLen := keyLen / 2
+	dataLen := compressedSize - int64(saltLen) - 2 - 10
+	if dataLen < 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	header := make([]byte, saltLen+2)
+	if _, err := io.ReadFull(raw, header); err != nil {
+		return nil, err
+	}
+	derived, err := pbkdf2.Key(sha1.New, password, header[:saltLen], 1000, 2*keyLen+2)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(derived[2*keyLen:], header[saltLen:]) {
+		return nil, ErrArchivePassword
+	}
+	block, err := aes.NewCipher(derived[:keyLen])
+	if err != nil {
+		return nil, err
+	}
+
+	return &zipAESReader{
+		raw:   raw,
+		data:  io.LimitReader(raw, dataLen),
+		block: block,
+		used:  aes.BlockSize,
+		mac:   hmac.New(sha1.New, derived[keyLen:2*keyLen]),
+	}, nil
+}
+
+func (z *zipAESReader) Read(p []byte) (int, error) {
+	n, err := z.data.Read(p)
+	z.mac.Write(p[:n])
+	for i := 0; i < n; i++ {
+		if z.used == aes.BlockSize {
+			for j := range z.counter {
+				z.counter[j]++
+				if z.counter[j] != 0 {
+					break
+				}
+			}
+			z.block.Encrypt(z.stream[:], z.counter[:])
+			z.used = 0
+		}
+		p[i] ^= z.stream[z.used]
+		z.used++
+	}
+
+	if err == io.EOF {
+		auth := make([]byte, 10)
+		if _, authErr := io.ReadFull(z.raw, auth); authErr != nil {
+			return n, authErr
+		}
+		if !hmac.Equal(z.mac.Sum(nil)[:10], auth) {
+			return n, errArchiveChecksum
+		}
+	}
+	return n, err
+}
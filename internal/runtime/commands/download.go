@@ -104,8 +104,8 @@ func (r *Runner) ExecuteDownload(nzbPath string) {
 					barRendered = false
 				}
 
-			case domain.StatusCompleted, domain.StatusFailed:
-				if itm.Status == domain.StatusFailed {
+			case domain.StatusCompleted, domain.StatusFailed, domain.StatusPasswordRequired:
+				if itm.Status != domain.StatusCompleted {
 					errText := "Unknown error"
 					if itm.Error != nil {
 						errText = *itm.Error
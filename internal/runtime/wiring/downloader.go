@@ -35,6 +35,11 @@ func BuildDownloader(appCtx *app.Context) error {
 
 	writer := engine.NewFileWriter()
 
+	// the limiter outlives downloader rebuilds so runtime overrides are not lost on reload.
+	if appCtx.SpeedLimiter == nil {
+		appCtx.SpeedLimiter = engine.NewRateLimiter(int64(appCtx.Config.Download.SpeedLimitKBps) * 1024)
+	}
+
 	downloadCtx := appCtx
 	if servers := scopedDownloaderServers(appCtx); len(servers) > 0 {
 		cfg := *appCtx.Config
@@ -104,7 +109,7 @@ func ReloadDownloaderIfIdle(appCtx *app.Context) error {
 	if appCtx.Queue == nil {
 		return nil
 	}
-	if active := appCtx.Queue.GetActiveItem(); active != nil {
+	if active := appCtx.Queue.GetActiveItems(); len(active) > 0 {
 		return errDownloaderReloadDeferred
 	}
 
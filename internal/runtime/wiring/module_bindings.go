@@ -41,6 +41,8 @@ func BindApplicationModules(appCtx *app.Context) {
 			BlobStore:      func() app.BlobStore { return appCtx.BlobStore },
 			JobStore:       func() app.JobStore { return appCtx.JobStore },
 			QueueFileStore: func() app.QueueFileStore { return appCtx.QueueFileStore },
+			SpeedLimiter:   func() app.SpeedLimiter { return appCtx.SpeedLimiter },
+			Config:         appCtx.CurrentConfig,
 		})
 	} else {
 		appCtx.DownloaderModule = nil
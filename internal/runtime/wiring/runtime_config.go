@@ -3,6 +3,7 @@ package wiring
 import (
 	"context"
 	"fmt"
+	"time"
 
 	aggregatorpkg "github.com/datallboy/gonzb/internal/aggregator"
 	"github.com/datallboy/gonzb/internal/aggregator/sources/localblob"
@@ -17,7 +18,7 @@ import (
 
 type aggregatorCacheStore interface {
 	UpsertAggregatorReleaseCache(ctx context.Context, releases []*domain.Release) error
-	SearchAggregatorReleaseCache(ctx context.Context, query string, limit int) ([]*domain.Release, error)
+	SearchAggregatorReleaseCache(ctx context.Context, q domain.ReleaseCacheQuery) ([]*domain.Release, int, error)
 	GetAggregatorReleaseCacheByID(ctx context.Context, id string) (*domain.Release, error)
 }
 
@@ -100,7 +101,7 @@ func buildAggregator(appCtx *app.Context, effective *config.Config) app.IndexerA
 	}
 
 	for _, idxCfg := range effective.Indexers {
-		client := newznab.New(idxCfg.ID, idxCfg.BaseUrl, idxCfg.ApiPath, idxCfg.ApiKey, idxCfg.Redirect)
+		client := newznab.New(idxCfg.ID, idxCfg.BaseUrl, idxCfg.ApiPath, idxCfg.ApiKey, idxCfg.Redirect, time.Duration(idxCfg.TimeoutSeconds)*time.Second)
 		manager.AddSource(client)
 	}
 
@@ -6,6 +6,7 @@ import (
 	"io"
 
 	"github.com/datallboy/gonzb/internal/app"
+	downloadermodule "github.com/datallboy/gonzb/internal/downloader"
 	"github.com/datallboy/gonzb/internal/infra/config"
 )
 
@@ -17,7 +18,9 @@ const (
 )
 
 type downloaderRuntimeModule struct {
-	appCtx *app.Context
+	appCtx         *app.Context
+	speedScheduler *downloadermodule.SpeedScheduler
+	folderWatcher  *downloadermodule.FolderWatcher
 }
 
 func (m *downloaderRuntimeModule) Name() string { return moduleNameDownloader }
@@ -43,6 +46,25 @@ func (m *downloaderRuntimeModule) Start(ctx context.Context) error {
 
 	m.appCtx.Logger.Info("starting downloader queue manager")
 	go m.appCtx.Queue.Start(ctx)
+
+	m.speedScheduler = downloadermodule.NewSpeedScheduler(
+		func() app.SpeedLimiter { return m.appCtx.SpeedLimiter },
+		func() *config.Config { return m.appCtx.Config },
+		m.appCtx.Logger,
+	)
+	go m.speedScheduler.Run(ctx)
+
+	m.folderWatcher = downloadermodule.NewFolderWatcher(
+		func() app.DownloaderCommands {
+			if m.appCtx.DownloaderModule == nil {
+				return nil
+			}
+			return m.appCtx.DownloaderModule.Commands()
+		},
+		func() *config.Config { return m.appCtx.Config },
+		m.appCtx.Logger,
+	)
+	go m.folderWatcher.Run(ctx)
 	return nil
 }
 
@@ -56,6 +78,10 @@ func (m *downloaderRuntimeModule) Reload(ctx context.Context) error {
 	if m.appCtx.Queue != nil {
 		m.appCtx.Queue.ReloadRuntime(m.appCtx)
 	}
+	// speed limits apply to in-flight downloads, so they do not wait for an idle queue.
+	if m.speedScheduler != nil {
+		m.speedScheduler.Refresh()
+	}
 	if err := ReloadDownloaderIfIdle(m.appCtx); err != nil {
 		return err
 	}
@@ -94,6 +120,11 @@ func (m *downloaderRuntimeModule) ReadinessChecks(ctx context.Context) []app.Run
 		checks = append(checks, runtimeErrorCheck("job_store_ping", m.appCtx.JobStore.Ping(ctx)))
 		checks = append(checks, runtimeErrorCheck("job_store_schema", m.appCtx.JobStore.ValidateSchema(ctx)))
 	}
+	if m.appCtx.Queue != nil {
+		disk := m.appCtx.Queue.DiskSpace()
+		// a low-disk pause only holds downloads; the API and aggregator keep serving.
+		checks = append(checks, app.RuntimeCheck{Name: "disk_space", OK: !disk.Paused, Detail: disk.Reason, Advisory: true})
+	}
 
 	return checks
 }
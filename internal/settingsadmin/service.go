@@ -165,7 +165,7 @@ func ValidateRuntimeSettings(base *config.Config, runtime *app.RuntimeSettings)
 
 	issues = append(issues, validateServers("servers", runtime.Servers)...)
 	issues = append(issues, validateIndexers(runtime.Indexers)...)
-	issues = append(issues, validateDownload(runtime.Download)...)
+	issues = append(issues, validateDownload(base, runtime.Download)...)
 	issues = append(issues, validateNNTPPool(runtime.NNTPPool)...)
 	issues = append(issues, validateIndexing(runtime.Indexing)...)
 
@@ -243,7 +243,7 @@ func validateServers(field string, servers []app.ServerRuntimeSettings) []string
 		}
 		for j, role := range server.Roles {
 			if !validNNTPProviderRole(role) {
-				issues = append(issues, fmt.Sprintf("%s.roles[%d] must be one of scrape, yenc_recovery, inspection, download", prefix, j))
+				issues = append(issues, fmt.Sprintf("%s.roles[%d] must be one of scrape, yenc_recovery, inspection, download, backup", prefix, j))
 			}
 		}
 	}
@@ -252,7 +252,7 @@ func validateServers(field string, servers []app.ServerRuntimeSettings) []string
 
 func validNNTPProviderRole(role string) bool {
 	switch strings.TrimSpace(strings.ToLower(role)) {
-	case "scrape", "yenc_recovery", "inspection", "download":
+	case "scrape", "yenc_recovery", "inspection", "download", "backup":
 		return true
 	default:
 		return false
@@ -278,11 +278,14 @@ func validateIndexers(indexers []app.IndexerRuntimeSettings) []string {
 		if strings.TrimSpace(indexer.APIPath) == "" {
 			issues = append(issues, prefix+".api_path is required")
 		}
+		if indexer.TimeoutSeconds < 0 {
+			issues = append(issues, prefix+".timeout_seconds must be >= 0")
+		}
 	}
 	return issues
 }
 
-func validateDownload(download *app.DownloadRuntimeSettings) []string {
+func validateDownload(base *config.Config, download *app.DownloadRuntimeSettings) []string {
 	if download == nil {
 		return nil
 	}
@@ -293,6 +296,74 @@ func validateDownload(download *app.DownloadRuntimeSettings) []string {
 	if strings.TrimSpace(download.CompletedDir) == "" {
 		issues = append(issues, "download.completed_dir is required when downloader runtime settings are present")
 	}
+	if download.MaxActiveDownloads < 0 {
+		issues = append(issues, "download.max_active_downloads must be >= 0")
+	}
+	if download.SpeedLimitKBps < 0 {
+		issues = append(issues, "download.speed_limit_kbps must be >= 0")
+	}
+	if download.AvailabilitySample < 0 {
+		issues = append(issues, "download.availability_sample must be >= 0")
+	}
+	for i, schedule := range download.SpeedSchedules {
+		window := config.SpeedScheduleConfig{
+			Days:      schedule.Days,
+			Start:     schedule.Start,
+			End:       schedule.End,
+			LimitKBps: schedule.LimitKBps,
+		}
+		if _, err := window.Window(); err != nil {
+			issues = append(issues, fmt.Sprintf("download.speed_schedules[%d]: %v", i, err))
+		}
+	}
+	if download.ScriptTimeoutSeconds < 0 {
+		issues = append(issues, "download.script_timeout_seconds must be >= 0")
+	}
+	// scripts_dir only comes from config.yaml, so settings cannot widen it.
+	scriptsDir := config.DownloadConfig{}
+	if base != nil {
+		scriptsDir.ScriptsDir = base.Download.ScriptsDir
+	}
+	if name := strings.TrimSpace(download.PreQueueScript); name != "" {
+		if _, err := scriptsDir.ResolveScript(name); err != nil {
+			issues = append(issues, fmt.Sprintf("download.pre_queue_script: %v", err))
+		}
+	}
+	if download.MinFreeIncompleteMB < 0 {
+		issues = append(issues, "download.min_free_incomplete_mb must be >= 0")
+	}
+	if download.MinFreeCompleteMB < 0 {
+		issues = append(issues, "download.min_free_complete_mb must be >= 0")
+	}
+	if download.UnpackHeadroomPercent < 0 {
+		issues = append(issues, "download.unpack_headroom_percent must be >= 0")
+	}
+	if download.WatchIntervalSeconds < 0 {
+		issues = append(issues, "download.watch_interval_seconds must be >= 0")
+	}
+	seenCategories := make(map[string]bool, len(download.Categories))
+	for i, category := range download.Categories {
+		cat := config.CategoryConfig{
+			Name:            category.Name,
+			PostProcessing:  category.PostProcessing,
+			Priority:        category.Priority,
+			DuplicatePolicy: category.DuplicatePolicy,
+		}
+		if err := cat.Validate(); err != nil {
+			issues = append(issues, fmt.Sprintf("download.categories[%d]: %v", i, err))
+			continue
+		}
+		if name := strings.TrimSpace(category.Script); name != "" {
+			if _, err := scriptsDir.ResolveScript(name); err != nil {
+				issues = append(issues, fmt.Sprintf("download.categories[%d].script: %v", i, err))
+			}
+		}
+		key := strings.ToLower(strings.TrimSpace(category.Name))
+		if seenCategories[key] {
+			issues = append(issues, fmt.Sprintf("download.categories[%d]: duplicate name %q", i, category.Name))
+		}
+		seenCategories[key] = true
+	}
 	return issues
 }
 
@@ -167,3 +167,26 @@ func TestBuildCapabilitiesReportsAggregatorMissingSource(t *testing.T) {
 		t.Fatalf("expected aggregator missing source requirement, got %+v", agg)
 	}
 }
+
+func TestValidateRuntimeSettingsKeepsScriptsInsideScriptsDir(t *testing.T) {
+	runtime := app.DefaultRuntimeSettings()
+	runtime.Download.PreQueueScript = "/bin/sh"
+	runtime.Download.Categories = []app.DownloadCategoryRuntimeSettings{{Name: "tv", Script: "../notify.sh"}}
+
+	err := ValidateRuntimeSettings(&config.Config{}, runtime)
+	if err == nil || !strings.Contains(err.Error(), "download.pre_queue_script: download.scripts_dir is not set") {
+		t.Fatalf("expected scripts to require scripts_dir, got %v", err)
+	}
+
+	base := &config.Config{Download: config.DownloadConfig{ScriptsDir: t.TempDir()}}
+	err = ValidateRuntimeSettings(base, runtime)
+	if err == nil || !strings.Contains(err.Error(), "pre_queue_script") || !strings.Contains(err.Error(), "download.categories[0].script") {
+		t.Fatalf("expected both scripts outside scripts_dir to be rejected, got %v", err)
+	}
+
+	runtime.Download.PreQueueScript = "prequeue.sh"
+	runtime.Download.Categories[0].Script = "tv/notify.sh"
+	if err := ValidateRuntimeSettings(base, runtime); err != nil {
+		t.Fatalf("expected scripts inside scripts_dir to validate, got %v", err)
+	}
+}
@@ -15,7 +15,7 @@ type blob interface {
 
 type aggregatorCache interface {
 	UpsertAggregatorReleaseCache(ctx context.Context, releases []*domain.Release) error
-	SearchAggregatorReleaseCache(ctx context.Context, query string, limit int) ([]*domain.Release, error)
+	SearchAggregatorReleaseCache(ctx context.Context, q domain.ReleaseCacheQuery) ([]*domain.Release, int, error)
 	GetAggregatorReleaseCacheByID(ctx context.Context, id string) (*domain.Release, error)
 }
 
@@ -50,11 +50,11 @@ func (s *AggregatorStore) UpsertAggregatorReleaseCache(ctx context.Context, rele
 	return s.cache.UpsertAggregatorReleaseCache(ctx, releases)
 }
 
-func (s *AggregatorStore) SearchAggregatorReleaseCache(ctx context.Context, query string, limit int) ([]*domain.Release, error) {
+func (s *AggregatorStore) SearchAggregatorReleaseCache(ctx context.Context, q domain.ReleaseCacheQuery) ([]*domain.Release, int, error) {
 	if s.cache == nil {
-		return []*domain.Release{}, nil
+		return []*domain.Release{}, 0, nil
 	}
-	return s.cache.SearchAggregatorReleaseCache(ctx, query, limit)
+	return s.cache.SearchAggregatorReleaseCache(ctx, q)
 }
 
 func (s *AggregatorStore) GetAggregatorReleaseCacheByID(ctx context.Context, id string) (*domain.Release, error) {
@@ -13,12 +13,14 @@ import (
 
 type PublicIndexerReleaseListParams struct {
 	Query             string
+	Terms             []string // each must appear in search_title, as t=music/t=book fields
 	Limit             int
 	Offset            int
 	Sort              string
 	Classification    string
 	BrowseCategory    string
 	BrowseSubcategory string
+	CategoryIDs       []int // exact Newznab ids; used instead of the browse filter when set
 	HasNFO            *bool
 	HasPAR2           *bool
 	PasswordState     string
@@ -228,10 +230,18 @@ func buildPublicIndexerFilterSQL(params PublicIndexerReleaseListParams) (string,
 	if query := strings.TrimSpace(params.Query); query != "" {
 		add(fmt.Sprintf("r.search_title ILIKE '%%' || $%d || '%%'", arg), query)
 	}
+	for _, term := range params.Terms {
+		if term = strings.TrimSpace(term); term != "" {
+			add(fmt.Sprintf("r.search_title ILIKE '%%' || $%d || '%%'", arg), term)
+		}
+	}
 	if v := strings.TrimSpace(params.Classification); v != "" {
 		add(fmt.Sprintf("r.classification = $%d", arg), v)
 	}
-	if clause, values := publicBrowseClause(params.BrowseCategory, params.BrowseSubcategory, arg); clause != "" {
+	if len(params.CategoryIDs) > 0 {
+		clause, values := publicCategoryIDsClause(params.CategoryIDs, arg)
+		add(clause, values...)
+	} else if clause, values := publicBrowseClause(params.BrowseCategory, params.BrowseSubcategory, arg); clause != "" {
 		add(clause, values...)
 	}
 	if params.HasNFO != nil {
@@ -297,6 +307,10 @@ func publicBrowseClause(category, subcategory string, argStart int) (string, []a
 	if len(ids) == 0 {
 		return "", nil
 	}
+	return publicCategoryIDsClause(ids, argStart)
+}
+
+func publicCategoryIDsClause(ids []int, argStart int) (string, []any) {
 	parts := make([]string, 0, len(ids))
 	args := make([]any, 0, len(ids))
 	for i, id := range ids {
@@ -547,3 +561,37 @@ func (s *Store) GetPublicIndexerReleaseDetailWithPolicy(ctx context.Context, rel
 		},
 	}, nil
 }
+
+// GetPublicIndexerReleaseNFOWithPolicy returns the newest NFO text inspected
+// for a visible release, or "" when the release has none.
+func (s *Store) GetPublicIndexerReleaseNFOWithPolicy(ctx context.Context, releaseID string, policy ReleaseReadyPolicy) (string, error) {
+	releaseID = strings.TrimSpace(releaseID)
+	if releaseID == "" {
+		return "", fmt.Errorf("release id is required")
+	}
+
+	var text string
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT bte.text_value
+		FROM releases r
+		JOIN release_files rf ON rf.release_id = r.release_id
+		JOIN binary_core bc ON bc.binary_id = rf.binary_id
+		JOIN binary_text_evidence bte
+		  ON bte.source_posted_at = bc.source_posted_at
+		 AND bte.binary_id = rf.binary_id
+		WHERE r.release_id = $1
+		  AND (%s)
+		  AND bte.evidence_kind = 'nfo_text'
+		  AND bte.text_value <> ''
+		ORDER BY bte.updated_at DESC
+		LIMIT 1`, publicIndexerReleaseVisibilityClause("r", policy)),
+		releaseID,
+	).Scan(&text)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get public release nfo %s: %w", releaseID, err)
+	}
+	return text, nil
+}
@@ -0,0 +1,24 @@
+package pgindex
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildPublicIndexerFilterSQLMatchesEveryTerm(t *testing.T) {
+	where, args := buildPublicIndexerFilterSQL(PublicIndexerReleaseListParams{
+		Terms:          []string{"daft punk", " ", "discovery"},
+		BrowseCategory: "audio",
+	})
+
+	if got := strings.Count(where, "r.search_title ILIKE"); got != 2 {
+		t.Fatalf("expected one search_title clause per non-empty term, got %d in %q", got, where)
+	}
+	found := map[any]bool{}
+	for _, arg := range args {
+		found[arg] = true
+	}
+	if !found["daft punk"] || !found["discovery"] {
+		t.Fatalf("expected terms to be bound as args, got %+v", args)
+	}
+}
@@ -16,6 +16,7 @@ import (
 const (
 	usenetIndexerModuleName = "usenet_indexer"
 	aggregatorModuleName    = "aggregator"
+	downloaderModuleName    = "downloader"
 )
 const expectedSchemaVersion = 1
 
@@ -420,11 +421,26 @@ func (s *Store) readStructuredSettings(ctx context.Context) (*RuntimeSettings, b
 			return nil, false, fmt.Errorf("unmarshal settings_download.cleanup_extensions_json: %w", unmarshalErr)
 		}
 
-		out.Download = &DownloadRuntimeSettings{
-			OutDir:            outDir,
-			CompletedDir:      completed,
-			CleanupExtensions: cleanup,
+		// queue/runtime knobs beyond the path columns live in downloader module options.
+		download := &DownloadRuntimeSettings{}
+		var downloadOptionsJSON string
+		err = s.db.QueryRowContext(ctx, `
+			SELECT options_json
+			FROM settings_module_options
+			WHERE module_name = ?`, downloaderModuleName).Scan(&downloadOptionsJSON)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, false, err
+		}
+		if err == nil && downloadOptionsJSON != "" {
+			if unmarshalErr := json.Unmarshal([]byte(downloadOptionsJSON), download); unmarshalErr != nil {
+				return nil, false, fmt.Errorf("unmarshal downloader module options: %w", unmarshalErr)
+			}
 		}
+
+		download.OutDir = outDir
+		download.CompletedDir = completed
+		download.CleanupExtensions = cleanup
+		out.Download = download
 	}
 
 	var optionsJSON string
@@ -564,10 +580,29 @@ func (s *Store) writeDownload(ctx context.Context, tx *sql.Tx, download *Downloa
 	if _, err := tx.ExecContext(ctx, `DELETE FROM settings_download WHERE singleton_id = 1`); err != nil {
 		return err
 	}
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM settings_module_options
+		WHERE module_name = ?`, downloaderModuleName); err != nil {
+		return err
+	}
 	if download == nil {
 		return nil
 	}
 
+	optionsJSON, err := json.Marshal(download)
+	if err != nil {
+		return fmt.Errorf("marshal downloader options: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO settings_module_options (
+			module_name, options_json, updated_at
+		) VALUES (?, ?, CURRENT_TIMESTAMP)`,
+		downloaderModuleName,
+		string(optionsJSON),
+	); err != nil {
+		return err
+	}
+
 	cleanupJSON, err := json.Marshal(download.CleanupExtensions)
 	if err != nil {
 		return fmt.Errorf("marshal cleanup extensions: %w", err)
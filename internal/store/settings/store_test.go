@@ -127,3 +127,31 @@ func TestUpdateSettingsPreservesZeroNewestPctAcrossReload(t *testing.T) {
 		t.Fatalf("expected newest pct 0, got %d", got)
 	}
 }
+
+func TestUpdateSettingsPersistsMaxActiveDownloads(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "settings.db"))
+	if err != nil {
+		t.Fatalf("new settings store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	runtime := DefaultRuntimeSettings()
+	runtime.Download.OutDir = "/downloads"
+	runtime.Download.MaxActiveDownloads = 3
+
+	if err := store.UpdateSettings(ctx, runtime); err != nil {
+		t.Fatalf("persist runtime settings: %v", err)
+	}
+
+	reloaded, err := store.GetRuntimeSettings(ctx)
+	if err != nil {
+		t.Fatalf("reload runtime settings: %v", err)
+	}
+	if got := reloaded.Download.MaxActiveDownloads; got != 3 {
+		t.Fatalf("expected max active downloads 3, got %d", got)
+	}
+	if got := reloaded.Download.OutDir; got != "/downloads" {
+		t.Fatalf("expected out dir to round-trip, got %q", got)
+	}
+}
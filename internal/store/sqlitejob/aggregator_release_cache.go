@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/datallboy/gonzb/internal/domain"
@@ -65,10 +66,36 @@ func (s *Store) UpsertAggregatorReleaseCache(ctx context.Context, releases []*do
 	return tx.Commit()
 }
 
-// SearchAggregatorReleaseCache searches cached releases by title.
-func (s *Store) SearchAggregatorReleaseCache(ctx context.Context, query string, limit int) ([]*domain.Release, error) {
-	if limit <= 0 {
-		limit = 100
+// SearchAggregatorReleaseCache searches cached releases by title and returns
+// one page of matches, newest first, with the total number of matches.
+func (s *Store) SearchAggregatorReleaseCache(ctx context.Context, q domain.ReleaseCacheQuery) ([]*domain.Release, int, error) {
+	if q.Limit <= 0 {
+		q.Limit = 100
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+
+	where := []string{"title LIKE ?"}
+	args := []any{"%" + q.Query + "%"}
+	if len(q.Categories) > 0 {
+		where = append(where, "category IN ("+strings.TrimSuffix(strings.Repeat("?, ", len(q.Categories)), ", ")+")")
+		for _, category := range q.Categories {
+			args = append(args, category)
+		}
+	}
+	if !q.PostedAfter.IsZero() {
+		where = append(where, "publish_date_unix >= ?")
+		args = append(args, q.PostedAfter.Unix())
+	}
+	filter := strings.Join(where, " AND ")
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM aggregator_release_cache
+		WHERE `+filter, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count aggregator_release_cache: %w", err)
 	}
 
 	rows, err := s.db.QueryContext(ctx, `
@@ -82,14 +109,13 @@ func (s *Store) SearchAggregatorReleaseCache(ctx context.Context, query string,
 			publish_date_unix,
 			nzb_cached
 		FROM aggregator_release_cache
-		WHERE title LIKE ?
+		WHERE `+filter+`
 		ORDER BY publish_date_unix DESC, updated_at DESC
-		LIMIT ?`,
-		"%"+query+"%",
-		limit,
+		LIMIT ? OFFSET ?`,
+		append(args, q.Limit, q.Offset)...,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("query aggregator_release_cache: %w", err)
+		return nil, 0, fmt.Errorf("query aggregator_release_cache: %w", err)
 	}
 	defer rows.Close()
 
@@ -97,16 +123,16 @@ func (s *Store) SearchAggregatorReleaseCache(ctx context.Context, query string,
 	for rows.Next() {
 		rel, scanErr := scanAggregatorReleaseCacheRow(rows)
 		if scanErr != nil {
-			return nil, scanErr
+			return nil, 0, scanErr
 		}
 		results = append(results, rel)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate aggregator_release_cache rows: %w", err)
+		return nil, 0, fmt.Errorf("iterate aggregator_release_cache rows: %w", err)
 	}
 
-	return results, nil
+	return results, total, nil
 }
 
 // GetAggregatorReleaseCacheByID returns one cached release by release id.
@@ -29,6 +29,11 @@ type queueItemDBO struct {
 	PostProcessSeconds  int64          `db:"postprocess_seconds"`
 	AvgBps              int64          `db:"avg_bps"`
 	DownloadedBytes     int64          `db:"downloaded_bytes"`
+	Priority            int            `db:"priority"`
+	SortPosition        int64          `db:"sort_position"`
+	NZBHash             string         `db:"nzb_hash"`
+	DuplicateOf         string         `db:"duplicate_of"`
+	DuplicateKey        string         `db:"duplicate_key"`
 }
 
 // Mapper: DBO to Domain QueueItem
@@ -50,6 +55,11 @@ func (q *queueItemDBO) ToDomain() *domain.QueueItem {
 		PostProcessSeconds:  q.PostProcessSeconds,
 		AvgBps:              q.AvgBps,
 		DownloadedBytes:     q.DownloadedBytes,
+		Priority:            domain.QueuePriority(q.Priority),
+		SortPosition:        q.SortPosition,
+		NZBHash:             q.NZBHash,
+		DuplicateOf:         q.DuplicateOf,
+		DuplicateKey:        q.DuplicateKey,
 	}
 
 	if item.PayloadMode == "" {
@@ -0,0 +1,7 @@
+ALTER TABLE queue_items ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE queue_items ADD COLUMN sort_position INTEGER NOT NULL DEFAULT 0;
+
+-- Preserve existing FIFO order for rows created before priorities existed.
+UPDATE queue_items SET sort_position = rowid;
+
+CREATE INDEX IF NOT EXISTS idx_queue_items_priority_sort ON queue_items(priority DESC, sort_position ASC);
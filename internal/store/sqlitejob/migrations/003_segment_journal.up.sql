@@ -0,0 +1,11 @@
+-- Per-file segment resume journal. Keyed by queue item rather than file set because
+-- file sets are shared between queue items with identical NZB contents.
+CREATE TABLE IF NOT EXISTS queue_item_segment_journal (
+  queue_id TEXT NOT NULL,
+  file_index INTEGER NOT NULL,
+  bitmap BLOB NOT NULL,
+  actual_size INTEGER NOT NULL DEFAULT 0,
+  updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY(queue_id, file_index),
+  FOREIGN KEY(queue_id) REFERENCES queue_items(id) ON DELETE CASCADE
+);
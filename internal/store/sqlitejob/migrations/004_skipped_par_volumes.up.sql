@@ -0,0 +1,3 @@
+-- Recovery volumes that smart par handling never had to download.
+ALTER TABLE queue_file_set_items ADD COLUMN skipped BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE queue_file_sets ADD COLUMN skipped_files INTEGER NOT NULL DEFAULT 0;
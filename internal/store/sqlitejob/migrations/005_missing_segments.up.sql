@@ -0,0 +1,10 @@
+-- Articles no provider could deliver, per file of a queue item. Kept after the
+-- item finishes so history can explain why PAR2 repair was needed.
+CREATE TABLE IF NOT EXISTS queue_item_missing_segments (
+  queue_id TEXT NOT NULL,
+  file_index INTEGER NOT NULL,
+  segments_json TEXT NOT NULL DEFAULT '[]',
+  updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY(queue_id, file_index),
+  FOREIGN KEY(queue_id) REFERENCES queue_items(id) ON DELETE CASCADE
+);
@@ -0,0 +1,10 @@
+-- Duplicate detection keys. title_key is the normalized release title; rows
+-- queued before this migration only match on source release ID.
+ALTER TABLE queue_items ADD COLUMN title_key TEXT NOT NULL DEFAULT '';
+ALTER TABLE queue_items ADD COLUMN nzb_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE queue_items ADD COLUMN duplicate_of TEXT NOT NULL DEFAULT '';
+ALTER TABLE queue_items ADD COLUMN duplicate_key TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_queue_items_source_release ON queue_items(source_kind, source_release_id);
+CREATE INDEX IF NOT EXISTS idx_queue_items_title_key ON queue_items(title_key);
+CREATE INDEX IF NOT EXISTS idx_queue_items_nzb_hash ON queue_items(nzb_hash);
@@ -0,0 +1,18 @@
+-- Recovery volumes held back per queue item. File sets are shared by every
+-- item added from the same NZB, so the flag cannot live on
+-- queue_file_set_items; the columns 004 added there are copied over and dropped.
+CREATE TABLE IF NOT EXISTS queue_item_skipped_files (
+  queue_id TEXT NOT NULL,
+  file_index INTEGER NOT NULL,
+  PRIMARY KEY(queue_id, file_index),
+  FOREIGN KEY(queue_id) REFERENCES queue_items(id) ON DELETE CASCADE
+);
+
+INSERT OR IGNORE INTO queue_item_skipped_files (queue_id, file_index)
+SELECT qi.id, fsi.file_index
+FROM queue_items qi
+JOIN queue_file_set_items fsi ON fsi.file_set_id = qi.file_set_id
+WHERE fsi.skipped = 1;
+
+ALTER TABLE queue_file_set_items DROP COLUMN skipped;
+ALTER TABLE queue_file_sets DROP COLUMN skipped_files;
@@ -0,0 +1,65 @@
+package sqlitejob
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ReplaceMissingSegments stores the permanently missing segment numbers of a
+// queue item, keyed by file index. Files absent from the map are cleared.
+func (s *Store) ReplaceMissingSegments(ctx context.Context, queueItemID string, missing map[int][]int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM queue_item_missing_segments WHERE queue_id = ?`, queueItemID); err != nil {
+		return fmt.Errorf("clear missing segments: %w", err)
+	}
+
+	for fileIndex, segments := range missing {
+		if len(segments) == 0 {
+			continue
+		}
+		raw, err := json.Marshal(segments)
+		if err != nil {
+			return fmt.Errorf("marshal missing segments for file %d: %w", fileIndex, err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO queue_item_missing_segments (queue_id, file_index, segments_json, updated_at)
+			VALUES (?, ?, ?, CURRENT_TIMESTAMP)`, queueItemID, fileIndex, string(raw)); err != nil {
+			return fmt.Errorf("save missing segments for file %d: %w", fileIndex, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// getMissingSegments returns the recorded missing segment numbers by file index.
+func (s *Store) getMissingSegments(ctx context.Context, queueItemID string) (map[int][]int, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT file_index, segments_json
+		FROM queue_item_missing_segments
+		WHERE queue_id = ?`, queueItemID)
+	if err != nil {
+		return nil, fmt.Errorf("query missing segments: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[int][]int)
+	for rows.Next() {
+		var fileIndex int
+		var raw string
+		if err := rows.Scan(&fileIndex, &raw); err != nil {
+			return nil, fmt.Errorf("scan missing segments: %w", err)
+		}
+		var segments []int
+		if err := json.Unmarshal([]byte(raw), &segments); err != nil {
+			return nil, fmt.Errorf("unmarshal missing segments for file %d: %w", fileIndex, err)
+		}
+		out[fileIndex] = segments
+	}
+	return out, rows.Err()
+}
@@ -28,7 +28,12 @@ q.completed_at_unix,
 q.download_seconds,
 q.postprocess_seconds,
 q.avg_bps,
-q.downloaded_bytes
+q.downloaded_bytes,
+q.priority,
+q.sort_position,
+q.nzb_hash,
+q.duplicate_of,
+q.duplicate_key
 `
 
 type rowScanner interface {
@@ -57,6 +62,11 @@ func scanReleaseWithQueue(s rowScanner) (*domain.QueueItem, error) {
 		&q.PostProcessSeconds,
 		&q.AvgBps,
 		&q.DownloadedBytes,
+		&q.Priority,
+		&q.SortPosition,
+		&q.NZBHash,
+		&q.DuplicateOf,
+		&q.DuplicateKey,
 	); err != nil {
 		return nil, err
 	}
@@ -120,9 +130,11 @@ func (s *Store) SaveQueueItem(ctx context.Context, item *domain.QueueItem) error
 		id, status, out_dir, error,
 		source_kind, source_release_id, release_title, release_size, release_snapshot_json,
 		payload_mode, resumable,
-		started_at_unix, completed_at_unix, download_seconds, postprocess_seconds, avg_bps, downloaded_bytes
+		started_at_unix, completed_at_unix, download_seconds, postprocess_seconds, avg_bps, downloaded_bytes,
+		priority, sort_position,
+		title_key, nzb_hash, duplicate_of, duplicate_key
 	)
-	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	ON CONFLICT(id) DO UPDATE SET
 		status = excluded.status,
 		error = excluded.error,
@@ -139,17 +151,78 @@ func (s *Store) SaveQueueItem(ctx context.Context, item *domain.QueueItem) error
 		download_seconds = excluded.download_seconds,
 		postprocess_seconds = excluded.postprocess_seconds,
 		avg_bps = excluded.avg_bps,
-		downloaded_bytes = excluded.downloaded_bytes`
+		downloaded_bytes = excluded.downloaded_bytes,
+		priority = excluded.priority,
+		sort_position = excluded.sort_position,
+		title_key = excluded.title_key,
+		nzb_hash = excluded.nzb_hash,
+		duplicate_of = excluded.duplicate_of,
+		duplicate_key = excluded.duplicate_key`
 
 	_, err := s.db.ExecContext(ctx, query,
 		item.ID, item.Status, item.OutDir, item.Error,
 		sourceKind, sourceReleaseID, releaseTitle, releaseSize, releaseSnapshotJSON,
 		payloadMode, resumable,
 		startedAtUnix, completedAtUnix, item.DownloadSeconds, item.PostProcessSeconds, item.AvgBps, item.DownloadedBytes,
+		int(item.Priority), item.SortPosition,
+		domain.NormalizeReleaseTitle(releaseTitle), item.NZBHash, item.DuplicateOf, item.DuplicateKey,
 	)
 	return err
 }
 
+// FindDuplicateQueueItems returns queued and completed items, other than
+// excludeID, sharing a source release, NZB hash or normalized title with item.
+// Failed items are not duplicates: downloading them again is the point.
+func (s *Store) FindDuplicateQueueItems(ctx context.Context, item *domain.QueueItem, excludeID string) ([]domain.DuplicateMatch, error) {
+	titleKey := domain.NormalizeReleaseTitle(item.ReleaseTitle)
+
+	query := `
+		SELECT ` + queueSelectColumns + `
+		FROM queue_items q
+		WHERE q.id != ?
+		  AND q.status NOT IN ('failed', 'password_required')
+		  AND (
+			(q.source_kind = ? AND q.source_release_id = ?)
+			OR (? != '' AND q.nzb_hash = ?)
+			OR (? != '' AND q.title_key = ?)
+		  )
+		ORDER BY q.created_at ASC`
+
+	rows, err := s.db.QueryContext(ctx, query,
+		excludeID,
+		item.SourceKind, item.SourceReleaseID,
+		item.NZBHash, item.NZBHash,
+		titleKey, titleKey,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query duplicate queue items: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []domain.DuplicateMatch
+	for rows.Next() {
+		existing, scanErr := scanReleaseWithQueue(rows)
+		if scanErr != nil {
+			return nil, fmt.Errorf("failed to scan duplicate queue row: %w", scanErr)
+		}
+
+		key := domain.DuplicateKeyTitle
+		switch {
+		case existing.SourceKind == item.SourceKind && existing.SourceReleaseID == item.SourceReleaseID:
+			key = domain.DuplicateKeyReleaseID
+		case item.NZBHash != "" && existing.NZBHash == item.NZBHash:
+			key = domain.DuplicateKeyNZBHash
+		}
+		matches = append(matches, domain.DuplicateMatch{Item: existing, Key: key})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("duplicate queue rows iteration error: %w", err)
+	}
+
+	return matches, nil
+}
+
 // GetQueueItems returns all items in the queue, ordered by creation date.
 func (s *Store) GetQueueItems(ctx context.Context) ([]*domain.QueueItem, error) {
 	query := `
@@ -199,13 +272,14 @@ func (s *Store) GetQueueItem(ctx context.Context, id string) (*domain.QueueItem,
 	return item, nil
 }
 
-// GetActiveQueueItems returns all jobs that are not in a terminal state (Completed/Failed).
+// GetActiveQueueItems returns all jobs that are not in a terminal state (Completed/Failed),
+// in dispatch order: priority first, then sort position.
 func (s *Store) GetActiveQueueItems(ctx context.Context) ([]*domain.QueueItem, error) {
 	query := `
 		SELECT ` + queueSelectColumns + `
 		FROM queue_items q
-		WHERE q.status NOT IN ('completed', 'failed')
-		ORDER BY q.created_at ASC`
+		WHERE q.status NOT IN ('completed', 'failed', 'password_required')
+		ORDER BY q.priority DESC, q.sort_position ASC, q.created_at ASC`
 
 	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
@@ -229,6 +303,36 @@ func (s *Store) GetActiveQueueItems(ctx context.Context) ([]*domain.QueueItem, e
 	return items, nil
 }
 
+// UpdateQueueItemOrder persists priority and sort position for a reordered queue in one transaction.
+func (s *Store) UpdateQueueItemOrder(ctx context.Context, items []*domain.QueueItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `UPDATE queue_items SET priority = ?, sort_position = ? WHERE id = ?`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		if _, err := stmt.ExecContext(ctx, int(item.Priority), item.SortPosition, item.ID); err != nil {
+			return fmt.Errorf("update order for queue item %s: %w", item.ID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
 func (s *Store) DeleteQueueItems(ctx context.Context, ids []string) (int64, error) {
 	if len(ids) == 0 {
 		return 0, nil
@@ -251,7 +355,7 @@ func (s *Store) DeleteQueueItems(ctx context.Context, ids []string) (int64, erro
 
 func (s *Store) ClearQueueHistory(ctx context.Context, statuses []domain.JobStatus) (int64, error) {
 	if len(statuses) == 0 {
-		statuses = []domain.JobStatus{domain.StatusCompleted, domain.StatusFailed}
+		statuses = []domain.JobStatus{domain.StatusCompleted, domain.StatusFailed, domain.StatusPasswordRequired}
 	}
 
 	placeholders := make([]string, len(statuses))
@@ -230,5 +230,66 @@ func (s *Store) GetQueueItemFiles(ctx context.Context, queueItemID string) ([]*d
 		return nil, fmt.Errorf("iterate queue_file_set_items rows: %w", err)
 	}
 
+	missing, err := s.getMissingSegments(ctx, queueItemID)
+	if err != nil {
+		return nil, err
+	}
+	skipped, err := s.getSkippedFiles(ctx, queueItemID)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		f.MissingSegments = missing[f.Index]
+		_, f.Skipped = skipped[f.Index]
+	}
+
 	return files, nil
 }
+
+// SetQueueItemFilesSkipped records which files of a queue item were never
+// downloaded, e.g. PAR2 recovery volumes that verification did not need. The
+// flags belong to the queue item, not to the file set it may share with
+// other items added from the same NZB.
+func (s *Store) SetQueueItemFilesSkipped(ctx context.Context, queueItemID string, fileIndexes []int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM queue_item_skipped_files WHERE queue_id = ?`, queueItemID); err != nil {
+		return fmt.Errorf("clear skipped files: %w", err)
+	}
+
+	for _, idx := range fileIndexes {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT OR IGNORE INTO queue_item_skipped_files (queue_id, file_index)
+			VALUES (?, ?)`, queueItemID, idx); err != nil {
+			return fmt.Errorf("mark file %d skipped: %w", idx, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// getSkippedFiles returns the file indexes held back for a queue item.
+func (s *Store) getSkippedFiles(ctx context.Context, queueItemID string) (map[int]struct{}, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT file_index
+		FROM queue_item_skipped_files
+		WHERE queue_id = ?`, queueItemID)
+	if err != nil {
+		return nil, fmt.Errorf("query skipped files: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[int]struct{})
+	for rows.Next() {
+		var fileIndex int
+		if err := rows.Scan(&fileIndex); err != nil {
+			return nil, fmt.Errorf("scan skipped files: %w", err)
+		}
+		out[fileIndex] = struct{}{}
+	}
+	return out, rows.Err()
+}
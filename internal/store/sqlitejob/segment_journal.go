@@ -0,0 +1,77 @@
+package sqlitejob
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/datallboy/gonzb/internal/domain"
+)
+
+// SaveSegmentJournals upserts the resume bitmaps for the given files of a queue item.
+func (s *Store) SaveSegmentJournals(ctx context.Context, queueItemID string, journals []*domain.SegmentJournal) error {
+	if len(journals) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO queue_item_segment_journal (queue_id, file_index, bitmap, actual_size, updated_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(queue_id, file_index) DO UPDATE SET
+			bitmap = excluded.bitmap,
+			actual_size = excluded.actual_size,
+			updated_at = excluded.updated_at`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, journal := range journals {
+		if journal == nil {
+			continue
+		}
+		if _, err := stmt.ExecContext(ctx, queueItemID, journal.FileIndex, journal.Bitmap, journal.ActualSize); err != nil {
+			return fmt.Errorf("save segment journal for file %d: %w", journal.FileIndex, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetSegmentJournals returns the persisted resume bitmaps for a queue item.
+func (s *Store) GetSegmentJournals(ctx context.Context, queueItemID string) ([]*domain.SegmentJournal, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT file_index, bitmap, actual_size
+		FROM queue_item_segment_journal
+		WHERE queue_id = ?
+		ORDER BY file_index ASC`, queueItemID)
+	if err != nil {
+		return nil, fmt.Errorf("query segment journal: %w", err)
+	}
+	defer rows.Close()
+
+	journals := make([]*domain.SegmentJournal, 0)
+	for rows.Next() {
+		journal := &domain.SegmentJournal{}
+		if err := rows.Scan(&journal.FileIndex, &journal.Bitmap, &journal.ActualSize); err != nil {
+			return nil, fmt.Errorf("scan segment journal row: %w", err)
+		}
+		journals = append(journals, journal)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate segment journal rows: %w", err)
+	}
+
+	return journals, nil
+}
+
+// DeleteSegmentJournals drops resume state once a queue item reaches a terminal state.
+func (s *Store) DeleteSegmentJournals(ctx context.Context, queueItemID string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM queue_item_segment_journal WHERE queue_id = ?`, queueItemID)
+	return err
+}
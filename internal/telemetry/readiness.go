@@ -162,10 +162,16 @@ func evaluateRuntimeModule(ctx context.Context, module app.RuntimeModule) Module
 	runtimeChecks := module.ReadinessChecks(ctx)
 	checks := make([]Check, 0, len(runtimeChecks))
 	ready := true
+	degraded := false
 
 	for _, runtimeCheck := range runtimeChecks {
 		status := "ok"
-		if !runtimeCheck.OK {
+		switch {
+		case runtimeCheck.OK:
+		case runtimeCheck.Advisory:
+			status = "degraded"
+			degraded = true
+		default:
 			status = "fail"
 			ready = false
 		}
@@ -176,10 +182,15 @@ func evaluateRuntimeModule(ctx context.Context, module app.RuntimeModule) Module
 		})
 	}
 
+	moduleStatus := readyStatus(ready)
+	if ready && degraded {
+		moduleStatus = "degraded"
+	}
+
 	return ModuleStatus{
 		Enabled: true,
 		Ready:   ready,
-		Status:  readyStatus(ready),
+		Status:  moduleStatus,
 		Checks:  checks,
 	}
 }
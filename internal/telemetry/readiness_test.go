@@ -52,6 +52,40 @@ func TestReadinessUsesRuntimeModuleChecks(t *testing.T) {
 	}
 }
 
+func TestReadinessReportsAdvisoryFailuresAsDegraded(t *testing.T) {
+	appCtx := &app.Context{
+		Config: &config.Config{
+			Modules: config.ModulesConfig{
+				API:        config.ModuleToggle{Enabled: true},
+				Downloader: config.ModuleToggle{Enabled: true},
+			},
+		},
+	}
+
+	appCtx.RegisterRuntimeModules(
+		fakeRuntimeModule{
+			name:    "downloader",
+			enabled: true,
+			checks: []app.RuntimeCheck{
+				{Name: "queue_manager", OK: true},
+				{Name: "disk_space", OK: false, Detail: "low disk space", Advisory: true},
+			},
+		},
+	)
+
+	code, report := Readiness(context.Background(), appCtx)
+	if code != 200 {
+		t.Fatalf("expected an advisory failure to keep readiness, got %d", code)
+	}
+	downloader := report.Modules["downloader"]
+	if !downloader.Ready || downloader.Status != "degraded" {
+		t.Fatalf("expected downloader ready but degraded, got %+v", downloader)
+	}
+	if downloader.Checks[1].Status != "degraded" || downloader.Checks[1].Detail == "" {
+		t.Fatalf("expected degraded disk_space check with detail, got %#v", downloader.Checks[1])
+	}
+}
+
 type fakeRuntimeModule struct {
 	name    string
 	enabled bool
@@ -1,11 +1,15 @@
 import { useEffect, useState } from 'react'
 import type { FormEvent, ReactNode } from 'react'
 import { Link } from 'react-router-dom'
-import { getCapabilities, getSettings, updateSettings } from '../../shared/api/settings'
+import { getAggregatorSourceHealth, getCapabilities, getSettings, updateSettings } from '../../shared/api/settings'
+import { formatDateTime } from '../../shared/lib/format'
 import type {
   AdminStageConfigPatch,
+  AggregatorSourceHealth,
   ArrIntegrationRuntimeSettings,
   ControlPlaneCapabilities,
+  DownloadCategoryRuntimeSettings,
+  DownloadSpeedScheduleRuntimeSettings,
   IndexerRuntimeSettings,
   IndexingRuntimeSettings,
   RuntimeSettings,
@@ -101,6 +105,7 @@ const nntpProviderRoles = [
   { key: 'yenc_recovery', label: 'yEnc recovery' },
   { key: 'inspection', label: 'Inspection' },
   { key: 'download', label: 'Download' },
+  { key: 'backup', label: 'Backup' },
 ]
 
 function defaultSettings(): RuntimeSettings {
@@ -114,6 +119,21 @@ function defaultSettings(): RuntimeSettings {
       out_dir: './downloads',
       completed_dir: './downloads/completed',
       cleanup_extensions: ['nzb', 'par2', 'sfv', 'nfo'],
+      max_active_downloads: 1,
+      speed_limit_kbps: 0,
+      speed_schedules: [],
+      availability_check: false,
+      availability_sample: 100,
+      categories: [categoryDefaults('movies'), categoryDefaults('tv')],
+      direct_unpack: false,
+      pre_queue_script: '',
+      script_timeout_seconds: 600,
+      archive_passwords: [],
+      min_free_incomplete_mb: 0,
+      min_free_complete_mb: 0,
+      unpack_headroom_percent: 100,
+      watch_dirs: [],
+      watch_interval_seconds: 5,
     },
     nntp_pool: {
       idle_borrow_enabled: true,
@@ -267,6 +287,8 @@ function normalizeSettings(input?: RuntimeSettings): RuntimeSettings {
       ...defaults.download!,
       ...input?.download,
       cleanup_extensions: input?.download?.cleanup_extensions ?? defaults.download!.cleanup_extensions,
+      speed_schedules: input?.download?.speed_schedules ?? [],
+      categories: input?.download?.categories ?? defaults.download!.categories,
     },
     nntp_pool: {
       ...defaults.nntp_pool!,
@@ -347,6 +369,14 @@ function arrDefaults(index: number): ArrIntegrationRuntimeSettings {
   return { id: `arr-${index + 1}`, kind: 'sonarr', enabled: false, base_url: '', api_key: '', client_name: '', category: '' }
 }
 
+function speedScheduleDefaults(): DownloadSpeedScheduleRuntimeSettings {
+  return { days: [], start: '09:00', end: '17:00', limit_kbps: 2048 }
+}
+
+function categoryDefaults(name = ''): DownloadCategoryRuntimeSettings {
+  return { name, completed_dir: '', post_processing: 'delete', priority: 'normal', cleanup_extensions: null, script: '', passwords: [], duplicate_policy: 'allow' }
+}
+
 function fieldNumber(value: string) {
   return Number.isFinite(Number(value)) ? Number(value) : 0
 }
@@ -516,13 +546,20 @@ export function AdminSettingsPage() {
   const [showAdvanced, setShowAdvanced] = useState(false)
   const [message, setMessage] = useState<string | null>(null)
   const [error, setError] = useState<string | null>(null)
+  const [sourceHealth, setSourceHealth] = useState<Record<string, AggregatorSourceHealth>>({})
 
   async function refresh() {
     try {
-      const [nextSettings, nextCapabilities] = await Promise.all([getSettings(), getCapabilities()])
+      const [nextSettings, nextCapabilities, nextHealth] = await Promise.all([
+        getSettings(),
+        getCapabilities(),
+        // Health is only served while the aggregator module runs.
+        getAggregatorSourceHealth().catch(() => null),
+      ])
       const normalized = normalizeSettings(nextSettings as RuntimeSettings)
       setSettings(normalized)
       setCapabilities(nextCapabilities as ControlPlaneCapabilities)
+      setSourceHealth(Object.fromEntries((nextHealth?.items ?? []).map((item) => [item.name, item])))
       setError(null)
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Failed to load runtime settings')
@@ -557,6 +594,8 @@ export function AdminSettingsPage() {
   const indexing = normalized.indexing!
   const aggregator = normalized.aggregator!
   const download = normalized.download!
+  const speedSchedules = download.speed_schedules ?? []
+  const categories = download.categories ?? []
   const nntpPool = normalized.nntp_pool!
   const servers = normalized.servers ?? []
   const indexers = normalized.indexers ?? []
@@ -622,9 +661,194 @@ export function AdminSettingsPage() {
               <TextField label="Output directory" value={download.out_dir} onChange={(value) => setSettings((current) => ({ ...current, download: { ...download, out_dir: value } }))} />
               <TextField label="Completed directory" value={download.completed_dir} onChange={(value) => setSettings((current) => ({ ...current, download: { ...download, completed_dir: value } }))} />
               <TextField label="Cleanup extensions" value={cleanupExtensionsText(download.cleanup_extensions)} onChange={(value) => setSettings((current) => ({ ...current, download: { ...download, cleanup_extensions: parseCleanupExtensions(value) } }))} />
+              <TextField
+                label="Watch folders"
+                value={(download.watch_dirs ?? []).join(', ')}
+                onChange={(value) => setSettings((current) => ({ ...current, download: { ...download, watch_dirs: parseCSV(value) } }))}
+                helpText="Comma-separated folders polled for .nzb, .nzb.gz and .zip files. A subfolder's name selects the category."
+              />
+              <NumberField
+                label="Watch interval (seconds)"
+                min={0}
+                value={download.watch_interval_seconds ?? 5}
+                onChange={(value) => setSettings((current) => ({ ...current, download: { ...download, watch_interval_seconds: value } }))}
+                helpText="Time between watch folder scans. 0 uses the 5 second default."
+              />
             </div>
           </SettingsSection>
 
+          <SettingsSection title="Queue">
+            <div className="toolbar-grid">
+              <NumberField
+                label="Concurrent downloads"
+                min={1}
+                value={download.max_active_downloads ?? 1}
+                onChange={(value) => setSettings((current) => ({ ...current, download: { ...download, max_active_downloads: value } }))}
+                helpText="Queue items downloaded at the same time. Items in post-processing do not count."
+              />
+              <NumberField
+                label="Speed limit (KB/s)"
+                min={0}
+                value={download.speed_limit_kbps ?? 0}
+                onChange={(value) => setSettings((current) => ({ ...current, download: { ...download, speed_limit_kbps: value } }))}
+                helpText="Global download cap outside scheduled windows. 0 means unlimited."
+              />
+              <CheckboxField
+                label="Check availability"
+                checked={Boolean(download.availability_check)}
+                onChange={(value) => setSettings((current) => ({ ...current, download: { ...download, availability_check: value } }))}
+                helpText="STAT a sample of each item's articles on every provider before downloading."
+              />
+              <NumberField
+                label="Availability sample"
+                min={0}
+                value={download.availability_sample ?? 100}
+                onChange={(value) => setSettings((current) => ({ ...current, download: { ...download, availability_sample: value } }))}
+                helpText="Articles checked per item. 0 checks every segment."
+              />
+              <CheckboxField
+                label="Direct unpack"
+                checked={Boolean(download.direct_unpack)}
+                onChange={(value) => setSettings((current) => ({ ...current, download: { ...download, direct_unpack: value } }))}
+                helpText="Extract RAR sets while the rest of the release downloads."
+              />
+              <TextField
+                label="Pre-queue script"
+                value={download.pre_queue_script ?? ''}
+                onChange={(value) => setSettings((current) => ({ ...current, download: { ...download, pre_queue_script: value } }))}
+                helpText="SABnzbd-style script in download.scripts_dir that can accept, reject, rename or reprioritise NZBs before they are queued. Limited to 30 seconds."
+              />
+              <NumberField
+                label="Script timeout (seconds)"
+                min={0}
+                value={download.script_timeout_seconds ?? 600}
+                onChange={(value) => setSettings((current) => ({ ...current, download: { ...download, script_timeout_seconds: value } }))}
+                helpText="Limit for post-processing scripts. 0 uses the 10 minute default."
+              />
+              <TextField
+                label="Archive passwords"
+                value={(download.archive_passwords ?? []).join(', ')}
+                onChange={(value) => setSettings((current) => ({ ...current, download: { ...download, archive_passwords: parseCSV(value) } }))}
+                helpText="Comma-separated, tried in order after the NZB's own password and the category's list."
+              />
+              <NumberField
+                label="Min free incomplete (MB)"
+                min={0}
+                value={download.min_free_incomplete_mb ?? 0}
+                onChange={(value) => setSettings((current) => ({ ...current, download: { ...download, min_free_incomplete_mb: value } }))}
+                helpText="The queue pauses while the output directory has less free space. 0 disables the check."
+              />
+              <NumberField
+                label="Min free complete (MB)"
+                min={0}
+                value={download.min_free_complete_mb ?? 0}
+                onChange={(value) => setSettings((current) => ({ ...current, download: { ...download, min_free_complete_mb: value } }))}
+                helpText="The queue pauses while the completed directory has less free space. 0 disables the check."
+              />
+              <NumberField
+                label="Unpack headroom (%)"
+                min={0}
+                value={download.unpack_headroom_percent ?? 100}
+                onChange={(value) => setSettings((current) => ({ ...current, download: { ...download, unpack_headroom_percent: value } }))}
+                helpText="Extra space, as a share of the release size, a new item needs in the output directory for unpacking. Only checked when a minimum free space is set."
+              />
+            </div>
+          </SettingsSection>
+
+          <SettingsSection
+            title="Speed schedules"
+            onAdd={() => setSettings((current) => ({ ...current, download: { ...download, speed_schedules: [...speedSchedules, speedScheduleDefaults()] } }))}
+          >
+            {speedSchedules.map((schedule, index) => (
+              <div className="settings-row stack" key={index}>
+                <div className="button-row">
+                  <strong>Window {index + 1}</strong>
+                  <RemoveButton onClick={() => setSettings((current) => ({ ...current, download: { ...download, speed_schedules: speedSchedules.filter((_, i) => i !== index) } }))} />
+                </div>
+                <div className="toolbar-grid">
+                  <TextField label="Days" value={schedule.days.join(', ')} onChange={(value) => updateSpeedSchedule(index, { days: parseCSV(value) })} />
+                  <TextField label="Start (HH:MM)" value={schedule.start} required onChange={(value) => updateSpeedSchedule(index, { start: value })} />
+                  <TextField label="End (HH:MM)" value={schedule.end} required onChange={(value) => updateSpeedSchedule(index, { end: value })} />
+                  <NumberField
+                    label="Limit (KB/s)"
+                    min={0}
+                    value={schedule.limit_kbps}
+                    onChange={(value) => updateSpeedSchedule(index, { limit_kbps: value })}
+                    helpText="Leave days empty for every day. 0 means unlimited."
+                  />
+                </div>
+              </div>
+            ))}
+          </SettingsSection>
+
+          <SettingsSection
+            title="Categories"
+            onAdd={() => setSettings((current) => ({ ...current, download: { ...download, categories: [...categories, categoryDefaults()] } }))}
+          >
+            {categories.map((category, index) => (
+              <div className="settings-row stack" key={index}>
+                <div className="button-row">
+                  <strong>{category.name || `Category ${index + 1}`}</strong>
+                  <RemoveButton onClick={() => setSettings((current) => ({ ...current, download: { ...download, categories: categories.filter((_, i) => i !== index) } }))} />
+                </div>
+                <div className="toolbar-grid">
+                  <TextField label="Name" value={category.name} required onChange={(value) => updateCategory(index, { name: value })} />
+                  <TextField
+                    label="Completed dir"
+                    value={category.completed_dir}
+                    onChange={(value) => updateCategory(index, { completed_dir: value })}
+                    helpText="Leave empty for <completed dir>/<name>."
+                  />
+                  <label>
+                    <span>Post-processing</span>
+                    <select value={category.post_processing || 'delete'} onChange={(event) => updateCategory(index, { post_processing: event.target.value })}>
+                      <option value="none">None</option>
+                      <option value="repair">Repair</option>
+                      <option value="unpack">Repair + unpack</option>
+                      <option value="delete">Repair + unpack + delete</option>
+                    </select>
+                  </label>
+                  <label>
+                    <span>Default priority</span>
+                    <select value={category.priority || 'normal'} onChange={(event) => updateCategory(index, { priority: event.target.value })}>
+                      <option value="low">Low</option>
+                      <option value="normal">Normal</option>
+                      <option value="high">High</option>
+                      <option value="force">Force</option>
+                    </select>
+                  </label>
+                  <label>
+                    <span>Duplicates</span>
+                    <select value={category.duplicate_policy || 'allow'} onChange={(event) => updateCategory(index, { duplicate_policy: event.target.value })}>
+                      <option value="allow">Allow (flag only)</option>
+                      <option value="pause">Pause for review</option>
+                      <option value="reject">Reject</option>
+                      <option value="replace">Replace if larger</option>
+                    </select>
+                  </label>
+                  <TextField
+                    label="Cleanup extensions"
+                    value={(category.cleanup_extensions ?? []).join(', ')}
+                    onChange={(value) => updateCategory(index, { cleanup_extensions: value.trim() === '' ? null : parseCSV(value) })}
+                    helpText="Leave empty to use the global cleanup list."
+                  />
+                  <TextField
+                    label="Post-processing script"
+                    value={category.script ?? ''}
+                    onChange={(value) => updateCategory(index, { script: value })}
+                    helpText="Script in download.scripts_dir run after each job with SABnzbd arguments and SAB_* variables."
+                  />
+                  <TextField
+                    label="Archive passwords"
+                    value={(category.passwords ?? []).join(', ')}
+                    onChange={(value) => updateCategory(index, { passwords: parseCSV(value) })}
+                    helpText="Comma-separated, tried before the global archive passwords."
+                  />
+                </div>
+              </div>
+            ))}
+          </SettingsSection>
+
           <SettingsSection
             title="ARR integrations"
             locked={lockArr}
@@ -744,8 +968,16 @@ export function AdminSettingsPage() {
                   <TextField label="Base URL" value={indexer.base_url} required onChange={(value) => updateIndexer(index, { base_url: value })} />
                   <TextField label="API path" value={indexer.api_path} required onChange={(value) => updateIndexer(index, { api_path: value })} />
                   <TextField label="API key" type="password" value={indexer.api_key} onChange={(value) => updateIndexer(index, { api_key: value })} />
+                  <NumberField
+                    label="Search timeout (seconds)"
+                    min={0}
+                    value={indexer.timeout_seconds ?? 0}
+                    onChange={(value) => updateIndexer(index, { timeout_seconds: value })}
+                    helpText="0 uses the 10 second default. Failing sources back off automatically."
+                  />
                   <CheckboxField label="Redirect downloads" checked={indexer.redirect} onChange={(value) => updateIndexer(index, { redirect: value })} />
                 </div>
+                <SourceHealthSummary health={sourceHealth[indexer.id]} />
               </div>
             ))}
           </SettingsSection>
@@ -1357,6 +1589,14 @@ export function AdminSettingsPage() {
   function updateArr(index: number, patch: Partial<ArrIntegrationRuntimeSettings>) {
     setSettings((current) => ({ ...current, arr_integrations: arrIntegrations.map((item, i) => (i === index ? { ...item, ...patch } : item)) }))
   }
+
+  function updateSpeedSchedule(index: number, patch: Partial<DownloadSpeedScheduleRuntimeSettings>) {
+    setSettings((current) => ({ ...current, download: { ...download, speed_schedules: speedSchedules.map((item, i) => (i === index ? { ...item, ...patch } : item)) } }))
+  }
+
+  function updateCategory(index: number, patch: Partial<DownloadCategoryRuntimeSettings>) {
+    setSettings((current) => ({ ...current, download: { ...download, categories: categories.map((item, i) => (i === index ? { ...item, ...patch } : item)) } }))
+  }
 }
 
 function capabilityRequirements(capabilities: ControlPlaneCapabilities | null) {
@@ -1520,6 +1760,28 @@ function TextField({
   )
 }
 
+function SourceHealthSummary({ health }: { health?: AggregatorSourceHealth }) {
+  if (!health || health.searches === 0) {
+    return <div className="muted-copy">No searches recorded yet.</div>
+  }
+  const parts = [
+    `Health: ${health.state}`,
+    `${health.searches} searches`,
+    `${Math.round(health.error_rate * 100)}% errors`,
+    `avg ${health.avg_latency_ms} ms`,
+    `last success ${health.last_success_at ? formatDateTime(health.last_success_at) : 'never'}`,
+  ]
+  if (health.backoff_until) {
+    parts.push(`skipped until ${formatDateTime(health.backoff_until)}`)
+  }
+  return (
+    <div className="muted-copy">
+      {parts.join(' · ')}
+      {health.last_error && health.consecutive_failures > 0 ? <div>Last error: {health.last_error}</div> : null}
+    </div>
+  )
+}
+
 function NumberField({
   label,
   value,
@@ -1,6 +1,6 @@
 import { useEffect, useMemo, useState } from 'react'
 import { Link, useParams } from 'react-router-dom'
-import { enqueueReleaseToDownloader, getPublicRelease } from '../../shared/api/indexer'
+import { checkReleaseAvailability, enqueueReleaseToDownloader, getPublicRelease } from '../../shared/api/indexer'
 import { formatBytes, formatDateTime, formatRuntime } from '../../shared/lib/format'
 import type { PublicReleaseDetail } from '../../shared/types'
 import { releaseCategoryLabel, simpleSceneName } from './browse'
@@ -19,6 +19,7 @@ export function IndexerReleaseDetailPage() {
   const [error, setError] = useState<string | null>(null)
   const [downloadMessage, setDownloadMessage] = useState<string | null>(null)
   const [downloading, setDownloading] = useState(false)
+  const [checking, setChecking] = useState(false)
 
   useEffect(() => {
     let cancelled = false
@@ -59,6 +60,23 @@ export function IndexerReleaseDetailPage() {
     }
   }
 
+  async function handleCheckAvailability() {
+    if (!data) return
+    setChecking(true)
+    setDownloadMessage(null)
+    try {
+      const report = await checkReleaseAvailability(data.release.release_id)
+      const providers = report.providers.map((p) => (p.error ? `${p.provider} error` : `${p.provider} ${p.percent.toFixed(1)}%`))
+      setDownloadMessage(
+        `${report.percent.toFixed(1)}% of ${report.checked} sampled articles available${providers.length ? ` (${providers.join(', ')})` : ''}.`,
+      )
+    } catch (err) {
+      setDownloadMessage(err instanceof Error ? err.message : 'Failed to check availability')
+    } finally {
+      setChecking(false)
+    }
+  }
+
   const simpleName = useMemo(() => (data ? simpleSceneName(data.release.title) : ''), [data])
 
   if (loading) {
@@ -84,6 +102,11 @@ export function IndexerReleaseDetailPage() {
           <Link className="secondary-button" to="/indexer/releases">
             Back to browse
           </Link>
+          {capabilities.can_send_to_downloader ? (
+            <button className="secondary-button" onClick={handleCheckAvailability} disabled={checking}>
+              {checking ? 'Checking...' : 'Check availability'}
+            </button>
+          ) : null}
           {capabilities.can_send_to_downloader ? (
             <button className="primary-button" onClick={handleSendToDownloader} disabled={downloading}>
               {downloading ? 'Sending...' : 'Download NZB'}
@@ -1,5 +1,5 @@
 import { apiRequest } from './http'
-import type { PublicReleaseDetail, PublicReleaseListResponse } from '../types'
+import type { AvailabilityReport, PublicReleaseDetail, PublicReleaseListResponse } from '../types'
 
 export function listPublicReleases(params: Record<string, string | number>) {
   const query = new URLSearchParams()
@@ -25,3 +25,13 @@ export function enqueueReleaseToDownloader(releaseID: string, title: string) {
     },
   })
 }
+
+export function checkReleaseAvailability(releaseID: string) {
+  return apiRequest<AvailabilityReport>('/api/v1/queue/availability', {
+    method: 'POST',
+    body: {
+      source_kind: 'usenet_index',
+      release_id: releaseID,
+    },
+  })
+}
@@ -1,4 +1,5 @@
 import { apiRequest } from './http'
+import type { AggregatorSourceHealthResponse } from '../types'
 
 export function getSettings() {
   return apiRequest<Record<string, unknown>>('/api/v1/admin/settings')
@@ -11,3 +12,7 @@ export function getCapabilities() {
 export function updateSettings(body: Record<string, unknown>) {
   return apiRequest<Record<string, unknown>>('/api/v1/admin/settings', { method: 'PUT', body })
 }
+
+export function getAggregatorSourceHealth() {
+  return apiRequest<AggregatorSourceHealthResponse>('/api/v1/admin/aggregator/sources')
+}
@@ -1352,12 +1352,67 @@ export type IndexerRuntimeSettings = {
   api_path: string
   api_key: string
   redirect: boolean
+  timeout_seconds?: number
+}
+
+export type AggregatorSourceHealth = {
+  name: string
+  state: 'healthy' | 'degraded' | 'backoff'
+  timeout_ms: number
+  searches: number
+  failures: number
+  error_rate: number
+  consecutive_failures: number
+  last_latency_ms: number
+  avg_latency_ms: number
+  last_success_at?: string | null
+  last_failure_at?: string | null
+  last_error?: string
+  backoff_until?: string | null
+}
+
+export type AggregatorSourceHealthResponse = {
+  items: AggregatorSourceHealth[]
+  count: number
 }
 
 export type DownloadRuntimeSettings = {
   out_dir: string
   completed_dir: string
   cleanup_extensions: string[]
+  max_active_downloads?: number
+  speed_limit_kbps?: number
+  speed_schedules?: DownloadSpeedScheduleRuntimeSettings[]
+  availability_check?: boolean
+  availability_sample?: number
+  categories?: DownloadCategoryRuntimeSettings[]
+  direct_unpack?: boolean
+  pre_queue_script?: string
+  script_timeout_seconds?: number
+  archive_passwords?: string[]
+  min_free_incomplete_mb?: number
+  min_free_complete_mb?: number
+  unpack_headroom_percent?: number
+  watch_dirs?: string[]
+  watch_interval_seconds?: number
+}
+
+export type DownloadCategoryRuntimeSettings = {
+  name: string
+  completed_dir: string
+  post_processing: string
+  priority: string
+  cleanup_extensions: string[] | null
+  script: string
+  passwords?: string[]
+  duplicate_policy?: string
+}
+
+export type DownloadSpeedScheduleRuntimeSettings = {
+  days: string[]
+  start: string
+  end: string
+  limit_kbps: number
 }
 
 export type NNTPPoolRuntimeSettings = {
@@ -1419,3 +1474,20 @@ export type ControlPlaneCapabilities = {
   }
   revision?: number
 }
+
+export type ProviderAvailability = {
+  provider_id: string
+  provider: string
+  backup?: boolean
+  checked: number
+  available: number
+  percent: number
+  error?: string
+}
+
+export type AvailabilityReport = {
+  checked: number
+  available: number
+  percent: number
+  providers: ProviderAvailability[]
+}